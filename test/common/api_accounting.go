@@ -0,0 +1,266 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package common
+
+import (
+	"cmp"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	"k8s.io/client-go/transport"
+)
+
+const apiRequestsReportEntry = "API requests"
+
+// APIRequestStats are the requests of a verb on a resource of a cluster counted by APIAccounting.
+type APIRequestStats struct {
+	Cluster  string `json:"cluster"`
+	Verb     string `json:"verb"`
+	Resource string `json:"resource"`
+	Count    int    `json:"count"`
+	// Errors are the requests which failed or had an error status code.
+	Errors       int           `json:"errors"`
+	TotalLatency time.Duration `json:"totalLatency"`
+	MaxLatency   time.Duration `json:"maxLatency"`
+}
+
+// AverageLatency is the average time until the response headers were received.
+func (s APIRequestStats) AverageLatency() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+
+	return s.TotalLatency / time.Duration(s.Count)
+}
+
+// APIRequests are the stats of APIAccounting, sorted from the most to the least requests.
+type APIRequests []APIRequestStats
+
+// Total returns the number of requests.
+func (r APIRequests) Total() int {
+	total := 0
+
+	for _, stats := range r {
+		total += stats.Count
+	}
+
+	return total
+}
+
+// String renders the requests as a table.
+func (r APIRequests) String() string {
+	builder := &strings.Builder{}
+	writer := tabwriter.NewWriter(builder, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(writer, "CLUSTER\tVERB\tRESOURCE\tCOUNT\tERRORS\tAVG LATENCY\tMAX LATENCY")
+
+	for _, stats := range r {
+		fmt.Fprintf(
+			writer, "%s\t%s\t%s\t%d\t%d\t%s\t%s\n",
+			stats.Cluster, stats.Verb, stats.Resource, stats.Count, stats.Errors,
+			stats.AverageLatency().Round(time.Microsecond), stats.MaxLatency.Round(time.Microsecond),
+		)
+	}
+
+	fmt.Fprintf(writer, "TOTAL\t\t\t%d\t\t\t\n", r.Total())
+	writer.Flush()
+
+	lines := strings.Split(strings.TrimSuffix(builder.String(), "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+type apiRequestKey struct {
+	cluster  string
+	verb     string
+	resource string
+}
+
+// APIAccounting counts the requests of the clients whose transport it wraps, by cluster, verb, and
+// resource, and records their latency.
+type APIAccounting struct {
+	stats map[apiRequestKey]*APIRequestStats
+	lock  sync.Mutex
+}
+
+// NewAPIAccounting returns an APIAccounting without any requests.
+func NewAPIAccounting() *APIAccounting {
+	return &APIAccounting{stats: map[apiRequestKey]*APIRequestStats{}}
+}
+
+// Wrapper returns a transport wrapper which counts the requests to the cluster of the given name.
+func (a *APIAccounting) Wrapper(cluster string) transport.WrapperFunc {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return &accountingTransport{accounting: a, cluster: cluster, next: next}
+	}
+}
+
+// Record counts a request.
+func (a *APIAccounting) Record(cluster, verb, resource string, latency time.Duration, failed bool) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	key := apiRequestKey{cluster: cluster, verb: verb, resource: resource}
+
+	stats, ok := a.stats[key]
+	if !ok {
+		stats = &APIRequestStats{Cluster: cluster, Verb: verb, Resource: resource}
+		a.stats[key] = stats
+	}
+
+	stats.Count++
+	stats.TotalLatency += latency
+	stats.MaxLatency = max(stats.MaxLatency, latency)
+
+	if failed {
+		stats.Errors++
+	}
+}
+
+// Requests returns the requests counted so far.
+func (a *APIAccounting) Requests() APIRequests {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	return a.requests()
+}
+
+// Reset returns the requests counted so far and starts counting from zero.
+func (a *APIAccounting) Reset() APIRequests {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	requests := a.requests()
+	a.stats = map[apiRequestKey]*APIRequestStats{}
+
+	return requests
+}
+
+func (a *APIAccounting) requests() APIRequests {
+	requests := make(APIRequests, 0, len(a.stats))
+
+	for _, stats := range a.stats {
+		requests = append(requests, *stats)
+	}
+
+	slices.SortFunc(requests, func(x, y APIRequestStats) int {
+		return cmp.Or(
+			cmp.Compare(y.Count, x.Count),
+			cmp.Compare(x.Cluster, y.Cluster),
+			cmp.Compare(x.Verb, y.Verb),
+			cmp.Compare(x.Resource, y.Resource),
+		)
+	})
+
+	return requests
+}
+
+// TrackAPIRequests counts the API requests of the clients of the Environment from now until the end
+// of the current spec, and adds them to its report. It's meant to be called in a top level
+// BeforeEach of the suite, so that the requests of the BeforeAll nodes are counted too.
+func (e *Environment) TrackAPIRequests() {
+	if e.APIAccounting == nil {
+		return
+	}
+
+	e.APIAccounting.Reset()
+
+	DeferCleanup(func() {
+		requests := e.APIAccounting.Reset()
+		if len(requests) != 0 {
+			AddReportEntry(apiRequestsReportEntry, requests, ReportEntryVisibilityFailureOrVerbose)
+		}
+	})
+}
+
+type accountingTransport struct {
+	accounting *APIAccounting
+	cluster    string
+	next       http.RoundTripper
+}
+
+func (t *accountingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+
+	resp, err := t.next.RoundTrip(req)
+
+	verb, resource := requestVerbAndResource(req)
+	t.accounting.Record(t.cluster, verb, resource, time.Since(start), err != nil || resp.StatusCode >= 400)
+
+	return resp, err
+}
+
+// requestVerbAndResource returns the Kubernetes verb of the request and its resource, in the format
+// of `resource.group/subresource`. The requests which aren't for a resource, like the discovery
+// requests, have their path as the resource.
+func requestVerbAndResource(req *http.Request) (string, string) {
+	parts := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+
+	var group string
+
+	switch {
+	case len(parts) >= 3 && parts[0] == "api":
+		parts = parts[2:]
+	case len(parts) >= 4 && parts[0] == "apis":
+		group = parts[1]
+		parts = parts[3:]
+	default:
+		return strings.ToLower(req.Method), req.URL.Path
+	}
+
+	// Skip the namespace of namespaced resources, but not the name of a namespace.
+	if len(parts) >= 3 && parts[0] == "namespaces" && !(len(parts) == 3 && isNamespaceSubresource(parts[2])) {
+		parts = parts[2:]
+	}
+
+	resource := parts[0]
+	if group != "" {
+		resource += "." + group
+	}
+
+	if len(parts) >= 3 {
+		resource += "/" + parts[2]
+	}
+
+	named := len(parts) >= 2
+
+	switch req.Method {
+	case http.MethodGet:
+		if req.URL.Query().Get("watch") == "true" {
+			return "watch", resource
+		}
+
+		if named {
+			return "get", resource
+		}
+
+		return "list", resource
+	case http.MethodPost:
+		return "create", resource
+	case http.MethodPut:
+		return "update", resource
+	case http.MethodPatch:
+		return "patch", resource
+	case http.MethodDelete:
+		if named {
+			return "delete", resource
+		}
+
+		return "deletecollection", resource
+	default:
+		return strings.ToLower(req.Method), resource
+	}
+}
+
+func isNamespaceSubresource(name string) bool {
+	return name == "status" || name == "finalize"
+}
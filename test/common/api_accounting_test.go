@@ -0,0 +1,95 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package common
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/stolostron/governance-policy-framework/test/common/kubeclient"
+)
+
+var _ = Describe("Test the API request accounting", func() {
+	DescribeTable("Identifies the verb and the resource of a request",
+		func(method, url, verb, resource string) {
+			req := httptest.NewRequest(method, url, nil)
+
+			actualVerb, actualResource := requestVerbAndResource(req)
+			Expect(actualVerb).To(Equal(verb))
+			Expect(actualResource).To(Equal(resource))
+		},
+		Entry("list", http.MethodGet, "/api/v1/namespaces/my-ns/pods", "list", "pods"),
+		Entry("get", http.MethodGet, "/api/v1/namespaces/my-ns/pods/my-pod", "get", "pods"),
+		Entry("get a namespace", http.MethodGet, "/api/v1/namespaces/my-ns", "get", "namespaces"),
+		Entry("finalize a namespace", http.MethodPut, "/api/v1/namespaces/my-ns/finalize", "update",
+			"namespaces/finalize"),
+		Entry("watch", http.MethodGet, "/apis/policy.open-cluster-management.io/v1/policies?watch=true",
+			"watch", "policies.policy.open-cluster-management.io"),
+		Entry("update the status", http.MethodPut,
+			"/apis/policy.open-cluster-management.io/v1/namespaces/my-ns/policies/my-policy/status",
+			"update", "policies.policy.open-cluster-management.io/status"),
+		Entry("create", http.MethodPost, "/apis/apps/v1/namespaces/my-ns/deployments", "create",
+			"deployments.apps"),
+		Entry("patch", http.MethodPatch, "/api/v1/configmaps/my-cm", "patch", "configmaps"),
+		Entry("delete", http.MethodDelete, "/api/v1/namespaces/my-ns/secrets/my-secret", "delete", "secrets"),
+		Entry("delete a collection", http.MethodDelete, "/api/v1/namespaces/my-ns/secrets", "deletecollection",
+			"secrets"),
+		Entry("discovery", http.MethodGet, "/apis", "get", "/apis"),
+	)
+
+	It("Counts the requests of a client by verb and resource", func(ctx SpecContext) {
+		requests := 0
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			requests++
+
+			if requests == 1 {
+				w.WriteHeader(http.StatusTooManyRequests)
+
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"apiVersion": "v1", "kind": "Namespace", "metadata": {"name": "my-ns"}}`))
+		}))
+		DeferCleanup(server.Close)
+
+		accounting := NewAPIAccounting()
+
+		options := kubeclient.DefaultClientOptions()
+		options.RetryBackoff = time.Millisecond
+		options.WrapTransport = accounting.Wrapper("hub")
+
+		config := &rest.Config{Host: server.URL}
+		options.Apply(config)
+
+		client, err := kubernetes.NewForConfig(config)
+		Expect(err).ToNot(HaveOccurred())
+
+		for range 2 {
+			_, err = client.CoreV1().Namespaces().Get(ctx, "my-ns", metav1.GetOptions{})
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		counted := accounting.Requests()
+		Expect(counted).To(HaveLen(1))
+		Expect(counted[0].Cluster).To(Equal("hub"))
+		Expect(counted[0].Verb).To(Equal("get"))
+		Expect(counted[0].Resource).To(Equal("namespaces"))
+		Expect(counted[0].Count).To(Equal(3))
+		Expect(counted[0].Errors).To(Equal(1))
+		Expect(counted.Total()).To(Equal(3))
+		Expect(counted.String()).To(MatchRegexp(`(?m)^hub\s+get\s+namespaces\s+3\s+1\s+\S+\s+\S+$`))
+		Expect(counted.String()).To(MatchRegexp(`(?m)^TOTAL\s+3$`))
+
+		Expect(accounting.Reset()).To(Equal(counted))
+		Expect(accounting.Requests()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,95 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package common
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clienttesting "k8s.io/client-go/testing"
+
+	"github.com/stolostron/governance-policy-framework/test/common/kubeclient"
+)
+
+var _ = Describe("Test the Applier", func() {
+	var cluster *FakeCluster
+	var applier *kubeclient.Applier
+	var forced []bool
+
+	configMap := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]any{"name": "my-cm", "namespace": "default"},
+		"data":       map[string]any{"key": "value"},
+	}}
+
+	// conflictWith makes the unforced apply requests fail with a conflict on a field of the manager.
+	conflictWith := func(manager string) {
+		cluster.ClientDynamic.PrependReactor("patch", "configmaps",
+			func(action clienttesting.Action) (bool, runtime.Object, error) {
+				options := action.(clienttesting.PatchActionImpl).PatchOptions
+				forced = append(forced, options.Force != nil && *options.Force)
+
+				Expect(options.FieldManager).To(Equal(kubeclient.ApplierFieldManager))
+
+				if options.Force != nil && *options.Force {
+					return false, nil, nil
+				}
+
+				return true, nil, k8serrors.NewApplyConflict([]metav1.StatusCause{{
+					Type:    metav1.CauseTypeFieldManagerConflict,
+					Message: `conflict with "` + manager + `" using v1`,
+					Field:   ".data.key",
+				}}, "Apply failed with 1 conflict")
+			},
+		)
+	}
+
+	BeforeEach(func() {
+		cluster = NewFakeCluster()
+		applier = kubeclient.NewApplier(cluster.ClientDynamic, cluster.Client.Discovery())
+		forced = nil
+	})
+
+	It("Doesn't force the apply requests without conflicts", func(ctx SpecContext) {
+		cluster.ClientDynamic.PrependReactor("patch", "configmaps",
+			func(action clienttesting.Action) (bool, runtime.Object, error) {
+				options := action.(clienttesting.PatchActionImpl).PatchOptions
+				forced = append(forced, options.Force != nil && *options.Force)
+
+				return false, nil, nil
+			},
+		)
+
+		results, err := applier.Apply(ctx, "", configMap)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results).To(HaveLen(1))
+		Expect(forced).To(Equal([]bool{false}))
+	})
+
+	DescribeTable("Overwrites the fields last set by the tests",
+		func(ctx SpecContext, manager string) {
+			conflictWith(manager)
+
+			results, err := applier.Apply(ctx, "", configMap)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results[0].Object.Object["data"]).To(Equal(map[string]any{"key": "value"}))
+			Expect(forced).To(Equal([]bool{false, true}))
+		},
+		Entry("client-side kubectl apply", "kubectl-client-side-apply"),
+		Entry("kubectl patch", "kubectl-patch"),
+		Entry("the Applier", kubeclient.ApplierFieldManager),
+	)
+
+	It("Returns the conflicts with the fields of the controllers", func(ctx SpecContext) {
+		conflictWith("config-policy-controller")
+
+		_, err := applier.Apply(ctx, "", configMap)
+		Expect(k8serrors.IsConflict(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("failed to apply ConfigMap default/my-cm")))
+		Expect(forced).To(Equal([]bool{false}))
+	})
+})
@@ -0,0 +1,336 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package common
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	. "github.com/onsi/ginkgo/v2"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// Capability is a feature of the clusters which specs depend on.
+type Capability string
+
+const (
+	// CapabilityOpenShift is whether the managed cluster is an OpenShift cluster.
+	CapabilityOpenShift Capability = "OpenShift"
+	// CapabilityHostedMode is whether the policy templates are hosted on the hub.
+	CapabilityHostedMode Capability = "HostedMode"
+	// CapabilityMCE is whether MCE is installed on the hub.
+	CapabilityMCE Capability = "MCE"
+	// CapabilityOLM is whether OLM is installed on the managed cluster.
+	CapabilityOLM Capability = "OLM"
+	// CapabilityGatekeeper is whether Gatekeeper is installed on the managed cluster.
+	CapabilityGatekeeper Capability = "Gatekeeper"
+	// CapabilityPrometheusRoutes is whether the hub exposes Prometheus with a route.
+	CapabilityPrometheusRoutes Capability = "PrometheusRoutes"
+	// CapabilityInsightsClient is whether the insights client is deployed on the hub.
+	CapabilityInsightsClient Capability = "InsightsClient"
+	// CapabilityAdmissionWebhooks is whether admission webhooks are registered on the managed
+	// cluster.
+	CapabilityAdmissionWebhooks Capability = "AdmissionWebhooks"
+	// CapabilityOpenShiftNamespaces is whether the admission webhooks of the managed cluster let the
+	// policy controllers create namespaces starting with `openshift-`.
+	CapabilityOpenShiftNamespaces Capability = "OpenShiftNamespaces"
+)
+
+// allCapabilities are the capabilities found by DiscoverCapabilities, in the order of the matrix.
+var allCapabilities = []Capability{
+	CapabilityOpenShift,
+	CapabilityHostedMode,
+	CapabilityMCE,
+	CapabilityOLM,
+	CapabilityGatekeeper,
+	CapabilityPrometheusRoutes,
+	CapabilityInsightsClient,
+	CapabilityAdmissionWebhooks,
+	CapabilityOpenShiftNamespaces,
+}
+
+// The objects and API group whose presence tells the capabilities apart.
+const (
+	mceNamespace        = "multicluster-engine"
+	monitoringNamespace = "openshift-monitoring"
+	prometheusRouteName = "prometheus-k8s"
+	insightsClientName  = "insights-client"
+	olmGroup            = "operators.coreos.com"
+)
+
+const capabilitiesReportName = "Cluster capabilities"
+
+// CapabilityResult is whether a capability is available, with details like the version found.
+type CapabilityResult struct {
+	Available bool   `json:"available"`
+	Detail    string `json:"detail,omitempty"`
+}
+
+// Capabilities are the capabilities found by DiscoverCapabilities.
+type Capabilities map[Capability]CapabilityResult
+
+// Has returns whether the capability is available.
+func (c Capabilities) Has(capability Capability) bool {
+	return c[capability].Available
+}
+
+// String returns the capabilities as a matrix with a row per capability.
+func (c Capabilities) String() string {
+	builder := &strings.Builder{}
+	writer := tabwriter.NewWriter(builder, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(writer, "CAPABILITY\tAVAILABLE\tDETAIL")
+
+	for _, capability := range allCapabilities {
+		result, ok := c[capability]
+		if !ok {
+			continue
+		}
+
+		available := "no"
+		if result.Available {
+			available = "yes"
+		}
+
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", capability, available, result.Detail)
+	}
+
+	_ = writer.Flush()
+
+	lines := strings.Split(strings.TrimRight(builder.String(), "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// DiscoverCapabilities probes the clusters for all of the capabilities the first time it's called,
+// and returns the cached capabilities afterwards, so that specs don't probe the clusters again.
+func (e *Environment) DiscoverCapabilities(ctx context.Context) (Capabilities, error) {
+	e.capabilitiesLock.Lock()
+	defer e.capabilitiesLock.Unlock()
+
+	if e.capabilities != nil {
+		return e.capabilities, nil
+	}
+
+	probes := map[Capability]func(context.Context) (CapabilityResult, error){
+		CapabilityOpenShift:           e.probeOpenShift,
+		CapabilityHostedMode:          e.probeHostedMode,
+		CapabilityMCE:                 e.probeMCE,
+		CapabilityOLM:                 e.probeOLM,
+		CapabilityGatekeeper:          e.probeGatekeeper,
+		CapabilityPrometheusRoutes:    e.probePrometheusRoutes,
+		CapabilityInsightsClient:      e.probeInsightsClient,
+		CapabilityAdmissionWebhooks:   e.probeAdmissionWebhooks,
+		CapabilityOpenShiftNamespaces: e.probeOpenShiftNamespaces,
+	}
+
+	capabilities := Capabilities{}
+
+	for _, capability := range allCapabilities {
+		result, err := probes[capability](ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to discover the %s capability: %w", capability, err)
+		}
+
+		capabilities[capability] = result
+	}
+
+	e.capabilities = capabilities
+
+	return capabilities, nil
+}
+
+// SetCapabilities sets the capabilities returned by DiscoverCapabilities, so that the capabilities
+// discovered by process 1 of SynchronizedBeforeSuite are shared with the other processes instead of
+// each of them probing the clusters again.
+func (e *Environment) SetCapabilities(capabilities Capabilities) {
+	e.capabilitiesLock.Lock()
+	defer e.capabilitiesLock.Unlock()
+
+	e.capabilities = capabilities
+}
+
+// HasCapability returns whether the capability is available, discovering the capabilities if they
+// weren't yet. It fails the spec if the capabilities can't be discovered.
+func (e *Environment) HasCapability(ctx context.Context, capability Capability) bool {
+	GinkgoHelper()
+
+	capabilities, err := e.DiscoverCapabilities(ctx)
+	if err != nil {
+		Fail(err.Error())
+	}
+
+	return capabilities.Has(capability)
+}
+
+// ReportCapabilities discovers the capabilities, and prints them as a matrix in the output and as
+// a report entry. When running in parallel, call it in the process 1 function of
+// SynchronizedBeforeSuite, so that the matrix is only reported once, and pass the capabilities to
+// SetCapabilities in the other processes.
+func (e *Environment) ReportCapabilities(ctx context.Context) error {
+	capabilities, err := e.DiscoverCapabilities(ctx)
+	if err != nil {
+		return err
+	}
+
+	GinkgoWriter.Printf("%s:\n%s\n", capabilitiesReportName, capabilities)
+	AddReportEntry(capabilitiesReportName, capabilities.String())
+
+	return nil
+}
+
+func (e *Environment) probeOpenShift(ctx context.Context) (CapabilityResult, error) {
+	version, err := e.ComponentVersion(ctx, ComponentOCP)
+	if err != nil || version == nil {
+		return CapabilityResult{}, err
+	}
+
+	return CapabilityResult{Available: true, Detail: version.String()}, nil
+}
+
+func (e *Environment) probeHostedMode(_ context.Context) (CapabilityResult, error) {
+	return CapabilityResult{Available: e.IsHosted}, nil
+}
+
+func (e *Environment) probeMCE(ctx context.Context) (CapabilityResult, error) {
+	found, err := exists(ctx, e.ClientHubDynamic, GvrNS, "", mceNamespace)
+	if err != nil || !found {
+		return CapabilityResult{}, err
+	}
+
+	version, err := e.ComponentVersion(ctx, ComponentMCE)
+	if err != nil || version == nil {
+		return CapabilityResult{Available: true}, err
+	}
+
+	return CapabilityResult{Available: true, Detail: version.String()}, nil
+}
+
+func (e *Environment) probeOLM(_ context.Context) (CapabilityResult, error) {
+	groups, err := e.ClientManaged.Discovery().ServerGroups()
+	if err != nil {
+		return CapabilityResult{}, err
+	}
+
+	for _, group := range groups.Groups {
+		if group.Name == olmGroup {
+			return CapabilityResult{Available: true, Detail: group.PreferredVersion.GroupVersion}, nil
+		}
+	}
+
+	return CapabilityResult{}, nil
+}
+
+func (e *Environment) probeGatekeeper(ctx context.Context) (CapabilityResult, error) {
+	installed, err := e.crdInstalled(ctx, CRDGatekeeperConstraintTemplates)
+
+	return CapabilityResult{Available: installed}, err
+}
+
+func (e *Environment) probePrometheusRoutes(ctx context.Context) (CapabilityResult, error) {
+	route, err := e.ClientHubDynamic.Resource(GvrRoute).Namespace(monitoringNamespace).Get(
+		ctx, prometheusRouteName, metav1.GetOptions{},
+	)
+	if k8serrors.IsNotFound(err) {
+		return CapabilityResult{}, nil
+	}
+
+	if err != nil {
+		return CapabilityResult{}, err
+	}
+
+	host, _, _ := unstructured.NestedString(route.Object, "spec", "host")
+
+	return CapabilityResult{Available: true, Detail: host}, nil
+}
+
+func (e *Environment) probeInsightsClient(ctx context.Context) (CapabilityResult, error) {
+	found, err := exists(ctx, e.ClientHubDynamic, GvrDeployment, e.OCMNamespace, insightsClientName)
+
+	return CapabilityResult{Available: found}, err
+}
+
+func (e *Environment) probeAdmissionWebhooks(ctx context.Context) (CapabilityResult, error) {
+	validating, err := e.ClientManaged.AdmissionregistrationV1().ValidatingWebhookConfigurations().List(
+		ctx, metav1.ListOptions{},
+	)
+	if err != nil {
+		return CapabilityResult{}, err
+	}
+
+	mutating, err := e.ClientManaged.AdmissionregistrationV1().MutatingWebhookConfigurations().List(
+		ctx, metav1.ListOptions{},
+	)
+	if err != nil {
+		return CapabilityResult{}, err
+	}
+
+	names := []string{}
+
+	for _, webhook := range validating.Items {
+		names = append(names, webhook.Name)
+	}
+
+	for _, webhook := range mutating.Items {
+		names = append(names, webhook.Name)
+	}
+
+	slices.Sort(names)
+
+	return CapabilityResult{Available: len(names) > 0, Detail: strings.Join(names, ", ")}, nil
+}
+
+// probeOpenShiftNamespaces does a server-side dry run of creating an `openshift-` namespace, which
+// checks the admission webhooks, on OpenShift clusters. It impersonates the service account of the
+// configuration policy controller, since the kubeconfig might have superuser privileges to get
+// around the webhooks.
+func (e *Environment) probeOpenShiftNamespaces(ctx context.Context) (CapabilityResult, error) {
+	version, err := e.ComponentVersion(ctx, ComponentOCP)
+	if err != nil || version == nil {
+		return CapabilityResult{Detail: "not OpenShift"}, err
+	}
+
+	out, err := e.OcManaged(
+		"create", "ns", "openshift-grc-test",
+		"--dry-run=server",
+		"--as=system:serviceaccount:"+e.OCMAddOnNamespace+":config-policy-controller-sa",
+	)
+	if err != nil {
+		out += err.Error()
+	}
+
+	switch {
+	case strings.Contains(out, "namespace/openshift-grc-test created"):
+		return CapabilityResult{Available: true}, nil
+	case strings.Contains(out, "namespaces \"openshift-grc-test\" already exists"):
+		// Weird situation, but probably means it could make the namespace
+		return CapabilityResult{Available: true, Detail: "the namespace already exists"}, nil
+	case strings.Contains(out, "admission webhook \"mutation.gatekeeper.sh\" does not support dry run"):
+		// Gatekeeper is installed, so assume the namespace could be created
+		return CapabilityResult{Available: true, Detail: "assumed, since Gatekeeper doesn't support dry runs"}, nil
+	default:
+		return CapabilityResult{}, nil
+	}
+}
+
+// exists returns whether the object exists.
+func exists(ctx context.Context, client dynamic.Interface, gvr schema.GroupVersionResource, namespace, name string) (
+	bool, error,
+) {
+	_, err := client.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
@@ -0,0 +1,135 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package common
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var _ = Describe("Test the capability discovery", func() {
+	var backend *FakeBackend
+	var env *Environment
+
+	BeforeEach(func() {
+		backend = startFakeBackend()
+		env = backend.Env
+	})
+
+	createOnHub := func(ctx SpecContext, gvr schema.GroupVersionResource, obj map[string]any) {
+		GinkgoHelper()
+
+		object := &unstructured.Unstructured{Object: obj}
+
+		_, err := env.ClientHubDynamic.Resource(gvr).Namespace(object.GetNamespace()).Create(
+			ctx, object, metav1.CreateOptions{},
+		)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	It("Discovers the capabilities of the clusters once", func(ctx SpecContext) {
+		env.IsHosted = true
+
+		createOnHub(ctx, GvrNS, map[string]any{
+			"apiVersion": "v1",
+			"kind":       "Namespace",
+			"metadata":   map[string]any{"name": "multicluster-engine"},
+		})
+		createOnHub(ctx, GvrClusterServiceVersion, map[string]any{
+			"apiVersion": "operators.coreos.com/v1alpha1",
+			"kind":       "ClusterServiceVersion",
+			"metadata":   map[string]any{"name": "multicluster-engine.v2.9.0", "namespace": "multicluster-engine"},
+			"spec":       map[string]any{"version": "2.9.0"},
+		})
+		createOnHub(ctx, GvrRoute, map[string]any{
+			"apiVersion": "route.openshift.io/v1",
+			"kind":       "Route",
+			"metadata":   map[string]any{"name": "prometheus-k8s", "namespace": "openshift-monitoring"},
+			"spec":       map[string]any{"host": "prometheus-k8s.apps.example.com"},
+		})
+
+		_, err := backend.Managed.Client.AdmissionregistrationV1().ValidatingWebhookConfigurations().Create(
+			ctx,
+			&admissionv1.ValidatingWebhookConfiguration{ObjectMeta: metav1.ObjectMeta{Name: "policy-webhook"}},
+			metav1.CreateOptions{},
+		)
+		Expect(err).ToNot(HaveOccurred())
+
+		capabilities, err := env.DiscoverCapabilities(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(capabilities.String()).To(Equal(`CAPABILITY           AVAILABLE  DETAIL
+OpenShift            no
+HostedMode           yes
+MCE                  yes        2.9.0
+OLM                  yes        operators.coreos.com/v1alpha1
+Gatekeeper           no
+PrometheusRoutes     yes        prometheus-k8s.apps.example.com
+InsightsClient       no
+AdmissionWebhooks    yes        policy-webhook
+OpenShiftNamespaces  no         not OpenShift`))
+
+		createOnHub(ctx, GvrDeployment, map[string]any{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata":   map[string]any{"name": "insights-client", "namespace": env.OCMNamespace},
+		})
+
+		Expect(env.HasCapability(ctx, CapabilityMCE)).To(BeTrue())
+		Expect(env.HasCapability(ctx, CapabilityInsightsClient)).To(BeFalse())
+	})
+
+	It("Uses the capabilities shared by another process", func(ctx SpecContext) {
+		shared := Capabilities{}
+
+		err := json.Unmarshal([]byte(`{"MCE": {"available": true, "detail": "2.9.0"}, "OLM": {"available": false}}`),
+			&shared)
+		Expect(err).ToNot(HaveOccurred())
+
+		env.SetCapabilities(shared)
+
+		capabilities, err := env.DiscoverCapabilities(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(capabilities).To(Equal(Capabilities{
+			CapabilityMCE: {Available: true, Detail: "2.9.0"},
+			CapabilityOLM: {Available: false},
+		}))
+		Expect(env.HasCapability(ctx, CapabilityMCE)).To(BeTrue())
+	})
+
+	It("Verifies MCE with a lookup of its namespace only", func(ctx SpecContext) {
+		env.ManuallyPatchDecisions = true
+
+		env.VerifyMCE(ctx)
+
+		createOnHub(ctx, GvrNS, map[string]any{
+			"apiVersion": "v1",
+			"kind":       "Namespace",
+			"metadata":   map[string]any{"name": "multicluster-engine"},
+		})
+
+		Expect(InterceptGomegaFailure(func() { env.VerifyMCE(ctx) })).To(
+			MatchError(ContainSubstring("MCE namespace found")),
+		)
+
+		env.capabilitiesLock.Lock()
+		defer env.capabilitiesLock.Unlock()
+
+		Expect(env.capabilities).To(BeNil())
+	})
+
+	It("Reports the capability matrix", func(ctx SpecContext) {
+		Expect(env.ReportCapabilities(ctx)).To(Succeed())
+
+		entries := CurrentSpecReport().ReportEntries
+		Expect(entries).To(ContainElement(And(
+			HaveField("Name", capabilitiesReportName),
+			HaveField("Value.String()", ContainSubstring("OpenShift            no\n")),
+		)))
+	})
+})
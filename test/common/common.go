@@ -3,202 +3,79 @@
 package common
 
 import (
+	"bytes"
 	"context"
 	"errors"
-	"flag"
 	"fmt"
-	"os"
 	"os/exec"
-	"os/user"
-	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/Masterminds/semver/v3"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
-	"golang.org/x/mod/semver"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/rest"
-	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/klog"
-	"open-cluster-management.io/governance-policy-propagator/test/utils"
-)
 
-var (
-	KubeconfigHub          string
-	KubeconfigManaged      string
-	UserNamespace          string
-	ClusterNamespace       string
-	ClusterNamespaceOnHub  string
-	PolicyCollectionBranch string
-	OCMNamespace           string
-	OCMAddOnNamespace      string
-	DefaultTimeoutSeconds  int
-	ManuallyPatchDecisions bool
-	K8sClient              string
-	IsHosted               bool
-
-	ClientHub            kubernetes.Interface
-	ClientHubDynamic     dynamic.Interface
-	ClientManaged        kubernetes.Interface
-	ClientManagedDynamic dynamic.Interface
-	ClientHosting        kubernetes.Interface
-	ClientHostingDynamic dynamic.Interface
+	"github.com/stolostron/governance-policy-framework/test/common/kubeclient"
 )
 
 const MaxTimeoutSeconds = 900 // 15 minutes
 
-func InitFlags(flagset *flag.FlagSet) {
-	if flagset == nil {
-		flagset = flag.CommandLine
-	}
-
-	flagset.StringVar(
-		&KubeconfigHub, "kubeconfig_hub", "../../kubeconfig_hub",
-		"Location of the kubeconfig to use; defaults to KUBECONFIG if not set",
-	)
-	flagset.StringVar(
-		&KubeconfigManaged, "kubeconfig_managed", "../../kubeconfig_managed",
-		"Location of the kubeconfig to use; defaults to KUBECONFIG if not set",
-	)
-
-	flagset.BoolVar(
-		&IsHosted, "is_hosted", false,
-		"Whether is hosted mode or not",
-	)
-	flagset.StringVar(&UserNamespace, "user_namespace",
-		"policy-test", "ns on hub to create root policy")
-	flagset.StringVar(&ClusterNamespace, "cluster_namespace", "local-cluster", "cluster ns name")
-	flagset.StringVar(&ClusterNamespaceOnHub, "cluster_namespace_on_hub", "", "cluster ns name on hub")
-	flagset.StringVar(&OCMNamespace, "ocm_namespace", "open-cluster-management", "ns of ocm installation")
-	flagset.StringVar(
-		&OCMAddOnNamespace,
-		"ocm_addon_namespace",
-		"open-cluster-management-agent-addon",
-		"ns of ocm addon installations",
-	)
-	flagset.StringVar(
-		&PolicyCollectionBranch, "policy_collection_branch", "main", "the branch of the policy-collection repo",
-	)
-	flagset.IntVar(&DefaultTimeoutSeconds, "timeout_seconds", 30, "Timeout seconds for assertion")
-	flagset.BoolVar(
-		&ManuallyPatchDecisions, "patch_decisions", true,
-		"Whether to 'manually' patch Placements with PlacementDecisions "+
-			"(set to false if the Placement controller is running)",
-	)
-	flagset.StringVar(
-		&K8sClient, "k8s_client", "oc",
-		"Which k8s client to use for some tests - `oc`, `kubectl`, "+
-			"or something else entirely",
-	)
-}
-
-// InitInterfaces Initializes the Hub and Managed Clients. Should be called after InitFlags,
-// and before any tests using common functions are run.
-func InitInterfaces(hubConfig, managedConfig string, isHosted bool) {
-	if isHosted {
-		ClientHosting = NewKubeClient("", hubConfig, "")
-		ClientHostingDynamic = NewKubeClientDynamic("", hubConfig, "")
-	} else {
-		ClientHosting = NewKubeClient("", managedConfig, "")
-		ClientHostingDynamic = NewKubeClientDynamic("", managedConfig, "")
-	}
-
-	if ClusterNamespaceOnHub == "" {
-		ClusterNamespaceOnHub = ClusterNamespace
-	}
-
-	ClientHub = NewKubeClient("", hubConfig, "")
-	ClientHubDynamic = NewKubeClientDynamic("", hubConfig, "")
-	ClientManaged = NewKubeClient("", managedConfig, "")
-	ClientManagedDynamic = NewKubeClientDynamic("", managedConfig, "")
-}
-
-func NewKubeClient(url, kubeconfig, context string) kubernetes.Interface {
+// NewKubeClient returns a client of the cluster of the kubeconfig, or of the URL if it's set, configured
+// with the ClientOptions.
+func NewKubeClient(url, kubeconfig, context string, options kubeclient.ClientOptions) (kubernetes.Interface, error) {
 	klog.V(5).Infof("Create kubeclient for url %s using kubeconfig path %s\n", url, kubeconfig)
 
-	config, err := LoadConfig(url, kubeconfig, context)
+	config, err := kubeclient.LoadConfig(url, kubeconfig, context)
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("failed to load the kubeconfig %s: %w", kubeconfig, err)
 	}
 
+	options.Apply(config)
+
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("failed to create a client for the kubeconfig %s: %w", kubeconfig, err)
 	}
 
-	return clientset
+	return clientset, nil
 }
 
-func NewKubeClientDynamic(url, kubeconfig, context string) dynamic.Interface {
+// NewKubeClientDynamic returns a dynamic client of the cluster of the kubeconfig, or of the URL if
+// it's set, configured with the ClientOptions.
+func NewKubeClientDynamic(
+	url, kubeconfig, context string, options kubeclient.ClientOptions,
+) (dynamic.Interface, error) {
 	klog.V(5).Infof("Create kubeclient dynamic for url %s using kubeconfig path %s\n", url, kubeconfig)
 
-	config, err := LoadConfig(url, kubeconfig, context)
+	config, err := kubeclient.LoadConfig(url, kubeconfig, context)
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("failed to load the kubeconfig %s: %w", kubeconfig, err)
 	}
 
+	options.Apply(config)
+
 	clientset, err := dynamic.NewForConfig(config)
 	if err != nil {
-		panic(err)
-	}
-
-	return clientset
-}
-
-func LoadConfig(url, kubeconfig, context string) (*rest.Config, error) {
-	if kubeconfig == "" {
-		kubeconfig = os.Getenv("KUBECONFIG")
-	}
-
-	klog.V(5).Infof("Kubeconfig path %s\n", kubeconfig)
-	// If we have an explicit indication of where the kubernetes config lives, read that.
-	if kubeconfig != "" {
-		if context == "" {
-			return clientcmd.BuildConfigFromFlags(url, kubeconfig)
-		}
-
-		return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
-			&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfig},
-			&clientcmd.ConfigOverrides{
-				CurrentContext: context,
-			}).ClientConfig()
-	}
-	// If not, try the in-cluster config.
-	if c, err := rest.InClusterConfig(); err == nil {
-		return c, nil
-	}
-	// If no in-cluster config, try the default location in the user's home directory.
-	if usr, err := user.Current(); err == nil {
-		klog.V(5).Infof(
-			"clientcmd.BuildConfigFromFlags for url %s using %s\n",
-			url,
-			filepath.Join(usr.HomeDir, ".kube", "config"),
-		)
-
-		if c, err := clientcmd.BuildConfigFromFlags("", filepath.Join(usr.HomeDir, ".kube", "config")); err == nil {
-			return c, nil
-		}
+		return nil, fmt.Errorf("failed to create a dynamic client for the kubeconfig %s: %w", kubeconfig, err)
 	}
 
-	return nil, errors.New("could not create a valid kubeconfig")
+	return clientset, nil
 }
 
-func VerifyManagedCluster(ctx context.Context) {
+func (e *Environment) VerifyManagedCluster(ctx context.Context) {
 	GinkgoHelper()
 
-	managedClusterName := ClusterNamespace
-
-	if IsHosted {
-		managedClusterName = strings.TrimPrefix(managedClusterName, "klusterlet-")
-	}
+	managedClusterName := e.Topology().ManagedClusterName
 
 	By("Verify managed cluster '" + managedClusterName + "' exists")
 
-	managedClusterList, err := ClientHubDynamic.Resource(GvrManagedCluster).List(ctx, metav1.ListOptions{})
+	managedClusterList, err := e.ClientHubDynamic.Resource(GvrManagedCluster).List(ctx, metav1.ListOptions{})
 	Expect(err).ToNot(HaveOccurred())
 
 	managedClusters := make([]string, len(managedClusterList.Items))
@@ -210,40 +87,42 @@ func VerifyManagedCluster(ctx context.Context) {
 		ContainElement(managedClusterName),
 		"Managed cluster '%s' not found. "+
 			"Set the MANAGED_CLUSTER_NAME environment variable to the name of the managed cluster.",
-		ClusterNamespace,
+		e.ClusterNamespace,
 	)
 }
 
-func VerifyMCE(ctx context.Context) {
+func (e *Environment) VerifyMCE(ctx context.Context) {
 	GinkgoHelper()
 
-	By("Checking for MCE namespace multicluster-engine")
-
-	_, err := ClientHub.CoreV1().Namespaces().Get(ctx, "multicluster-engine", metav1.GetOptions{})
-	if err == nil && ManuallyPatchDecisions {
-		Fail("MCE namespace found, but ManuallyPatchDecisions is set to true. " +
-			"Set PATCH_DECISIONS environment variable to false to run the test.")
-	} else if err != nil && !k8serrors.IsNotFound(err) {
-		Expect(err).ToNot(HaveOccurred())
+	if !e.ManuallyPatchDecisions {
+		return
 	}
+
+	By("Checking for MCE namespace " + mceNamespace)
+
+	found, err := exists(ctx, e.ClientHubDynamic, GvrNS, "", mceNamespace)
+	Expect(err).ToNot(HaveOccurred())
+
+	Expect(found).To(BeFalse(), "MCE namespace found, but ManuallyPatchDecisions is set to true. "+
+		"Set PATCH_DECISIONS environment variable to false to run the test.")
 }
 
-func oc(args ...string) (string, error) {
-	// Determine whether output should be logged
-	printOutput := true
+func (e *Environment) oc(args ...string) (string, error) {
+	k8sCmd := exec.CommandContext(context.TODO(), e.K8sClient, args...)
 
-	for _, a := range args {
-		if a == "whoami" || strings.HasPrefix(a, "secret") {
-			printOutput = false
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	k8sCmd.Stdout = stdout
+	k8sCmd.Stderr = stderr
 
-			break
-		}
-	}
+	start := time.Now()
+	err := k8sCmd.Run()
+	output := stdout.String()
 
-	k8sCmd := exec.CommandContext(context.TODO(), K8sClient, args...)
+	e.recordCommand(newCommandTranscript(k8sCmd.Args, start, output, stderr.String(), err))
 
-	output, err := k8sCmd.Output()
-	if len(args) > 0 && printOutput {
+	// Don't log the output of the commands which may output secrets
+	if len(args) > 0 && !hasSensitiveOutput(args) {
 		klog.V(2).Infof("OC command output %s\n", output)
 	}
 
@@ -251,115 +130,96 @@ func oc(args ...string) (string, error) {
 
 	ok := errors.As(err, &exitError)
 	if ok {
-		if exitError.Stderr == nil {
-			return string(output), nil
+		if stderr.Len() == 0 {
+			return output, nil
 		}
 
 		// Reformat error to include command and stderr output
 		err = fmt.Errorf(
 			"error running command '%s':\n %s: %s",
-			strings.Join(k8sCmd.Args, " "),
+			strings.Join(maskArgs(k8sCmd.Args), " "),
 			output,
-			exitError.Stderr,
+			stderr,
 		)
 
-		return string(output), err
+		return output, err
 	}
 
 	if err != nil {
 		// Reformat error to include command and stderr output
 		err = fmt.Errorf(
 			"error running command '%s':\n %s: %s",
-			strings.Join(k8sCmd.Args, " "),
+			strings.Join(maskArgs(k8sCmd.Args), " "),
 			output,
 			err.Error(),
 		)
 	}
 
-	return string(output), err
+	return output, err
 }
 
 // OcHub Runs the given oc/kubectl command against the configured hub cluster.
-// Prints and returns the stdout from the command.
+// The apply, delete, patch, and logs commands are handled with client-go unless NativeK8sClient is
+// false. Prints and returns the stdout from the command.
 // If the command fails (non-zero exit code) and stderr was populated, that
 // content will be returned in the error.
-func OcHub(args ...string) (string, error) {
-	args = append([]string{"--kubeconfig=" + KubeconfigHub}, args...)
-
-	return oc(args...)
+func (e *Environment) OcHub(args ...string) (string, error) {
+	return e.runCommand(e.KubeconfigHub, e.ContextHub, e.ClientHub, e.ClientHubDynamic, args...)
 }
 
 // OcManaged Runs the given oc/kubectl command against the configured managed cluster.
-// Prints and returns the stdout from the command.
+// The apply, delete, patch, and logs commands are handled with client-go unless NativeK8sClient is
+// false. Prints and returns the stdout from the command.
 // If the command fails (non-zero exit code) and stderr was populated, that
 // content will be returned in the error.
-func OcManaged(args ...string) (string, error) {
-	args = append([]string{"--kubeconfig=" + KubeconfigManaged}, args...)
-
-	return oc(args...)
+func (e *Environment) OcManaged(args ...string) (string, error) {
+	return e.runCommand(e.KubeconfigManaged, e.ContextManaged, e.ClientManaged, e.ClientManagedDynamic, args...)
 }
 
-func OcHosting(args ...string) (string, error) {
-	if IsHosted {
-		args = append([]string{"--kubeconfig=" + KubeconfigHub}, args...)
-	} else {
-		args = append([]string{"--kubeconfig=" + KubeconfigManaged}, args...)
-	}
+// OcHosting Runs the given oc/kubectl command against the cluster hosting the policy templates,
+// which is the hub in hosted mode and the managed cluster otherwise. The apply, delete, patch, and
+// logs commands are handled with client-go unless NativeK8sClient is false.
+func (e *Environment) OcHosting(args ...string) (string, error) {
+	templates := e.Topology().Templates
 
-	return oc(args...)
+	return e.runCommand(templates.Kubeconfig, templates.Context, templates.Client, templates.ClientDynamic, args...)
 }
 
-func OutputDebugInfo(testName string, kubeconfig string, additionalResources ...string) {
-	GinkgoWriter.Printf("%s test Kubernetes info:\n", testName)
+// IsAtLeastVersion detects OCP versions given an x.y version lower bound. Prefer decorating the
+// specs with RequiresOCP, which reports why they're skipped.
+func (e *Environment) IsAtLeastVersion(minVersion string) bool {
+	version, err := e.ComponentVersion(context.TODO(), ComponentOCP)
+	if err != nil {
+		klog.Infof("Encountered an error fetching the OCP version: %v", err)
 
-	resources := []string{
-		"policies.policy.open-cluster-management.io",
-		"placements.cluster.open-cluster-management.io",
-		"placementbindings.policy.open-cluster-management.io",
+		return false
 	}
-	resources = append(resources, additionalResources...)
 
-	for _, resource := range resources {
-		_, _ = utils.KubectlWithOutput("get", resource, "--all-namespaces", "-o", "yaml", "--kubeconfig="+kubeconfig)
-	}
-}
-
-// IsAtLeastVersion detects OCP versions given an x.y version lower bound
-func IsAtLeastVersion(minVersion string) bool {
-	clusterVersion, err := ClientManagedDynamic.Resource(GvrClusterVersion).Get(
-		context.TODO(),
-		"version",
-		metav1.GetOptions{},
-	)
-	if err != nil {
-		if k8serrors.IsNotFound(err) {
-			// no version CR, not ocp
-			klog.V(5).Info("This is not an OCP cluster")
-		} else {
-			klog.Infof("Encountered an error fetching the OCP version: %v", err)
-		}
+	if version == nil {
+		// no version CR, not ocp
+		klog.V(5).Info("This is not an OCP cluster")
 
 		return false
 	}
 
-	version, _, _ := unstructured.NestedString(clusterVersion.Object, "status", "desired", "version")
+	klog.V(5).Info("OCP Version " + version.String())
 
-	klog.V(5).Info("OCP Version " + version)
+	minSemVer, err := semver.NewVersion(minVersion)
+	if err != nil {
+		klog.Infof("The minimum OCP version %s is invalid: %v", minVersion, err)
 
-	// Convert to valid semantic versions by adding the "v" prefix
-	minSemVer := "v" + minVersion
-	ocpSemVer := semver.MajorMinor("v" + version)
+		return false
+	}
 
-	// Compare returns: 0 if ver1 == ver2, -1 if ver1 < ver2, or +1 if ver1 > ver2
-	return semver.Compare(ocpSemVer, minSemVer) >= 0
+	return !version.LessThan(minSemVer)
 }
 
-func CleanupHubNamespace(namespace string) {
+func (e *Environment) CleanupHubNamespace(namespace string) {
 	GinkgoHelper()
 
 	By("Deleting namespace " + namespace)
 
-	err := ClientHub.CoreV1().Namespaces().Delete(context.TODO(), namespace, metav1.DeleteOptions{})
+	err := e.ClientHub.CoreV1().Namespaces().Delete(context.TODO(), namespace, metav1.DeleteOptions{})
 	if !k8serrors.IsNotFound(err) {
 		Expect(err).ShouldNot(HaveOccurred())
 	}
@@ -367,7 +227,7 @@ func CleanupHubNamespace(namespace string) {
 	// Wait for the namespace to be fully deleted before proceeding.
 	Eventually(
 		func() bool {
-			_, err := ClientHub.CoreV1().Namespaces().Get(
+			_, err := e.ClientHub.CoreV1().Namespaces().Get(
 				context.TODO(), namespace, metav1.GetOptions{},
 			)
 
@@ -378,12 +238,18 @@ func CleanupHubNamespace(namespace string) {
 
 			return isNotFound
 		},
-		DefaultTimeoutSeconds*6,
+		e.DefaultTimeoutSeconds*6,
 		1,
 	).Should(BeTrue(), fmt.Sprintf("Namespace %s should be deleted.", namespace))
 }
 
-func ApplyManagedClusterSetBinding(ctx SpecContext) error {
+func (e *Environment) ApplyManagedClusterSetBinding(ctx SpecContext) error {
+	return e.createManagedClusterSetBinding(ctx, e.UserNamespace)
+}
+
+// createManagedClusterSetBinding creates the ManagedClusterSetBinding binding the global
+// ManagedClusterSet to the namespace.
+func (e *Environment) createManagedClusterSetBinding(ctx context.Context, namespace string) error {
 	managedClusterSetBinding := unstructured.Unstructured{
 		Object: map[string]any{
 			"apiVersion": GvrManagedClusterSetBinding.Group +
@@ -398,8 +264,10 @@ func ApplyManagedClusterSetBinding(ctx SpecContext) error {
 		},
 	}
 
-	_, err := ClientHubDynamic.Resource(GvrManagedClusterSetBinding).
-		Namespace(UserNamespace).Create(
+	e.setOwnershipLabels(&managedClusterSetBinding)
+
+	_, err := e.ClientHubDynamic.Resource(GvrManagedClusterSetBinding).
+		Namespace(namespace).Create(
 		ctx, &managedClusterSetBinding, metav1.CreateOptions{},
 	)
 
@@ -0,0 +1,20 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package common
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/klog"
+)
+
+func TestCommon(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Policy Framework common helpers Suite")
+}
+
+func init() {
+	klog.SetOutput(GinkgoWriter)
+}
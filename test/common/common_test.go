@@ -0,0 +1,22 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package common
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/stolostron/governance-policy-framework/test/common/kubeclient"
+)
+
+var _ = Describe("Test the clients", func() {
+	It("Returns an error for a missing kubeconfig", func() {
+		options := kubeclient.DefaultClientOptions()
+
+		_, err := NewKubeClient("", "testdata/missing-kubeconfig", "", options)
+		Expect(err).To(MatchError(ContainSubstring("failed to load the kubeconfig testdata/missing-kubeconfig")))
+
+		_, err = NewKubeClientDynamic("", "testdata/missing-kubeconfig", "", options)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,436 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package common
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// redactedValue replaces the values of the data and stringData fields of Secrets in debug bundles.
+	redactedValue = "REDACTED"
+	// debugBundleReportEntry is the name of the spec report entry with the path of the debug bundle.
+	debugBundleReportEntry = "Debug bundle"
+)
+
+// DebugResource is a list of objects to add to a debug bundle. All namespaces are collected when
+// the Namespace is empty, and all of the objects when the Name is empty.
+type DebugResource struct {
+	Cluster   ClusterTarget
+	GVR       schema.GroupVersionResource
+	Namespace string
+	Name      string
+}
+
+// DebugPods are pods to add to a debug bundle, with the logs of all of their containers.
+type DebugPods struct {
+	Cluster       ClusterTarget
+	Namespace     string
+	LabelSelector string
+}
+
+// DebugBundleOptions are the resources and pods to add to a debug bundle, in addition to those
+// always collected by CollectDebugBundle.
+type DebugBundleOptions struct {
+	Resources []DebugResource
+	Pods      []DebugPods
+}
+
+// debugBundle is a debug bundle being written. Failures to collect part of it are recorded in
+// errors.txt instead of stopping the collection.
+type debugBundle struct {
+	dir    string
+	errors []string
+}
+
+// hubControllers are the deployments of the policy controllers on the hub.
+var hubControllers = []string{"grc-policy-propagator", "governance-policy-propagator"}
+
+// managedControllers are the deployments of the policy controllers on the cluster hosting the policy
+// templates.
+var managedControllers = []string{
+	"governance-policy-framework",
+	"governance-policy-framework-addon",
+	"config-policy-controller",
+	"cert-policy-controller",
+}
+
+// CollectDebugBundle writes the debugging information of the current spec into a new directory
+// under DebugBundleDir, and returns its path. The bundle contains the policies, placements, events
+// and addon status from the hub, the replicated policies, policy templates and events from each
+// managed cluster, the logs of the policy controllers, and everything in the options. The data of
+// Secrets is redacted.
+func (e *Environment) CollectDebugBundle(ctx context.Context, opts DebugBundleOptions) (string, error) {
+	bundle := &debugBundle{dir: filepath.Join(e.DebugBundleDir, debugBundleName(CurrentSpecReport().FullText()))}
+
+	if err := os.MkdirAll(bundle.dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create the debug bundle directory: %w", err)
+	}
+
+	hubDir := filepath.Join(bundle.dir, string(TargetHub))
+
+	for _, gvr := range []schema.GroupVersionResource{
+		GvrPolicy, GvrPolicySet, GvrPlacement, GvrPlacementBinding, GvrPlacementDecision,
+	} {
+		bundle.writeResource(ctx, hubDir, e.ClientHubDynamic, gvr, e.UserNamespace, "")
+	}
+
+	bundle.writeResource(ctx, hubDir, e.ClientHubDynamic, GvrManagedCluster, "", "")
+	bundle.writeResource(ctx, hubDir, e.ClientHubDynamic, GvrClusterManagementAddOn, "", "")
+	bundle.writeEvents(ctx, hubDir, e.ClientHub, e.UserNamespace)
+	bundle.writeControllerLogs(ctx, hubDir, e.ClientHub, e.OCMNamespace, hubControllers)
+
+	for _, clusterName := range e.ManagedClusterNames() {
+		cluster := e.GetManagedCluster(clusterName)
+		clusterDir := filepath.Join(bundle.dir, "clusters", clusterName)
+
+		bundle.writeResource(ctx, hubDir, e.ClientHubDynamic, GvrManagedClusterAddOn, clusterName, "")
+
+		for _, gvr := range []schema.GroupVersionResource{
+			GvrPolicy, GvrConfigurationPolicy, GvrOperatorPolicy, GvrCertPolicy,
+		} {
+			bundle.writeResource(ctx, clusterDir, cluster.ClientHostingDynamic, gvr, cluster.Namespace, "")
+		}
+
+		bundle.writeEvents(ctx, clusterDir, cluster.ClientHosting, cluster.Namespace)
+
+		addon := e.TopologyOf(cluster).Addon
+
+		bundle.writeControllerLogs(ctx, clusterDir, addon.Client, addon.Namespace, managedControllers)
+	}
+
+	for _, resource := range opts.Resources {
+		_, clientDynamic := e.targetClients(resource.Cluster)
+
+		bundle.writeResource(ctx, filepath.Join(bundle.dir, string(resource.Cluster)), clientDynamic,
+			resource.GVR, resource.Namespace, resource.Name)
+	}
+
+	for _, pods := range opts.Pods {
+		client, _ := e.targetClients(pods.Cluster)
+
+		bundle.writePods(ctx, filepath.Join(bundle.dir, string(pods.Cluster)), client, pods.Namespace,
+			pods.LabelSelector)
+	}
+
+	if len(bundle.errors) != 0 {
+		err := os.WriteFile(
+			filepath.Join(bundle.dir, "errors.txt"), []byte(strings.Join(bundle.errors, "\n")+"\n"), 0o644,
+		)
+		if err != nil {
+			return bundle.dir, fmt.Errorf("failed to write the debug bundle errors: %w", err)
+		}
+	}
+
+	return bundle.dir, nil
+}
+
+// ReportDebugBundle collects a debug bundle with CollectDebugBundle and adds its path to the report
+// of the current spec.
+func (e *Environment) ReportDebugBundle(ctx context.Context, opts DebugBundleOptions) {
+	By("Collecting a debug bundle")
+
+	dir, err := e.CollectDebugBundle(ctx, opts)
+	if err != nil {
+		GinkgoWriter.Printf("Failed to collect the debug bundle: %v\n", err)
+	}
+
+	if dir != "" {
+		if absDir, err := filepath.Abs(dir); err == nil {
+			dir = absDir
+		}
+
+		AddReportEntry(debugBundleReportEntry, dir)
+	}
+}
+
+// ReportFailedSpecDebugBundle collects a debug bundle with ReportDebugBundle if the current spec
+// failed, with the options registered by AddDebugResources. It's meant to be registered as a suite
+// level JustAfterEach, so that the bundle is collected before the cleanup of the spec.
+func (e *Environment) ReportFailedSpecDebugBundle(ctx SpecContext) {
+	if CurrentSpecReport().Failed() {
+		e.ReportDebugBundle(ctx, e.registeredDebugOptions())
+	}
+}
+
+// AddDebugResources adds the resources and pods of the options to the debug bundles collected by
+// ReportFailedSpecDebugBundle, until the end of the current container or spec. It's meant to be
+// called in a BeforeAll, so that the options apply to all of the specs of an Ordered container.
+func (e *Environment) AddDebugResources(opts DebugBundleOptions) {
+	registered := &opts
+
+	e.debugOptionsLock.Lock()
+	e.debugOptions = append(e.debugOptions, registered)
+	e.debugOptionsLock.Unlock()
+
+	DeferCleanup(func() {
+		e.debugOptionsLock.Lock()
+		defer e.debugOptionsLock.Unlock()
+
+		for i, options := range e.debugOptions {
+			if options == registered {
+				e.debugOptions = append(e.debugOptions[:i], e.debugOptions[i+1:]...)
+
+				break
+			}
+		}
+	})
+}
+
+// registeredDebugOptions merges the options registered by AddDebugResources.
+func (e *Environment) registeredDebugOptions() DebugBundleOptions {
+	e.debugOptionsLock.Lock()
+	defer e.debugOptionsLock.Unlock()
+
+	merged := DebugBundleOptions{}
+
+	for _, options := range e.debugOptions {
+		merged.Resources = append(merged.Resources, options.Resources...)
+		merged.Pods = append(merged.Pods, options.Pods...)
+	}
+
+	return merged
+}
+
+var unsafeFileNameChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// debugBundleName returns a unique directory name for the debug bundle of the given spec.
+func debugBundleName(specText string) string {
+	name := strings.Trim(unsafeFileNameChars.ReplaceAllString(specText, "-"), "-")
+	if len(name) > 100 {
+		name = name[:100]
+	}
+
+	if name == "" {
+		name = "suite"
+	}
+
+	return fmt.Sprintf("%s-%s-p%d", name, time.Now().Format("20060102T150405.000"), GinkgoParallelProcess())
+}
+
+func (b *debugBundle) addError(format string, args ...any) {
+	b.errors = append(b.errors, fmt.Sprintf(format, args...))
+}
+
+// write writes the object as YAML in the given file.
+func (b *debugBundle) write(dir, fileName string, obj any) {
+	content, err := yaml.Marshal(obj)
+	if err != nil {
+		b.addError("%s: %v", fileName, err)
+
+		return
+	}
+
+	b.writeFile(dir, fileName, content)
+}
+
+func (b *debugBundle) writeFile(dir, fileName string, content []byte) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		b.addError("%s: %v", dir, err)
+
+		return
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, fileName), content, 0o644); err != nil {
+		b.addError("%s: %v", fileName, err)
+	}
+}
+
+// writeResource writes the matching objects as a YAML List named after the resource, namespace,
+// and name, with the data of Secrets redacted. Missing resources are skipped.
+func (b *debugBundle) writeResource(
+	ctx context.Context,
+	dir string,
+	client dynamic.Interface,
+	gvr schema.GroupVersionResource,
+	namespace, name string,
+) {
+	fileName := strings.Join(nonEmpty(gvr.GroupResource().String(), namespace, name), "_") + ".yaml"
+	resource := client.Resource(gvr).Namespace(namespace)
+
+	list := &unstructured.UnstructuredList{Object: map[string]any{"apiVersion": "v1", "kind": "List"}}
+
+	if name != "" {
+		obj, err := resource.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			b.addResourceError(dir, fileName, err)
+
+			return
+		}
+
+		list.Items = append(list.Items, *obj)
+	} else {
+		objs, err := resource.List(ctx, metav1.ListOptions{})
+		if err != nil {
+			b.addResourceError(dir, fileName, err)
+
+			return
+		}
+
+		list.Items = objs.Items
+	}
+
+	for i := range list.Items {
+		unstructured.RemoveNestedField(list.Items[i].Object, "metadata", "managedFields")
+		redactSecrets(list.Items[i].Object)
+	}
+
+	b.write(dir, fileName, list.UnstructuredContent())
+}
+
+func (b *debugBundle) addResourceError(dir, fileName string, err error) {
+	// The resource isn't installed or the object doesn't exist, which is not worth reporting
+	if k8serrors.IsNotFound(err) {
+		return
+	}
+
+	b.addError("%s: %v", filepath.Join(filepath.Base(dir), fileName), err)
+}
+
+// writeEvents writes the events in the namespace to events_<namespace>.yaml.
+func (b *debugBundle) writeEvents(ctx context.Context, dir string, client kubernetes.Interface, namespace string) {
+	events, err := client.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		b.addError("events in %s: %v", namespace, err)
+
+		return
+	}
+
+	for i := range events.Items {
+		events.Items[i].ManagedFields = nil
+	}
+
+	b.write(dir, "events_"+namespace+".yaml", events)
+}
+
+// writeControllerLogs writes the pods and logs of the deployments of the given names which exist in
+// the namespace.
+func (b *debugBundle) writeControllerLogs(
+	ctx context.Context, dir string, client kubernetes.Interface, namespace string, deployments []string,
+) {
+	for _, name := range deployments {
+		deployment, err := client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if k8serrors.IsNotFound(err) {
+			continue
+		}
+
+		if err != nil {
+			b.addError("deployment %s/%s: %v", namespace, name, err)
+
+			continue
+		}
+
+		selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+		if err != nil {
+			b.addError("deployment %s/%s: %v", namespace, name, err)
+
+			continue
+		}
+
+		b.writePods(ctx, dir, client, namespace, selector.String())
+	}
+}
+
+// writePods writes the matching pods to pods_<namespace>.yaml, and the logs of each of their
+// containers to logs/<namespace>_<pod>_<container>.log.
+func (b *debugBundle) writePods(
+	ctx context.Context, dir string, client kubernetes.Interface, namespace, labelSelector string,
+) {
+	pods, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		b.addError("pods in %s with labels %s: %v", namespace, labelSelector, err)
+
+		return
+	}
+
+	if len(pods.Items) == 0 {
+		return
+	}
+
+	for i := range pods.Items {
+		pods.Items[i].ManagedFields = nil
+	}
+
+	b.write(dir, strings.Join(nonEmpty("pods", namespace, labelSelectorFileName(labelSelector)), "_")+".yaml", pods)
+
+	for _, pod := range pods.Items {
+		for _, container := range pod.Spec.Containers {
+			logs, err := client.CoreV1().Pods(namespace).GetLogs(
+				pod.Name, &corev1.PodLogOptions{Container: container.Name},
+			).DoRaw(ctx)
+			if err != nil {
+				b.addError("logs of %s/%s container %s: %v", namespace, pod.Name, container.Name, err)
+
+				continue
+			}
+
+			b.writeFile(filepath.Join(dir, "logs"), namespace+"_"+pod.Name+"_"+container.Name+".log", logs)
+		}
+	}
+}
+
+func labelSelectorFileName(labelSelector string) string {
+	return strings.Trim(unsafeFileNameChars.ReplaceAllString(labelSelector, "-"), "-")
+}
+
+func nonEmpty(values ...string) []string {
+	result := make([]string, 0, len(values))
+
+	for _, value := range values {
+		if value != "" {
+			result = append(result, value)
+		}
+	}
+
+	return result
+}
+
+// redactSecrets replaces the values of the data and stringData of any Secret in the object,
+// including Secrets embedded in policy templates. The last applied configuration annotation is
+// removed from every object since it could contain an embedded Secret.
+func redactSecrets(obj map[string]any) {
+	if obj["kind"] == "Secret" {
+		for _, field := range []string{"data", "stringData"} {
+			if data, ok := obj[field].(map[string]any); ok {
+				for key := range data {
+					data[key] = redactedValue
+				}
+			}
+		}
+	}
+
+	unstructured.RemoveNestedField(
+		obj, "metadata", "annotations", "kubectl.kubernetes.io/last-applied-configuration",
+	)
+
+	for _, value := range obj {
+		redactValue(value)
+	}
+}
+
+func redactValue(value any) {
+	switch value := value.(type) {
+	case map[string]any:
+		redactSecrets(value)
+	case []any:
+		for _, item := range value {
+			redactValue(item)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package common
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/ginkgo/v2/types"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var _ = Describe("Test the debug bundle", func() {
+	var env *Environment
+
+	BeforeEach(func(ctx SpecContext) {
+		env = startFakeBackend().Env
+		env.DebugBundleDir = GinkgoT().TempDir()
+
+		env.DoCreatePolicyTest(ctx, fakePolicyFile, GvrConfigurationPolicy)
+
+		labels := map[string]string{"app": "grc-policy-propagator"}
+
+		_, err := env.ClientHub.AppsV1().Deployments(env.OCMNamespace).Create(ctx, &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "grc-policy-propagator", Namespace: env.OCMNamespace},
+			Spec:       appsv1.DeploymentSpec{Selector: &metav1.LabelSelector{MatchLabels: labels}},
+		}, metav1.CreateOptions{})
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = env.ClientHub.CoreV1().Pods(env.OCMNamespace).Create(ctx, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "grc-policy-propagator-1", Namespace: env.OCMNamespace, Labels: labels},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "manager"}}},
+		}, metav1.CreateOptions{})
+		Expect(err).ToNot(HaveOccurred())
+
+		secret := &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata": map[string]any{
+				"name":      "my-secret",
+				"namespace": env.UserNamespace,
+				"annotations": map[string]any{
+					"kubectl.kubernetes.io/last-applied-configuration": `{"data":{"password":"c2VjcmV0"}}`,
+				},
+			},
+			"data": map[string]any{"password": "c2VjcmV0"},
+		}}
+
+		_, err = env.ClientHubDynamic.Resource(GvrSecret).Namespace(env.UserNamespace).Create(
+			ctx, secret, metav1.CreateOptions{},
+		)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	readFile := func(dir string, path ...string) string {
+		GinkgoHelper()
+
+		content, err := os.ReadFile(filepath.Join(append([]string{dir}, path...)...))
+		Expect(err).ToNot(HaveOccurred())
+
+		return string(content)
+	}
+
+	It("Collects the policies, controller logs and requested resources", func(ctx SpecContext) {
+		dir, err := env.CollectDebugBundle(ctx, DebugBundleOptions{
+			Resources: []DebugResource{
+				{Cluster: TargetHub, GVR: GvrSecret, Namespace: env.UserNamespace, Name: "my-secret"},
+				{Cluster: TargetHub, GVR: GvrConfigMap, Namespace: env.UserNamespace, Name: "missing"},
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(filepath.Dir(dir)).To(Equal(env.DebugBundleDir))
+		Expect(filepath.Base(dir)).To(HavePrefix(
+			"Test-the-debug-bundle-Collects-the-policies-controller-logs-and-requested-resources-",
+		))
+
+		Expect(readFile(dir, "hub", "policies.policy.open-cluster-management.io_policy-test.yaml")).To(
+			ContainSubstring("name: fake-policy"),
+		)
+		Expect(readFile(dir, "hub", "placements.cluster.open-cluster-management.io_policy-test.yaml")).To(
+			ContainSubstring("name: fake-policy-plr"),
+		)
+		Expect(readFile(dir, "clusters", "local-cluster",
+			"configurationpolicies.policy.open-cluster-management.io_local-cluster.yaml",
+		)).To(ContainSubstring("name: fake-policy"))
+		Expect(readFile(dir, "hub", "pods_open-cluster-management_app-grc-policy-propagator.yaml")).To(
+			ContainSubstring("name: grc-policy-propagator-1"),
+		)
+		Expect(readFile(dir, "hub", "logs", "open-cluster-management_grc-policy-propagator-1_manager.log")).To(
+			Equal("fake logs"),
+		)
+
+		secret := readFile(dir, "hub", "secrets_policy-test_my-secret.yaml")
+		Expect(secret).To(ContainSubstring("password: REDACTED"))
+		Expect(secret).ToNot(ContainSubstring("c2VjcmV0"))
+
+		Expect(filepath.Join(dir, "hub", "configmaps_policy-test_missing.yaml")).ToNot(BeAnExistingFile())
+		Expect(filepath.Join(dir, "errors.txt")).ToNot(BeAnExistingFile())
+	})
+
+	It("Attaches the path of the debug bundle to the spec report", func(ctx SpecContext) {
+		env.ReportDebugBundle(ctx, DebugBundleOptions{})
+
+		var entry *types.ReportEntry
+
+		for i := range CurrentSpecReport().ReportEntries {
+			if CurrentSpecReport().ReportEntries[i].Name == debugBundleReportEntry {
+				entry = &CurrentSpecReport().ReportEntries[i]
+			}
+		}
+
+		Expect(entry).ToNot(BeNil())
+		Expect(entry.Value.String()).To(HavePrefix(env.DebugBundleDir))
+		Expect(filepath.Join(entry.Value.String(), "hub")).To(BeADirectory())
+	})
+})
+
+var _ = Describe("Test redactSecrets", func() {
+	It("Redacts Secrets embedded in policy templates", func() {
+		policy := map[string]any{
+			"kind": "ConfigurationPolicy",
+			"spec": map[string]any{
+				"object-templates": []any{
+					map[string]any{"objectDefinition": map[string]any{
+						"kind":       "Secret",
+						"stringData": map[string]any{"token": "my-token"},
+					}},
+					map[string]any{"objectDefinition": map[string]any{
+						"kind": "ConfigMap",
+						"data": map[string]any{"city": "Raleigh"},
+					}},
+				},
+			},
+		}
+
+		redactSecrets(policy)
+
+		templates := policy["spec"].(map[string]any)["object-templates"].([]any)
+		Expect(templates[0]).To(HaveKeyWithValue("objectDefinition", HaveKeyWithValue(
+			"stringData", map[string]any{"token": redactedValue},
+		)))
+		Expect(templates[1]).To(HaveKeyWithValue("objectDefinition", HaveKeyWithValue(
+			"data", map[string]any{"city": "Raleigh"},
+		)))
+	})
+
+	It("Removes the last applied configuration of a Policy embedding a Secret", func() {
+		policy := map[string]any{
+			"kind": "Policy",
+			"metadata": map[string]any{
+				"name": "my-policy",
+				"annotations": map[string]any{
+					"kubectl.kubernetes.io/last-applied-configuration": `{"stringData":{"token":"my-token"}}`,
+					"policy.open-cluster-management.io/standards":      "NIST SP 800-53",
+				},
+			},
+			"spec": map[string]any{
+				"policy-templates": []any{
+					map[string]any{"objectDefinition": map[string]any{
+						"kind": "ConfigurationPolicy",
+						"spec": map[string]any{
+							"object-templates": []any{
+								map[string]any{"objectDefinition": map[string]any{
+									"kind":       "Secret",
+									"stringData": map[string]any{"token": "my-token"},
+								}},
+							},
+						},
+					}},
+				},
+			},
+		}
+
+		redactSecrets(policy)
+
+		Expect(policy["metadata"]).To(HaveKeyWithValue("annotations", map[string]any{
+			"policy.open-cluster-management.io/standards": "NIST SP 800-53",
+		}))
+
+		out, err := json.Marshal(policy)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(out)).ToNot(ContainSubstring("my-token"))
+	})
+})
+
+var _ = Describe("Test AddDebugResources", Ordered, func() {
+	env := &Environment{}
+	secrets := DebugResource{Cluster: TargetHub, GVR: GvrSecret, Namespace: "policy-test"}
+	pods := DebugPods{Cluster: TargetManaged, Namespace: "open-cluster-management", LabelSelector: "app=test"}
+
+	BeforeAll(func() {
+		env.AddDebugResources(DebugBundleOptions{Resources: []DebugResource{secrets}})
+	})
+
+	It("Merges the options registered by the container and the spec", func() {
+		env.AddDebugResources(DebugBundleOptions{Pods: []DebugPods{pods}})
+
+		Expect(env.registeredDebugOptions()).To(Equal(DebugBundleOptions{
+			Resources: []DebugResource{secrets},
+			Pods:      []DebugPods{pods},
+		}))
+	})
+
+	It("Removes the options of the previous spec", func() {
+		Expect(env.registeredDebugOptions()).To(Equal(DebugBundleOptions{Resources: []DebugResource{secrets}}))
+	})
+})
@@ -0,0 +1,398 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package common
+
+import (
+	"context"
+
+	"github.com/Masterminds/semver/v3"
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/ginkgo/v2/types"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	policiesv1 "open-cluster-management.io/governance-policy-propagator/api/v1"
+
+	"github.com/stolostron/governance-policy-framework/test/common/kubeclient"
+)
+
+// The functions in this file call the Environment method of the same name on the
+// DefaultEnvironment, so that suites which only talk to a single hub can keep using the
+// package-level helpers.
+
+func VerifyManagedCluster(ctx context.Context) {
+	GinkgoHelper()
+
+	DefaultEnvironment.VerifyManagedCluster(ctx)
+}
+
+func VerifyMCE(ctx context.Context) {
+	GinkgoHelper()
+
+	DefaultEnvironment.VerifyMCE(ctx)
+}
+
+func OcHub(args ...string) (string, error) {
+	return DefaultEnvironment.OcHub(args...)
+}
+
+func OcManaged(args ...string) (string, error) {
+	return DefaultEnvironment.OcManaged(args...)
+}
+
+func OcHosting(args ...string) (string, error) {
+	return DefaultEnvironment.OcHosting(args...)
+}
+
+func OcUser(user OCPUser, args ...string) (string, error) {
+	return DefaultEnvironment.OcUser(user, args...)
+}
+
+func IsAtLeastVersion(minVersion string) bool {
+	return DefaultEnvironment.IsAtLeastVersion(minVersion)
+}
+
+func CleanupHubNamespace(namespace string) {
+	GinkgoHelper()
+
+	DefaultEnvironment.CleanupHubNamespace(namespace)
+}
+
+func ApplyManagedClusterSetBinding(ctx SpecContext) error {
+	return DefaultEnvironment.ApplyManagedClusterSetBinding(ctx)
+}
+
+// Deprecated: Use WaitForCompliance, which watches the policy instead of polling it.
+func GetComplianceState(policyName string) func(Gomega) any {
+	return DefaultEnvironment.GetComplianceState(policyName)
+}
+
+// Deprecated: Use WaitForRootPolicy with the ClusterComplianceIs predicate, which watches the policy
+// instead of polling it.
+func GetClusterComplianceState(policyName, clusterName string) func(Gomega) any {
+	return DefaultEnvironment.GetClusterComplianceState(policyName, clusterName)
+}
+
+func PatchPlacement(namespace, name string) error {
+	return DefaultEnvironment.PatchPlacement(namespace, name)
+}
+
+func CreatePlacementDecision(ctx context.Context, namespace, placementName string) (*unstructured.Unstructured, error) {
+	return DefaultEnvironment.CreatePlacementDecision(ctx, namespace, placementName)
+}
+
+func ApplyPlacement(ctx SpecContext, namespace, policyName string) error {
+	return DefaultEnvironment.ApplyPlacement(ctx, namespace, policyName)
+}
+
+func DeletePlacement(namespace, policyName string) error {
+	return DefaultEnvironment.DeletePlacement(namespace, policyName)
+}
+
+func DoCreatePolicyTest(ctx context.Context, policyFile string, templateGVRs ...schema.GroupVersionResource) {
+	GinkgoHelper()
+
+	DefaultEnvironment.DoCreatePolicyTest(ctx, policyFile, templateGVRs...)
+}
+
+func DoCleanupPolicy(policyFile string, templateGVRs ...schema.GroupVersionResource) {
+	GinkgoHelper()
+
+	DefaultEnvironment.DoCleanupPolicy(policyFile, templateGVRs...)
+}
+
+func DoCreatePolicyTestOnClusters(
+	ctx context.Context, policyFile string, clusterNames []string, templateGVRs ...schema.GroupVersionResource,
+) {
+	GinkgoHelper()
+
+	DefaultEnvironment.DoCreatePolicyTestOnClusters(ctx, policyFile, clusterNames, templateGVRs...)
+}
+
+func DoCleanupPolicyOnClusters(policyFile string, clusterNames []string, templateGVRs ...schema.GroupVersionResource) {
+	GinkgoHelper()
+
+	DefaultEnvironment.DoCleanupPolicyOnClusters(policyFile, clusterNames, templateGVRs...)
+}
+
+func DoRootComplianceTest(policyName string, compliance policiesv1.ComplianceState) {
+	GinkgoHelper()
+
+	DefaultEnvironment.DoRootComplianceTest(policyName, compliance)
+}
+
+func DoClusterComplianceTest(policyName string, compliance policiesv1.ComplianceState, clusterNames ...string) {
+	GinkgoHelper()
+
+	DefaultEnvironment.DoClusterComplianceTest(policyName, compliance, clusterNames...)
+}
+
+func GetReplicatedComplianceState(policyName, clusterName string) func(Gomega) any {
+	return DefaultEnvironment.GetReplicatedComplianceState(policyName, clusterName)
+}
+
+func GetRootPolicy(policyName string) (*policiesv1.Policy, error) {
+	return DefaultEnvironment.GetRootPolicy(policyName)
+}
+
+func GetReplicatedPolicy(policyName string) (*policiesv1.Policy, error) {
+	return DefaultEnvironment.GetReplicatedPolicy(policyName)
+}
+
+func GetTemplateHistory(policyName string, templateIdx int) ([]policiesv1.ComplianceHistory, error) {
+	return DefaultEnvironment.GetTemplateHistory(policyName, templateIdx)
+}
+
+func GetHistoryMessages(policyName string, templateIdx int) ([]any, bool, error) {
+	return DefaultEnvironment.GetHistoryMessages(policyName, templateIdx)
+}
+
+func GetOpPolicyCompMsg(policyName string) func() string {
+	return DefaultEnvironment.GetOpPolicyCompMsg(policyName)
+}
+
+func GetLatestStatusMessage(policyName string, templateIdx int) func() string {
+	return DefaultEnvironment.GetLatestStatusMessage(policyName, templateIdx)
+}
+
+func GetDuplicateHistoryMessage(policyName string) string {
+	return DefaultEnvironment.GetDuplicateHistoryMessage(policyName)
+}
+
+func DoHistoryUpdatedTest(policyName string, messages ...string) {
+	GinkgoHelper()
+
+	DefaultEnvironment.DoHistoryUpdatedTest(policyName, messages...)
+}
+
+func InformPolicy(policyName string, templateGVRs ...schema.GroupVersionResource) {
+	GinkgoHelper()
+
+	DefaultEnvironment.InformPolicy(policyName, templateGVRs...)
+}
+
+func EnforcePolicy(policyName string, templateGVRs ...schema.GroupVersionResource) {
+	GinkgoHelper()
+
+	DefaultEnvironment.EnforcePolicy(policyName, templateGVRs...)
+}
+
+func InformPolicyOnClusters(policyName string, clusterNames []string, templateGVRs ...schema.GroupVersionResource) {
+	GinkgoHelper()
+
+	DefaultEnvironment.InformPolicyOnClusters(policyName, clusterNames, templateGVRs...)
+}
+
+func EnforcePolicyOnClusters(policyName string, clusterNames []string, templateGVRs ...schema.GroupVersionResource) {
+	GinkgoHelper()
+
+	DefaultEnvironment.EnforcePolicyOnClusters(policyName, clusterNames, templateGVRs...)
+}
+
+func GitOpsUserSetup(ctx SpecContext, ocpUser *OCPUser) {
+	GinkgoHelper()
+
+	DefaultEnvironment.GitOpsUserSetup(ctx, ocpUser)
+}
+
+func GitOpsCleanup(ctx SpecContext, user OCPUser) {
+	GinkgoHelper()
+
+	DefaultEnvironment.GitOpsCleanup(ctx, user)
+}
+
+func WaitForObject(
+	ctx context.Context,
+	client dynamic.Interface,
+	gvr schema.GroupVersionResource,
+	namespace, name, description string,
+	predicate Predicate,
+) (*unstructured.Unstructured, error) {
+	return DefaultEnvironment.WaitForObject(ctx, client, gvr, namespace, name, description, predicate)
+}
+
+func WaitForRootPolicy(
+	ctx context.Context, policyName, description string, predicate Predicate,
+) (*unstructured.Unstructured, error) {
+	return DefaultEnvironment.WaitForRootPolicy(ctx, policyName, description, predicate)
+}
+
+func WaitForCompliance(
+	ctx context.Context, policyName string, compliance policiesv1.ComplianceState, timeoutSeconds int,
+) error {
+	return DefaultEnvironment.WaitForCompliance(ctx, policyName, compliance, timeoutSeconds)
+}
+
+func WaitForReplicatedPolicy(
+	ctx context.Context, policyName, clusterName, description string, predicate Predicate,
+) (*unstructured.Unstructured, error) {
+	return DefaultEnvironment.WaitForReplicatedPolicy(ctx, policyName, clusterName, description, predicate)
+}
+
+func WaitForConfigurationPolicy(
+	ctx context.Context, name, clusterName, description string, predicate Predicate,
+) (*unstructured.Unstructured, error) {
+	return DefaultEnvironment.WaitForConfigurationPolicy(ctx, name, clusterName, description, predicate)
+}
+
+func WaitForOperatorPolicy(
+	ctx context.Context, name, clusterName, description string, predicate Predicate,
+) (*unstructured.Unstructured, error) {
+	return DefaultEnvironment.WaitForOperatorPolicy(ctx, name, clusterName, description, predicate)
+}
+
+func NewComplianceTimeline(policyNames ...string) *ComplianceTimeline {
+	return DefaultEnvironment.NewComplianceTimeline(policyNames...)
+}
+
+func RecordComplianceTimeline(policyNames ...string) *ComplianceTimeline {
+	GinkgoHelper()
+
+	return DefaultEnvironment.RecordComplianceTimeline(policyNames...)
+}
+
+func CollectDebugBundle(ctx context.Context, opts DebugBundleOptions) (string, error) {
+	return DefaultEnvironment.CollectDebugBundle(ctx, opts)
+}
+
+func ReportDebugBundle(ctx context.Context, opts DebugBundleOptions) {
+	DefaultEnvironment.ReportDebugBundle(ctx, opts)
+}
+
+func ReportFailedSpecDebugBundle(ctx SpecContext) {
+	DefaultEnvironment.ReportFailedSpecDebugBundle(ctx)
+}
+
+func AddDebugResources(opts DebugBundleOptions) {
+	DefaultEnvironment.AddDebugResources(opts)
+}
+
+func NewScenarioRun(scenario *Scenario) *ScenarioRun {
+	return DefaultEnvironment.NewScenarioRun(scenario)
+}
+
+func DescribeScenario(path string) bool {
+	return DefaultEnvironment.DescribeScenario(path)
+}
+
+func DescribeScenarios(pattern string) bool {
+	return DefaultEnvironment.DescribeScenarios(pattern)
+}
+
+func LoadFixture(source string, vars map[string]any) (*Fixture, error) {
+	return DefaultEnvironment.LoadFixture(source, vars)
+}
+
+func ApplyFixture(ctx context.Context, cluster ClusterTarget, fixture *Fixture) ([]kubeclient.ApplyResult, error) {
+	return DefaultEnvironment.ApplyFixture(ctx, cluster, fixture)
+}
+
+func DeleteFixture(ctx context.Context, cluster ClusterTarget, fixture *Fixture) ([]kubeclient.ApplyResult, error) {
+	return DefaultEnvironment.DeleteFixture(ctx, cluster, fixture)
+}
+
+func UserNamespaceIsolated() bool {
+	return DefaultEnvironment.UserNamespaceIsolated()
+}
+
+func SetupUserNamespace(ctx context.Context, opts UserNamespaceOptions) error {
+	return DefaultEnvironment.SetupUserNamespace(ctx, opts)
+}
+
+func TeardownUserNamespace(ctx context.Context) error {
+	return DefaultEnvironment.TeardownUserNamespace(ctx)
+}
+
+func CreateIsolatedNamespace(ctx context.Context, opts UserNamespaceOptions) (string, error) {
+	return DefaultEnvironment.CreateIsolatedNamespace(ctx, opts)
+}
+
+func NewLeakAuditor(resources ...AuditedResource) *LeakAuditor {
+	return DefaultEnvironment.NewLeakAuditor(resources...)
+}
+
+func StartLeakAudit(ctx context.Context) error {
+	return DefaultEnvironment.StartLeakAudit(ctx)
+}
+
+func OwnershipLabels() map[string]string {
+	return DefaultEnvironment.OwnershipLabels()
+}
+
+func CleanupByRun(ctx context.Context, runID string) ([]kubeclient.ApplyResult, error) {
+	return DefaultEnvironment.CleanupByRun(ctx, runID)
+}
+
+func CheckGates(ctx context.Context) {
+	GinkgoHelper()
+
+	DefaultEnvironment.CheckGates(ctx)
+}
+
+func UnmetGate(ctx context.Context, report types.SpecReport) (string, error) {
+	return DefaultEnvironment.UnmetGate(ctx, report)
+}
+
+func ComponentVersion(ctx context.Context, component string) (*semver.Version, error) {
+	return DefaultEnvironment.ComponentVersion(ctx, component)
+}
+
+func SupportedACMVersions() ([]string, error) {
+	return DefaultEnvironment.SupportedACMVersions()
+}
+
+func DiscoverCapabilities(ctx context.Context) (Capabilities, error) {
+	return DefaultEnvironment.DiscoverCapabilities(ctx)
+}
+
+func HasCapability(ctx context.Context, capability Capability) bool {
+	GinkgoHelper()
+
+	return DefaultEnvironment.HasCapability(ctx, capability)
+}
+
+func ReportCapabilities(ctx context.Context) error {
+	return DefaultEnvironment.ReportCapabilities(ctx)
+}
+
+func SetCapabilities(capabilities Capabilities) {
+	DefaultEnvironment.SetCapabilities(capabilities)
+}
+
+func TopologyOf(cluster *ManagedCluster) Topology {
+	return DefaultEnvironment.TopologyOf(cluster)
+}
+
+func GetPolicyTemplate(ctx context.Context, gvr schema.GroupVersionResource, name string) (
+	*unstructured.Unstructured, error,
+) {
+	return DefaultEnvironment.GetPolicyTemplate(ctx, gvr, name)
+}
+
+func GetManagedObject(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string) (
+	*unstructured.Unstructured, error,
+) {
+	return DefaultEnvironment.GetManagedObject(ctx, gvr, namespace, name)
+}
+
+func AddonPods(ctx context.Context, labelSelector string) (*corev1.PodList, error) {
+	return DefaultEnvironment.AddonPods(ctx, labelSelector)
+}
+
+func ApplyTopologyConfig(config *TopologyConfig, hubName string) error {
+	return DefaultEnvironment.ApplyTopologyConfig(config, hubName)
+}
+
+func TrackAPIRequests() {
+	DefaultEnvironment.TrackAPIRequests()
+}
+
+func TrackCommands() {
+	DefaultEnvironment.TrackCommands()
+}
+
+func Commands() CommandTranscripts {
+	return DefaultEnvironment.Commands()
+}
@@ -0,0 +1,389 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package common
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/Masterminds/semver/v3"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/transport"
+
+	"github.com/stolostron/governance-policy-framework/test/common/kubeclient"
+)
+
+// Environment holds the configuration and clients needed to run policy tests against a hub and a
+// managed cluster. Every helper in this package is available as a method on Environment, so that
+// a test suite can work with several hubs at once by creating multiple instances.
+type Environment struct {
+	KubeconfigHub     string
+	KubeconfigManaged string
+	// ContextHub and ContextManaged are the contexts of the kubeconfigs to use instead of their
+	// current contexts.
+	ContextHub     string
+	ContextManaged string
+	// TopologyFile is a TopologyConfig which InitInterfaces applies, overriding the kubeconfig and
+	// namespace flags, with the clusters of its TopologyHub, or of its first hub if that's not set.
+	TopologyFile  string
+	TopologyHub   string
+	UserNamespace string
+	// IsolateUserNamespace makes SetupUserNamespace create a user namespace per Ginkgo process even
+	// when not running in parallel.
+	IsolateUserNamespace   bool
+	ClusterNamespace       string
+	ClusterNamespaceOnHub  string
+	PolicyCollectionBranch string
+	OCMNamespace           string
+	OCMAddOnNamespace      string
+	DefaultTimeoutSeconds  int
+	ManuallyPatchDecisions bool
+	K8sClient              string
+	IsHosted               bool
+	// NativeK8sClient handles the apply, delete, patch, and logs commands of OcHub, OcManaged, and
+	// OcHosting with client-go instead of running the K8sClient binary.
+	NativeK8sClient bool
+	// DebugBundleDir is the directory where the debug bundles of failed specs are written.
+	DebugBundleDir string
+	// RunID identifies the objects created by this run with the RunIDLabel. InitInterfaces generates
+	// one if it's not set, and the suites share the run ID of process 1 with the other processes.
+	RunID string
+	// LeakAudit is the mode of the leak audit run by StartLeakAudit: off, report, or remove.
+	LeakAudit string
+	// ACMVersion is the ACM version under test used by RequiresACM, which must be one of the
+	// SupportedACMVersions. It defaults to the version of the ACM operator installed on the hub, or
+	// else CURRENT_VERSION.
+	ACMVersion string
+	// VersionFilesDir is the directory of the CURRENT_VERSION and CURRENT_SUPPORTED_VERSIONS files.
+	VersionFilesDir string
+
+	// AdditionalManagedClusters are registered by InitInterfaces in the format of
+	// `name=kubeconfig[,name=kubeconfig...]`, in addition to the default managed cluster.
+	AdditionalManagedClusters string
+	// ClientOptions configure the rate limiting, timeouts, and retries of the clients created by
+	// InitInterfaces.
+	ClientOptions kubeclient.ClientOptions
+	// APIAccounting counts the requests of the clients created by InitInterfaces, which
+	// TrackAPIRequests reports for each spec.
+	APIAccounting *APIAccounting
+	// ManagedClusters are the managed clusters that policies can be placed on, keyed by name.
+	ManagedClusters map[string]*ManagedCluster
+
+	ClientHub            kubernetes.Interface
+	ClientHubDynamic     dynamic.Interface
+	ClientManaged        kubernetes.Interface
+	ClientManagedDynamic dynamic.Interface
+	ClientHosting        kubernetes.Interface
+	ClientHostingDynamic dynamic.Interface
+
+	appliers     map[dynamic.Interface]*kubeclient.Applier
+	appliersLock sync.Mutex
+	// userNamespace is what SetupUserNamespace created, for the teardown.
+	userNamespace *namespaceAllocation
+	// additionalClusterConfigs are the additional managed clusters of the topology file.
+	additionalClusterConfigs []ManagedClusterConfig
+	topologyFileApplied      bool
+	// componentVersions and crds cache what the gates of CheckGates looked up.
+	componentVersions map[string]*semver.Version
+	crds              map[string]bool
+	gatesLock         sync.Mutex
+	// transcripts are the commands recorded since TrackCommands was called.
+	transcripts     CommandTranscripts
+	trackCommands   bool
+	transcriptsLock sync.Mutex
+	// capabilities are what DiscoverCapabilities found.
+	capabilities     Capabilities
+	capabilitiesLock sync.Mutex
+	// debugOptions are what AddDebugResources registered for the debug bundles of failed specs.
+	debugOptions     []*DebugBundleOptions
+	debugOptionsLock sync.Mutex
+}
+
+// ClusterTarget selects one of the clusters of an Environment.
+type ClusterTarget string
+
+const (
+	TargetHub     ClusterTarget = "hub"
+	TargetManaged ClusterTarget = "managed"
+	// TargetHosting is the cluster hosting the policy templates, which is the hub in hosted mode.
+	TargetHosting ClusterTarget = "hosting"
+)
+
+// DefaultEnvironment is the Environment used by the package-level helper functions. It is
+// configured by InitFlags and InitInterfaces.
+var DefaultEnvironment = NewEnvironment()
+
+// NewEnvironment returns an Environment with the same defaults as the flags registered by
+// InitFlags. The clients are not set until InitInterfaces is called.
+func NewEnvironment() *Environment {
+	return &Environment{
+		KubeconfigHub:          "../../kubeconfig_hub",
+		KubeconfigManaged:      "../../kubeconfig_managed",
+		UserNamespace:          "policy-test",
+		ClusterNamespace:       "local-cluster",
+		PolicyCollectionBranch: "main",
+		OCMNamespace:           "open-cluster-management",
+		OCMAddOnNamespace:      "open-cluster-management-agent-addon",
+		DefaultTimeoutSeconds:  30,
+		ManuallyPatchDecisions: true,
+		K8sClient:              "oc",
+		NativeK8sClient:        true,
+		DebugBundleDir:         "../../debug-bundles",
+		LeakAudit:              LeakAuditReport,
+		VersionFilesDir:        "../..",
+		ClientOptions:          kubeclient.DefaultClientOptions(),
+		APIAccounting:          NewAPIAccounting(),
+	}
+}
+
+// InitFlags registers the command line flags which configure the Environment on the given
+// flagset, or on flag.CommandLine if it is nil.
+func (e *Environment) InitFlags(flagset *flag.FlagSet) {
+	if flagset == nil {
+		flagset = flag.CommandLine
+	}
+
+	flagset.StringVar(
+		&e.KubeconfigHub, "kubeconfig_hub", e.KubeconfigHub,
+		"Location of the kubeconfig to use; defaults to KUBECONFIG if not set",
+	)
+	flagset.StringVar(
+		&e.KubeconfigManaged, "kubeconfig_managed", e.KubeconfigManaged,
+		"Location of the kubeconfig to use; defaults to KUBECONFIG if not set",
+	)
+
+	flagset.StringVar(
+		&e.ContextHub, "context_hub", e.ContextHub,
+		"Context of the hub kubeconfig to use; defaults to its current context",
+	)
+	flagset.StringVar(
+		&e.ContextManaged, "context_managed", e.ContextManaged,
+		"Context of the managed kubeconfig to use; defaults to its current context",
+	)
+	flagset.StringVar(
+		&e.TopologyFile, "topology_file", e.TopologyFile,
+		"Topology file describing the hubs and managed clusters, which overrides the kubeconfig and namespace flags",
+	)
+	flagset.StringVar(
+		&e.TopologyHub, "topology_hub", e.TopologyHub,
+		"Name of the hub of the topology file to test; defaults to the first hub",
+	)
+	flagset.BoolVar(
+		&e.IsHosted, "is_hosted", e.IsHosted,
+		"Whether is hosted mode or not",
+	)
+	flagset.StringVar(&e.UserNamespace, "user_namespace",
+		e.UserNamespace, "ns on hub to create root policy")
+	flagset.BoolVar(
+		&e.IsolateUserNamespace, "isolate_user_namespace", e.IsolateUserNamespace,
+		"Whether to create a user namespace per Ginkgo process, which is always done when running in parallel",
+	)
+	flagset.StringVar(&e.ClusterNamespace, "cluster_namespace", e.ClusterNamespace, "cluster ns name")
+	flagset.StringVar(
+		&e.ClusterNamespaceOnHub, "cluster_namespace_on_hub", e.ClusterNamespaceOnHub, "cluster ns name on hub",
+	)
+	flagset.StringVar(&e.OCMNamespace, "ocm_namespace", e.OCMNamespace, "ns of ocm installation")
+	flagset.StringVar(
+		&e.OCMAddOnNamespace,
+		"ocm_addon_namespace",
+		e.OCMAddOnNamespace,
+		"ns of ocm addon installations",
+	)
+	flagset.StringVar(
+		&e.PolicyCollectionBranch, "policy_collection_branch", e.PolicyCollectionBranch,
+		"the branch of the policy-collection repo",
+	)
+	flagset.IntVar(
+		&e.DefaultTimeoutSeconds, "timeout_seconds", e.DefaultTimeoutSeconds, "Timeout seconds for assertion",
+	)
+	flagset.BoolVar(
+		&e.ManuallyPatchDecisions, "patch_decisions", e.ManuallyPatchDecisions,
+		"Whether to 'manually' patch Placements with PlacementDecisions "+
+			"(set to false if the Placement controller is running)",
+	)
+	flagset.StringVar(
+		&e.K8sClient, "k8s_client", e.K8sClient,
+		"Which k8s client to use for some tests - `oc`, `kubectl`, "+
+			"or something else entirely",
+	)
+	flagset.BoolVar(
+		&e.NativeK8sClient, "native_k8s_client", e.NativeK8sClient,
+		"Whether to run the apply, delete, patch, and logs commands with client-go instead of the k8s_client",
+	)
+	flagset.StringVar(
+		&e.DebugBundleDir, "debug_bundle_dir", e.DebugBundleDir,
+		"Directory to write the debug bundles of failed specs to",
+	)
+	flagset.StringVar(
+		&e.RunID, "run_id", e.RunID,
+		"ID of the run to label the created objects with; defaults to one generated from the current time",
+	)
+	flagset.StringVar(
+		&e.LeakAudit, "leak_audit", e.LeakAudit,
+		"Whether to report (`report`) the objects the suite leaked, also remove (`remove`) those labeled with "+
+			"its run ID, or not audit them (`off`)",
+	)
+	flagset.StringVar(
+		&e.ACMVersion, "acm_version", e.ACMVersion,
+		"ACM version under test, one of CURRENT_VERSION or CURRENT_SUPPORTED_VERSIONS; "+
+			"defaults to the version of the installed ACM operator, or else CURRENT_VERSION",
+	)
+	flagset.StringVar(
+		&e.VersionFilesDir, "version_files_dir", e.VersionFilesDir,
+		"Directory of the CURRENT_VERSION and CURRENT_SUPPORTED_VERSIONS files",
+	)
+	flagset.Var(
+		(*float32Flag)(&e.ClientOptions.QPS), "client_qps",
+		"Client-side rate limit of the requests to each cluster, in queries per second",
+	)
+	flagset.IntVar(
+		&e.ClientOptions.Burst, "client_burst", e.ClientOptions.Burst,
+		"Client-side burst of the requests to each cluster above the client_qps",
+	)
+	flagset.DurationVar(
+		&e.ClientOptions.Timeout, "client_timeout", e.ClientOptions.Timeout,
+		"Timeout of each attempt of a request to the clusters, except for watches; 0 means no timeout",
+	)
+	flagset.IntVar(
+		&e.ClientOptions.Retries, "client_retries", e.ClientOptions.Retries,
+		"How many times to retry a request to the clusters after a transient error, with an exponential backoff",
+	)
+	flagset.DurationVar(
+		&e.ClientOptions.RetryBackoff, "client_retry_backoff", e.ClientOptions.RetryBackoff,
+		"Delay before the first retry of a request, which doubles with every retry",
+	)
+	flagset.StringVar(
+		&e.AdditionalManagedClusters, "managed_clusters", e.AdditionalManagedClusters,
+		"Additional managed clusters to register, in the format name=kubeconfig[,name=kubeconfig...]",
+	)
+}
+
+// InitInterfaces applies the TopologyFile, initializes the hub, managed, and hosting clients from
+// the configured kubeconfigs and contexts with the ClientOptions, and generates the RunID if it's not
+// set. Should be called after the flags are parsed, and before any tests using the Environment are
+// run.
+func (e *Environment) InitInterfaces() error {
+	if e.RunID == "" {
+		e.RunID = NewRunID()
+	}
+
+	if !e.topologyFileApplied {
+		if err := e.loadTopologyFile(); err != nil {
+			return err
+		}
+
+		e.topologyFileApplied = true
+	}
+
+	var err error
+
+	if e.ClientHub, e.ClientHubDynamic, err = e.newClients("hub", e.KubeconfigHub, e.ContextHub); err != nil {
+		return fmt.Errorf("failed to create the hub clients: %w", err)
+	}
+
+	if e.ClusterNamespaceOnHub == "" {
+		e.ClusterNamespaceOnHub = e.ClusterNamespace
+	}
+
+	if e.ClientManaged, e.ClientManagedDynamic, err = e.newClients(
+		e.ClusterNamespaceOnHub, e.KubeconfigManaged, e.ContextManaged,
+	); err != nil {
+		return fmt.Errorf("failed to create the managed cluster clients: %w", err)
+	}
+
+	if e.IsHosted {
+		e.ClientHosting, e.ClientHostingDynamic = e.ClientHub, e.ClientHubDynamic
+	} else {
+		e.ClientHosting, e.ClientHostingDynamic = e.ClientManaged, e.ClientManagedDynamic
+	}
+
+	e.ManagedClusters = map[string]*ManagedCluster{}
+	e.ManagedClusters[e.ClusterNamespaceOnHub] = e.DefaultManagedCluster()
+
+	if err := e.registerManagedClusters(e.AdditionalManagedClusters); err != nil {
+		return err
+	}
+
+	return e.registerClusterConfigs()
+}
+
+// newClients returns the clients of the kubeconfig and context configured with the ClientOptions,
+// whose requests are counted by the APIAccounting under the given cluster name.
+func (e *Environment) newClients(
+	cluster, kubeconfig, kubeContext string,
+) (kubernetes.Interface, dynamic.Interface, error) {
+	options := e.ClientOptions
+
+	if e.APIAccounting != nil {
+		options.WrapTransport = transport.Wrappers(options.WrapTransport, e.APIAccounting.Wrapper(cluster))
+	}
+
+	client, err := NewKubeClient("", kubeconfig, kubeContext, options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	clientDynamic, err := NewKubeClientDynamic("", kubeconfig, kubeContext, options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return client, clientDynamic, nil
+}
+
+// targetClients returns the clients of the given cluster.
+func (e *Environment) targetClients(cluster ClusterTarget) (kubernetes.Interface, dynamic.Interface) {
+	switch cluster {
+	case TargetManaged:
+		return e.ClientManaged, e.ClientManagedDynamic
+	case TargetHosting:
+		return e.ClientHosting, e.ClientHostingDynamic
+	default:
+		return e.ClientHub, e.ClientHubDynamic
+	}
+}
+
+// targetOrDefault returns the cluster, or the default cluster if it is not set.
+func targetOrDefault(cluster, defaultCluster ClusterTarget) ClusterTarget {
+	if cluster == "" {
+		return defaultCluster
+	}
+
+	return cluster
+}
+
+// InitFlags registers the command line flags for the DefaultEnvironment.
+func InitFlags(flagset *flag.FlagSet) {
+	DefaultEnvironment.InitFlags(flagset)
+}
+
+// InitInterfaces Initializes the Hub and Managed Clients of the DefaultEnvironment. Should be
+// called after InitFlags, and before any tests using common functions are run.
+func InitInterfaces(hubConfig, managedConfig string, isHosted bool) error {
+	DefaultEnvironment.KubeconfigHub = hubConfig
+	DefaultEnvironment.KubeconfigManaged = managedConfig
+	DefaultEnvironment.IsHosted = isHosted
+
+	return DefaultEnvironment.InitInterfaces()
+}
+
+// float32Flag is a flag.Value of the float32 it points to.
+type float32Flag float32
+
+func (f *float32Flag) String() string {
+	return strconv.FormatFloat(float64(*f), 'g', -1, 32)
+}
+
+func (f *float32Flag) Set(value string) error {
+	parsed, err := strconv.ParseFloat(value, 32)
+	if err != nil {
+		return err
+	}
+
+	*f = float32Flag(parsed)
+
+	return nil
+}
@@ -0,0 +1,88 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package common
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// FakeBackend is an offline hub and managed cluster, with an Environment configured to use them, so
+// that the helpers of this package can be unit tested without a live cluster. The Propagator takes
+// the place of the policy controllers.
+type FakeBackend struct {
+	Env        *Environment
+	Hub        *FakeCluster
+	Managed    *FakeCluster
+	Propagator *FakePropagator
+	// Clusters are the fake managed clusters, keyed by name, including the default managed cluster.
+	Clusters map[string]*FakeCluster
+}
+
+// NewFakeBackend returns a FakeBackend with the default Environment configuration, except that the
+// timeout is shortened to keep failing unit tests fast. The hub is seeded with the given objects,
+// and a ManagedCluster for the default managed cluster.
+func NewFakeBackend(hubObjects ...runtime.Object) *FakeBackend {
+	env := NewEnvironment()
+	env.DefaultTimeoutSeconds = 5
+	env.ClusterNamespaceOnHub = env.ClusterNamespace
+
+	hub := NewFakeCluster(hubObjects...)
+	managed := NewFakeCluster()
+
+	env.ClientHub = hub.Client
+	env.ClientHubDynamic = hub.ClientDynamic
+	env.ClientManaged = managed.Client
+	env.ClientManagedDynamic = managed.ClientDynamic
+	env.ClientHosting = managed.Client
+	env.ClientHostingDynamic = managed.ClientDynamic
+
+	env.ManagedClusters = map[string]*ManagedCluster{}
+	env.ManagedClusters[env.ClusterNamespaceOnHub] = env.DefaultManagedCluster()
+
+	backend := &FakeBackend{
+		Env:        env,
+		Hub:        hub,
+		Managed:    managed,
+		Propagator: NewFakePropagator(env),
+		Clusters:   map[string]*FakeCluster{env.ClusterNamespaceOnHub: managed},
+	}
+
+	backend.addManagedClusterObject(env.ClusterNamespaceOnHub)
+
+	return backend
+}
+
+// AddManagedCluster adds a fake managed cluster of the given name to the backend, and registers it
+// in the Environment like RegisterManagedCluster does.
+func (b *FakeBackend) AddManagedCluster(name string) *FakeCluster {
+	cluster := NewFakeCluster()
+
+	b.Clusters[name] = cluster
+	b.Env.ManagedClusters[name] = &ManagedCluster{
+		Name:                 name,
+		Namespace:            name,
+		Client:               cluster.Client,
+		ClientDynamic:        cluster.ClientDynamic,
+		ClientHosting:        cluster.Client,
+		ClientHostingDynamic: cluster.ClientDynamic,
+	}
+
+	b.addManagedClusterObject(name)
+
+	return cluster
+}
+
+func (b *FakeBackend) addManagedClusterObject(name string) {
+	managedCluster := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": GvrManagedCluster.GroupVersion().String(),
+		"kind":       "ManagedCluster",
+		"metadata": map[string]any{
+			"name":   name,
+			"labels": map[string]any{"name": name},
+		},
+	}}
+
+	// The tracker only fails to add an object when it already exists, which is fine to ignore.
+	_ = b.Hub.ClientDynamic.Tracker().Add(managedCluster)
+}
@@ -0,0 +1,200 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+// fakeResource is a resource known to a FakeCluster.
+type fakeResource struct {
+	gvr        schema.GroupVersionResource
+	kind       string
+	namespaced bool
+}
+
+// fakeResources are the resources served by a FakeCluster, which are all of the Gvr variables of
+// this package.
+var fakeResources = []fakeResource{
+	{GvrPod, "Pod", true},
+	{GvrNS, "Namespace", false},
+	{GvrConfigMap, "ConfigMap", true},
+	{GvrSecret, "Secret", true},
+	{GvrRole, "Role", true},
+	{GvrClusterRole, "ClusterRole", false},
+	{GvrClusterRoleBinding, "ClusterRoleBinding", false},
+	{GvrCRD, "CustomResourceDefinition", false},
+	{GvrPolicy, "Policy", true},
+	{GvrPolicySet, "PolicySet", true},
+	{GvrConfigurationPolicy, "ConfigurationPolicy", true},
+	{GvrOperatorPolicy, "OperatorPolicy", true},
+	{GvrSubscriptionOLM, "Subscription", true},
+	{GvrOperatorGroup, "OperatorGroup", true},
+	{GvrClusterServiceVersion, "ClusterServiceVersion", true},
+	{GvrCertPolicy, "CertificatePolicy", true},
+	{GvrDeployment, "Deployment", true},
+	{GvrPlacementBinding, "PlacementBinding", true},
+	{GvrSubscription, "Subscription", true},
+	{GvrK8sRequiredLabels, "K8sRequiredLabels", false},
+	{GvrClusterVersion, "ClusterVersion", false},
+	{GvrAPIServer, "APIServer", false},
+	{GvrComplianceScan, "ComplianceScan", true},
+	{GvrComplianceSuite, "ComplianceSuite", true},
+	{GvrComplianceCheckResult, "ComplianceCheckResult", true},
+	{GvrSCC, "SecurityContextConstraints", false},
+	{GvrRoute, "Route", true},
+	{GvrOAuth, "OAuth", false},
+	{GvrUser, "User", false},
+	{GvrIdentity, "Identity", false},
+	{GvrManagedCluster, "ManagedCluster", false},
+	{GvrManagedClusterSet, "ManagedClusterSet", false},
+	{GvrAddonDeploymentConfig, "AddOnDeploymentConfig", true},
+	{GvrClusterManagementAddOn, "ClusterManagementAddOn", false},
+	{GvrManagedClusterAddOn, "ManagedClusterAddOn", true},
+	{GvrManagedClusterSetBinding, "ManagedClusterSetBinding", true},
+	{GvrPlacement, "Placement", true},
+	{GvrPlacementDecision, "PlacementDecision", true},
+	{GvrPolicyReport, "PolicyReport", true},
+}
+
+// fakeResourceFor returns the resource of the given kind served by a FakeCluster, if any.
+func fakeResourceFor(gvk schema.GroupVersionKind) (fakeResource, bool) {
+	for _, resource := range fakeResources {
+		if resource.gvr.GroupVersion() == gvk.GroupVersion() && resource.kind == gvk.Kind {
+			return resource, true
+		}
+	}
+
+	return fakeResource{}, false
+}
+
+// FakeCluster is an in-memory cluster built on the client-go fakes, for unit testing helpers without
+// a live cluster. The typed and dynamic clients have separate object stores, and the dynamic client
+// serves all of the Gvr resources of this package.
+type FakeCluster struct {
+	Client        *kubefake.Clientset
+	ClientDynamic *dynamicfake.FakeDynamicClient
+}
+
+// NewFakeCluster returns a FakeCluster whose dynamic client is seeded with the given objects.
+func NewFakeCluster(objects ...runtime.Object) *FakeCluster {
+	listKinds := make(map[schema.GroupVersionResource]string, len(fakeResources))
+	resourceLists := map[string]*metav1.APIResourceList{}
+	groupVersions := []string{}
+
+	for _, resource := range fakeResources {
+		listKinds[resource.gvr] = resource.kind + "List"
+
+		groupVersion := resource.gvr.GroupVersion().String()
+		if _, ok := resourceLists[groupVersion]; !ok {
+			resourceLists[groupVersion] = &metav1.APIResourceList{GroupVersion: groupVersion}
+			groupVersions = append(groupVersions, groupVersion)
+		}
+
+		resourceLists[groupVersion].APIResources = append(resourceLists[groupVersion].APIResources,
+			metav1.APIResource{
+				Name:       resource.gvr.Resource,
+				Kind:       resource.kind,
+				Namespaced: resource.namespaced,
+				Verbs:      metav1.Verbs{"create", "delete", "get", "list", "patch", "update", "watch"},
+			},
+		)
+	}
+
+	cluster := &FakeCluster{
+		Client: kubefake.NewClientset(),
+		ClientDynamic: dynamicfake.NewSimpleDynamicClientWithCustomListKinds(
+			runtime.NewScheme(), listKinds, objects...,
+		),
+	}
+
+	if discovery, ok := cluster.Client.Discovery().(*fakediscovery.FakeDiscovery); ok {
+		for _, groupVersion := range groupVersions {
+			discovery.Resources = append(discovery.Resources, resourceLists[groupVersion])
+		}
+	}
+
+	// The fake object tracker can't handle server-side apply requests for unstructured objects, so
+	// approximate them with a JSON merge patch, creating the object if it doesn't exist.
+	cluster.ClientDynamic.PrependReactor("patch", "*", cluster.applyReactor)
+
+	return cluster
+}
+
+func (c *FakeCluster) applyReactor(action clienttesting.Action) (bool, runtime.Object, error) {
+	patchAction, ok := action.(clienttesting.PatchAction)
+	if !ok || patchAction.GetPatchType() != types.ApplyPatchType {
+		return false, nil, nil
+	}
+
+	tracker := c.ClientDynamic.Tracker()
+	gvr := patchAction.GetResource()
+	namespace := patchAction.GetNamespace()
+	name := patchAction.GetName()
+
+	patch := map[string]any{}
+	if err := json.Unmarshal(patchAction.GetPatch(), &patch); err != nil {
+		return true, nil, k8serrors.NewBadRequest(err.Error())
+	}
+
+	existing, err := tracker.Get(gvr, namespace, name)
+	if k8serrors.IsNotFound(err) {
+		obj := &unstructured.Unstructured{Object: patch}
+		obj.SetName(name)
+		obj.SetNamespace(namespace)
+
+		err = tracker.Create(gvr, obj, namespace)
+	} else if err == nil {
+		existingObj, ok := existing.(*unstructured.Unstructured)
+		if !ok {
+			return true, nil, fmt.Errorf("unexpected object type %T", existing)
+		}
+
+		obj := existingObj.DeepCopy()
+		mergeJSON(obj.Object, patch)
+
+		err = tracker.Update(gvr, obj, namespace)
+	}
+
+	if err != nil {
+		return true, nil, err
+	}
+
+	applied, err := tracker.Get(gvr, namespace, name)
+
+	return true, applied, err
+}
+
+// mergeJSON merges the patch into the object with the semantics of a JSON merge patch.
+func mergeJSON(obj, patch map[string]any) {
+	for key, patchValue := range patch {
+		if patchValue == nil {
+			delete(obj, key)
+
+			continue
+		}
+
+		patchMap, patchIsMap := patchValue.(map[string]any)
+		objMap, objIsMap := obj[key].(map[string]any)
+
+		if patchIsMap && objIsMap {
+			mergeJSON(objMap, patchMap)
+
+			continue
+		}
+
+		obj[key] = patchValue
+	}
+}
@@ -0,0 +1,516 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package common
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog"
+	policiesv1 "open-cluster-management.io/governance-policy-propagator/api/v1"
+)
+
+const (
+	rootPolicyLabel       = "policy.open-cluster-management.io/root-policy"
+	clusterNameLabel      = "policy.open-cluster-management.io/cluster-name"
+	clusterNamespaceLabel = "policy.open-cluster-management.io/cluster-namespace"
+	placementLabel        = "cluster.open-cluster-management.io/placement"
+	maxHistoryLength      = 10
+)
+
+// FakePropagator is a simplified policy propagator and template sync for an Environment backed by
+// fake clusters. Root policies are replicated as `<namespace>.<name>` into the namespace of each
+// managed cluster chosen by the PlacementDecisions of their bound Placements, along with their policy
+// templates. The compliance of the replicated policies, which can be scripted with SetTemplateStatus,
+// is copied back to the status of the root policies.
+type FakePropagator struct {
+	env  *Environment
+	lock sync.Mutex
+}
+
+// NewFakePropagator returns a FakePropagator for the hub and managed clusters of the Environment.
+func NewFakePropagator(env *Environment) *FakePropagator {
+	return &FakePropagator{env: env}
+}
+
+// Start runs Sync on the given interval in a goroutine, until the context is canceled.
+func (p *FakePropagator) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if err := p.Sync(ctx); err != nil && ctx.Err() == nil {
+				klog.Errorf("Failed to sync the fake propagator: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Sync replicates all of the root policies on the hub to their managed clusters, removes any
+// replicated policies which are no longer placed, and updates the status of the root policies.
+func (p *FakePropagator) Sync(ctx context.Context) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	policies, err := p.env.ClientHubDynamic.Resource(GvrPolicy).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	// The names of the replicated policies that should exist, keyed by managed cluster name
+	desired := map[string]map[string]bool{}
+
+	for i := range policies.Items {
+		rootPolicy := &policies.Items[i]
+		if _, isReplicated := rootPolicy.GetLabels()[rootPolicyLabel]; isReplicated {
+			continue
+		}
+
+		clusterNames, err := p.placedClusters(ctx, rootPolicy)
+		if err != nil {
+			return err
+		}
+
+		for _, clusterName := range clusterNames {
+			cluster := p.env.GetManagedCluster(clusterName)
+			if cluster == nil {
+				continue
+			}
+
+			if desired[clusterName] == nil {
+				desired[clusterName] = map[string]bool{}
+			}
+
+			desired[clusterName][rootPolicy.GetNamespace()+"."+rootPolicy.GetName()] = true
+
+			if err := p.replicate(ctx, rootPolicy, cluster); err != nil {
+				return err
+			}
+		}
+
+		if err := p.updateRootStatus(ctx, rootPolicy, clusterNames); err != nil {
+			return err
+		}
+	}
+
+	for _, clusterName := range p.env.ManagedClusterNames() {
+		if err := p.prune(ctx, p.env.GetManagedCluster(clusterName), desired[clusterName]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// placedClusters returns the sorted names of the managed clusters in the PlacementDecisions of the
+// Placements bound to the root policy.
+func (p *FakePropagator) placedClusters(ctx context.Context, rootPolicy *unstructured.Unstructured) ([]string, error) {
+	namespace := rootPolicy.GetNamespace()
+
+	bindings, err := p.env.ClientHubDynamic.Resource(GvrPlacementBinding).Namespace(namespace).List(
+		ctx, metav1.ListOptions{},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	clusterNames := []string{}
+
+	for _, binding := range bindings.Items {
+		placementKind, _, _ := unstructured.NestedString(binding.Object, "placementRef", "kind")
+		placementName, _, _ := unstructured.NestedString(binding.Object, "placementRef", "name")
+
+		if placementKind != "Placement" || !bindsPolicy(&binding, rootPolicy.GetName()) {
+			continue
+		}
+
+		decisions, err := p.env.ClientHubDynamic.Resource(GvrPlacementDecision).Namespace(namespace).List(
+			ctx, metav1.ListOptions{LabelSelector: placementLabel + "=" + placementName},
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, decision := range decisions.Items {
+			decisionList, _, _ := unstructured.NestedSlice(decision.Object, "status", "decisions")
+
+			for _, item := range decisionList {
+				itemMap, ok := item.(map[string]any)
+				if !ok {
+					continue
+				}
+
+				clusterName, _, _ := unstructured.NestedString(itemMap, "clusterName")
+				if clusterName != "" && !slices.Contains(clusterNames, clusterName) {
+					clusterNames = append(clusterNames, clusterName)
+				}
+			}
+		}
+	}
+
+	slices.Sort(clusterNames)
+
+	return clusterNames, nil
+}
+
+// replicate creates or updates the replicated policy and its policy templates on the managed
+// cluster, keeping any existing status.
+func (p *FakePropagator) replicate(
+	ctx context.Context, rootPolicy *unstructured.Unstructured, cluster *ManagedCluster,
+) error {
+	replicatedName := rootPolicy.GetNamespace() + "." + rootPolicy.GetName()
+	policyClient := cluster.ClientHostingDynamic.Resource(GvrPolicy).Namespace(cluster.Namespace)
+
+	spec, _, _ := unstructured.NestedMap(rootPolicy.Object, "spec")
+	labels := map[string]string{
+		rootPolicyLabel:       replicatedName,
+		clusterNameLabel:      cluster.Name,
+		clusterNamespaceLabel: cluster.Namespace,
+	}
+
+	replicated, err := policyClient.Get(ctx, replicatedName, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		replicated = &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": rootPolicy.GetAPIVersion(),
+			"kind":       rootPolicy.GetKind(),
+			"metadata": map[string]any{
+				"name":      replicatedName,
+				"namespace": cluster.Namespace,
+			},
+			"spec": spec,
+		}}
+		replicated.SetLabels(labels)
+
+		_, err = policyClient.Create(ctx, replicated, metav1.CreateOptions{})
+	} else if err == nil {
+		existingSpec, _, _ := unstructured.NestedMap(replicated.Object, "spec")
+
+		if !equality.Semantic.DeepEqual(existingSpec, spec) || !equality.Semantic.DeepEqual(
+			replicated.GetLabels(), labels,
+		) {
+			replicated.Object["spec"] = spec
+			replicated.SetLabels(labels)
+
+			_, err = policyClient.Update(ctx, replicated, metav1.UpdateOptions{})
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to replicate the policy %s to cluster %s: %w", replicatedName, cluster.Name, err)
+	}
+
+	templates, err := policyTemplates(spec, cluster.Namespace, replicatedName)
+	if err != nil {
+		return err
+	}
+
+	for _, template := range templates {
+		if err := syncTemplate(ctx, cluster.ClientHostingDynamic, template); err != nil {
+			return fmt.Errorf("failed to sync the policy template %s to cluster %s: %w",
+				template.GetName(), cluster.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// policyTemplates returns the policy templates of the policy spec, in the namespace of the managed
+// cluster, with the remediationAction of the policy set on each template when it is specified.
+func policyTemplates(spec map[string]any, namespace, replicatedName string) ([]*unstructured.Unstructured, error) {
+	remediationAction, _, _ := unstructured.NestedString(spec, "remediationAction")
+	policyTemplates, _, _ := unstructured.NestedSlice(spec, "policy-templates")
+	templates := make([]*unstructured.Unstructured, 0, len(policyTemplates))
+
+	for i, policyTemplate := range policyTemplates {
+		templateMap, ok := policyTemplate.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("policy-templates[%d] is not an object", i)
+		}
+
+		objectDefinition, found, err := unstructured.NestedMap(templateMap, "objectDefinition")
+		if !found || err != nil {
+			return nil, fmt.Errorf("policy-templates[%d].objectDefinition is not an object", i)
+		}
+
+		template := &unstructured.Unstructured{Object: objectDefinition}
+		template.SetNamespace(namespace)
+
+		labels := template.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+
+		labels[parentPolicyLabel] = replicatedName
+		template.SetLabels(labels)
+
+		if _, hasSpec := template.Object["spec"].(map[string]any); hasSpec && remediationAction != "" {
+			err := unstructured.SetNestedField(template.Object, remediationAction, "spec", "remediationAction")
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		templates = append(templates, template)
+	}
+
+	return templates, nil
+}
+
+// syncTemplate creates or updates the policy template, keeping any existing status. Templates of a
+// kind unknown to the fake clusters are skipped.
+func syncTemplate(ctx context.Context, client dynamic.Interface, template *unstructured.Unstructured) error {
+	resource, ok := fakeResourceFor(template.GroupVersionKind())
+	if !ok {
+		klog.V(2).Infof("Skipping the policy template %s of the unknown kind %s", template.GetName(),
+			template.GroupVersionKind())
+
+		return nil
+	}
+
+	templateClient := client.Resource(resource.gvr).Namespace(template.GetNamespace())
+
+	existing, err := templateClient.Get(ctx, template.GetName(), metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		_, err = templateClient.Create(ctx, template, metav1.CreateOptions{})
+
+		return err
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if equality.Semantic.DeepEqual(existing.Object["spec"], template.Object["spec"]) &&
+		equality.Semantic.DeepEqual(existing.GetLabels(), template.GetLabels()) {
+		return nil
+	}
+
+	existing.Object["spec"] = template.Object["spec"]
+	existing.SetLabels(template.GetLabels())
+
+	_, err = templateClient.Update(ctx, existing, metav1.UpdateOptions{})
+
+	return err
+}
+
+// prune deletes the replicated policies on the managed cluster which are not in the desired set,
+// along with their policy templates.
+func (p *FakePropagator) prune(ctx context.Context, cluster *ManagedCluster, desired map[string]bool) error {
+	policyClient := cluster.ClientHostingDynamic.Resource(GvrPolicy).Namespace(cluster.Namespace)
+
+	replicatedPolicies, err := policyClient.List(ctx, metav1.ListOptions{LabelSelector: rootPolicyLabel})
+	if err != nil {
+		return err
+	}
+
+	for i := range replicatedPolicies.Items {
+		replicated := &replicatedPolicies.Items[i]
+		if desired[replicated.GetName()] {
+			continue
+		}
+
+		spec, _, _ := unstructured.NestedMap(replicated.Object, "spec")
+
+		templates, err := policyTemplates(spec, cluster.Namespace, replicated.GetName())
+		if err != nil {
+			return err
+		}
+
+		for _, template := range templates {
+			resource, ok := fakeResourceFor(template.GroupVersionKind())
+			if !ok {
+				continue
+			}
+
+			err := cluster.ClientHostingDynamic.Resource(resource.gvr).Namespace(cluster.Namespace).Delete(
+				ctx, template.GetName(), metav1.DeleteOptions{},
+			)
+			if err != nil && !k8serrors.IsNotFound(err) {
+				return err
+			}
+		}
+
+		err = policyClient.Delete(ctx, replicated.GetName(), metav1.DeleteOptions{})
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// updateRootStatus sets the per-cluster compliance of the root policy from its replicated policies,
+// and the overall compliance from the per-cluster compliance.
+func (p *FakePropagator) updateRootStatus(
+	ctx context.Context, rootPolicy *unstructured.Unstructured, clusterNames []string,
+) error {
+	status := policiesv1.PolicyStatus{}
+	replicatedName := rootPolicy.GetNamespace() + "." + rootPolicy.GetName()
+
+	for _, clusterName := range clusterNames {
+		cluster := p.env.GetManagedCluster(clusterName)
+		if cluster == nil {
+			continue
+		}
+
+		replicated, err := cluster.ClientHostingDynamic.Resource(GvrPolicy).Namespace(cluster.Namespace).Get(
+			ctx, replicatedName, metav1.GetOptions{},
+		)
+		if err != nil {
+			return err
+		}
+
+		compliance, _, _ := unstructured.NestedString(replicated.Object, "status", "compliant")
+
+		status.Status = append(status.Status, &policiesv1.CompliancePerClusterStatus{
+			ComplianceState:  policiesv1.ComplianceState(compliance),
+			ClusterName:      cluster.Name,
+			ClusterNamespace: cluster.Name,
+		})
+	}
+
+	complianceStates := make([]policiesv1.ComplianceState, 0, len(status.Status))
+	for _, clusterStatus := range status.Status {
+		complianceStates = append(complianceStates, clusterStatus.ComplianceState)
+	}
+
+	status.ComplianceState = aggregateCompliance(complianceStates)
+
+	statusMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&status)
+	if err != nil {
+		return err
+	}
+
+	if equality.Semantic.DeepEqual(rootPolicy.Object["status"], statusMap) {
+		return nil
+	}
+
+	rootPolicy = rootPolicy.DeepCopy()
+	rootPolicy.Object["status"] = statusMap
+
+	_, err = p.env.ClientHubDynamic.Resource(GvrPolicy).Namespace(rootPolicy.GetNamespace()).UpdateStatus(
+		ctx, rootPolicy, metav1.UpdateOptions{},
+	)
+
+	return err
+}
+
+// aggregateCompliance returns NonCompliant if any of the states are NonCompliant, then Pending if
+// any are Pending, then Compliant if all are Compliant. Otherwise, the compliance is unknown.
+func aggregateCompliance(states []policiesv1.ComplianceState) policiesv1.ComplianceState {
+	if len(states) == 0 {
+		return ""
+	}
+
+	for _, worst := range []policiesv1.ComplianceState{policiesv1.NonCompliant, policiesv1.Pending} {
+		if slices.Contains(states, worst) {
+			return worst
+		}
+	}
+
+	for _, state := range states {
+		if state != policiesv1.Compliant {
+			return ""
+		}
+	}
+
+	return policiesv1.Compliant
+}
+
+// SetTemplateStatus records a compliance event for the policy template at the given index on the
+// replicated policy on the managed cluster, like the status sync of the policy framework addon does.
+// The message is added to the top of the template's history, which is limited to 10 entries, and
+// the compliance of the replicated policy is recalculated. The root policy is updated on the next
+// Sync.
+func (p *FakePropagator) SetTemplateStatus(
+	ctx context.Context,
+	clusterName, policyNamespace, policyName string,
+	templateIdx int,
+	compliance policiesv1.ComplianceState,
+	message string,
+) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	cluster := p.env.GetManagedCluster(clusterName)
+	if cluster == nil {
+		return fmt.Errorf("managed cluster '%s' is not registered in the test environment", clusterName)
+	}
+
+	policyClient := cluster.ClientHostingDynamic.Resource(GvrPolicy).Namespace(cluster.Namespace)
+
+	replicated, err := policyClient.Get(ctx, policyNamespace+"."+policyName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	policy := policiesv1.Policy{}
+
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(replicated.Object, &policy)
+	if err != nil {
+		return err
+	}
+
+	if templateIdx < 0 || templateIdx >= len(policy.Spec.PolicyTemplates) {
+		return fmt.Errorf("the policy %s has no policy template at index %d", replicated.GetName(), templateIdx)
+	}
+
+	for len(policy.Status.Details) <= templateIdx {
+		policy.Status.Details = append(policy.Status.Details, &policiesv1.DetailsPerTemplate{})
+	}
+
+	template := &unstructured.Unstructured{}
+
+	err = template.UnmarshalJSON(policy.Spec.PolicyTemplates[templateIdx].ObjectDefinition.Raw)
+	if err != nil {
+		return err
+	}
+
+	details := policy.Status.Details[templateIdx]
+	details.TemplateMeta.Name = template.GetName()
+	details.TemplateMeta.Namespace = cluster.Namespace
+	details.ComplianceState = compliance
+	details.History = append([]policiesv1.ComplianceHistory{{
+		LastTimestamp: metav1.Now(),
+		Message:       message,
+		EventName:     fmt.Sprintf("%s.%s.%x", policyNamespace, policyName, time.Now().UnixNano()),
+	}}, details.History...)
+
+	if len(details.History) > maxHistoryLength {
+		details.History = details.History[:maxHistoryLength]
+	}
+
+	complianceStates := make([]policiesv1.ComplianceState, 0, len(policy.Status.Details))
+	for _, templateDetails := range policy.Status.Details {
+		complianceStates = append(complianceStates, templateDetails.ComplianceState)
+	}
+
+	policy.Status.ComplianceState = aggregateCompliance(complianceStates)
+
+	statusMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&policy.Status)
+	if err != nil {
+		return err
+	}
+
+	replicated.Object["status"] = statusMap
+
+	_, err = policyClient.UpdateStatus(ctx, replicated, metav1.UpdateOptions{})
+
+	return err
+}
@@ -0,0 +1,174 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package common
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/stolostron/governance-policy-framework/test/common/kubeclient"
+)
+
+// The delimiters of the Go templates in fixtures differ from the defaults, so that the templates
+// in the policies themselves, like `{{hub ... hub}}`, are left alone.
+const (
+	FixtureLeftDelim  = "[["
+	FixtureRightDelim = "]]"
+)
+
+// FixtureData are the variables of the templates in a fixture, for example
+// `name: my-policy-[[ .Suffix ]]` or `namespace: [[ .UserNamespace ]]`.
+type FixtureData struct {
+	UserNamespace         string
+	ClusterNamespace      string
+	ClusterNamespaceOnHub string
+	OCMNamespace          string
+	// Suffix is unique to the spec, so that the fixtures of specs running concurrently don't collide.
+	Suffix string
+	// Vars are additional variables set by the test. A missing key is an error.
+	Vars map[string]any
+}
+
+// Fixture is a YAML manifest rendered from a template.
+type Fixture struct {
+	Source string
+	// Data are the variables the template was rendered with, for deriving the names of objects which
+	// are not in the manifest, like those created by a policy.
+	Data     FixtureData
+	Manifest []byte
+	Objects  []*unstructured.Unstructured
+}
+
+// Names returns the names of the objects of the kind, in the order of the manifest.
+func (f *Fixture) Names(kind string) []string {
+	names := []string{}
+
+	for _, obj := range f.Objects {
+		if obj.GetKind() == kind {
+			names = append(names, obj.GetName())
+		}
+	}
+
+	return names
+}
+
+// Name returns the name of the first object of the kind, or an empty string if there is none.
+func (f *Fixture) Name(kind string) string {
+	names := f.Names(kind)
+	if len(names) == 0 {
+		return ""
+	}
+
+	return names[0]
+}
+
+// RenderFixture renders the template in the file or URL with the data, and decodes the objects of
+// the resulting manifest.
+func RenderFixture(source string, data FixtureData) (*Fixture, error) {
+	content, err := kubeclient.ReadSource(source)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := template.New(source).Delims(FixtureLeftDelim, FixtureRightDelim).Option("missingkey=error").
+		Parse(string(content))
+	if err != nil {
+		return nil, &kubeclient.ManifestError{Source: source, Document: -1, Err: err}
+	}
+
+	var manifest bytes.Buffer
+
+	if err := tmpl.Execute(&manifest, data); err != nil {
+		return nil, &kubeclient.ManifestError{Source: source, Document: -1, Err: err}
+	}
+
+	objects, err := kubeclient.DecodeManifests(source, manifest.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Fixture{Source: source, Data: data, Manifest: manifest.Bytes(), Objects: objects}, nil
+}
+
+// SpecSuffix returns a suffix for names which is unique to the current spec and Ginkgo process.
+// The specs of an Ordered container share the same suffix, so that they can work on the same
+// objects.
+func SpecSuffix() string {
+	report := CurrentSpecReport()
+	texts := report.ContainerHierarchyTexts
+
+	if !report.IsInOrderedContainer {
+		texts = append(texts, report.LeafNodeText)
+	}
+
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(strings.Join(texts, "/") + "/" + strconv.Itoa(GinkgoParallelProcess())))
+
+	return fmt.Sprintf("%08x", hash.Sum32())
+}
+
+// WriteFile writes the rendered manifest to a file named like the source of the fixture in the
+// directory, and returns its path, for the helpers which take a policy file like DoCreatePolicyTest.
+func (f *Fixture) WriteFile(dir string) (string, error) {
+	path := filepath.Join(dir, filepath.Base(f.Source))
+
+	if err := os.WriteFile(path, f.Manifest, 0o600); err != nil {
+		return "", fmt.Errorf("failed to write the fixture %s: %w", f.Source, err)
+	}
+
+	return path, nil
+}
+
+// FixtureData returns the variables of the Environment and current spec for rendering fixtures,
+// along with the additional variables.
+func (e *Environment) FixtureData(vars map[string]any) FixtureData {
+	return FixtureData{
+		UserNamespace:         e.UserNamespace,
+		ClusterNamespace:      e.ClusterNamespace,
+		ClusterNamespaceOnHub: e.ClusterNamespaceOnHub,
+		OCMNamespace:          e.OCMNamespace,
+		Suffix:                SpecSuffix(),
+		Vars:                  vars,
+	}
+}
+
+// LoadFixture renders the fixture in the file or URL with the FixtureData of the Environment.
+func (e *Environment) LoadFixture(source string, vars map[string]any) (*Fixture, error) {
+	return RenderFixture(source, e.FixtureData(vars))
+}
+
+// ApplyFixture applies the objects of the fixture on the cluster, where objects without a
+// namespace are created in the user namespace on the hub, or in the default namespace otherwise.
+func (e *Environment) ApplyFixture(
+	ctx context.Context, cluster ClusterTarget, fixture *Fixture,
+) ([]kubeclient.ApplyResult, error) {
+	applier, namespace := e.targetApplier(cluster, "")
+
+	return applier.Apply(ctx, namespace, fixture.Objects...)
+}
+
+// DeleteFixture deletes the objects of the fixture from the cluster, ignoring those which don't
+// exist, and waits up to the default timeout for them to be removed like `oc delete`.
+func (e *Environment) DeleteFixture(
+	ctx context.Context, cluster ClusterTarget, fixture *Fixture,
+) ([]kubeclient.ApplyResult, error) {
+	applier, namespace := e.targetApplier(cluster, "")
+
+	results, err := applier.DeleteObjects(ctx, namespace, true, fixture.Objects...)
+	if err != nil {
+		return results, err
+	}
+
+	return results, applier.WaitForDeletion(ctx, time.Duration(e.DefaultTimeoutSeconds)*time.Second, results...)
+}
@@ -0,0 +1,125 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package common
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/stolostron/governance-policy-framework/test/common/kubeclient"
+)
+
+const fakeFixtureFile = "testdata/fake-fixture.yaml"
+
+var _ = Describe("Test the fixture loader", func() {
+	var env *Environment
+
+	BeforeEach(func() {
+		env = NewFakeBackend().Env
+	})
+
+	It("Renders the variables of the Environment and the spec", func() {
+		fixture, err := env.LoadFixture(fakeFixtureFile, map[string]any{"remediationAction": "enforce"})
+		Expect(err).ToNot(HaveOccurred())
+
+		suffix := SpecSuffix()
+		Expect(suffix).To(MatchRegexp(`^[0-9a-f]{8}$`))
+		Expect(fixture.Data.Suffix).To(Equal(suffix))
+
+		Expect(fixture.Objects).To(HaveLen(2))
+		Expect(fixture.Name("Policy")).To(Equal("fixture-policy-" + suffix))
+		Expect(fixture.Names("Placement")).To(Equal([]string{"fixture-placement-" + suffix}))
+		Expect(fixture.Name("PlacementBinding")).To(BeEmpty())
+
+		policy := fixture.Objects[0]
+		Expect(policy.GetNamespace()).To(Equal(env.UserNamespace))
+
+		action, _, _ := unstructured.NestedString(policy.Object, "spec", "remediationAction")
+		Expect(action).To(Equal("enforce"))
+
+		By("Leaving the templates of the policy alone")
+		Expect(string(fixture.Manifest)).To(ContainSubstring("cluster: '{{hub .ManagedClusterName hub}}'"))
+
+		Expect(string(fixture.Manifest)).To(ContainSubstring("name: " + env.ClusterNamespaceOnHub))
+	})
+
+	Context("When the specs are not ordered", func() {
+		suffixes := map[string]string{}
+
+		for _, text := range []string{"Uses a suffix unique to the spec", "Uses another suffix for another spec"} {
+			It(text, func() {
+				for otherText, otherSuffix := range suffixes {
+					Expect(SpecSuffix()).ToNot(Equal(otherSuffix), "the suffix is the same as for: "+otherText)
+				}
+
+				suffixes[text] = SpecSuffix()
+			})
+		}
+	})
+
+	Context("When the specs are ordered", Ordered, func() {
+		var suffix string
+
+		It("Uses a suffix unique to the container", func() {
+			suffix = SpecSuffix()
+		})
+
+		It("Shares the suffix with the other specs of the container", func() {
+			Expect(SpecSuffix()).To(Equal(suffix))
+		})
+	})
+
+	It("Writes the rendered manifest to a file named like the source", func() {
+		fixture, err := env.LoadFixture(fakeFixtureFile, map[string]any{"remediationAction": "enforce"})
+		Expect(err).ToNot(HaveOccurred())
+
+		dir := GinkgoT().TempDir()
+
+		path, err := fixture.WriteFile(dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(path).To(Equal(filepath.Join(dir, "fake-fixture.yaml")))
+
+		content, err := os.ReadFile(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(content).To(Equal(fixture.Manifest))
+	})
+
+	It("Fails on a missing variable", func() {
+		_, err := env.LoadFixture(fakeFixtureFile, nil)
+
+		var manifestErr *kubeclient.ManifestError
+		Expect(errors.As(err, &manifestErr)).To(BeTrue())
+		Expect(manifestErr.Document).To(Equal(-1))
+		Expect(err.Error()).To(ContainSubstring(`map has no entry for key "remediationAction"`))
+	})
+
+	It("Applies and deletes the objects of the fixture", func(ctx SpecContext) {
+		fixture, err := env.LoadFixture(fakeFixtureFile, map[string]any{"remediationAction": "inform"})
+		Expect(err).ToNot(HaveOccurred())
+
+		results, err := env.ApplyFixture(ctx, TargetHub, fixture)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results).To(HaveLen(2))
+		Expect(results[1].Namespace).To(Equal(env.UserNamespace))
+
+		_, err = env.ClientHubDynamic.Resource(GvrPlacement).Namespace(env.UserNamespace).Get(
+			ctx, fixture.Name("Placement"), metav1.GetOptions{},
+		)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = env.DeleteFixture(ctx, TargetHub, fixture)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = env.ClientHubDynamic.Resource(GvrPolicy).Namespace(env.UserNamespace).Get(
+			ctx, fixture.Name("Policy"), metav1.GetOptions{},
+		)
+		Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+	})
+})
@@ -0,0 +1,392 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package common
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/ginkgo/v2/types"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// The components whose version can gate specs with ComponentSemVerConstraint decorators, like the
+// ones returned by RequiresOCP.
+const (
+	// ComponentOCP is the OpenShift version of the managed cluster, which is unknown on other
+	// Kubernetes distributions.
+	ComponentOCP = "ocp"
+	// ComponentKubernetes is the Kubernetes version of the managed cluster.
+	ComponentKubernetes = "kubernetes"
+	// ComponentACM is the ACM version under test: the ACMVersion if it's set, or the version of the
+	// ACM operator installed on the hub, or else the version in the CURRENT_VERSION file.
+	ComponentACM = "acm"
+	// ComponentMCE is the version of the MCE operator installed on the hub.
+	ComponentMCE = "mce"
+)
+
+// requiresCRDLabelPrefix prefixes the labels returned by RequiresCRD. Ginkgo label filters can
+// match on them with `requires-crd: <name>`.
+const requiresCRDLabelPrefix = "requires-crd:"
+
+// CRDs commonly required by specs, to be used with RequiresCRD.
+const (
+	CRDGatekeeperConstraintTemplates = "constrainttemplates.templates.gatekeeper.sh"
+	CRDComplianceSuites              = "compliancesuites.compliance.openshift.io"
+)
+
+// The files at the root of the repository listing the ACM versions the suites run against.
+const (
+	currentVersionFile          = "CURRENT_VERSION"
+	currentSupportedVersionFile = "CURRENT_SUPPORTED_VERSIONS"
+)
+
+// The name prefixes of the CSVs of the operators whose version is the ACM and MCE versions.
+var operatorCSVPrefixes = map[string]string{
+	ComponentACM: "advanced-cluster-management.",
+	ComponentMCE: "multicluster-engine.",
+}
+
+// RequiresOCP decorates specs which only run when the OpenShift version of the managed cluster
+// meets the constraints, like `>= 4.12`. The specs are skipped on other Kubernetes distributions.
+func RequiresOCP(constraints ...string) ComponentSemVerConstraints {
+	return ComponentSemVerConstraint(ComponentOCP, constraints...)
+}
+
+// RequiresKubernetes decorates specs which only run when the Kubernetes version of the managed
+// cluster meets the constraints.
+func RequiresKubernetes(constraints ...string) ComponentSemVerConstraints {
+	return ComponentSemVerConstraint(ComponentKubernetes, constraints...)
+}
+
+// RequiresACM decorates specs which only run when the ACM version under test meets the
+// constraints.
+func RequiresACM(constraints ...string) ComponentSemVerConstraints {
+	return ComponentSemVerConstraint(ComponentACM, constraints...)
+}
+
+// RequiresMCE decorates specs which only run when the MCE version of the hub meets the
+// constraints. The specs are skipped when MCE isn't installed.
+func RequiresMCE(constraints ...string) ComponentSemVerConstraints {
+	return ComponentSemVerConstraint(ComponentMCE, constraints...)
+}
+
+// RequiresCRD decorates specs which only run when the CRDs are installed on the managed cluster,
+// like CRDGatekeeperConstraintTemplates.
+func RequiresCRD(names ...string) Labels {
+	labels := make(Labels, 0, len(names))
+	for _, name := range names {
+		labels = append(labels, requiresCRDLabelPrefix+name)
+	}
+
+	return labels
+}
+
+// CheckGates skips the current spec when the cluster doesn't meet the requirements of its
+// decorators, like RequiresOCP and RequiresCRD, with a reason in the same format for every
+// requirement. It fails the spec if a requirement can't be checked. Register it as a top level
+// BeforeEach of the suite, so that it runs before the BeforeAll nodes of ordered containers.
+func (e *Environment) CheckGates(ctx context.Context) {
+	GinkgoHelper()
+
+	reason, err := e.UnmetGate(ctx, CurrentSpecReport())
+	if err != nil {
+		Fail(err.Error())
+	}
+
+	if reason != "" {
+		Skip(reason)
+	}
+}
+
+// UnmetGate returns the reason to skip the spec, or an empty string if the cluster meets all of the
+// requirements of its decorators. The versions and CRDs are looked up once and cached.
+func (e *Environment) UnmetGate(ctx context.Context, report types.SpecReport) (string, error) {
+	componentConstraints := report.ComponentSemVerConstraints()
+
+	components := make([]string, 0, len(componentConstraints))
+	for component := range componentConstraints {
+		components = append(components, component)
+	}
+
+	slices.Sort(components)
+
+	for _, component := range components {
+		version, err := e.ComponentVersion(ctx, component)
+		if err != nil {
+			return "", err
+		}
+
+		for _, constraint := range componentConstraints[component] {
+			if version == nil {
+				return fmt.Sprintf("Requires %s %s, but %s was not found", component, constraint, component), nil
+			}
+
+			parsed, err := semver.NewConstraint(constraint)
+			if err != nil {
+				return "", fmt.Errorf("the %s version constraint %s is invalid: %w", component, constraint, err)
+			}
+
+			if !parsed.Check(version) {
+				return fmt.Sprintf("Requires %s %s, but found %s %s", component, constraint, component, version), nil
+			}
+		}
+	}
+
+	for _, label := range report.Labels() {
+		name, ok := strings.CutPrefix(label, requiresCRDLabelPrefix)
+		if !ok {
+			continue
+		}
+
+		name = strings.TrimSpace(name)
+
+		installed, err := e.crdInstalled(ctx, name)
+		if err != nil {
+			return "", err
+		}
+
+		if !installed {
+			return fmt.Sprintf("Requires the CRD %s, but it was not found on the managed cluster", name), nil
+		}
+	}
+
+	return "", nil
+}
+
+// ComponentVersion returns the version of the component, like ComponentOCP, or nil if it's unknown,
+// like the OpenShift version of a Kubernetes cluster which isn't OpenShift. The pre-release of the
+// version is dropped, so that a release candidate meets the constraints of its release. Versions
+// are cached once found.
+func (e *Environment) ComponentVersion(ctx context.Context, component string) (*semver.Version, error) {
+	e.gatesLock.Lock()
+	defer e.gatesLock.Unlock()
+
+	if version, ok := e.componentVersions[component]; ok {
+		return version, nil
+	}
+
+	var (
+		version string
+		err     error
+	)
+
+	switch component {
+	case ComponentOCP:
+		version, err = e.ocpVersion(ctx)
+	case ComponentKubernetes:
+		version, err = e.kubernetesVersion()
+	case ComponentACM:
+		version, err = e.acmVersion(ctx)
+	case ComponentMCE:
+		version, err = e.operatorVersion(ctx, ComponentMCE)
+	default:
+		return nil, fmt.Errorf("the component %s is unknown", component)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the %s version: %w", component, err)
+	}
+
+	var parsed *semver.Version
+
+	if version != "" {
+		parsed, err = semver.NewVersion(version)
+		if err != nil {
+			return nil, fmt.Errorf("the %s version %s is invalid: %w", component, version, err)
+		}
+
+		parsed = semver.New(parsed.Major(), parsed.Minor(), parsed.Patch(), "", "")
+	}
+
+	if e.componentVersions == nil {
+		e.componentVersions = map[string]*semver.Version{}
+	}
+
+	e.componentVersions[component] = parsed
+
+	return parsed, nil
+}
+
+// SupportedACMVersions returns the ACM versions the suites run against, which are the versions in
+// the CURRENT_VERSION and CURRENT_SUPPORTED_VERSIONS files of the VersionFilesDir.
+func (e *Environment) SupportedACMVersions() ([]string, error) {
+	versions := []string{}
+
+	for _, file := range []string{currentVersionFile, currentSupportedVersionFile} {
+		lines, err := readVersionFile(filepath.Join(e.VersionFilesDir, file))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, line := range lines {
+			if !slices.Contains(versions, line) {
+				versions = append(versions, line)
+			}
+		}
+	}
+
+	return versions, nil
+}
+
+// ocpVersion returns the desired version of the ClusterVersion of the managed cluster, or an empty
+// string if it isn't an OpenShift cluster.
+func (e *Environment) ocpVersion(ctx context.Context) (string, error) {
+	clusterVersion, err := e.ClientManagedDynamic.Resource(GvrClusterVersion).Get(ctx, "version", metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	version, _, _ := unstructured.NestedString(clusterVersion.Object, "status", "desired", "version")
+
+	return version, nil
+}
+
+// kubernetesVersion returns the Kubernetes version of the managed cluster. Distributions whose git
+// version isn't a semantic version fall back to the major and minor versions.
+func (e *Environment) kubernetesVersion() (string, error) {
+	info, err := e.ClientManaged.Discovery().ServerVersion()
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := semver.NewVersion(info.GitVersion); err == nil {
+		return info.GitVersion, nil
+	}
+
+	return strings.TrimSuffix(info.Major, "+") + "." + strings.TrimSuffix(info.Minor, "+"), nil
+}
+
+// acmVersion returns the ACMVersion, which must be one of the SupportedACMVersions, or the version
+// of the ACM operator installed on the hub, or else the first line of the CURRENT_VERSION file.
+func (e *Environment) acmVersion(ctx context.Context) (string, error) {
+	if e.ACMVersion != "" {
+		supported, err := e.SupportedACMVersions()
+		if err != nil {
+			return "", err
+		}
+
+		version, err := semver.NewVersion(e.ACMVersion)
+		if err != nil {
+			return "", fmt.Errorf("the ACM version %s is invalid: %w", e.ACMVersion, err)
+		}
+
+		if !slices.Contains(supported, fmt.Sprintf("%d.%d", version.Major(), version.Minor())) {
+			return "", fmt.Errorf(
+				"the ACM version %s is not one of the supported versions %s",
+				e.ACMVersion, strings.Join(supported, ", "),
+			)
+		}
+
+		return e.ACMVersion, nil
+	}
+
+	version, err := e.operatorVersion(ctx, ComponentACM)
+	if err != nil || version != "" {
+		return version, err
+	}
+
+	lines, err := readVersionFile(filepath.Join(e.VersionFilesDir, currentVersionFile))
+	if err != nil {
+		return "", err
+	}
+
+	return lines[0], nil
+}
+
+// operatorVersion returns the highest version of the CSVs of the component's operator on the hub,
+// or an empty string if the operator isn't installed.
+func (e *Environment) operatorVersion(ctx context.Context, component string) (string, error) {
+	csvs, err := e.ClientHubDynamic.Resource(GvrClusterServiceVersion).List(
+		ctx, metav1.ListOptions{LabelSelector: "!olm.copiedFrom"},
+	)
+	if k8serrors.IsNotFound(err) {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	var highest *semver.Version
+
+	for _, csv := range csvs.Items {
+		if !strings.HasPrefix(csv.GetName(), operatorCSVPrefixes[component]) {
+			continue
+		}
+
+		version, _, _ := unstructured.NestedString(csv.Object, "spec", "version")
+
+		parsed, err := semver.NewVersion(version)
+		if err != nil {
+			return "", fmt.Errorf("the version %q of the CSV %s is invalid: %w", version, csv.GetName(), err)
+		}
+
+		if highest == nil || parsed.GreaterThan(highest) {
+			highest = parsed
+		}
+	}
+
+	if highest == nil {
+		return "", nil
+	}
+
+	return highest.Original(), nil
+}
+
+// crdInstalled returns whether the CRD is installed on the managed cluster, caching the result.
+func (e *Environment) crdInstalled(ctx context.Context, name string) (bool, error) {
+	e.gatesLock.Lock()
+	defer e.gatesLock.Unlock()
+
+	if installed, ok := e.crds[name]; ok {
+		return installed, nil
+	}
+
+	_, err := e.ClientManagedDynamic.Resource(GvrCRD).Get(ctx, name, metav1.GetOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return false, fmt.Errorf("failed to get the CRD %s: %w", name, err)
+	}
+
+	if e.crds == nil {
+		e.crds = map[string]bool{}
+	}
+
+	e.crds[name] = err == nil
+
+	return err == nil, nil
+}
+
+// readVersionFile returns the non-empty lines of a version file, which must have at least one.
+func readVersionFile(path string) ([]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the version file: %w", err)
+	}
+
+	lines := []string{}
+
+	for line := range strings.Lines(string(content)) {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("the version file %s is empty", path)
+	}
+
+	return lines, nil
+}
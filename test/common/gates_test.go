@@ -0,0 +1,160 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package common
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/ginkgo/v2/types"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/dynamic"
+)
+
+var _ = Describe("Test the version and CRD gates", func() {
+	var backend *FakeBackend
+	var env *Environment
+
+	BeforeEach(func() {
+		backend = startFakeBackend()
+		env = backend.Env
+
+		discovery, ok := backend.Managed.Client.Discovery().(*fakediscovery.FakeDiscovery)
+		Expect(ok).To(BeTrue())
+
+		discovery.FakedServerVersion = &version.Info{Major: "1", Minor: "24", GitVersion: "v1.24.6+5658434"}
+	})
+
+	create := func(ctx SpecContext, client dynamic.Interface, gvr schema.GroupVersionResource, obj map[string]any) {
+		GinkgoHelper()
+
+		object := &unstructured.Unstructured{Object: obj}
+
+		_, err := client.Resource(gvr).Namespace(object.GetNamespace()).Create(ctx, object, metav1.CreateOptions{})
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	createCSV := func(ctx SpecContext, name, namespace, version string, labels map[string]any) {
+		GinkgoHelper()
+
+		create(ctx, env.ClientHubDynamic, GvrClusterServiceVersion, map[string]any{
+			"apiVersion": "operators.coreos.com/v1alpha1",
+			"kind":       "ClusterServiceVersion",
+			"metadata":   map[string]any{"name": name, "namespace": namespace, "labels": labels},
+			"spec":       map[string]any{"version": version},
+		})
+	}
+
+	specWith := func(constraints map[string][]string, labels ...string) types.SpecReport {
+		return types.SpecReport{LeafNodeComponentSemVerConstraints: constraints, LeafNodeLabels: labels}
+	}
+
+	It("Skips the specs whose version constraints aren't met", func(ctx SpecContext) {
+		create(ctx, env.ClientManagedDynamic, GvrClusterVersion, map[string]any{
+			"apiVersion": "config.openshift.io/v1",
+			"kind":       "ClusterVersion",
+			"metadata":   map[string]any{"name": "version"},
+			"status":     map[string]any{"desired": map[string]any{"version": "4.11.3"}},
+		})
+
+		reason, err := env.UnmetGate(ctx, specWith(map[string][]string{
+			ComponentOCP:        {">= 4.6"},
+			ComponentKubernetes: {"< 1.25"},
+		}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reason).To(BeEmpty())
+
+		reason, err = env.UnmetGate(ctx, specWith(map[string][]string{ComponentOCP: {">= 4.6", ">= 4.12"}}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reason).To(Equal("Requires ocp >= 4.12, but found ocp 4.11.3"))
+
+		reason, err = env.UnmetGate(ctx, specWith(map[string][]string{ComponentKubernetes: {">= 1.25.0"}}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reason).To(Equal("Requires kubernetes >= 1.25.0, but found kubernetes 1.24.6"))
+
+		Expect(env.IsAtLeastVersion("4.11")).To(BeTrue())
+		Expect(env.IsAtLeastVersion("4.12")).To(BeFalse())
+	})
+
+	It("Skips the specs which require OpenShift on other distributions", func(ctx SpecContext) {
+		reason, err := env.UnmetGate(ctx, specWith(map[string][]string{ComponentOCP: {">= 4.6"}}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reason).To(Equal("Requires ocp >= 4.6, but ocp was not found"))
+
+		Expect(env.IsAtLeastVersion("4.6")).To(BeFalse())
+	})
+
+	It("Gets the ACM and MCE versions from the installed operators", func(ctx SpecContext) {
+		createCSV(ctx, "advanced-cluster-management.v2.14.1", "open-cluster-management", "2.14.1", nil)
+		createCSV(ctx, "advanced-cluster-management.v2.14.1", "other", "2.14.1", map[string]any{
+			"olm.copiedFrom": "open-cluster-management",
+		})
+		createCSV(ctx, "multicluster-engine.v2.9.0", "multicluster-engine", "2.9.0", nil)
+		createCSV(ctx, "multicluster-engine.v2.8.2", "multicluster-engine-old", "2.8.2", nil)
+
+		acm, err := env.ComponentVersion(ctx, ComponentACM)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(acm.String()).To(Equal("2.14.1"))
+
+		mce, err := env.ComponentVersion(ctx, ComponentMCE)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mce.String()).To(Equal("2.9.0"))
+	})
+
+	It("Gets the ACM version from CURRENT_VERSION without an ACM operator", func(ctx SpecContext) {
+		acm, err := env.ComponentVersion(ctx, ComponentACM)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(acm.String()).To(Equal("5.0.0"))
+
+		reason, err := env.UnmetGate(ctx, specWith(map[string][]string{ComponentMCE: {">= 2.9"}}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reason).To(Equal("Requires mce >= 2.9, but mce was not found"))
+	})
+
+	It("Only accepts an ACM version under test which is supported", func(ctx SpecContext) {
+		supported, err := env.SupportedACMVersions()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(supported).To(ContainElements("5.0", "2.14", "2.11"))
+
+		env.ACMVersion = "2.14"
+
+		reason, err := env.UnmetGate(ctx, specWith(map[string][]string{ComponentACM: {">= 2.15"}}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reason).To(Equal("Requires acm >= 2.15, but found acm 2.14.0"))
+
+		env.ACMVersion = "2.10"
+		env.componentVersions = nil
+
+		_, err = env.ComponentVersion(ctx, ComponentACM)
+		Expect(err).To(MatchError(ContainSubstring("the ACM version 2.10 is not one of the supported versions 5.0")))
+	})
+
+	It("Skips the specs which require a CRD which isn't installed", func(ctx SpecContext) {
+		create(ctx, env.ClientManagedDynamic, GvrCRD, map[string]any{
+			"apiVersion": "apiextensions.k8s.io/v1",
+			"kind":       "CustomResourceDefinition",
+			"metadata":   map[string]any{"name": CRDComplianceSuites},
+		})
+
+		reason, err := env.UnmetGate(ctx, specWith(nil, RequiresCRD(CRDComplianceSuites)...))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reason).To(BeEmpty())
+
+		reason, err = env.UnmetGate(
+			ctx, specWith(nil, RequiresCRD(CRDComplianceSuites, CRDGatekeeperConstraintTemplates)...),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reason).To(Equal(
+			"Requires the CRD constrainttemplates.templates.gatekeeper.sh, but it was not found on the managed cluster",
+		))
+	})
+
+	It("Runs a spec whose decorators are met", RequiresKubernetes(">= 1.20"), func(ctx SpecContext) {
+		env.CheckGates(ctx)
+
+		Expect(env.componentVersions).To(HaveKey(ComponentKubernetes))
+	})
+})
@@ -25,7 +25,7 @@ var gitopsTestNamespaces = []string{
 
 // GitOpsUserSetup configures a new user to use for the GitOps tests. It updates the provided
 // OCPUser instance, which contains a path to the created kubeconfig file.
-func GitOpsUserSetup(ctx SpecContext, ocpUser *OCPUser) {
+func (e *Environment) GitOpsUserSetup(ctx SpecContext, ocpUser *OCPUser) {
 	GinkgoHelper()
 
 	const subAdminBinding = "open-cluster-management:subscription-admin"
@@ -37,6 +37,7 @@ func GitOpsUserSetup(ctx SpecContext, ocpUser *OCPUser) {
 	}
 	ocpUser.ClusterRoleBindings = []string{subAdminBinding}
 	ocpUser.Username = "grc-e2e-subadmin-user"
+	ocpUser.Labels = e.OwnershipLabels()
 
 	// Add additional cluster roles for each namespace
 	for _, ns := range gitopsTestNamespaces {
@@ -67,7 +68,9 @@ func GitOpsUserSetup(ctx SpecContext, ocpUser *OCPUser) {
 		},
 	}
 
-	_, err := ClientHub.RbacV1().ClusterRoles().Create(
+	e.setOwnershipLabels(&clusterSetRule)
+
+	_, err := e.ClientHub.RbacV1().ClusterRoles().Create(
 		ctx, &clusterSetRule, metav1.CreateOptions{},
 	)
 	if err != nil {
@@ -91,7 +94,9 @@ func GitOpsUserSetup(ctx SpecContext, ocpUser *OCPUser) {
 
 	By("Verifying that the subscription-admin ClusterRoleBinding exists")
 
-	_, err = ClientHub.RbacV1().ClusterRoleBindings().Create(
+	e.setOwnershipLabels(&subAdminBindingObj)
+
+	_, err = e.ClientHub.RbacV1().ClusterRoleBindings().Create(
 		ctx, &subAdminBindingObj, metav1.CreateOptions{},
 	)
 	if err != nil {
@@ -102,12 +107,12 @@ func GitOpsUserSetup(ctx SpecContext, ocpUser *OCPUser) {
 	}
 
 	By("Cleaning up any existing subscription-admin user config")
-	GitOpsCleanup(ctx, *ocpUser)
+	e.GitOpsCleanup(ctx, *ocpUser)
 
 	// Wait for the oauth deployment to be completely ready in case an update was made that's still being processed
 	By("Waiting for the OCP oauth deployment to be ready")
 	Eventually(func(g Gomega) {
-		authDeployment, err := ClientHub.AppsV1().Deployments("openshift-authentication").Get(
+		authDeployment, err := e.ClientHub.AppsV1().Deployments("openshift-authentication").Get(
 			ctx, "oauth-openshift", metav1.GetOptions{},
 		)
 		g.Expect(err).ShouldNot(HaveOccurred())
@@ -115,17 +120,19 @@ func GitOpsUserSetup(ctx SpecContext, ocpUser *OCPUser) {
 		availableReplicas := authDeployment.Status.AvailableReplicas
 		expectedReplicas := authDeployment.Status.Replicas
 		g.Expect(availableReplicas).Should(Equal(expectedReplicas))
-	}, DefaultTimeoutSeconds*6, 1).Should(Succeed())
+	}, e.DefaultTimeoutSeconds*6, 1).Should(Succeed())
 
 	for _, ns := range gitopsTestNamespaces {
-		CleanupHubNamespace(ns)
+		e.CleanupHubNamespace(ns)
 	}
 
 	By("Creating a subscription-admin user and configuring IDP")
 	// Create a namespace to house the subscription configuration.
 	for _, ns := range gitopsTestNamespaces {
 		nsObj := corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: ns}}
-		_, err = ClientHub.CoreV1().Namespaces().Create(
+		e.setOwnershipLabels(&nsObj)
+
+		_, err = e.ClientHub.CoreV1().Namespaces().Create(
 			ctx, &nsObj, metav1.CreateOptions{},
 		)
 		Expect(err).ShouldNot(HaveOccurred())
@@ -136,20 +143,20 @@ func GitOpsUserSetup(ctx SpecContext, ocpUser *OCPUser) {
 	Expect(err).ShouldNot(HaveOccurred())
 
 	// Fetch the current generation of the auth deployment to monitor its update
-	authDeployment, err := ClientHub.AppsV1().Deployments("openshift-authentication").Get(
+	authDeployment, err := e.ClientHub.AppsV1().Deployments("openshift-authentication").Get(
 		ctx, "oauth-openshift", metav1.GetOptions{},
 	)
 	Expect(err).ShouldNot(HaveOccurred())
 
 	oldAuthGeneration := authDeployment.Status.ObservedGeneration
 
-	err = CreateOCPUser(ClientHub, ClientHubDynamic, *ocpUser)
+	err = CreateOCPUser(e.ClientHub, e.ClientHubDynamic, *ocpUser)
 	Expect(err).ShouldNot(HaveOccurred())
 
 	// Wait for the oauth deployment to update with at least one ready Pod
 	By("Waiting for at least one OCP oauth pod to be ready")
 	Eventually(func(g Gomega) {
-		authDeployment, err := ClientHub.AppsV1().Deployments("openshift-authentication").Get(
+		authDeployment, err := e.ClientHub.AppsV1().Deployments("openshift-authentication").Get(
 			ctx, "oauth-openshift", metav1.GetOptions{},
 		)
 		g.Expect(err).ShouldNot(HaveOccurred())
@@ -160,11 +167,11 @@ func GitOpsUserSetup(ctx SpecContext, ocpUser *OCPUser) {
 
 		availableReplicas := authDeployment.Status.AvailableReplicas
 		g.Expect(availableReplicas).ShouldNot(BeZero())
-	}, DefaultTimeoutSeconds*10, 1).Should(Succeed())
+	}, e.DefaultTimeoutSeconds*10, 1).Should(Succeed())
 
 	// Get a kubeconfig logged in as the subscription and local-cluster administrator OpenShift
 	// user.
-	hubServerURL, err := OcHub("whoami", "--show-server=true")
+	hubServerURL, err := e.OcHub("whoami", "--show-server=true")
 	Expect(err).ShouldNot(HaveOccurred())
 
 	hubServerURL = strings.TrimSuffix(hubServerURL, "\n")
@@ -194,7 +201,7 @@ func GitOpsUserSetup(ctx SpecContext, ocpUser *OCPUser) {
 // GitOpsCleanup will remove any test data/configuration on the OpenShift cluster that was added/updated
 // as part of the GitOps test. The kubeconfig file is also deleted from the filesystem. Any errors will
 // be propagated as gomega failed assertions.
-func GitOpsCleanup(ctx SpecContext, user OCPUser) {
+func (e *Environment) GitOpsCleanup(ctx SpecContext, user OCPUser) {
 	GinkgoHelper()
 
 	By("Cleaning up artifacts from user " + user.Username)
@@ -204,15 +211,15 @@ func GitOpsCleanup(ctx SpecContext, user OCPUser) {
 		Expect(err).ShouldNot(HaveOccurred())
 	}
 
-	err := CleanupOCPUser(ClientHub, ClientHubDynamic, user)
+	err := CleanupOCPUser(e.ClientHub, e.ClientHubDynamic, user)
 	Expect(err).ShouldNot(HaveOccurred())
 
-	err = ClientHub.CoreV1().Secrets("openshift-config").Delete(ctx, user.Username, metav1.DeleteOptions{})
+	err = e.ClientHub.CoreV1().Secrets("openshift-config").Delete(ctx, user.Username, metav1.DeleteOptions{})
 	if !k8serrors.IsNotFound(err) {
 		Expect(err).ShouldNot(HaveOccurred())
 	}
 
 	for _, ns := range gitopsTestNamespaces {
-		CleanupHubNamespace(ns)
+		e.CleanupHubNamespace(ns)
 	}
 }
@@ -17,11 +17,25 @@ var (
 		Version:  "v1",
 		Resource: "configmaps",
 	}
+	GvrSecret = schema.GroupVersionResource{
+		Version:  "v1",
+		Resource: "secrets",
+	}
 	GvrRole = schema.GroupVersionResource{
 		Group:    "rbac.authorization.k8s.io",
 		Version:  "v1",
 		Resource: "roles",
 	}
+	GvrClusterRole = schema.GroupVersionResource{
+		Group:    "rbac.authorization.k8s.io",
+		Version:  "v1",
+		Resource: "clusterroles",
+	}
+	GvrClusterRoleBinding = schema.GroupVersionResource{
+		Group:    "rbac.authorization.k8s.io",
+		Version:  "v1",
+		Resource: "clusterrolebindings",
+	}
 	GvrCRD = schema.GroupVersionResource{
 		Group:    "apiextensions.k8s.io",
 		Version:  "v1",
@@ -177,4 +191,9 @@ var (
 		Version:  "v1beta1",
 		Resource: "placementdecisions",
 	}
+	GvrPolicyReport = schema.GroupVersionResource{
+		Group:    "wgpolicyk8s.io",
+		Version:  "v1alpha2",
+		Resource: "policyreports",
+	}
 )
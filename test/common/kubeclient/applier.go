@@ -0,0 +1,621 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package kubeclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/apimachinery/pkg/util/wait"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/restmapper"
+)
+
+// ApplierFieldManager is the field manager of the server-side apply and patch requests of an Applier.
+const ApplierFieldManager = "governance-policy-framework-test"
+
+// Operation describes what an Applier did to an object.
+type Operation string
+
+const (
+	OperationCreated    Operation = "created"
+	OperationConfigured Operation = "configured"
+	OperationUnchanged  Operation = "unchanged"
+	OperationDeleted    Operation = "deleted"
+	OperationPatched    Operation = "patched"
+	OperationNotFound   Operation = "not found"
+)
+
+// ApplyResult is the result of an Applier operation on a single object.
+type ApplyResult struct {
+	GVR       schema.GroupVersionResource
+	Kind      string
+	Namespace string
+	Name      string
+	Operation Operation
+	// Object is the object returned by the API server. It is nil for deletions.
+	Object *unstructured.Unstructured
+}
+
+// String formats the result like the output of kubectl, for example
+// `policy.policy.open-cluster-management.io/my-policy created`.
+func (r ApplyResult) String() string {
+	kind := strings.ToLower(r.Kind)
+	if r.GVR.Group != "" {
+		kind += "." + r.GVR.Group
+	}
+
+	return kind + "/" + r.Name + " " + string(r.Operation)
+}
+
+// ManifestError is returned when a YAML manifest could not be read or decoded.
+type ManifestError struct {
+	Source string
+	// Document is the index of the YAML document in the source which failed to decode, or -1 if
+	// the source could not be read.
+	Document int
+	Err      error
+}
+
+func (e *ManifestError) Error() string {
+	if e.Document < 0 {
+		return fmt.Sprintf("failed to read the manifest %s: %v", e.Source, e.Err)
+	}
+
+	return fmt.Sprintf("failed to decode document %d of the manifest %s: %v", e.Document, e.Source, e.Err)
+}
+
+func (e *ManifestError) Unwrap() error {
+	return e.Err
+}
+
+// ObjectError is returned when an Applier operation on a single object fails. The API error is
+// wrapped, so functions like k8serrors.IsNotFound can be used on it.
+type ObjectError struct {
+	Operation string
+	GVK       schema.GroupVersionKind
+	Namespace string
+	Name      string
+	Err       error
+}
+
+func (e *ObjectError) Error() string {
+	name := e.Name
+	if e.Namespace != "" {
+		name = e.Namespace + "/" + e.Name
+	}
+
+	return fmt.Sprintf("failed to %s %s %s: %v", e.Operation, e.GVK.Kind, name, e.Err)
+}
+
+func (e *ObjectError) Unwrap() error {
+	return e.Err
+}
+
+// Applier applies, deletes, and patches objects with the dynamic client, using a RESTMapper to
+// find the resource for each object. It is a Go-native replacement for the `oc`/`kubectl`
+// apply, delete, and patch commands.
+type Applier struct {
+	// OwnershipLabels returns the labels to set on the objects the Applier creates, and on those
+	// which already have one of these labels from an earlier apply, so that server-side apply doesn't
+	// remove them. Objects which existed before aren't labeled, so that they're not mistaken for
+	// objects created by the tests.
+	OwnershipLabels func() map[string]string
+
+	client    dynamic.Interface
+	discovery discovery.CachedDiscoveryInterface
+	deferred  *restmapper.DeferredDiscoveryRESTMapper
+	mapper    meta.RESTMapper
+}
+
+// NewApplier returns an Applier using the given clients. The discovery information is cached
+// and only refreshed when an unknown kind is encountered.
+func NewApplier(client dynamic.Interface, discoveryClient discovery.DiscoveryInterface) *Applier {
+	cached := memory.NewMemCacheClient(discoveryClient)
+	deferred := restmapper.NewDeferredDiscoveryRESTMapper(cached)
+
+	return &Applier{
+		client:    client,
+		discovery: cached,
+		deferred:  deferred,
+		mapper:    restmapper.NewShortcutExpander(deferred, cached, func(string) {}),
+	}
+}
+
+// ReadManifests reads all of the objects in the multi-document YAML file or URL. Items of a List
+// are returned as separate objects.
+func ReadManifests(source string) ([]*unstructured.Unstructured, error) {
+	data, err := ReadSource(source)
+	if err != nil {
+		return nil, err
+	}
+
+	return DecodeManifests(source, data)
+}
+
+// ReadSource reads the file or URL, returning a ManifestError if it can't be read.
+func ReadSource(source string) ([]byte, error) {
+	var data []byte
+	var err error
+
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		data, err = readURL(source)
+	} else {
+		data, err = os.ReadFile(source) // #nosec G304 -- the files are provided by the tests
+	}
+
+	if err != nil {
+		return nil, &ManifestError{Source: source, Document: -1, Err: err}
+	}
+
+	return data, nil
+}
+
+// DecodeManifests decodes all of the objects in the multi-document YAML, where the source is only
+// used in errors. Items of a List are returned as separate objects.
+func DecodeManifests(source string, data []byte) ([]*unstructured.Unstructured, error) {
+	decoder := k8syaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	objects := []*unstructured.Unstructured{}
+
+	for i := 0; ; i++ {
+		obj := &unstructured.Unstructured{}
+
+		err := decoder.Decode(&obj.Object)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, &ManifestError{Source: source, Document: i, Err: err}
+		}
+
+		if len(obj.Object) == 0 {
+			continue
+		}
+
+		if !obj.IsList() {
+			objects = append(objects, obj)
+
+			continue
+		}
+
+		err = obj.EachListItem(func(item runtime.Object) error {
+			itemObj, ok := item.(*unstructured.Unstructured)
+			if !ok {
+				return fmt.Errorf("unexpected list item type %T", item)
+			}
+
+			objects = append(objects, itemObj)
+
+			return nil
+		})
+		if err != nil {
+			return nil, &ManifestError{Source: source, Document: i, Err: err}
+		}
+	}
+
+	return objects, nil
+}
+
+func readURL(url string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// RESTMapping returns the mapping for the kind, refreshing the discovery information once if the
+// kind is not known, for example when its CRD was installed after the Applier was created.
+func (a *Applier) RESTMapping(gvk schema.GroupVersionKind) (*meta.RESTMapping, error) {
+	mapping, err := a.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) {
+		a.deferred.Reset()
+
+		mapping, err = a.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	}
+
+	return mapping, err
+}
+
+// resourceFor resolves a resource argument as accepted by kubectl, such as `configmap`, `cm`, or
+// `placements.cluster.open-cluster-management.io`.
+func (a *Applier) resourceFor(resource string) (*meta.RESTMapping, error) {
+	var gvr schema.GroupVersionResource
+
+	fullGVR, groupResource := schema.ParseResourceArg(resource)
+	if fullGVR != nil {
+		if _, err := a.mapper.KindFor(*fullGVR); err == nil {
+			gvr = *fullGVR
+		}
+	}
+
+	if gvr.Empty() {
+		var err error
+
+		gvr, err = a.mapper.ResourceFor(groupResource.WithVersion(""))
+		if meta.IsNoMatchError(err) {
+			a.deferred.Reset()
+
+			gvr, err = a.mapper.ResourceFor(groupResource.WithVersion(""))
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	gvk, err := a.mapper.KindFor(gvr)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.RESTMapping(gvk)
+}
+
+// resourceInterface returns the client for the mapped resource, using the object's namespace if
+// set, or the default namespace otherwise. Cluster scoped resources ignore the namespace.
+func (a *Applier) resourceInterface(
+	mapping *meta.RESTMapping, objNamespace, defaultNamespace string,
+) (dynamic.ResourceInterface, string) {
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		return a.client.Resource(mapping.Resource), ""
+	}
+
+	namespace := objNamespace
+	if namespace == "" {
+		namespace = defaultNamespace
+	}
+
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+
+	return a.client.Resource(mapping.Resource).Namespace(namespace), namespace
+}
+
+// ApplyFile server-side applies all of the objects in the YAML file or URL. Objects without a
+// namespace are created in the given namespace.
+func (a *Applier) ApplyFile(ctx context.Context, source, namespace string) ([]ApplyResult, error) {
+	objects, err := ReadManifests(source)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.Apply(ctx, namespace, objects...)
+}
+
+// Apply server-side applies the objects with the ApplierFieldManager. Objects without a namespace
+// are created in the given namespace. It stops at the first failure, and returns the results of the
+// objects applied so far.
+//
+// Like the client-side `kubectl apply` it replaces, it overwrites the fields last set by the tests,
+// through kubectl or the Applier. Unlike it, the fields managed by a controller are not taken over:
+// the conflict is returned instead. Fields removed from the manifest are pruned when the Applier
+// applied them, rather than when they're in the last-applied-configuration annotation.
+func (a *Applier) Apply(
+	ctx context.Context, namespace string, objects ...*unstructured.Unstructured,
+) ([]ApplyResult, error) {
+	results := make([]ApplyResult, 0, len(objects))
+
+	for _, obj := range objects {
+		gvk := obj.GroupVersionKind()
+
+		mapping, err := a.RESTMapping(gvk)
+		if err != nil {
+			return results, &ObjectError{"map", gvk, obj.GetNamespace(), obj.GetName(), err}
+		}
+
+		client, objNamespace := a.resourceInterface(mapping, obj.GetNamespace(), namespace)
+
+		obj = obj.DeepCopy()
+		obj.SetNamespace(objNamespace)
+
+		operation := OperationConfigured
+
+		existing, err := client.Get(ctx, obj.GetName(), metav1.GetOptions{})
+		if k8serrors.IsNotFound(err) {
+			operation = OperationCreated
+			existing = nil
+		} else if err != nil {
+			return results, &ObjectError{"get", gvk, objNamespace, obj.GetName(), err}
+		}
+
+		a.setOwnershipLabels(obj, existing)
+
+		data, err := json.Marshal(obj.Object)
+		if err != nil {
+			return results, &ObjectError{"apply", gvk, objNamespace, obj.GetName(), err}
+		}
+
+		options := metav1.PatchOptions{FieldManager: ApplierFieldManager}
+
+		applied, err := client.Patch(ctx, obj.GetName(), types.ApplyPatchType, data, options)
+		if k8serrors.IsConflict(err) && isTestConflict(err) {
+			force := true
+			options.Force = &force
+
+			applied, err = client.Patch(ctx, obj.GetName(), types.ApplyPatchType, data, options)
+		}
+
+		if err != nil {
+			return results, &ObjectError{"apply", gvk, objNamespace, obj.GetName(), err}
+		}
+
+		if existing != nil && existing.GetResourceVersion() == applied.GetResourceVersion() {
+			operation = OperationUnchanged
+		}
+
+		results = append(results, ApplyResult{
+			GVR:       mapping.Resource,
+			Kind:      gvk.Kind,
+			Namespace: objNamespace,
+			Name:      obj.GetName(),
+			Operation: operation,
+			Object:    applied,
+		})
+	}
+
+	return results, nil
+}
+
+// isTestConflict returns whether all the fields of the server-side apply conflict were last set by
+// the tests, through kubectl or the Applier, so that applying the manifest can overwrite them like
+// `kubectl apply` would.
+func isTestConflict(err error) bool {
+	var status k8serrors.APIStatus
+	if !errors.As(err, &status) || status.Status().Details == nil {
+		return false
+	}
+
+	conflicts := 0
+
+	for _, cause := range status.Status().Details.Causes {
+		if cause.Type != metav1.CauseTypeFieldManagerConflict {
+			continue
+		}
+
+		conflicts++
+
+		// The message is like `conflict with "kubectl-client-side-apply" using v1`.
+		quoted, _ := strings.CutPrefix(cause.Message, "conflict with ")
+
+		manager, err := strconv.QuotedPrefix(quoted)
+		if err != nil {
+			return false
+		}
+
+		manager, err = strconv.Unquote(manager)
+		if err != nil || !isTestFieldManager(manager) {
+			return false
+		}
+	}
+
+	return conflicts > 0
+}
+
+// isTestFieldManager returns whether the field manager is the one of the Applier, or of a kubectl or
+// oc command, which are all run by the tests.
+func isTestFieldManager(manager string) bool {
+	return manager == ApplierFieldManager || manager == "before-first-apply" ||
+		manager == "kubectl" || strings.HasPrefix(manager, "kubectl-")
+}
+
+// setOwnershipLabels adds the OwnershipLabels to the object if it doesn't exist, or if the existing
+// object has one of them.
+func (a *Applier) setOwnershipLabels(obj, existing *unstructured.Unstructured) {
+	if a.OwnershipLabels == nil {
+		return
+	}
+
+	labels := a.OwnershipLabels()
+
+	if existing != nil {
+		existingLabels := existing.GetLabels()
+
+		owned := slices.ContainsFunc(slices.Collect(maps.Keys(labels)), func(key string) bool {
+			_, found := existingLabels[key]
+
+			return found
+		})
+		if !owned {
+			return
+		}
+	}
+
+	AddLabels(obj, labels)
+}
+
+// AddLabels adds the labels to the labels of the object, replacing those with the same key.
+func AddLabels(obj metav1.Object, labels map[string]string) {
+	if len(labels) == 0 {
+		return
+	}
+
+	merged := obj.GetLabels()
+	if merged == nil {
+		merged = map[string]string{}
+	}
+
+	maps.Copy(merged, labels)
+	obj.SetLabels(merged)
+}
+
+// DeleteFile deletes all of the objects in the YAML file or URL. Objects without a namespace are
+// deleted from the given namespace. When ignoreNotFound is false, objects which don't exist cause
+// an error.
+func (a *Applier) DeleteFile(
+	ctx context.Context, source, namespace string, ignoreNotFound bool,
+) ([]ApplyResult, error) {
+	objects, err := ReadManifests(source)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.DeleteObjects(ctx, namespace, ignoreNotFound, objects...)
+}
+
+// DeleteObjects deletes the objects, where objects without a namespace are deleted from the given
+// namespace. When ignoreNotFound is false, objects which don't exist cause an error. It stops at
+// the first failure, and returns the results of the objects deleted so far.
+func (a *Applier) DeleteObjects(
+	ctx context.Context, namespace string, ignoreNotFound bool, objects ...*unstructured.Unstructured,
+) ([]ApplyResult, error) {
+	results := make([]ApplyResult, 0, len(objects))
+
+	for _, obj := range objects {
+		gvk := obj.GroupVersionKind()
+
+		mapping, err := a.RESTMapping(gvk)
+		if err != nil {
+			// When the CRD of the object is not installed, the object can't exist either.
+			if meta.IsNoMatchError(err) && ignoreNotFound {
+				continue
+			}
+
+			return results, &ObjectError{"map", gvk, obj.GetNamespace(), obj.GetName(), err}
+		}
+
+		result, err := a.delete(ctx, mapping, obj.GetNamespace(), namespace, obj.GetName(), ignoreNotFound)
+		if err != nil {
+			return results, err
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// Delete deletes the named object of the given resource, which can be in any of the forms accepted
+// by kubectl, for example `configmap` or `placements.cluster.open-cluster-management.io`.
+func (a *Applier) Delete(
+	ctx context.Context, resource, namespace, name string, ignoreNotFound bool,
+) (ApplyResult, error) {
+	mapping, err := a.resourceFor(resource)
+	if err != nil {
+		return ApplyResult{}, &ObjectError{"map", schema.GroupVersionKind{Kind: resource}, namespace, name, err}
+	}
+
+	return a.delete(ctx, mapping, "", namespace, name, ignoreNotFound)
+}
+
+func (a *Applier) delete(
+	ctx context.Context, mapping *meta.RESTMapping, objNamespace, namespace, name string, ignoreNotFound bool,
+) (ApplyResult, error) {
+	client, objNamespace := a.resourceInterface(mapping, objNamespace, namespace)
+	result := ApplyResult{
+		GVR:       mapping.Resource,
+		Kind:      mapping.GroupVersionKind.Kind,
+		Namespace: objNamespace,
+		Name:      name,
+		Operation: OperationDeleted,
+	}
+
+	err := client.Delete(ctx, name, metav1.DeleteOptions{})
+	if k8serrors.IsNotFound(err) && ignoreNotFound {
+		result.Operation = OperationNotFound
+
+		return result, nil
+	}
+
+	if err != nil {
+		return result, &ObjectError{"delete", mapping.GroupVersionKind, objNamespace, name, err}
+	}
+
+	return result, nil
+}
+
+// Patch patches the named object of the given resource, which can be in any of the forms accepted
+// by kubectl, for example `configmap` or `placements.cluster.open-cluster-management.io`.
+func (a *Applier) Patch(
+	ctx context.Context, resource, namespace, name string, patchType types.PatchType, patch []byte,
+) (ApplyResult, error) {
+	mapping, err := a.resourceFor(resource)
+	if err != nil {
+		return ApplyResult{}, &ObjectError{"map", schema.GroupVersionKind{Kind: resource}, namespace, name, err}
+	}
+
+	client, objNamespace := a.resourceInterface(mapping, "", namespace)
+
+	patched, err := client.Patch(ctx, name, patchType, patch, metav1.PatchOptions{FieldManager: ApplierFieldManager})
+	if err != nil {
+		return ApplyResult{}, &ObjectError{"patch", mapping.GroupVersionKind, objNamespace, name, err}
+	}
+
+	return ApplyResult{
+		GVR:       mapping.Resource,
+		Kind:      mapping.GroupVersionKind.Kind,
+		Namespace: objNamespace,
+		Name:      name,
+		Operation: OperationPatched,
+		Object:    patched,
+	}, nil
+}
+
+// WaitForDeletion waits until the deleted objects are no longer returned by the API server, for
+// example because of finalizers, in the same way as `kubectl delete --wait`.
+func (a *Applier) WaitForDeletion(ctx context.Context, timeout time.Duration, results ...ApplyResult) error {
+	for _, result := range results {
+		if result.Operation != OperationDeleted {
+			continue
+		}
+
+		var client dynamic.ResourceInterface = a.client.Resource(result.GVR)
+		if result.Namespace != "" {
+			client = a.client.Resource(result.GVR).Namespace(result.Namespace)
+		}
+
+		err := wait.PollUntilContextTimeout(ctx, time.Second, timeout, true, func(ctx context.Context) (bool, error) {
+			_, err := client.Get(ctx, result.Name, metav1.GetOptions{})
+			if k8serrors.IsNotFound(err) {
+				return true, nil
+			}
+
+			return false, err
+		})
+		if err != nil {
+			gvk := schema.GroupVersionKind{Group: result.GVR.Group, Version: result.GVR.Version, Kind: result.Kind}
+
+			return &ObjectError{"wait for the deletion of", gvk, result.Namespace, result.Name, err}
+		}
+	}
+
+	return nil
+}
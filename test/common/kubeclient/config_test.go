@@ -0,0 +1,24 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package kubeclient
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Test the kubeconfig loading", func() {
+	It("Uses the given context of the kubeconfig", func() {
+		config, err := LoadConfig("", "../testdata/fake-kubeconfig.yaml", "cluster2")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(config.Host).To(Equal("https://cluster2.example.com:6443"))
+	})
+
+	It("Returns an error for a missing kubeconfig or context", func() {
+		_, err := LoadConfig("", "../testdata/missing-kubeconfig", "")
+		Expect(err).To(HaveOccurred())
+
+		_, err = LoadConfig("", "../testdata/fake-kubeconfig.yaml", "missing")
+		Expect(err).To(MatchError(ContainSubstring(`context "missing" does not exist`)))
+	})
+})
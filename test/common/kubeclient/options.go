@@ -0,0 +1,230 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package kubeclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/transport"
+	"k8s.io/klog"
+)
+
+// ClientOptions configures the rate limiting, timeouts, and retries of the clients created by
+// NewKubeClient and NewKubeClientDynamic.
+type ClientOptions struct {
+	// QPS and Burst are the client-side rate limit of the requests to the API server.
+	QPS   float32
+	Burst int
+	// Timeout is the timeout of each attempt of a request, except for watches. Zero means no timeout.
+	Timeout time.Duration
+	// Retries is how many times a request is retried after a transient error: a 429 or 503 response
+	// for any request, and a 500, 502, or 504 response or a connection error for the idempotent
+	// requests, which are the ones that are safe to send twice.
+	Retries int
+	// RetryBackoff is the delay before the first retry, which doubles with every retry up to
+	// RetryBackoffCap. A Retry-After header of the response takes precedence, up to the cap.
+	RetryBackoff    time.Duration
+	RetryBackoffCap time.Duration
+	// WrapTransport wraps the transport of each attempt of a request, like the wrapper of an
+	// APIAccounting.
+	WrapTransport transport.WrapperFunc
+}
+
+// DefaultClientOptions returns the ClientOptions used by NewEnvironment, which have a higher rate
+// limit than the client-go defaults of 5 QPS and a burst of 10 so that large runs aren't throttled.
+func DefaultClientOptions() ClientOptions {
+	return ClientOptions{
+		QPS:             50,
+		Burst:           100,
+		Timeout:         time.Minute,
+		Retries:         5,
+		RetryBackoff:    500 * time.Millisecond,
+		RetryBackoffCap: 30 * time.Second,
+	}
+}
+
+// Apply configures the rest.Config with the ClientOptions.
+func (o ClientOptions) Apply(config *rest.Config) {
+	config.QPS = o.QPS
+	config.Burst = o.Burst
+
+	if o.WrapTransport != nil {
+		config.Wrap(o.WrapTransport)
+	}
+
+	if o.Retries <= 0 && o.Timeout <= 0 {
+		return
+	}
+
+	config.Wrap(func(next http.RoundTripper) http.RoundTripper {
+		return &retryTransport{next: next, options: o}
+	})
+}
+
+// retryTransport retries the requests which failed with a transient error and applies the timeout
+// of each attempt.
+type retryTransport struct {
+	next    http.RoundTripper
+	options ClientOptions
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	backoff := wait.Backoff{
+		Duration: t.options.RetryBackoff,
+		Factor:   2,
+		Jitter:   0.1,
+		Steps:    t.options.Retries + 1,
+		Cap:      t.options.RetryBackoffCap,
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := t.attempt(req)
+
+		if attempt >= t.options.Retries || req.Context().Err() != nil || !isRetryable(req, resp, err) {
+			return resp, err
+		}
+
+		// The body was consumed by the attempt, so a request without a way to get it again can't be
+		// retried.
+		if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+			return resp, err
+		}
+
+		delay := backoff.Step()
+
+		if retryAfter := retryAfter(resp); retryAfter > 0 {
+			delay = retryAfter
+			if t.options.RetryBackoffCap > 0 {
+				delay = min(delay, t.options.RetryBackoffCap)
+			}
+		}
+
+		klog.V(2).Infof(
+			"Retrying %s %s in %s after a transient error (attempt %d of %d): %s",
+			req.Method, req.URL.Path, delay, attempt+1, t.options.Retries, describeAttempt(resp, err),
+		)
+
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(delay):
+		}
+
+		retry := req.Clone(req.Context())
+
+		if req.GetBody != nil {
+			if retry.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+
+		req = retry
+	}
+}
+
+// attempt sends the request with the timeout of an attempt, unless it's a watch.
+func (t *retryTransport) attempt(req *http.Request) (*http.Response, error) {
+	if t.options.Timeout <= 0 || req.URL.Query().Get("watch") == "true" {
+		return t.next.RoundTrip(req)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), t.options.Timeout)
+
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+
+		return nil, err
+	}
+
+	// The response body is read after RoundTrip returns, so the timeout ends when it's closed.
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+
+	return resp, nil
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	defer c.cancel()
+
+	return c.ReadCloser.Close()
+}
+
+// isRetryable returns whether the request failed with a transient error that is safe to retry.
+func isRetryable(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		return isIdempotent(req.Method) && isTransientConnectionError(err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusGatewayTimeout:
+		return isIdempotent(req.Method)
+	default:
+		return false
+	}
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+func isTransientConnectionError(err error) bool {
+	if utilnet.IsConnectionReset(err) || utilnet.IsConnectionRefused(err) || utilnet.IsProbableEOF(err) {
+		return true
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// retryAfter returns the delay of the Retry-After header of the response in seconds, or zero.
+func retryAfter(resp *http.Response) time.Duration {
+	if resp == nil {
+		return 0
+	}
+
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+
+	return time.Duration(seconds) * time.Second
+}
+
+func describeAttempt(resp *http.Response, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+
+	return resp.Status
+}
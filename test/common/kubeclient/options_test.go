@@ -0,0 +1,114 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package kubeclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+var _ = Describe("Test the client options", func() {
+	var requests atomic.Int32
+	var options ClientOptions
+
+	BeforeEach(func() {
+		requests.Store(0)
+
+		options = DefaultClientOptions()
+		options.Retries = 3
+		options.RetryBackoff = time.Millisecond
+		options.RetryBackoffCap = 10 * time.Millisecond
+	})
+
+	// newClient returns a client of a server which responds with the status codes in order, and then
+	// with a namespace.
+	newClient := func(delay time.Duration, statusCodes ...int) kubernetes.Interface {
+		GinkgoHelper()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			request := int(requests.Add(1))
+
+			if request == 1 {
+				time.Sleep(delay)
+			}
+
+			if request <= len(statusCodes) {
+				w.WriteHeader(statusCodes[request-1])
+
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"apiVersion": "v1", "kind": "Namespace", "metadata": {"name": "my-ns"}}`))
+		}))
+		DeferCleanup(server.Close)
+
+		config := &rest.Config{Host: server.URL}
+		options.Apply(config)
+
+		Expect(config.QPS).To(BeEquivalentTo(50))
+		Expect(config.Burst).To(Equal(100))
+
+		client, err := kubernetes.NewForConfig(config)
+		Expect(err).ToNot(HaveOccurred())
+
+		return client
+	}
+
+	It("Retries the transient errors", func(ctx SpecContext) {
+		client := newClient(0, http.StatusTooManyRequests, http.StatusBadGateway)
+
+		namespace, err := client.CoreV1().Namespaces().Get(ctx, "my-ns", metav1.GetOptions{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(namespace.Name).To(Equal("my-ns"))
+		Expect(requests.Load()).To(BeEquivalentTo(3))
+	})
+
+	It("Gives up after the retries", func(ctx SpecContext) {
+		client := newClient(0, http.StatusServiceUnavailable, http.StatusServiceUnavailable,
+			http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+
+		_, err := client.CoreV1().Namespaces().Get(ctx, "my-ns", metav1.GetOptions{})
+		Expect(err).To(HaveOccurred())
+		Expect(requests.Load()).To(BeEquivalentTo(4))
+	})
+
+	It("Doesn't retry a create after a server error", func(ctx SpecContext) {
+		client := newClient(0, http.StatusInternalServerError)
+
+		_, err := client.CoreV1().Namespaces().Create(
+			ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "my-ns"}}, metav1.CreateOptions{},
+		)
+		Expect(err).To(HaveOccurred())
+		Expect(requests.Load()).To(BeEquivalentTo(1))
+	})
+
+	It("Retries a create after a throttling error", func(ctx SpecContext) {
+		client := newClient(0, http.StatusTooManyRequests)
+
+		_, err := client.CoreV1().Namespaces().Create(
+			ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "my-ns"}}, metav1.CreateOptions{},
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(requests.Load()).To(BeEquivalentTo(2))
+	})
+
+	It("Retries an attempt which timed out", func(ctx SpecContext) {
+		options.Timeout = 50 * time.Millisecond
+
+		client := newClient(200 * time.Millisecond)
+
+		_, err := client.CoreV1().Namespaces().Get(ctx, "my-ns", metav1.GetOptions{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(requests.Load()).To(BeEquivalentTo(2))
+	})
+})
@@ -0,0 +1,270 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// The modes of the leak audit run by StartLeakAudit.
+const (
+	LeakAuditOff    = "off"
+	LeakAuditReport = "report"
+	LeakAuditRemove = "remove"
+)
+
+const leakedResourcesReportEntry = "Leaked resources"
+
+// AuditedResource is a resource whose objects are audited for leaks on one of the clusters.
+type AuditedResource struct {
+	Cluster ClusterTarget
+	GVR     schema.GroupVersionResource
+	// LabelSelector limits the audit to the matching objects.
+	LabelSelector string
+}
+
+// LeakedObject is an object which was created after the snapshot of a LeakAuditor.
+type LeakedObject struct {
+	Cluster   ClusterTarget
+	GVR       schema.GroupVersionResource
+	Namespace string
+	Name      string
+	// RunID is the value of the RunIDLabel of the object, or empty if it doesn't have one.
+	RunID string
+}
+
+func (o LeakedObject) String() string {
+	return fmt.Sprintf("%s %s %s", o.Cluster, o.GVR.GroupResource(), namespacedName(o.Namespace, o.Name))
+}
+
+// LeakAuditor finds the objects of the audited resources which were created after its snapshot.
+// Objects are told apart by their UID, so an object which was deleted and recreated since the
+// snapshot counts as new.
+type LeakAuditor struct {
+	Resources []AuditedResource
+
+	env      *Environment
+	snapshot map[string]bool
+}
+
+// DefaultAuditedResources returns the resources created by the policy tests: the policies and their
+// placements on the hub, the policy templates on the cluster hosting them, the operators installed
+// on the managed cluster, and the namespaces and cluster roles on both. The CSVs copied by OLM to
+// every namespace are skipped, since they are removed along with the original.
+func (e *Environment) DefaultAuditedResources() []AuditedResource {
+	return []AuditedResource{
+		{Cluster: TargetHub, GVR: GvrPolicy},
+		{Cluster: TargetHub, GVR: GvrPolicySet},
+		{Cluster: TargetHub, GVR: GvrPlacement},
+		{Cluster: TargetHub, GVR: GvrPlacementBinding},
+		{Cluster: TargetHub, GVR: GvrPlacementDecision},
+		{Cluster: TargetHub, GVR: GvrManagedClusterSetBinding},
+		{Cluster: TargetHub, GVR: GvrNS},
+		{Cluster: TargetHub, GVR: GvrClusterRole},
+		{Cluster: TargetHub, GVR: GvrClusterRoleBinding},
+		{Cluster: TargetHosting, GVR: GvrPolicy},
+		{Cluster: TargetHosting, GVR: GvrConfigurationPolicy},
+		{Cluster: TargetHosting, GVR: GvrOperatorPolicy},
+		{Cluster: TargetHosting, GVR: GvrCertPolicy},
+		{Cluster: TargetManaged, GVR: GvrSubscriptionOLM},
+		{Cluster: TargetManaged, GVR: GvrOperatorGroup},
+		{Cluster: TargetManaged, GVR: GvrClusterServiceVersion, LabelSelector: "!olm.copiedFrom"},
+		{Cluster: TargetManaged, GVR: GvrNS},
+		{Cluster: TargetManaged, GVR: GvrClusterRole},
+	}
+}
+
+// NewLeakAuditor returns a LeakAuditor of the resources, or of the DefaultAuditedResources if none
+// are given.
+func (e *Environment) NewLeakAuditor(resources ...AuditedResource) *LeakAuditor {
+	if len(resources) == 0 {
+		resources = e.DefaultAuditedResources()
+	}
+
+	return &LeakAuditor{Resources: resources, env: e}
+}
+
+// Snapshot records the objects which exist now, so that they're not reported as leaks.
+func (a *LeakAuditor) Snapshot(ctx context.Context) error {
+	snapshot := map[string]bool{}
+
+	err := a.list(ctx, func(_ LeakedObject, key string) {
+		snapshot[key] = true
+	})
+	if err != nil {
+		return err
+	}
+
+	a.snapshot = snapshot
+
+	return nil
+}
+
+// Leaks returns the objects which were created after the snapshot, sorted by cluster, resource,
+// namespace, and name. An object listed on two clusters which are the same, like the hub and the
+// hosting cluster in hosted mode, is only returned once.
+func (a *LeakAuditor) Leaks(ctx context.Context) ([]LeakedObject, error) {
+	if a.snapshot == nil {
+		return nil, errors.New("the leak auditor has no snapshot to compare to")
+	}
+
+	leaks := []LeakedObject{}
+	seen := map[string]bool{}
+
+	err := a.list(ctx, func(obj LeakedObject, key string) {
+		if !a.snapshot[key] && !seen[key] {
+			leaks = append(leaks, obj)
+		}
+
+		seen[key] = true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(leaks, func(a, b LeakedObject) int {
+		return strings.Compare(a.String(), b.String())
+	})
+
+	return leaks, nil
+}
+
+// Remove deletes the leaked objects, ignoring those which don't exist anymore. It doesn't wait for
+// the objects to be removed, since deleting a namespace can take minutes.
+func (a *LeakAuditor) Remove(ctx context.Context, leaks []LeakedObject) error {
+	var errs []error
+
+	for _, leak := range leaks {
+		_, client := a.env.targetClients(leak.Cluster)
+
+		err := client.Resource(leak.GVR).Namespace(leak.Namespace).Delete(ctx, leak.Name, metav1.DeleteOptions{})
+		if err != nil && !k8serrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("failed to delete the leaked object %s: %w", leak, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// list calls the function with every object of the audited resources and its key, which is its UID,
+// or its description if it has no UID. Resources which aren't served by the cluster, like those of
+// an optional operator, are skipped.
+func (a *LeakAuditor) list(ctx context.Context, fn func(obj LeakedObject, key string)) error {
+	for _, resource := range a.Resources {
+		_, client := a.env.targetClients(resource.Cluster)
+
+		list, err := client.Resource(resource.GVR).List(ctx, metav1.ListOptions{LabelSelector: resource.LabelSelector})
+		if k8serrors.IsNotFound(err) {
+			continue
+		}
+
+		if err != nil {
+			return fmt.Errorf(
+				"failed to list %s on the %s cluster: %w", resource.GVR.GroupResource(), resource.Cluster, err,
+			)
+		}
+
+		for _, item := range list.Items {
+			obj := LeakedObject{
+				Cluster:   resource.Cluster,
+				GVR:       resource.GVR,
+				Namespace: item.GetNamespace(),
+				Name:      item.GetName(),
+				RunID:     item.GetLabels()[RunIDLabel],
+			}
+
+			key := string(item.GetUID())
+			if key == "" {
+				key = obj.String()
+			}
+
+			fn(obj, key)
+		}
+	}
+
+	return nil
+}
+
+// StartLeakAudit snapshots the DefaultAuditedResources, and registers a DeferCleanup which reports
+// the objects created since, and removes those labeled with the RunID of the Environment when
+// LeakAudit is `remove`. The other leaks could be from another run sharing the clusters, so they're
+// only reported. Leaks are reported in the output and as a report entry rather than failing the
+// suite. When running in parallel, call it in
+// the process 1 function of SynchronizedBeforeSuite, so that the audit runs once every process is
+// done.
+func (e *Environment) StartLeakAudit(ctx context.Context) error {
+	switch e.LeakAudit {
+	case LeakAuditOff:
+		return nil
+	case LeakAuditReport, LeakAuditRemove:
+	default:
+		return fmt.Errorf(
+			"the leak audit must be one of %s, %s, or %s, but got %s",
+			LeakAuditOff, LeakAuditReport, LeakAuditRemove, e.LeakAudit,
+		)
+	}
+
+	auditor := e.NewLeakAuditor()
+
+	if err := auditor.Snapshot(ctx); err != nil {
+		return err
+	}
+
+	DeferCleanup(func(ctx context.Context) error {
+		return e.auditLeaks(ctx, auditor)
+	})
+
+	return nil
+}
+
+// auditLeaks reports the leaks found by the auditor, and removes those of this run when LeakAudit is
+// `remove`.
+func (e *Environment) auditLeaks(ctx context.Context, auditor *LeakAuditor) error {
+	leaks, err := auditor.Leaks(ctx)
+	if err != nil || len(leaks) == 0 {
+		return err
+	}
+
+	descriptions := make([]string, 0, len(leaks))
+	for _, leak := range leaks {
+		descriptions = append(descriptions, leak.String())
+	}
+
+	GinkgoWriter.Printf("Found %d leaked objects:\n  %s\n", len(leaks), strings.Join(descriptions, "\n  "))
+	AddReportEntry(leakedResourcesReportEntry, strings.Join(descriptions, "\n"))
+
+	if e.LeakAudit != LeakAuditRemove {
+		return nil
+	}
+
+	ownLeaks := []LeakedObject{}
+
+	for _, leak := range leaks {
+		if e.RunID != "" && leak.RunID == e.RunID {
+			ownLeaks = append(ownLeaks, leak)
+		}
+	}
+
+	if skipped := len(leaks) - len(ownLeaks); skipped != 0 {
+		GinkgoWriter.Printf(
+			"Not removing %d leaked objects without the %s=%s label\n", skipped, RunIDLabel, e.RunID,
+		)
+	}
+
+	if len(ownLeaks) == 0 {
+		return nil
+	}
+
+	By("Removing the leaked objects of this run")
+
+	return auditor.Remove(ctx, ownLeaks)
+}
@@ -0,0 +1,142 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package common
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var _ = Describe("Test the leak auditor", func() {
+	var env *Environment
+
+	BeforeEach(func() {
+		env = startFakeBackend().Env
+	})
+
+	create := func(
+		ctx SpecContext, client dynamic.Interface, gvr schema.GroupVersionResource, obj map[string]any,
+	) {
+		GinkgoHelper()
+
+		object := &unstructured.Unstructured{Object: obj}
+
+		_, err := client.Resource(gvr).Namespace(object.GetNamespace()).Create(ctx, object, metav1.CreateOptions{})
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	namespace := func(name string) map[string]any {
+		return map[string]any{"apiVersion": "v1", "kind": "Namespace", "metadata": map[string]any{"name": name}}
+	}
+
+	It("Reports and removes the objects created after the snapshot", func(ctx SpecContext) {
+		create(ctx, env.ClientHubDynamic, GvrNS, namespace("existing"))
+
+		auditor := env.NewLeakAuditor()
+		Expect(auditor.Snapshot(ctx)).To(Succeed())
+
+		create(ctx, env.ClientManagedDynamic, GvrNS, namespace("leaked"))
+		create(ctx, env.ClientHubDynamic, GvrPolicy, map[string]any{
+			"apiVersion": "policy.open-cluster-management.io/v1",
+			"kind":       "Policy",
+			"metadata":   map[string]any{"name": "leaked-policy", "namespace": env.UserNamespace},
+		})
+		create(ctx, env.ClientManagedDynamic, GvrClusterServiceVersion, map[string]any{
+			"apiVersion": "operators.coreos.com/v1alpha1",
+			"kind":       "ClusterServiceVersion",
+			"metadata": map[string]any{
+				"name":      "my-operator.v1.0.0",
+				"namespace": "other",
+				"labels":    map[string]any{"olm.copiedFrom": "operators"},
+			},
+		})
+
+		leaks, err := auditor.Leaks(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(leaks).To(HaveLen(2))
+		Expect(leaks[0].String()).To(Equal("hub policies.policy.open-cluster-management.io policy-test/leaked-policy"))
+		Expect(leaks[1].String()).To(Equal("managed namespaces leaked"))
+
+		Expect(auditor.Remove(ctx, leaks)).To(Succeed())
+
+		leaks, err = auditor.Leaks(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(leaks).To(BeEmpty())
+
+		_, err = env.ClientHubDynamic.Resource(GvrNS).Get(ctx, "existing", metav1.GetOptions{})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Reports an object which was recreated after the snapshot", func(ctx SpecContext) {
+		configMap := func(uid string) map[string]any {
+			return map[string]any{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata":   map[string]any{"name": "my-config", "namespace": "default", "uid": uid},
+			}
+		}
+
+		create(ctx, env.ClientHubDynamic, GvrConfigMap, configMap("uid-1"))
+
+		auditor := env.NewLeakAuditor(AuditedResource{Cluster: TargetHub, GVR: GvrConfigMap})
+		Expect(auditor.Snapshot(ctx)).To(Succeed())
+
+		err := env.ClientHubDynamic.Resource(GvrConfigMap).Namespace("default").Delete(
+			ctx, "my-config", metav1.DeleteOptions{},
+		)
+		Expect(err).ToNot(HaveOccurred())
+
+		create(ctx, env.ClientHubDynamic, GvrConfigMap, configMap("uid-2"))
+
+		leaks, err := auditor.Leaks(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(leaks).To(Equal([]LeakedObject{
+			{Cluster: TargetHub, GVR: GvrConfigMap, Namespace: "default", Name: "my-config"},
+		}))
+	})
+
+	It("Only removes the leaked objects of this run", func(ctx SpecContext) {
+		env.RunID = "20260102-150405-a1b2c3"
+		env.LeakAudit = LeakAuditRemove
+
+		auditor := env.NewLeakAuditor(AuditedResource{Cluster: TargetHub, GVR: GvrNS})
+		Expect(auditor.Snapshot(ctx)).To(Succeed())
+
+		labeled := func(name, runID string) map[string]any {
+			obj := namespace(name)
+			obj["metadata"].(map[string]any)["labels"] = map[string]any{RunIDLabel: runID}
+
+			return obj
+		}
+
+		create(ctx, env.ClientHubDynamic, GvrNS, labeled("this-run", env.RunID))
+		create(ctx, env.ClientHubDynamic, GvrNS, labeled("other-run", "20260102-150405-d4e5f6"))
+		create(ctx, env.ClientHubDynamic, GvrNS, namespace("unlabeled"))
+
+		Expect(env.auditLeaks(ctx, auditor)).To(Succeed())
+
+		leaks, err := auditor.Leaks(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(leaks).To(Equal([]LeakedObject{
+			{Cluster: TargetHub, GVR: GvrNS, Name: "other-run", RunID: "20260102-150405-d4e5f6"},
+			{Cluster: TargetHub, GVR: GvrNS, Name: "unlabeled"},
+		}))
+	})
+
+	It("Requires a snapshot", func(ctx SpecContext) {
+		_, err := env.NewLeakAuditor().Leaks(ctx)
+		Expect(err).To(MatchError("the leak auditor has no snapshot to compare to"))
+	})
+
+	It("Rejects an unknown leak audit mode", func(ctx SpecContext) {
+		env.LeakAudit = "delete"
+
+		Expect(env.StartLeakAudit(ctx)).To(MatchError(
+			"the leak audit must be one of off, report, or remove, but got delete",
+		))
+	})
+})
@@ -0,0 +1,205 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package common
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	policiesv1 "open-cluster-management.io/governance-policy-propagator/api/v1"
+)
+
+// ManagedCluster is a managed cluster that policies can be placed on. The default managed cluster
+// of an Environment is always available under the ClusterNamespaceOnHub name, and additional
+// clusters can be added with RegisterManagedCluster.
+type ManagedCluster struct {
+	// Name is the name of the ManagedCluster on the hub, which is also its namespace on the hub.
+	Name string
+	// Namespace is the namespace of the replicated policies and policy templates on the cluster
+	// hosting the policy templates.
+	Namespace  string
+	Kubeconfig string
+	// Context is the context of the kubeconfig, which defaults to its current context.
+	Context string
+	// Hosted is whether the policy templates are hosted on the hub, which is only supported for the
+	// default managed cluster.
+	Hosted bool
+
+	Client               kubernetes.Interface
+	ClientDynamic        dynamic.Interface
+	ClientHosting        kubernetes.Interface
+	ClientHostingDynamic dynamic.Interface
+}
+
+// RegisterManagedCluster creates clients from the given kubeconfig and adds the managed cluster to
+// the Environment under the given name. The replicated policies are expected in the namespace of
+// the same name on the managed cluster.
+func (e *Environment) RegisterManagedCluster(name, kubeconfig string) (*ManagedCluster, error) {
+	return e.registerManagedCluster(name, kubeconfig, "")
+}
+
+// registerManagedCluster registers the managed cluster with the given context of the kubeconfig, or
+// its current context if it's empty.
+func (e *Environment) registerManagedCluster(name, kubeconfig, kubeContext string) (*ManagedCluster, error) {
+	cluster := &ManagedCluster{
+		Name:       name,
+		Namespace:  name,
+		Kubeconfig: kubeconfig,
+		Context:    kubeContext,
+	}
+
+	var err error
+
+	if cluster.Client, cluster.ClientDynamic, err = e.newClients(name, kubeconfig, kubeContext); err != nil {
+		return nil, fmt.Errorf("failed to create the clients of the managed cluster %s: %w", name, err)
+	}
+
+	cluster.ClientHosting = cluster.Client
+	cluster.ClientHostingDynamic = cluster.ClientDynamic
+
+	if e.ManagedClusters == nil {
+		e.ManagedClusters = map[string]*ManagedCluster{}
+	}
+
+	e.ManagedClusters[name] = cluster
+
+	return cluster, nil
+}
+
+// registerManagedClusters registers the managed clusters in the format of
+// `name=kubeconfig[,name=kubeconfig...]` as set by the managed_clusters flag.
+func (e *Environment) registerManagedClusters(clusters string) error {
+	for entry := range strings.SplitSeq(clusters, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, kubeconfig, found := strings.Cut(entry, "=")
+		if !found || name == "" || kubeconfig == "" {
+			return fmt.Errorf("invalid managed cluster '%s', expected the format name=kubeconfig", entry)
+		}
+
+		if _, err := e.RegisterManagedCluster(name, kubeconfig); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DefaultManagedCluster returns the managed cluster configured by the cluster_namespace,
+// cluster_namespace_on_hub, and kubeconfig_managed flags.
+func (e *Environment) DefaultManagedCluster() *ManagedCluster {
+	if cluster, ok := e.ManagedClusters[e.ClusterNamespaceOnHub]; ok {
+		return cluster
+	}
+
+	return &ManagedCluster{
+		Name:                 e.ClusterNamespaceOnHub,
+		Namespace:            e.ClusterNamespace,
+		Kubeconfig:           e.KubeconfigManaged,
+		Context:              e.ContextManaged,
+		Hosted:               e.IsHosted,
+		Client:               e.ClientManaged,
+		ClientDynamic:        e.ClientManagedDynamic,
+		ClientHosting:        e.ClientHosting,
+		ClientHostingDynamic: e.ClientHostingDynamic,
+	}
+}
+
+// GetManagedCluster returns the registered managed cluster of the given name, or nil if it has
+// not been registered.
+func (e *Environment) GetManagedCluster(name string) *ManagedCluster {
+	if cluster, ok := e.ManagedClusters[name]; ok {
+		return cluster
+	}
+
+	if name == e.ClusterNamespaceOnHub {
+		return e.DefaultManagedCluster()
+	}
+
+	return nil
+}
+
+// ManagedClusterNames returns the sorted names of all registered managed clusters.
+func (e *Environment) ManagedClusterNames() []string {
+	names := make([]string, 0, len(e.ManagedClusters))
+
+	for name := range e.ManagedClusters {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	return names
+}
+
+// managedClusters looks up the managed clusters of the given names, and fails the test if any of
+// them are not registered.
+func (e *Environment) managedClusters(clusterNames []string) []*ManagedCluster {
+	GinkgoHelper()
+
+	clusters := make([]*ManagedCluster, 0, len(clusterNames))
+
+	for _, name := range clusterNames {
+		cluster := e.GetManagedCluster(name)
+		Expect(cluster).ToNot(BeNil(), "Managed cluster '%s' is not registered in the test environment", name)
+
+		clusters = append(clusters, cluster)
+	}
+
+	return clusters
+}
+
+// GetReplicatedComplianceState returns a function usable by ginkgo.Eventually that retrieves the
+// compliance state of the replicated policy on the specified managed cluster.
+func (e *Environment) GetReplicatedComplianceState(policyName, clusterName string) func(Gomega) any {
+	return func(g Gomega) any {
+		cluster := e.GetManagedCluster(clusterName)
+		g.Expect(cluster).ToNot(BeNil(), "Managed cluster '%s' is not registered in the test environment", clusterName)
+
+		replicatedPlc, err := cluster.ClientHostingDynamic.Resource(GvrPolicy).Namespace(cluster.Namespace).Get(
+			context.TODO(), e.UserNamespace+"."+policyName, metav1.GetOptions{},
+		)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		compliance, _, _ := unstructured.NestedString(replicatedPlc.Object, "status", "compliant")
+
+		return policiesv1.ComplianceState(compliance)
+	}
+}
+
+// DoClusterComplianceTest asserts that the given policy has the given compliance on each of the
+// given managed clusters, both in the per-cluster status of the root policy on the hub and on the
+// replicated policy on the managed cluster.
+func (e *Environment) DoClusterComplianceTest(
+	policyName string, compliance policiesv1.ComplianceState, clusterNames ...string,
+) {
+	GinkgoHelper()
+
+	for _, cluster := range e.managedClusters(clusterNames) {
+		By("Checking if the status of root policy " + policyName + " is " + string(compliance) +
+			" on cluster " + cluster.Name)
+		_, err := e.WaitForRootPolicy(
+			context.TODO(), policyName, "cluster "+cluster.Name+" is "+string(compliance),
+			ClusterComplianceIs(cluster.Name, compliance),
+		)
+		Expect(err).ToNot(HaveOccurred())
+
+		By("Checking if the status of the replicated policy " + policyName + " is " + string(compliance) +
+			" on cluster " + cluster.Name)
+		_, err = e.WaitForReplicatedPolicy(
+			context.TODO(), policyName, cluster.Name, "compliant is "+string(compliance), ComplianceIs(compliance),
+		)
+		Expect(err).ToNot(HaveOccurred())
+	}
+}
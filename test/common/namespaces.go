@@ -0,0 +1,231 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// TestNamespaceLabel is set on the namespaces created by SetupUserNamespace and
+// CreateIsolatedNamespace, with the configured user namespace as the value. A namespace with the
+// label which exists before it is set up was leaked by an earlier run.
+const TestNamespaceLabel = "policy-test.open-cluster-management.io/user-namespace"
+
+// globalSetNamespace is bound to the global ManagedClusterSet by the hub itself, so it's used as is
+// and never deleted.
+const globalSetNamespace = "open-cluster-management-global-set"
+
+const leakedNamespaceReportEntry = "Leaked namespace"
+
+// UserNamespaceOptions configure the namespaces created by SetupUserNamespace and
+// CreateIsolatedNamespace.
+type UserNamespaceOptions struct {
+	// ManagedClusterSetBinding creates the `global` ManagedClusterSetBinding in the namespace if it
+	// doesn't exist, so that Placements in the namespace can select the managed clusters.
+	ManagedClusterSetBinding bool
+	// KeepNamespace leaves the user namespace in place on teardown when it isn't isolated. Isolated
+	// namespaces are always deleted.
+	KeepNamespace bool
+}
+
+// namespaceAllocation records what was created for a namespace, so that the teardown only deletes
+// those objects.
+type namespaceAllocation struct {
+	name string
+	// base is the user namespace the name was derived from.
+	base             string
+	keep             bool
+	createdNamespace bool
+	createdBinding   bool
+}
+
+// UserNamespaceIsolated returns whether SetupUserNamespace creates a user namespace per Ginkgo
+// process, which is the case when the IsolateUserNamespace flag is set or when running in
+// parallel, unless the user namespace is the global ManagedClusterSet namespace.
+func (e *Environment) UserNamespaceIsolated() bool {
+	suiteConfig, _ := GinkgoConfiguration()
+
+	return (e.IsolateUserNamespace || suiteConfig.ParallelTotal > 1) && e.UserNamespace != globalSetNamespace
+}
+
+// SetupUserNamespace creates the user namespace on the hub, and its ManagedClusterSetBinding when
+// requested. When the user namespace is isolated, the number of the Ginkgo process is appended to
+// its name and UserNamespace is updated, so that the processes of `ginkgo -p` don't share any
+// namespaced objects. An isolated namespace left over by an earlier run is reported and recreated.
+// The teardown is registered with DeferCleanup, so it runs even when the suite fails or is
+// interrupted.
+func (e *Environment) SetupUserNamespace(ctx context.Context, opts UserNamespaceOptions) error {
+	if e.userNamespace != nil {
+		return fmt.Errorf("the user namespace %s is already set up", e.UserNamespace)
+	}
+
+	name := e.UserNamespace
+	isolated := e.UserNamespaceIsolated()
+
+	if isolated {
+		name = fmt.Sprintf("%s-%d", e.UserNamespace, GinkgoParallelProcess())
+	}
+
+	alloc, err := e.allocateNamespace(ctx, name, e.UserNamespace, isolated, opts)
+	if alloc != nil {
+		e.userNamespace = alloc
+		e.UserNamespace = name
+
+		DeferCleanup(e.TeardownUserNamespace)
+	}
+
+	return err
+}
+
+// TeardownUserNamespace deletes the ManagedClusterSetBinding and namespace created by
+// SetupUserNamespace, waits for the namespace to be removed, and restores UserNamespace. It only
+// needs to be called directly to tear down before the DeferCleanup registered by
+// SetupUserNamespace, and does nothing the second time.
+func (e *Environment) TeardownUserNamespace(ctx context.Context) error {
+	alloc := e.userNamespace
+	if alloc == nil {
+		return nil
+	}
+
+	e.userNamespace = nil
+	e.UserNamespace = alloc.base
+
+	return e.releaseNamespace(ctx, alloc)
+}
+
+// CreateIsolatedNamespace creates a namespace on the hub for the current spec, or for the specs of
+// the current Ordered container when called in BeforeAll, named after the user namespace with the
+// SpecSuffix appended. The namespace is deleted with DeferCleanup once the spec or container is
+// done.
+func (e *Environment) CreateIsolatedNamespace(ctx context.Context, opts UserNamespaceOptions) (string, error) {
+	name := e.UserNamespace + "-" + SpecSuffix()
+
+	alloc, err := e.allocateNamespace(ctx, name, e.UserNamespace, true, opts)
+	if alloc != nil {
+		DeferCleanup(func(ctx context.Context) error {
+			return e.releaseNamespace(ctx, alloc)
+		})
+	}
+
+	return name, err
+}
+
+// allocateNamespace creates the namespace unless it exists, and the ManagedClusterSetBinding when
+// requested. A labeled namespace which exists already was leaked by an earlier run, so it's
+// reported and recreated, unless it's a user namespace which is kept. The returned allocation is
+// set when the namespace is ready, even if the ManagedClusterSetBinding failed.
+func (e *Environment) allocateNamespace(
+	ctx context.Context, name, base string, isolated bool, opts UserNamespaceOptions,
+) (*namespaceAllocation, error) {
+	alloc := &namespaceAllocation{name: name, base: base, keep: opts.KeepNamespace && !isolated}
+	namespaces := e.ClientHubDynamic.Resource(GvrNS)
+
+	existing, err := namespaces.Get(ctx, name, metav1.GetOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to get the namespace %s: %w", name, err)
+	}
+
+	exists := err == nil
+
+	if exists {
+		if _, labeled := existing.GetLabels()[TestNamespaceLabel]; labeled && !alloc.keep {
+			GinkgoWriter.Printf("The namespace %s was leaked by an earlier run, recreating it\n", name)
+			AddReportEntry(leakedNamespaceReportEntry, name)
+
+			if err := e.deleteNamespace(ctx, name); err != nil {
+				return nil, err
+			}
+
+			exists = false
+		} else {
+			By("Using the existing namespace " + name)
+		}
+	}
+
+	if !exists {
+		By("Creating the namespace " + name)
+
+		namespace := &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "v1",
+			"kind":       "Namespace",
+			"metadata": map[string]any{
+				"name":   name,
+				"labels": map[string]any{TestNamespaceLabel: base},
+			},
+		}}
+
+		e.setOwnershipLabels(namespace)
+
+		if _, err := namespaces.Create(ctx, namespace, metav1.CreateOptions{}); err != nil {
+			return nil, fmt.Errorf("failed to create the namespace %s: %w", name, err)
+		}
+
+		alloc.createdNamespace = true
+	}
+
+	if opts.ManagedClusterSetBinding {
+		err := e.createManagedClusterSetBinding(ctx, name)
+		if k8serrors.IsAlreadyExists(err) {
+			return alloc, nil
+		}
+
+		if err != nil {
+			return alloc, fmt.Errorf("failed to create the ManagedClusterSetBinding in %s: %w", name, err)
+		}
+
+		alloc.createdBinding = true
+	}
+
+	return alloc, nil
+}
+
+// releaseNamespace deletes the ManagedClusterSetBinding and namespace of the allocation which were
+// created for it, and waits for the namespace to be removed. The error of a namespace which isn't
+// removed in time lists the conditions of the namespace, which name the objects and finalizers
+// left in it.
+func (e *Environment) releaseNamespace(ctx context.Context, alloc *namespaceAllocation) error {
+	var errs []error
+
+	if alloc.createdBinding {
+		By("Deleting the ManagedClusterSetBinding in " + alloc.name)
+
+		err := e.ClientHubDynamic.Resource(GvrManagedClusterSetBinding).Namespace(alloc.name).Delete(
+			ctx, "global", metav1.DeleteOptions{},
+		)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("failed to delete the ManagedClusterSetBinding in %s: %w", alloc.name, err))
+		}
+	}
+
+	if alloc.createdNamespace && !alloc.keep {
+		if err := e.deleteNamespace(ctx, alloc.name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// deleteNamespace deletes the namespace on the hub and waits for it to be removed.
+func (e *Environment) deleteNamespace(ctx context.Context, name string) error {
+	By("Deleting the namespace " + name)
+
+	err := e.ClientHubDynamic.Resource(GvrNS).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete the namespace %s: %w", name, err)
+	}
+
+	_, err = NewWaiter(e.ClientHubDynamic, GvrNS, "", name).Until(
+		ctx, time.Duration(e.DefaultTimeoutSeconds*6)*time.Second, "deleted", NotFound,
+	)
+
+	return err
+}
@@ -0,0 +1,152 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package common
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var _ = Describe("Test the user namespace isolation", func() {
+	var env *Environment
+
+	BeforeEach(func() {
+		env = startFakeBackend().Env
+	})
+
+	getNamespace := func(ctx SpecContext, name string) (*unstructured.Unstructured, error) {
+		return env.ClientHubDynamic.Resource(GvrNS).Get(ctx, name, metav1.GetOptions{})
+	}
+
+	createNamespace := func(ctx SpecContext, name string, labels map[string]any) {
+		GinkgoHelper()
+
+		_, err := env.ClientHubDynamic.Resource(GvrNS).Create(ctx, &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "v1",
+			"kind":       "Namespace",
+			"metadata":   map[string]any{"name": name, "labels": labels},
+		}}, metav1.CreateOptions{})
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	It("Creates and tears down a user namespace per process", func(ctx SpecContext) {
+		env.IsolateUserNamespace = true
+
+		Expect(env.SetupUserNamespace(ctx, UserNamespaceOptions{ManagedClusterSetBinding: true})).To(Succeed())
+		Expect(env.UserNamespace).To(Equal("policy-test-1"))
+
+		namespace, err := getNamespace(ctx, "policy-test-1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(namespace.GetLabels()).To(HaveKeyWithValue(TestNamespaceLabel, "policy-test"))
+
+		_, err = env.ClientHubDynamic.Resource(GvrManagedClusterSetBinding).Namespace("policy-test-1").Get(
+			ctx, "global", metav1.GetOptions{},
+		)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(env.SetupUserNamespace(ctx, UserNamespaceOptions{})).To(MatchError(ContainSubstring(
+			"the user namespace policy-test-1 is already set up",
+		)))
+
+		Expect(env.TeardownUserNamespace(ctx)).To(Succeed())
+		Expect(env.UserNamespace).To(Equal("policy-test"))
+
+		_, err = getNamespace(ctx, "policy-test-1")
+		Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+
+		_, err = env.ClientHubDynamic.Resource(GvrManagedClusterSetBinding).Namespace("policy-test-1").Get(
+			ctx, "global", metav1.GetOptions{},
+		)
+		Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+
+		Expect(env.TeardownUserNamespace(ctx)).To(Succeed())
+	})
+
+	It("Reports and recreates a namespace leaked by an earlier run", func(ctx SpecContext) {
+		env.IsolateUserNamespace = true
+		createNamespace(ctx, "policy-test-1", map[string]any{TestNamespaceLabel: "policy-test", "leaked": "true"})
+
+		Expect(env.SetupUserNamespace(ctx, UserNamespaceOptions{})).To(Succeed())
+
+		namespace, err := getNamespace(ctx, "policy-test-1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(namespace.GetLabels()).ToNot(HaveKey("leaked"))
+
+		entries := CurrentSpecReport().ReportEntries
+		Expect(entries).To(ContainElement(HaveField("Name", leakedNamespaceReportEntry)))
+	})
+
+	It("Leaves an existing namespace and ManagedClusterSetBinding in place", func(ctx SpecContext) {
+		createNamespace(ctx, "policy-test", nil)
+		Expect(env.createManagedClusterSetBinding(ctx, "policy-test")).To(Succeed())
+
+		Expect(env.SetupUserNamespace(ctx, UserNamespaceOptions{ManagedClusterSetBinding: true})).To(Succeed())
+		Expect(env.UserNamespace).To(Equal("policy-test"))
+		Expect(env.TeardownUserNamespace(ctx)).To(Succeed())
+
+		_, err := getNamespace(ctx, "policy-test")
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = env.ClientHubDynamic.Resource(GvrManagedClusterSetBinding).Namespace("policy-test").Get(
+			ctx, "global", metav1.GetOptions{},
+		)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Keeps a created user namespace which isn't isolated when requested", func(ctx SpecContext) {
+		Expect(env.SetupUserNamespace(ctx, UserNamespaceOptions{KeepNamespace: true})).To(Succeed())
+		Expect(env.TeardownUserNamespace(ctx)).To(Succeed())
+
+		namespace, err := getNamespace(ctx, "policy-test")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(namespace.GetLabels()).To(HaveKeyWithValue(TestNamespaceLabel, "policy-test"))
+	})
+})
+
+var _ = Describe("Test the namespace per container", Ordered, func() {
+	var env *Environment
+	var namespace string
+
+	BeforeAll(func(ctx SpecContext) {
+		env = startFakeBackend().Env
+
+		var err error
+
+		namespace, err = env.CreateIsolatedNamespace(ctx, UserNamespaceOptions{})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Creates a namespace named after the container", func(ctx SpecContext) {
+		Expect(namespace).To(Equal("policy-test-" + SpecSuffix()))
+
+		_, err := env.ClientHubDynamic.Resource(GvrNS).Get(ctx, namespace, metav1.GetOptions{})
+		Expect(err).ToNot(HaveOccurred())
+	})
+})
+
+var _ = Describe("Test describeNamespace", func() {
+	It("Lists the true conditions of a terminating namespace", func() {
+		namespace := &unstructured.Unstructured{Object: map[string]any{
+			"kind": "Namespace",
+			"status": map[string]any{
+				"phase": "Terminating",
+				"conditions": []any{
+					map[string]any{"type": "NamespaceDeletionDiscoveryFailure", "status": "False"},
+					map[string]any{
+						"type":    "NamespaceContentRemaining",
+						"status":  "True",
+						"message": "Some resources are remaining: policies.policy.open-cluster-management.io has 1",
+					},
+				},
+			},
+		}}
+
+		Expect(describeState(namespace)).To(Equal(
+			`phase=Terminating NamespaceContentRemaining=` +
+				`"Some resources are remaining: policies.policy.open-cluster-management.io has 1"`,
+		))
+	})
+})
@@ -0,0 +1,453 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog"
+
+	"github.com/stolostron/governance-policy-framework/test/common/kubeclient"
+)
+
+// errUnsupportedCommand is returned when the arguments of an oc/kubectl command can't be handled
+// natively, in which case the command is run with the K8sClient binary instead.
+var errUnsupportedCommand = errors.New("the command is not supported by the native client")
+
+// nativeCommand is the parsed subset of an oc/kubectl command that can be handled natively.
+type nativeCommand struct {
+	verb           string
+	args           []string
+	files          []string
+	namespace      string
+	patch          string
+	patchType      string
+	container      string
+	ignoreNotFound bool
+	wait           bool
+}
+
+// parseNativeCommand parses the apply, delete, patch, and logs commands, with the flags used by the
+// tests. Any other command or flag returns errUnsupportedCommand.
+func parseNativeCommand(args []string) (*nativeCommand, error) {
+	cmd := &nativeCommand{wait: true}
+	positional := []string{}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			positional = append(positional, arg)
+
+			continue
+		}
+
+		flagName, value, hasValue := strings.Cut(arg, "=")
+
+		switch flagName {
+		case "--ignore-not-found", "--wait":
+			enabled := !hasValue || value == "true"
+			if hasValue && value != "true" && value != "false" {
+				return nil, errUnsupportedCommand
+			}
+
+			if flagName == "--wait" {
+				cmd.wait = enabled
+			} else {
+				cmd.ignoreNotFound = enabled
+			}
+
+			continue
+		case "-f", "--filename", "-n", "--namespace", "-p", "--patch", "--type", "-c", "--container":
+		default:
+			return nil, errUnsupportedCommand
+		}
+
+		if !hasValue {
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("flag needs an argument: %s", flagName)
+			}
+
+			i++
+			value = args[i]
+		}
+
+		switch flagName {
+		case "-f", "--filename":
+			cmd.files = append(cmd.files, value)
+		case "-n", "--namespace":
+			cmd.namespace = value
+		case "-p", "--patch":
+			cmd.patch = value
+		case "--type":
+			cmd.patchType = value
+		case "-c", "--container":
+			cmd.container = value
+		}
+	}
+
+	if len(positional) == 0 {
+		return nil, errUnsupportedCommand
+	}
+
+	cmd.verb = positional[0]
+	cmd.args = positional[1:]
+
+	var supported bool
+
+	switch cmd.verb {
+	case "apply":
+		supported = len(cmd.files) > 0 && len(cmd.args) == 0 && cmd.patch == "" && cmd.container == ""
+	case "delete":
+		supported = (len(cmd.files) > 0) != (len(cmd.args) > 0) && cmd.patch == "" && cmd.container == ""
+	case "patch":
+		supported = len(cmd.files) == 0 && len(cmd.args) > 0 && cmd.patch != "" && cmd.container == ""
+	case "logs":
+		supported = len(cmd.files) == 0 && len(cmd.args) == 1 && cmd.patch == ""
+	}
+
+	if !supported {
+		return nil, errUnsupportedCommand
+	}
+
+	return cmd, nil
+}
+
+// resourceNames splits the arguments of a delete or patch command, which are either in the format
+// of `type name [name...]` or `type/name [type/name...]`, into resource and name pairs.
+func resourceNames(args []string) ([][2]string, error) {
+	pairs := [][2]string{}
+
+	if len(args) == 0 {
+		return nil, errUnsupportedCommand
+	}
+
+	if !strings.Contains(args[0], "/") {
+		if len(args) < 2 {
+			return nil, errUnsupportedCommand
+		}
+
+		for _, name := range args[1:] {
+			if strings.Contains(name, "/") {
+				return nil, errors.New("there is no need to specify a resource type as a separate argument " +
+					"when passing arguments in resource/name form")
+			}
+
+			pairs = append(pairs, [2]string{args[0], name})
+		}
+
+		return pairs, nil
+	}
+
+	for _, arg := range args {
+		resource, name, found := strings.Cut(arg, "/")
+		if !found || resource == "" || name == "" {
+			return nil, fmt.Errorf("arguments in resource/name form must have a single resource and name: %s", arg)
+		}
+
+		pairs = append(pairs, [2]string{resource, name})
+	}
+
+	return pairs, nil
+}
+
+// nativeClient returns the cached Applier for the given dynamic client, along with the typed
+// client, or nil if NativeK8sClient is disabled or the clients of the Environment have not been
+// initialized.
+func (e *Environment) nativeClient(
+	client kubernetes.Interface, dynamicClient dynamic.Interface,
+) (*kubeclient.Applier, kubernetes.Interface) {
+	if !e.NativeK8sClient || client == nil || dynamicClient == nil {
+		return nil, nil
+	}
+
+	return e.applierFor(client, dynamicClient), client
+}
+
+// applierFor returns the cached Applier for the given dynamic client, creating it with the
+// discovery client of the typed client on first use.
+func (e *Environment) applierFor(client kubernetes.Interface, dynamicClient dynamic.Interface) *kubeclient.Applier {
+	e.appliersLock.Lock()
+	defer e.appliersLock.Unlock()
+
+	if e.appliers == nil {
+		e.appliers = map[dynamic.Interface]*kubeclient.Applier{}
+	}
+
+	applier, ok := e.appliers[dynamicClient]
+	if !ok {
+		applier = kubeclient.NewApplier(dynamicClient, client.Discovery())
+		applier.OwnershipLabels = e.OwnershipLabels
+		e.appliers[dynamicClient] = applier
+	}
+
+	return applier
+}
+
+// targetApplier returns the Applier of the cluster, and the namespace of the objects without one,
+// which defaults to the user namespace on the hub.
+func (e *Environment) targetApplier(cluster ClusterTarget, namespace string) (*kubeclient.Applier, string) {
+	client, dynamicClient := e.targetClients(cluster)
+
+	if namespace == "" && targetOrDefault(cluster, TargetHub) == TargetHub {
+		namespace = e.UserNamespace
+	}
+
+	return e.applierFor(client, dynamicClient), namespace
+}
+
+// runCommand runs the oc/kubectl command against the cluster of the given kubeconfig and context. The
+// apply, delete, patch, and logs commands are handled natively with client-go when possible, and any
+// other command is run with the K8sClient binary.
+func (e *Environment) runCommand(
+	kubeconfig, kubeContext string, client kubernetes.Interface, dynamicClient dynamic.Interface, args ...string,
+) (string, error) {
+	cliArgs := append(kubeconfigArgs(kubeconfig, kubeContext), args...)
+
+	applier, client := e.nativeClient(client, dynamicClient)
+	if applier != nil {
+		start := time.Now()
+
+		output, err := runNativeCommand(context.TODO(), applier, client, e.DefaultTimeoutSeconds, args)
+		if !errors.Is(err, errUnsupportedCommand) {
+			transcript := newCommandTranscript(append([]string{e.K8sClient}, cliArgs...), start, output, "", err)
+			transcript.Native = true
+			e.recordCommand(transcript)
+
+			// Don't log the output of the commands which may output secrets
+			if !hasSensitiveOutput(args) {
+				klog.V(2).Infof("Native command output %s\n", output)
+			}
+
+			if err != nil {
+				err = fmt.Errorf(
+					"error running command '%s':\n %s: %w", strings.Join(maskArgs(args), " "), output, err,
+				)
+			}
+
+			return output, err
+		}
+	}
+
+	return e.oc(cliArgs...)
+}
+
+// kubeconfigArgs returns the oc/kubectl flags selecting the kubeconfig and its context, if it's set.
+func kubeconfigArgs(kubeconfig, kubeContext string) []string {
+	if kubeContext == "" {
+		return []string{"--kubeconfig=" + kubeconfig}
+	}
+
+	return []string{"--kubeconfig=" + kubeconfig, "--context=" + kubeContext}
+}
+
+// runNativeCommand runs the oc/kubectl command with the Applier and typed client. If the command
+// can't be handled natively, errUnsupportedCommand is returned.
+func runNativeCommand(
+	ctx context.Context, applier *kubeclient.Applier, client kubernetes.Interface, timeoutSeconds int, args []string,
+) (string, error) {
+	cmd, err := parseNativeCommand(args)
+	if err != nil {
+		return "", err
+	}
+
+	var results []kubeclient.ApplyResult
+
+	switch cmd.verb {
+	case "apply":
+		for _, file := range cmd.files {
+			fileResults, err := applier.ApplyFile(ctx, file, cmd.namespace)
+			results = append(results, fileResults...)
+
+			if err != nil {
+				return formatResults(results), err
+			}
+		}
+	case "delete":
+		err = runNativeDelete(ctx, applier, cmd, &results)
+		if err == nil && cmd.wait {
+			err = applier.WaitForDeletion(ctx, time.Duration(timeoutSeconds)*time.Second, results...)
+		}
+
+		if err != nil {
+			return formatResults(results), err
+		}
+	case "patch":
+		pairs, err := resourceNames(cmd.args)
+		if err != nil {
+			return "", err
+		}
+
+		patchType, err := nativePatchType(cmd.patchType)
+		if err != nil {
+			return "", err
+		}
+
+		// Like kubectl, accept the patch in either JSON or YAML
+		patch, err := k8syaml.ToJSON([]byte(cmd.patch))
+		if err != nil {
+			return "", fmt.Errorf("unable to parse the patch: %w", err)
+		}
+
+		for _, pair := range pairs {
+			result, err := applier.Patch(ctx, pair[0], cmd.namespace, pair[1], patchType, patch)
+			if err != nil {
+				return formatResults(results), err
+			}
+
+			results = append(results, result)
+		}
+	case "logs":
+		return podLogs(ctx, client, cmd.namespace, cmd.args[0], cmd.container)
+	}
+
+	return formatResults(results), nil
+}
+
+func runNativeDelete(
+	ctx context.Context, applier *kubeclient.Applier, cmd *nativeCommand, results *[]kubeclient.ApplyResult,
+) error {
+	for _, file := range cmd.files {
+		fileResults, err := applier.DeleteFile(ctx, file, cmd.namespace, cmd.ignoreNotFound)
+		*results = append(*results, fileResults...)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(cmd.files) != 0 {
+		return nil
+	}
+
+	pairs, err := resourceNames(cmd.args)
+	if err != nil {
+		return err
+	}
+
+	for _, pair := range pairs {
+		result, err := applier.Delete(ctx, pair[0], cmd.namespace, pair[1], cmd.ignoreNotFound)
+		if err != nil {
+			return err
+		}
+
+		*results = append(*results, result)
+	}
+
+	return nil
+}
+
+func nativePatchType(patchType string) (types.PatchType, error) {
+	switch patchType {
+	case "", "strategic":
+		return types.StrategicMergePatchType, nil
+	case "merge":
+		return types.MergePatchType, nil
+	case "json":
+		return types.JSONPatchType, nil
+	default:
+		return "", fmt.Errorf("unsupported patch type %s", patchType)
+	}
+}
+
+// formatResults formats the results like the output of kubectl. Objects which were not found
+// while deleting with --ignore-not-found are left out, as they are by kubectl.
+func formatResults(results []kubeclient.ApplyResult) string {
+	var output strings.Builder
+
+	for _, result := range results {
+		if result.Operation == kubeclient.OperationNotFound {
+			continue
+		}
+
+		output.WriteString(result.String() + "\n")
+	}
+
+	return output.String()
+}
+
+// podLogs returns the logs of a container in a pod, where the target is either a pod name, or is
+// in the format of `pod/name` or `deployment/name`. When the container is not specified, the
+// default container of the pod is used.
+func podLogs(ctx context.Context, client kubernetes.Interface, namespace, target, container string) (string, error) {
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+
+	resource, name, found := strings.Cut(target, "/")
+	if !found {
+		resource, name = "pod", target
+	}
+
+	var pod *corev1.Pod
+	var err error
+
+	switch resource {
+	case "pod", "pods", "po":
+		pod, err = client.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	case "deployment", "deployments", "deploy":
+		pod, err = deploymentPod(ctx, client, namespace, name)
+	default:
+		return "", errUnsupportedCommand
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	if container == "" {
+		container = pod.Annotations["kubectl.kubernetes.io/default-container"]
+	}
+
+	if container == "" && len(pod.Spec.Containers) > 0 {
+		container = pod.Spec.Containers[0].Name
+	}
+
+	logs, err := client.CoreV1().Pods(namespace).GetLogs(pod.Name, &corev1.PodLogOptions{Container: container}).
+		DoRaw(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get the logs of the container %s in pod %s: %w", container, pod.Name, err)
+	}
+
+	return string(logs), nil
+}
+
+// deploymentPod returns a running pod of the deployment, or any of its pods if none are running.
+func deploymentPod(ctx context.Context, client kubernetes.Interface, namespace, name string) (*corev1.Pod, error) {
+	deployment, err := client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pods.Items) == 0 {
+		return nil, fmt.Errorf("no pods found for deployment %s/%s", namespace, name)
+	}
+
+	for i := range pods.Items {
+		if pods.Items[i].Status.Phase == corev1.PodRunning {
+			return &pods.Items[i], nil
+		}
+	}
+
+	return &pods.Items[0], nil
+}
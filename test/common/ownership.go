@@ -0,0 +1,217 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package common
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/ginkgo/v2/types"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/stolostron/governance-policy-framework/test/common/kubeclient"
+)
+
+// The ownership labels set on the objects created by the helpers of this package, so that the
+// objects of a run can be queried and cleaned up with CleanupByRun.
+const (
+	// RunIDLabel is the RunID of the Environment which created the object.
+	RunIDLabel = "policy-test.open-cluster-management.io/run-id"
+	// SpecLabel is the full text of the spec which created the object, shortened to fit in a label.
+	SpecLabel = "policy-test.open-cluster-management.io/spec"
+)
+
+// maxLabelValueLength is the maximum length of a label value enforced by the API server.
+const maxLabelValueLength = 63
+
+var invalidLabelValueChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// NewRunID returns a new run ID made of the current time and random characters, like
+// `20260102-150405-a1b2c3`.
+func NewRunID() string {
+	random := make([]byte, 3)
+	_, _ = rand.Read(random)
+
+	return time.Now().UTC().Format("20060102-150405") + "-" + hex.EncodeToString(random)
+}
+
+// OwnershipLabels returns the labels identifying the run and the current spec, which are set on the
+// objects created by the helpers. It returns nil when the Environment has no RunID, in which case
+// the objects aren't labeled.
+func (e *Environment) OwnershipLabels() map[string]string {
+	if e.RunID == "" {
+		return nil
+	}
+
+	return map[string]string{
+		RunIDLabel: e.RunID,
+		SpecLabel:  specLabelValue(CurrentSpecReport()),
+	}
+}
+
+// specLabelValue returns the full text of the spec as a label value, or the type of the node when
+// outside of a spec, like `BeforeSuite`. Characters which aren't allowed are replaced with dashes,
+// and a text which is too long is truncated with a hash of the full text appended.
+func specLabelValue(report types.SpecReport) string {
+	text := report.FullText()
+	if text == "" {
+		text = report.LeafNodeType.String()
+	}
+
+	value := strings.Trim(invalidLabelValueChars.ReplaceAllString(text, "-"), "-_.")
+	if len(value) <= maxLabelValueLength {
+		return value
+	}
+
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(text))
+
+	return fmt.Sprintf("%s-%08x", strings.TrimRight(value[:maxLabelValueLength-9], "-_."), hash.Sum32())
+}
+
+// setOwnershipLabels adds the OwnershipLabels to the labels of the object.
+func (e *Environment) setOwnershipLabels(obj metav1.Object) {
+	kubeclient.AddLabels(obj, e.OwnershipLabels())
+}
+
+// CleanupByRun deletes the objects labeled with the run ID from the hub, managed, and hosting
+// clusters, across all of the resources they serve, and returns the deleted objects. Namespaced
+// objects are deleted before cluster scoped ones like namespaces. It doesn't wait for the objects
+// to be removed.
+func (e *Environment) CleanupByRun(ctx context.Context, runID string) ([]kubeclient.ApplyResult, error) {
+	if runID == "" {
+		return nil, errors.New("a run ID is required")
+	}
+
+	selector := metav1.ListOptions{LabelSelector: RunIDLabel + "=" + runID}
+	results := []kubeclient.ApplyResult{}
+	done := map[dynamic.Interface]bool{}
+
+	var errs []error
+
+	for _, cluster := range []ClusterTarget{TargetHub, TargetManaged, TargetHosting} {
+		client, dynamicClient := e.targetClients(cluster)
+		if client == nil || done[dynamicClient] {
+			continue
+		}
+
+		done[dynamicClient] = true
+
+		resources, err := deletableResources(client.Discovery())
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to discover the resources of the %s cluster: %w", cluster, err))
+
+			continue
+		}
+
+		for _, resource := range resources {
+			list, err := dynamicClient.Resource(resource.gvr).List(ctx, selector)
+			if err != nil {
+				if !k8serrors.IsNotFound(err) && !k8serrors.IsMethodNotSupported(err) {
+					errs = append(errs, fmt.Errorf(
+						"failed to list %s on the %s cluster: %w", resource.gvr.GroupResource(), cluster, err,
+					))
+				}
+
+				continue
+			}
+
+			for _, item := range list.Items {
+				err := dynamicClient.Resource(resource.gvr).Namespace(item.GetNamespace()).Delete(
+					ctx, item.GetName(), metav1.DeleteOptions{},
+				)
+				if k8serrors.IsNotFound(err) {
+					continue
+				}
+
+				if err != nil {
+					errs = append(errs, fmt.Errorf(
+						"failed to delete %s %s on the %s cluster: %w",
+						resource.gvr.GroupResource(), namespacedName(item.GetNamespace(), item.GetName()), cluster, err,
+					))
+
+					continue
+				}
+
+				result := kubeclient.ApplyResult{
+					GVR:       resource.gvr,
+					Kind:      resource.kind,
+					Namespace: item.GetNamespace(),
+					Name:      item.GetName(),
+					Operation: kubeclient.OperationDeleted,
+				}
+
+				GinkgoWriter.Printf("%s on the %s cluster\n", result, cluster)
+
+				results = append(results, result)
+			}
+		}
+	}
+
+	return results, errors.Join(errs...)
+}
+
+// discoveredResource is a resource served by a cluster.
+type discoveredResource struct {
+	gvr        schema.GroupVersionResource
+	kind       string
+	namespaced bool
+}
+
+// deletableResources returns the preferred version of every resource which can be listed and
+// deleted, with the namespaced resources first. Groups which fail discovery, like those of an
+// unavailable aggregated API, are skipped.
+func deletableResources(client discovery.DiscoveryInterface) ([]discoveredResource, error) {
+	lists, err := discovery.ServerPreferredResources(client)
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, err
+	}
+
+	resources := []discoveredResource{}
+
+	for _, list := range lists {
+		groupVersion, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+
+		for _, resource := range list.APIResources {
+			if strings.Contains(resource.Name, "/") ||
+				!slices.Contains(resource.Verbs, "list") || !slices.Contains(resource.Verbs, "delete") {
+				continue
+			}
+
+			resources = append(resources, discoveredResource{
+				gvr:        groupVersion.WithResource(resource.Name),
+				kind:       resource.Kind,
+				namespaced: resource.Namespaced,
+			})
+		}
+	}
+
+	slices.SortStableFunc(resources, func(a, b discoveredResource) int {
+		switch {
+		case a.namespaced == b.namespaced:
+			return 0
+		case a.namespaced:
+			return -1
+		default:
+			return 1
+		}
+	})
+
+	return resources, nil
+}
@@ -0,0 +1,148 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package common
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/ginkgo/v2/types"
+	. "github.com/onsi/gomega"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var _ = Describe("Test the ownership labels", func() {
+	var env *Environment
+
+	BeforeEach(func() {
+		env = startFakeBackend().Env
+		env.RunID = "20260102-150405-a1b2c3"
+	})
+
+	configMap := func(name string, labels map[string]any) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]any{"name": name, "labels": labels},
+		}}
+	}
+
+	It("Labels the objects created by the helpers with the run and spec", func(ctx SpecContext) {
+		Expect(env.OwnershipLabels()).To(Equal(map[string]string{
+			RunIDLabel: "20260102-150405-a1b2c3",
+			SpecLabel:  "Test-the-ownership-labels-Labels-the-objects-created-b-604e0ecb",
+		}))
+
+		Expect(env.ApplyPlacement(ctx, env.UserNamespace, "my-policy")).To(Succeed())
+
+		placement, err := env.ClientHubDynamic.Resource(GvrPlacement).Namespace(env.UserNamespace).Get(
+			ctx, "placement-my-policy", metav1.GetOptions{},
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(placement.GetLabels()).To(Equal(env.OwnershipLabels()))
+
+		binding, err := env.ClientHubDynamic.Resource(GvrPlacementBinding).Namespace(env.UserNamespace).Get(
+			ctx, "placement-binding-my-policy", metav1.GetOptions{},
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(binding.GetLabels()).To(Equal(env.OwnershipLabels()))
+	})
+
+	It("Labels the objects the Applier creates but not those which existed", func(ctx SpecContext) {
+		_, err := env.ClientHubDynamic.Resource(GvrConfigMap).Namespace(env.UserNamespace).Create(
+			ctx, configMap("existing", nil), metav1.CreateOptions{},
+		)
+		Expect(err).ToNot(HaveOccurred())
+
+		applier, namespace := env.targetApplier(TargetHub, "")
+
+		results, err := applier.Apply(ctx, namespace,
+			configMap("existing", nil), configMap("created", map[string]any{"app": "test"}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results).To(HaveLen(2))
+		Expect(results[0].Object.GetLabels()).To(BeEmpty())
+		Expect(results[1].Object.GetLabels()).To(Equal(map[string]string{
+			"app":      "test",
+			RunIDLabel: env.RunID,
+			SpecLabel:  env.OwnershipLabels()[SpecLabel],
+		}))
+	})
+
+	It("Doesn't label objects without a run ID", func(ctx SpecContext) {
+		env.RunID = ""
+		Expect(env.OwnershipLabels()).To(BeNil())
+
+		applier, namespace := env.targetApplier(TargetHub, "")
+
+		results, err := applier.Apply(ctx, namespace, configMap("created", nil))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results[0].Object.GetLabels()).To(BeEmpty())
+	})
+
+	It("Cleans up the objects of the run", func(ctx SpecContext) {
+		applier, namespace := env.targetApplier(TargetHub, "")
+
+		_, err := applier.Apply(ctx, namespace, configMap("created", nil))
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = env.ClientHubDynamic.Resource(GvrConfigMap).Namespace(env.UserNamespace).Create(
+			ctx, configMap("other-run", map[string]any{RunIDLabel: "other"}), metav1.CreateOptions{},
+		)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(env.SetupUserNamespace(ctx, UserNamespaceOptions{})).To(Succeed())
+
+		managedApplier, _ := env.targetApplier(TargetManaged, "")
+
+		_, err = managedApplier.Apply(ctx, "default", configMap("managed", nil))
+		Expect(err).ToNot(HaveOccurred())
+
+		results, err := env.CleanupByRun(ctx, env.RunID)
+		Expect(err).ToNot(HaveOccurred())
+
+		deleted := make([]string, 0, len(results))
+		for _, result := range results {
+			deleted = append(deleted, result.String())
+		}
+
+		Expect(strings.Join(deleted, "\n")).To(Equal(strings.Join([]string{
+			"configmap/created deleted",
+			"namespace/policy-test deleted",
+			"configmap/managed deleted",
+		}, "\n")))
+
+		_, err = env.ClientHubDynamic.Resource(GvrConfigMap).Namespace(env.UserNamespace).Get(
+			ctx, "other-run", metav1.GetOptions{},
+		)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = env.ClientHubDynamic.Resource(GvrNS).Get(ctx, env.UserNamespace, metav1.GetOptions{})
+		Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+	})
+})
+
+var _ = Describe("Test specLabelValue", func() {
+	DescribeTable("Derives a valid label value from the spec",
+		func(report types.SpecReport, expected string) {
+			Expect(specLabelValue(report)).To(Equal(expected))
+		},
+		Entry("with a short text",
+			types.SpecReport{ContainerHierarchyTexts: []string{"Test policies"}, LeafNodeText: "is compliant"},
+			"Test-policies-is-compliant",
+		),
+		Entry("with a suite node", types.SpecReport{LeafNodeType: types.NodeTypeBeforeSuite}, "BeforeSuite"),
+		Entry("with characters which aren't allowed at the ends",
+			types.SpecReport{LeafNodeText: "[hub] creates a policy (again)"}, "hub-creates-a-policy-again",
+		),
+		Entry("with a long text",
+			types.SpecReport{
+				ContainerHierarchyTexts: []string{"GRC: [P1][Sev1][policy-grc] Test the policy templates"},
+				LeafNodeText:            "should be created on the managed cluster",
+			},
+			"GRC-P1-Sev1-policy-grc-Test-the-policy-templates-shoul-a8f57a3f",
+		),
+	)
+})
@@ -0,0 +1,387 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package common
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	policiesv1 "open-cluster-management.io/governance-policy-propagator/api/v1"
+)
+
+// The types in this file are typed views of the policy template kinds, covering the fields that the
+// tests inspect. Unknown fields are dropped when converting from unstructured.
+
+// Condition is a status condition of a policy template.
+type Condition struct {
+	Type               string      `json:"type"`
+	Status             string      `json:"status"`
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	Reason             string      `json:"reason,omitempty"`
+	Message            string      `json:"message,omitempty"`
+}
+
+// RelatedObject is an object related to a policy template in its status.
+type RelatedObject struct {
+	Object     ObjectResource    `json:"object"`
+	Compliant  string            `json:"compliant,omitempty"`
+	Reason     string            `json:"reason,omitempty"`
+	Properties *ObjectProperties `json:"properties,omitempty"`
+}
+
+// ObjectResource identifies a RelatedObject.
+type ObjectResource struct {
+	Kind       string         `json:"kind"`
+	APIVersion string         `json:"apiVersion"`
+	Metadata   ObjectMetadata `json:"metadata"`
+}
+
+// ObjectMetadata is the name and namespace of a RelatedObject.
+type ObjectMetadata struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// ObjectProperties are the additional properties of a RelatedObject.
+type ObjectProperties struct {
+	CreatedByPolicy *bool  `json:"createdByPolicy,omitempty"`
+	UID             string `json:"uid,omitempty"`
+	Diff            string `json:"diff,omitempty"`
+}
+
+// ObjectTemplate is an entry of the object-templates of a ConfigurationPolicy.
+type ObjectTemplate struct {
+	ComplianceType         string         `json:"complianceType"`
+	MetadataComplianceType string         `json:"metadataComplianceType,omitempty"`
+	RecreateOption         string         `json:"recreateOption,omitempty"`
+	RecordDiff             string         `json:"recordDiff,omitempty"`
+	ObjectDefinition       map[string]any `json:"objectDefinition"`
+}
+
+// Object returns the objectDefinition of the object template.
+func (t *ObjectTemplate) Object() *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: t.ObjectDefinition}
+}
+
+// ConfigurationPolicySpec is the spec of a ConfigurationPolicy.
+type ConfigurationPolicySpec struct {
+	RemediationAction   string           `json:"remediationAction,omitempty"`
+	Severity            string           `json:"severity,omitempty"`
+	PruneObjectBehavior string           `json:"pruneObjectBehavior,omitempty"`
+	EvaluationInterval  map[string]any   `json:"evaluationInterval,omitempty"`
+	NamespaceSelector   map[string]any   `json:"namespaceSelector,omitempty"`
+	ObjectTemplates     []ObjectTemplate `json:"object-templates,omitempty"`
+	ObjectTemplatesRaw  string           `json:"object-templates-raw,omitempty"`
+}
+
+// TemplateStatus is an entry of the compliancyDetails of a ConfigurationPolicy.
+type TemplateStatus struct {
+	ComplianceState string      `json:"Compliant,omitempty"`
+	Conditions      []Condition `json:"conditions,omitempty"`
+}
+
+// ConfigurationPolicyStatus is the status of a ConfigurationPolicy.
+type ConfigurationPolicyStatus struct {
+	ComplianceState   policiesv1.ComplianceState `json:"compliant,omitempty"`
+	CompliancyDetails []TemplateStatus           `json:"compliancyDetails,omitempty"`
+	RelatedObjects    []RelatedObject            `json:"relatedObjects,omitempty"`
+}
+
+// ConfigurationPolicy is a typed view of a ConfigurationPolicy.
+type ConfigurationPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              ConfigurationPolicySpec   `json:"spec,omitempty"`
+	Status            ConfigurationPolicyStatus `json:"status,omitempty"`
+}
+
+// OperatorPolicySpec is the spec of an OperatorPolicy.
+type OperatorPolicySpec struct {
+	RemediationAction string         `json:"remediationAction,omitempty"`
+	Severity          string         `json:"severity,omitempty"`
+	ComplianceType    string         `json:"complianceType,omitempty"`
+	OperatorGroup     map[string]any `json:"operatorGroup,omitempty"`
+	Subscription      map[string]any `json:"subscription,omitempty"`
+	UpgradeApproval   string         `json:"upgradeApproval,omitempty"`
+	Versions          []string       `json:"versions,omitempty"`
+	RemovalBehavior   map[string]any `json:"removalBehavior,omitempty"`
+}
+
+// OperatorPolicyStatus is the status of an OperatorPolicy.
+type OperatorPolicyStatus struct {
+	ComplianceState policiesv1.ComplianceState `json:"compliant,omitempty"`
+	Conditions      []Condition                `json:"conditions,omitempty"`
+	RelatedObjects  []RelatedObject            `json:"relatedObjects,omitempty"`
+}
+
+// OperatorPolicy is a typed view of an OperatorPolicy.
+type OperatorPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              OperatorPolicySpec   `json:"spec,omitempty"`
+	Status            OperatorPolicyStatus `json:"status,omitempty"`
+}
+
+// CertificatePolicySpec is the spec of a CertificatePolicy.
+type CertificatePolicySpec struct {
+	RemediationAction    string         `json:"remediationAction,omitempty"`
+	Severity             string         `json:"severity,omitempty"`
+	NamespaceSelector    map[string]any `json:"namespaceSelector,omitempty"`
+	MinDuration          string         `json:"minimumDuration,omitempty"`
+	MinCADuration        string         `json:"minimumCADuration,omitempty"`
+	MaxDuration          string         `json:"maximumDuration,omitempty"`
+	MaxCADuration        string         `json:"maximumCADuration,omitempty"`
+	AllowedSANPattern    string         `json:"allowedSANPattern,omitempty"`
+	DisallowedSANPattern string         `json:"disallowedSANPattern,omitempty"`
+}
+
+// CertificateNamespaceStatus is the compliance of the certificates in a namespace.
+type CertificateNamespaceStatus struct {
+	NonCompliantCertificates     int            `json:"nonCompliantCertificates,omitempty"`
+	NonCompliantCertificatesList map[string]any `json:"nonCompliantCertificatesList,omitempty"`
+	Message                      string         `json:"message,omitempty"`
+}
+
+// CertificatePolicyStatus is the status of a CertificatePolicy.
+type CertificatePolicyStatus struct {
+	ComplianceState   policiesv1.ComplianceState            `json:"compliant,omitempty"`
+	CompliancyDetails map[string]CertificateNamespaceStatus `json:"compliancyDetails,omitempty"`
+}
+
+// CertificatePolicy is a typed view of a CertificatePolicy.
+type CertificatePolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              CertificatePolicySpec   `json:"spec,omitempty"`
+	Status            CertificatePolicyStatus `json:"status,omitempty"`
+}
+
+// FieldError is returned by the typed accessors when a field of a policy is missing or invalid. The
+// path is in the format of `status.details[0].history[0]`.
+type FieldError struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Path      string
+	Reason    string
+}
+
+func (e *FieldError) Error() string {
+	name := e.Name
+	if e.Namespace != "" {
+		name = e.Namespace + "/" + e.Name
+	}
+
+	return fmt.Sprintf("%s %s: %s %s", e.Kind, name, e.Path, e.Reason)
+}
+
+func fieldError(obj metav1.Object, kind, path, reason string, args ...any) *FieldError {
+	return &FieldError{
+		Kind:      kind,
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+		Path:      path,
+		Reason:    fmt.Sprintf(reason, args...),
+	}
+}
+
+// fromUnstructured converts the unstructured object to the typed view, after checking its kind.
+func fromUnstructured[T any](obj *unstructured.Unstructured, kind string) (*T, error) {
+	if obj == nil {
+		return nil, fmt.Errorf("the %s is nil", kind)
+	}
+
+	if obj.GetKind() != kind {
+		return nil, fieldError(obj, kind, "kind", "is %s, not %s", obj.GetKind(), kind)
+	}
+
+	typed := new(T)
+
+	err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, typed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert the %s %s/%s: %w", kind, obj.GetNamespace(), obj.GetName(), err)
+	}
+
+	return typed, nil
+}
+
+// PolicyFromUnstructured converts the unstructured Policy to the propagator's Policy type.
+func PolicyFromUnstructured(obj *unstructured.Unstructured) (*policiesv1.Policy, error) {
+	return fromUnstructured[policiesv1.Policy](obj, "Policy")
+}
+
+// ConfigurationPolicyFromUnstructured converts the unstructured ConfigurationPolicy to its typed view.
+func ConfigurationPolicyFromUnstructured(obj *unstructured.Unstructured) (*ConfigurationPolicy, error) {
+	return fromUnstructured[ConfigurationPolicy](obj, "ConfigurationPolicy")
+}
+
+// OperatorPolicyFromUnstructured converts the unstructured OperatorPolicy to its typed view.
+func OperatorPolicyFromUnstructured(obj *unstructured.Unstructured) (*OperatorPolicy, error) {
+	return fromUnstructured[OperatorPolicy](obj, "OperatorPolicy")
+}
+
+// CertificatePolicyFromUnstructured converts the unstructured CertificatePolicy to its typed view.
+func CertificatePolicyFromUnstructured(obj *unstructured.Unstructured) (*CertificatePolicy, error) {
+	return fromUnstructured[CertificatePolicy](obj, "CertificatePolicy")
+}
+
+// TemplateDetails returns the status details of the policy template at the given index.
+func TemplateDetails(policy *policiesv1.Policy, templateIdx int) (*policiesv1.DetailsPerTemplate, error) {
+	path := fmt.Sprintf("status.details[%d]", templateIdx)
+
+	if templateIdx < 0 || templateIdx >= len(policy.Status.Details) {
+		return nil, fieldError(policy, "Policy", path, "not found, the policy has %d template details",
+			len(policy.Status.Details))
+	}
+
+	if policy.Status.Details[templateIdx] == nil {
+		return nil, fieldError(policy, "Policy", path, "is null")
+	}
+
+	return policy.Status.Details[templateIdx], nil
+}
+
+// TemplateHistory returns the compliance history of the policy template at the given index, with
+// the most recent event first. The history is empty if no events have been recorded yet.
+func TemplateHistory(policy *policiesv1.Policy, templateIdx int) ([]policiesv1.ComplianceHistory, error) {
+	details, err := TemplateDetails(policy, templateIdx)
+	if err != nil {
+		return nil, err
+	}
+
+	return details.History, nil
+}
+
+// LatestHistoryMessage returns the most recent compliance message of the policy template at the
+// given index.
+func LatestHistoryMessage(policy *policiesv1.Policy, templateIdx int) (string, error) {
+	history, err := TemplateHistory(policy, templateIdx)
+	if err != nil {
+		return "", err
+	}
+
+	if len(history) == 0 {
+		return "", fieldError(policy, "Policy", fmt.Sprintf("status.details[%d].history[0]", templateIdx),
+			"not found, no compliance events have been recorded")
+	}
+
+	return history[0].Message, nil
+}
+
+// ClusterComplianceState returns the compliance of the root policy on the given managed cluster.
+func ClusterComplianceState(policy *policiesv1.Policy, clusterName string) (policiesv1.ComplianceState, error) {
+	for _, clusterStatus := range policy.Status.Status {
+		if clusterStatus != nil && clusterStatus.ClusterNamespace == clusterName {
+			return clusterStatus.ComplianceState, nil
+		}
+	}
+
+	return "", fieldError(policy, "Policy", "status.status[clusternamespace="+clusterName+"]", "not found")
+}
+
+// PolicyTemplate returns the objectDefinition of the policy template at the given index.
+func PolicyTemplate(policy *policiesv1.Policy, templateIdx int) (*unstructured.Unstructured, error) {
+	path := fmt.Sprintf("spec.policy-templates[%d]", templateIdx)
+
+	if templateIdx < 0 || templateIdx >= len(policy.Spec.PolicyTemplates) {
+		return nil, fieldError(policy, "Policy", path, "not found, the policy has %d policy templates",
+			len(policy.Spec.PolicyTemplates))
+	}
+
+	if policy.Spec.PolicyTemplates[templateIdx] == nil {
+		return nil, fieldError(policy, "Policy", path, "is null")
+	}
+
+	template := &unstructured.Unstructured{}
+
+	err := template.UnmarshalJSON(policy.Spec.PolicyTemplates[templateIdx].ObjectDefinition.Raw)
+	if err != nil {
+		return nil, fieldError(policy, "Policy", path+".objectDefinition", "is not a valid object: %v", err)
+	}
+
+	return template, nil
+}
+
+// PolicyTemplateConfigurationPolicy returns the ConfigurationPolicy of the policy template at the
+// given index.
+func PolicyTemplateConfigurationPolicy(policy *policiesv1.Policy, templateIdx int) (*ConfigurationPolicy, error) {
+	template, err := PolicyTemplate(policy, templateIdx)
+	if err != nil {
+		return nil, err
+	}
+
+	if template.GetKind() != "ConfigurationPolicy" {
+		return nil, fieldError(policy, "Policy", fmt.Sprintf("spec.policy-templates[%d].objectDefinition.kind",
+			templateIdx), "is %s, not ConfigurationPolicy", template.GetKind())
+	}
+
+	return ConfigurationPolicyFromUnstructured(template)
+}
+
+// ObjectTemplate returns the object template at the given index.
+func (p *ConfigurationPolicy) ObjectTemplate(idx int) (*ObjectTemplate, error) {
+	if idx < 0 || idx >= len(p.Spec.ObjectTemplates) {
+		return nil, fieldError(p, "ConfigurationPolicy", fmt.Sprintf("spec.object-templates[%d]", idx),
+			"not found, the policy has %d object templates", len(p.Spec.ObjectTemplates))
+	}
+
+	return &p.Spec.ObjectTemplates[idx], nil
+}
+
+// ConditionMessages returns the condition messages of the compliancyDetails entry at the given index.
+func (p *ConfigurationPolicy) ConditionMessages(idx int) ([]string, error) {
+	if idx < 0 || idx >= len(p.Status.CompliancyDetails) {
+		return nil, fieldError(p, "ConfigurationPolicy", fmt.Sprintf("status.compliancyDetails[%d]", idx),
+			"not found, the policy has %d compliancy details", len(p.Status.CompliancyDetails))
+	}
+
+	messages := make([]string, 0, len(p.Status.CompliancyDetails[idx].Conditions))
+	for _, condition := range p.Status.CompliancyDetails[idx].Conditions {
+		messages = append(messages, condition.Message)
+	}
+
+	return messages, nil
+}
+
+// RelatedObject returns the related object at the given index.
+func (p *ConfigurationPolicy) RelatedObject(idx int) (*RelatedObject, error) {
+	if idx < 0 || idx >= len(p.Status.RelatedObjects) {
+		return nil, fieldError(p, "ConfigurationPolicy", fmt.Sprintf("status.relatedObjects[%d]", idx),
+			"not found, the policy has %d related objects", len(p.Status.RelatedObjects))
+	}
+
+	return &p.Status.RelatedObjects[idx], nil
+}
+
+// Condition returns the status condition of the given type.
+func (p *OperatorPolicy) Condition(conditionType string) (*Condition, error) {
+	for i := range p.Status.Conditions {
+		if p.Status.Conditions[i].Type == conditionType {
+			return &p.Status.Conditions[i], nil
+		}
+	}
+
+	types := make([]string, 0, len(p.Status.Conditions))
+	for _, condition := range p.Status.Conditions {
+		types = append(types, condition.Type)
+	}
+
+	return nil, fieldError(p, "OperatorPolicy", "status.conditions[type="+conditionType+"]",
+		"not found, the condition types are [%s]", strings.Join(types, ", "))
+}
+
+// RelatedObjectsOfKind returns the related objects of the given kind.
+func (p *OperatorPolicy) RelatedObjectsOfKind(kind string) []RelatedObject {
+	relatedObjects := []RelatedObject{}
+
+	for _, relatedObject := range p.Status.RelatedObjects {
+		if relatedObject.Object.Kind == kind {
+			relatedObjects = append(relatedObjects, relatedObject)
+		}
+	}
+
+	return relatedObjects
+}
@@ -0,0 +1,137 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package common
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	policiesv1 "open-cluster-management.io/governance-policy-propagator/api/v1"
+)
+
+var _ = Describe("Test the typed policy accessors", func() {
+	policy := func() *policiesv1.Policy {
+		GinkgoHelper()
+
+		obj := &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "policy.open-cluster-management.io/v1",
+			"kind":       "Policy",
+			"metadata":   map[string]any{"name": "my-policy", "namespace": "policy-test"},
+			"spec": map[string]any{
+				"disabled": false,
+				"policy-templates": []any{
+					map[string]any{"objectDefinition": map[string]any{
+						"apiVersion": "policy.open-cluster-management.io/v1",
+						"kind":       "ConfigurationPolicy",
+						"metadata":   map[string]any{"name": "my-config-policy"},
+						"spec": map[string]any{
+							"severity": "low",
+							"object-templates": []any{map[string]any{
+								"complianceType":   "musthave",
+								"objectDefinition": map[string]any{"kind": "ConfigMap"},
+							}},
+						},
+					}},
+					map[string]any{"objectDefinition": map[string]any{
+						"apiVersion": "policy.open-cluster-management.io/v1beta1",
+						"kind":       "OperatorPolicy",
+						"metadata":   map[string]any{"name": "my-operator-policy"},
+					}},
+				},
+			},
+			"status": map[string]any{
+				"status": []any{map[string]any{"clusternamespace": "cluster1", "compliant": "Compliant"}},
+				"details": []any{
+					map[string]any{"history": []any{
+						map[string]any{"message": "Compliant; second"},
+						map[string]any{"message": "NonCompliant; first"},
+					}},
+					map[string]any{"compliant": "Pending"},
+				},
+			},
+		}}
+
+		typed, err := PolicyFromUnstructured(obj)
+		Expect(err).ToNot(HaveOccurred())
+
+		return typed
+	}
+
+	It("Rejects objects of the wrong kind", func() {
+		_, err := OperatorPolicyFromUnstructured(&unstructured.Unstructured{Object: map[string]any{
+			"kind":     "ConfigurationPolicy",
+			"metadata": map[string]any{"name": "my-config-policy", "namespace": "cluster1"},
+		}})
+		Expect(err).To(MatchError("OperatorPolicy cluster1/my-config-policy: kind is ConfigurationPolicy, " +
+			"not OperatorPolicy"))
+	})
+
+	It("Returns the latest history message", func() {
+		message, err := LatestHistoryMessage(policy(), 0)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(message).To(Equal("Compliant; second"))
+	})
+
+	It("Reports the missing history path", func() {
+		_, err := LatestHistoryMessage(policy(), 1)
+		Expect(err).To(MatchError("Policy policy-test/my-policy: status.details[1].history[0] not found, " +
+			"no compliance events have been recorded"))
+
+		_, err = TemplateHistory(policy(), 2)
+		Expect(err).To(MatchError("Policy policy-test/my-policy: status.details[2] not found, " +
+			"the policy has 2 template details"))
+
+		var fieldErr *FieldError
+		Expect(err).To(BeAssignableToTypeOf(fieldErr))
+	})
+
+	It("Returns the cluster compliance", func() {
+		compliance, err := ClusterComplianceState(policy(), "cluster1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(compliance).To(Equal(policiesv1.Compliant))
+
+		_, err = ClusterComplianceState(policy(), "cluster2")
+		Expect(err).To(MatchError(ContainSubstring("status.status[clusternamespace=cluster2] not found")))
+	})
+
+	It("Returns the typed ConfigurationPolicy template", func() {
+		configPolicy, err := PolicyTemplateConfigurationPolicy(policy(), 0)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(configPolicy.Name).To(Equal("my-config-policy"))
+		Expect(configPolicy.Spec.Severity).To(Equal("low"))
+
+		objTemplate, err := configPolicy.ObjectTemplate(0)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(objTemplate.ComplianceType).To(Equal("musthave"))
+		Expect(objTemplate.Object().GetKind()).To(Equal("ConfigMap"))
+
+		_, err = configPolicy.ObjectTemplate(1)
+		Expect(err).To(MatchError("ConfigurationPolicy my-config-policy: spec.object-templates[1] not found, " +
+			"the policy has 1 object templates"))
+
+		_, err = PolicyTemplateConfigurationPolicy(policy(), 1)
+		Expect(err).To(MatchError("Policy policy-test/my-policy: spec.policy-templates[1].objectDefinition.kind " +
+			"is OperatorPolicy, not ConfigurationPolicy"))
+	})
+
+	It("Finds OperatorPolicy conditions by type", func() {
+		opPolicy, err := OperatorPolicyFromUnstructured(&unstructured.Unstructured{Object: map[string]any{
+			"kind":     "OperatorPolicy",
+			"metadata": map[string]any{"name": "my-operator-policy", "namespace": "cluster1"},
+			"status": map[string]any{"conditions": []any{
+				map[string]any{"type": "Compliant", "status": "True", "message": "Compliant; ok"},
+				map[string]any{"type": "ValidPolicySpec", "status": "True"},
+			}},
+		}})
+		Expect(err).ToNot(HaveOccurred())
+
+		condition, err := opPolicy.Condition("Compliant")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(condition.Message).To(Equal("Compliant; ok"))
+
+		_, err = opPolicy.Condition("CatalogSourcesUnhealthy")
+		Expect(err).To(MatchError("OperatorPolicy cluster1/my-operator-policy: " +
+			"status.conditions[type=CatalogSourcesUnhealthy] not found, " +
+			"the condition types are [Compliant, ValidPolicySpec]"))
+	})
+})
@@ -4,7 +4,6 @@ package common
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"path/filepath"
 	"strconv"
@@ -22,17 +21,22 @@ import (
 )
 
 // GetComplianceState returns a function usable by ginkgo.Eventually that retrieves the
-// compliance state of the input policy in the globally configured managed cluster.
-func GetComplianceState(policyName string) func(Gomega) any {
-	return GetClusterComplianceState(policyName, ClusterNamespaceOnHub)
+// compliance state of the input policy in the Environment's managed cluster.
+//
+// Deprecated: Use WaitForCompliance, which watches the policy instead of polling it.
+func (e *Environment) GetComplianceState(policyName string) func(Gomega) any {
+	return e.GetClusterComplianceState(policyName, e.ClusterNamespaceOnHub)
 }
 
 // GetClusterComplianceState returns a function usable by ginkgo.Eventually that retrieves the
 // compliance state of the input policy on the specified cluster.
-func GetClusterComplianceState(policyName, clusterName string) func(Gomega) any {
+//
+// Deprecated: Use WaitForRootPolicy with the ClusterComplianceIs predicate, which watches the policy
+// instead of polling it.
+func (e *Environment) GetClusterComplianceState(policyName, clusterName string) func(Gomega) any {
 	return func(g Gomega) any {
 		rootPlc := utils.GetWithTimeout(
-			ClientHubDynamic, GvrPolicy, policyName, UserNamespace, true, DefaultTimeoutSeconds,
+			e.ClientHubDynamic, GvrPolicy, policyName, e.UserNamespace, true, e.DefaultTimeoutSeconds,
 		)
 		var policy policiesv1.Policy
 		err := runtime.DefaultUnstructuredConverter.FromUnstructured(rootPlc.UnstructuredContent(), &policy)
@@ -50,11 +54,11 @@ func GetClusterComplianceState(policyName, clusterName string) func(Gomega) any
 
 // PatchPlacement Patches the requiredClusterSelector of the specified Placement so that it will
 // always only match the targetCluster.
-func PatchPlacement(namespace, name string) error {
+func (e *Environment) PatchPlacement(namespace, name string) error {
 	By("Patching Placement " + namespace + "/" + name +
-		" with requiredClusterSelector {name: " + ClusterNamespaceOnHub + "}")
+		" with requiredClusterSelector {name: " + e.ClusterNamespaceOnHub + "}")
 
-	_, err := OcHub(
+	_, err := e.OcHub(
 		"patch",
 		"-n",
 		namespace,
@@ -64,7 +68,7 @@ func PatchPlacement(namespace, name string) error {
 		`-p=[{
 			"op": "replace",
 			"path": "/spec/predicates/0/requiredClusterSelector/labelSelector",
-			"value":{"matchExpressions":[{"key": "name", "operator": "In", "values": ["`+ClusterNamespaceOnHub+`"]}]}
+			"value":{"matchExpressions":[{"key": "name", "operator": "In", "values": ["`+e.ClusterNamespaceOnHub+`"]}]}
 		}]`,
 	)
 
@@ -73,7 +77,9 @@ func PatchPlacement(namespace, name string) error {
 
 // CreatePlacementDecision creates a PlacementDecision for the specified
 // Placement and returns the created decision.
-func CreatePlacementDecision(ctx context.Context, namespace, placementName string) (*unstructured.Unstructured, error) {
+func (e *Environment) CreatePlacementDecision(
+	ctx context.Context, namespace, placementName string,
+) (*unstructured.Unstructured, error) {
 	pldName := placementName + "-1"
 
 	By("Creating PlacementDecision for Placement " + namespace + "/" + placementName)
@@ -83,7 +89,7 @@ func CreatePlacementDecision(ctx context.Context, namespace, placementName strin
 			"kind":       "PlacementDecision",
 			"metadata": map[string]any{
 				"name": pldName,
-				"labels": map[string]string{
+				"labels": map[string]any{
 					"generated-by-policy-test":                     "",
 					"cluster.open-cluster-management.io/placement": placementName,
 				},
@@ -91,7 +97,9 @@ func CreatePlacementDecision(ctx context.Context, namespace, placementName strin
 		},
 	}
 
-	err := ClientHubDynamic.Resource(GvrPlacementDecision).Namespace(namespace).Delete(
+	e.setOwnershipLabels(&placementDecision)
+
+	err := e.ClientHubDynamic.Resource(GvrPlacementDecision).Namespace(namespace).Delete(
 		ctx,
 		pldName,
 		metav1.DeleteOptions{},
@@ -100,7 +108,7 @@ func CreatePlacementDecision(ctx context.Context, namespace, placementName strin
 		return nil, err
 	}
 
-	decision, err := ClientHubDynamic.Resource(GvrPlacementDecision).Namespace(namespace).Create(
+	decision, err := e.ClientHubDynamic.Resource(GvrPlacementDecision).Namespace(namespace).Create(
 		ctx, &placementDecision, metav1.CreateOptions{},
 	)
 	if err != nil {
@@ -110,12 +118,32 @@ func CreatePlacementDecision(ctx context.Context, namespace, placementName strin
 	return decision, nil
 }
 
+// setPlacementDecision creates a PlacementDecision for the Placement which lists the given managed
+// clusters, in place of the Placement controller.
+func (e *Environment) setPlacementDecision(
+	ctx context.Context, namespace, placementName string, clusterNames []string,
+) error {
+	By("Patching " + placementName + " with decision of clusters " + strings.Join(clusterNames, ", "))
+
+	pld, err := e.CreatePlacementDecision(ctx, namespace, placementName)
+	if err != nil {
+		return err
+	}
+
+	pld.Object["status"] = utils.GeneratePldStatus("", "", clusterNames...)
+	_, err = e.ClientHubDynamic.Resource(GvrPlacementDecision).Namespace(namespace).UpdateStatus(
+		ctx, pld, metav1.UpdateOptions{},
+	)
+
+	return err
+}
+
 // ApplyPlacement function creates Placement and PlacementBinding so that it will
 // always only match the targetCluster.
-func ApplyPlacement(ctx SpecContext, namespace, policyName string) error {
+func (e *Environment) ApplyPlacement(ctx SpecContext, namespace, policyName string) error {
 	By("Apply Placement and PlacementBinding " + namespace + "/" +
 		"placement-" + policyName + "/" + "placement-binding-" + policyName +
-		" with clusterSelector {name: " + ClusterNamespaceOnHub + "}")
+		" with clusterSelector {name: " + e.ClusterNamespaceOnHub + "}")
 
 	placement := unstructured.Unstructured{
 		Object: map[string]any{
@@ -133,8 +161,8 @@ func ApplyPlacement(ctx SpecContext, namespace, policyName string) error {
 									map[string]any{
 										"key":      "name",
 										"operator": "In",
-										"values": []string{
-											ClusterNamespaceOnHub,
+										"values": []any{
+											e.ClusterNamespaceOnHub,
 										},
 									},
 								},
@@ -146,7 +174,9 @@ func ApplyPlacement(ctx SpecContext, namespace, policyName string) error {
 		},
 	}
 
-	_, err := ClientHubDynamic.Resource(GvrPlacement).Namespace(namespace).Create(
+	e.setOwnershipLabels(&placement)
+
+	_, err := e.ClientHubDynamic.Resource(GvrPlacement).Namespace(namespace).Create(
 		ctx, &placement, metav1.CreateOptions{},
 	)
 	if err != nil {
@@ -175,7 +205,9 @@ func ApplyPlacement(ctx SpecContext, namespace, policyName string) error {
 		},
 	}
 
-	_, err = ClientHubDynamic.Resource(GvrPlacementBinding).Namespace(namespace).Create(
+	e.setOwnershipLabels(&placementBinding)
+
+	_, err = e.ClientHubDynamic.Resource(GvrPlacementBinding).Namespace(namespace).Create(
 		ctx, &placementBinding, metav1.CreateOptions{},
 	)
 
@@ -183,11 +215,11 @@ func ApplyPlacement(ctx SpecContext, namespace, policyName string) error {
 }
 
 // DeletePlacement delete applied Placement and PlacementBinding
-func DeletePlacement(namespace, policyName string) error {
+func (e *Environment) DeletePlacement(namespace, policyName string) error {
 	By("Delete Placement and PlacementBinding " + namespace + "/" +
 		"placement-" + policyName + "/" + "placement-binding-" + "policyName")
 
-	_, err := OcHub(
+	_, err := e.OcHub(
 		"delete", "placements.cluster.open-cluster-management.io", "placement-"+policyName,
 		"-n", namespace, "--ignore-not-found",
 	)
@@ -195,7 +227,7 @@ func DeletePlacement(namespace, policyName string) error {
 		return err
 	}
 
-	_, err = OcHub(
+	_, err = e.OcHub(
 		"delete", "placementbindings.policy.open-cluster-management.io",
 		"placement-binding-"+policyName, "-n", namespace, "--ignore-not-found",
 	)
@@ -214,48 +246,58 @@ func DeletePlacement(namespace, policyName string) error {
 // It assumes that the given filename (stripped of an extension) matches the
 // name of the policy, and that the Placement has the same name, with '-plr'
 // appended.
-func DoCreatePolicyTest(ctx context.Context, policyFile string, templateGVRs ...schema.GroupVersionResource) {
+func (e *Environment) DoCreatePolicyTest(
+	ctx context.Context, policyFile string, templateGVRs ...schema.GroupVersionResource,
+) {
+	GinkgoHelper()
+
+	e.DoCreatePolicyTestOnClusters(ctx, policyFile, []string{e.ClusterNamespaceOnHub}, templateGVRs...)
+}
+
+// DoCreatePolicyTestOnClusters is like DoCreatePolicyTest, but the PlacementDecision lists all of
+// the given managed clusters, and the propagation is asserted on each of them.
+func (e *Environment) DoCreatePolicyTestOnClusters(
+	ctx context.Context, policyFile string, clusterNames []string, templateGVRs ...schema.GroupVersionResource,
+) {
 	GinkgoHelper()
 
+	clusters := e.managedClusters(clusterNames)
 	policyName := strings.TrimSuffix(filepath.Base(policyFile), filepath.Ext(policyFile))
 
-	By("DoCreatePolicyTest creates " + policyFile + " on namespace " + UserNamespace)
+	By("DoCreatePolicyTest creates " + policyFile + " on namespace " + e.UserNamespace)
 	//nolint:contextcheck
-	output, err := OcHub("apply", "-f", policyFile, "-n", UserNamespace)
+	output, err := e.OcHub("apply", "-f", policyFile, "-n", e.UserNamespace)
 	Expect(err).ToNot(HaveOccurred())
 	By("DoCreatePolicyTest OcHub apply output: " + output)
 
-	plc := utils.GetWithTimeout(ClientHubDynamic, GvrPolicy, policyName, UserNamespace, true, DefaultTimeoutSeconds)
+	plc := utils.GetWithTimeout(
+		e.ClientHubDynamic, GvrPolicy, policyName, e.UserNamespace, true, e.DefaultTimeoutSeconds,
+	)
 	Expect(plc).NotTo(BeNil())
 
-	if ManuallyPatchDecisions {
-		plrName := policyName + "-plr"
-		By("Patching " + plrName + " with decision of cluster " + ClusterNamespaceOnHub)
-		pld, err := CreatePlacementDecision(ctx, UserNamespace, plrName)
-		Expect(err).ToNot(HaveOccurred())
-
-		pld.Object["status"] = utils.GeneratePldStatus("", "", ClusterNamespaceOnHub)
-		_, err = ClientHubDynamic.Resource(GvrPlacementDecision).Namespace(UserNamespace).UpdateStatus(
-			ctx,
-			pld,
-			metav1.UpdateOptions{},
-		)
+	if e.ManuallyPatchDecisions {
+		err := e.setPlacementDecision(ctx, e.UserNamespace, policyName+"-plr", clusterNames)
 		Expect(err).ToNot(HaveOccurred())
 	}
 
-	managedPolicyName := UserNamespace + "." + policyName
-	By("Checking " + managedPolicyName + " on managed cluster in ns " + ClusterNamespace)
-	Expect(utils.GetWithTimeout(
-		ClientHostingDynamic, GvrPolicy, managedPolicyName, ClusterNamespace, true, DefaultTimeoutSeconds*2,
-	)).NotTo(BeNil())
-
-	for _, tmplGVR := range templateGVRs {
-		typedName := tmplGVR.String() + "/" + policyName
-		By("Checking that the policy template " + typedName + " is present on the managed cluster")
+	managedPolicyName := e.UserNamespace + "." + policyName
 
+	for _, cluster := range clusters {
+		By("Checking " + managedPolicyName + " on managed cluster " + cluster.Name + " in ns " + cluster.Namespace)
 		Expect(utils.GetWithTimeout(
-			ClientHostingDynamic, tmplGVR, policyName, ClusterNamespace, true, DefaultTimeoutSeconds,
+			cluster.ClientHostingDynamic, GvrPolicy, managedPolicyName, cluster.Namespace, true,
+			e.DefaultTimeoutSeconds*2,
 		)).NotTo(BeNil())
+
+		for _, tmplGVR := range templateGVRs {
+			typedName := tmplGVR.String() + "/" + policyName
+			By("Checking that the policy template " + typedName + " is present on the managed cluster " +
+				cluster.Name)
+
+			Expect(utils.GetWithTimeout(
+				cluster.ClientHostingDynamic, tmplGVR, policyName, cluster.Namespace, true, e.DefaultTimeoutSeconds,
+			)).NotTo(BeNil())
+		}
 	}
 }
 
@@ -263,161 +305,199 @@ func DoCreatePolicyTest(ctx context.Context, policyFile string, templateGVRs ...
 // the propagated policy was removed from the managed cluster. For each templateGVR,
 // it will check that there is no longer a policy template (for example
 // ConfigurationPolicy) of the same name on the managed cluster.
-func DoCleanupPolicy(policyFile string, templateGVRs ...schema.GroupVersionResource) {
+func (e *Environment) DoCleanupPolicy(policyFile string, templateGVRs ...schema.GroupVersionResource) {
 	GinkgoHelper()
 
+	e.DoCleanupPolicyOnClusters(policyFile, []string{e.ClusterNamespaceOnHub}, templateGVRs...)
+}
+
+// DoCleanupPolicyOnClusters is like DoCleanupPolicy, but asserts that the propagated policy and
+// policy templates were removed from each of the given managed clusters.
+func (e *Environment) DoCleanupPolicyOnClusters(
+	policyFile string, clusterNames []string, templateGVRs ...schema.GroupVersionResource,
+) {
+	GinkgoHelper()
+
+	clusters := e.managedClusters(clusterNames)
 	policyName := strings.TrimSuffix(filepath.Base(policyFile), filepath.Ext(policyFile))
 	By("Deleting " + policyFile)
-	_, err := OcHub(
-		"delete", "-f", policyFile, "-n", UserNamespace,
+	_, err := e.OcHub(
+		"delete", "-f", policyFile, "-n", e.UserNamespace,
 		"--ignore-not-found",
 	)
 	Expect(err).ToNot(HaveOccurred())
 
 	Expect(utils.GetWithTimeout(
-		ClientHubDynamic, GvrPolicy, policyName, UserNamespace, false, DefaultTimeoutSeconds,
+		e.ClientHubDynamic, GvrPolicy, policyName, e.UserNamespace, false, e.DefaultTimeoutSeconds,
 	)).To(BeNil())
 
-	managedPolicyName := UserNamespace + "." + policyName
-	By("Checking " + managedPolicyName + " was removed from managed cluster in ns " + ClusterNamespace)
-	Expect(utils.GetWithTimeout(
-		ClientManagedDynamic, GvrPolicy, managedPolicyName, ClusterNamespace, false, DefaultTimeoutSeconds,
-	)).To(BeNil())
+	managedPolicyName := e.UserNamespace + "." + policyName
 
-	for _, tmplGVR := range templateGVRs {
-		typedName := tmplGVR.String() + "/" + policyName
-		By("Checking that the policy template " + typedName + " was removed from the managed cluster")
+	for _, cluster := range clusters {
+		By("Checking " + managedPolicyName + " was removed from managed cluster " + cluster.Name +
+			" in ns " + cluster.Namespace)
 		Expect(utils.GetWithTimeout(
-			ClientManagedDynamic, tmplGVR, policyName, ClusterNamespace, false, DefaultTimeoutSeconds,
+			cluster.ClientDynamic, GvrPolicy, managedPolicyName, cluster.Namespace, false, e.DefaultTimeoutSeconds,
 		)).To(BeNil())
+
+		for _, tmplGVR := range templateGVRs {
+			typedName := tmplGVR.String() + "/" + policyName
+			By("Checking that the policy template " + typedName + " was removed from the managed cluster " +
+				cluster.Name)
+			Expect(utils.GetWithTimeout(
+				cluster.ClientDynamic, tmplGVR, policyName, cluster.Namespace, false, e.DefaultTimeoutSeconds,
+			)).To(BeNil())
+		}
 	}
 }
 
 // DoRootComplianceTest asserts that the given policy has the given compliance
 // on the root policy on the hub cluster.
-func DoRootComplianceTest(policyName string, compliance policiesv1.ComplianceState) {
+func (e *Environment) DoRootComplianceTest(policyName string, compliance policiesv1.ComplianceState) {
 	GinkgoHelper()
 
 	By("Checking if the status of root policy " + policyName + " is " + string(compliance))
-	EventuallyWithOffset(
-		1,
-		GetComplianceState(policyName),
-		DefaultTimeoutSeconds,
-		1,
-	).Should(Equal(compliance))
+	_, err := e.WaitForRootPolicy(
+		context.TODO(), policyName, "compliant is "+string(compliance), ComplianceIs(compliance),
+	)
+	Expect(err).ToNot(HaveOccurred())
 }
 
-func GetHistoryMessages(policyName string, templateIdx int) ([]any, bool, error) {
-	empty := make([]any, 0)
-	replicatedPolicyName := UserNamespace + "." + policyName
-	policyInterface := ClientHostingDynamic.Resource(GvrPolicy).Namespace(ClusterNamespace)
+// GetRootPolicy returns the root policy of the given name from the user namespace on the hub.
+func (e *Environment) GetRootPolicy(policyName string) (*policiesv1.Policy, error) {
+	policy, err := e.ClientHubDynamic.Resource(GvrPolicy).Namespace(e.UserNamespace).Get(
+		context.TODO(), policyName, metav1.GetOptions{},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the root policy %s/%s: %w", e.UserNamespace, policyName, err)
+	}
+
+	return PolicyFromUnstructured(policy)
+}
 
-	policy, err := policyInterface.Get(context.TODO(), replicatedPolicyName, metav1.GetOptions{})
+// GetReplicatedPolicy returns the replicated policy of the given root policy name from the cluster
+// namespace on the cluster hosting the policy templates.
+func (e *Environment) GetReplicatedPolicy(policyName string) (*policiesv1.Policy, error) {
+	replicatedPolicyName := e.UserNamespace + "." + policyName
+	templates := e.Topology().Templates
+
+	policy, err := templates.Get(context.TODO(), GvrPolicy, replicatedPolicyName)
 	if err != nil {
-		return empty, false, errors.New("error in getting policy")
+		return nil, fmt.Errorf(
+			"failed to get the replicated policy %s/%s: %w", templates.Namespace, replicatedPolicyName, err,
+		)
 	}
 
-	details, found, err := unstructured.NestedSlice(policy.Object, "status", "details")
-	if !found || err != nil || len(details) <= templateIdx {
-		return empty, false, errors.New("error in getting status")
+	return PolicyFromUnstructured(policy)
+}
+
+// GetTemplateHistory returns the compliance history of the policy template at the given index on
+// the replicated policy, with the most recent event first.
+func (e *Environment) GetTemplateHistory(
+	policyName string, templateIdx int,
+) ([]policiesv1.ComplianceHistory, error) {
+	policy, err := e.GetReplicatedPolicy(policyName)
+	if err != nil {
+		return nil, err
 	}
 
-	templateDetails, ok := details[templateIdx].(map[string]any)
-	if !ok {
-		return empty, false, errors.New("error in getting detail")
+	return TemplateHistory(policy, templateIdx)
+}
+
+// GetHistoryMessages returns the compliance history of the policy template at the given index on
+// the replicated policy as unstructured entries. The boolean is false when there is no history.
+// Prefer GetTemplateHistory for the typed history.
+func (e *Environment) GetHistoryMessages(policyName string, templateIdx int) ([]any, bool, error) {
+	empty := make([]any, 0)
+
+	history, err := e.GetTemplateHistory(policyName, templateIdx)
+	if err != nil {
+		return empty, false, err
 	}
 
-	history, found, err := unstructured.NestedSlice(templateDetails, "history")
+	if len(history) == 0 {
+		return empty, false, nil
+	}
 
-	return history, found, err
+	historyItems := make([]any, 0, len(history))
+
+	for _, historyItem := range history {
+		item, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&historyItem)
+		if err != nil {
+			return empty, false, err
+		}
+
+		historyItems = append(historyItems, item)
+	}
+
+	return historyItems, true, nil
 }
 
 // GetOpPolicyCompMsg returns a function (so that it can be used in an Eventually)
 // that returns the current Compliant condition message on the specified OperatorPolicy.
 // It will return an empty string if the OperatorPolicy or condition could not be found.
-func GetOpPolicyCompMsg(policyName string) func() string {
+func (e *Environment) GetOpPolicyCompMsg(policyName string) func() string {
 	return func() string {
 		unstructOpPol := utils.GetWithTimeout(
-			ClientManagedDynamic,
+			e.ClientManagedDynamic,
 			GvrOperatorPolicy,
 			policyName,
-			ClusterNamespace,
+			e.ClusterNamespace,
 			true,
-			DefaultTimeoutSeconds,
+			e.DefaultTimeoutSeconds,
 		)
 		Expect(unstructOpPol).NotTo(BeNil())
 
-		condList, found, err := unstructured.NestedSlice(
-			unstructOpPol.Object,
-			"status",
-			"conditions",
-		)
-		if err != nil || !found {
+		opPolicy, err := OperatorPolicyFromUnstructured(unstructOpPol)
+		if err != nil {
 			return ""
 		}
 
-		for _, cond := range condList {
-			condMap, ok := cond.(map[string]any)
-			if !ok {
-				continue
-			}
-
-			condType, _, _ := unstructured.NestedString(condMap, "type")
-			if condType == "Compliant" {
-				return fmt.Sprintf("%v", condMap["message"])
-			}
+		condition, err := opPolicy.Condition("Compliant")
+		if err != nil {
+			return ""
 		}
 
-		return ""
+		return condition.Message
 	}
 }
 
 // GetLatestStatusMessage returns the most recent status message for the given policy template.
 // If the policy, template, or status do not exist for any reason, an empty string is returned.
-func GetLatestStatusMessage(policyName string, templateIdx int) func() string {
+func (e *Environment) GetLatestStatusMessage(policyName string, templateIdx int) func() string {
 	return func() string {
-		history, found, err := GetHistoryMessages(policyName, templateIdx)
-		if !found || err != nil || len(history) == 0 {
+		policy, err := e.GetReplicatedPolicy(policyName)
+		if err != nil {
 			return ""
 		}
 
-		topHistoryItem, ok := history[0].(map[string]any)
-		if !ok {
+		message, err := LatestHistoryMessage(policy, templateIdx)
+		if err != nil {
 			return ""
 		}
 
-		message, _, _ := unstructured.NestedString(topHistoryItem, "message")
-
 		return message
 	}
 }
 
-func GetDuplicateHistoryMessage(policyName string) string {
-	history, _, err := GetHistoryMessages(policyName, 0)
+// GetDuplicateHistoryMessage returns the first message of the first policy template's history which
+// is repeated by consecutive events, or an empty string if there is none.
+func (e *Environment) GetDuplicateHistoryMessage(policyName string) string {
+	history, err := e.GetTemplateHistory(policyName, 0)
 	if err != nil {
 		return ""
 	}
 
-	historyMsgs := []string{}
-
-	for _, h := range history {
-		historyItem, _ := h.(map[string]any)
-		m, _, _ := unstructured.NestedString(historyItem, "message")
-		historyMsgs = append(historyMsgs, m)
-	}
-
-	for i, m := range historyMsgs {
-		if i > 0 {
-			if m == historyMsgs[i-1] {
-				return m
-			}
+	for i := 1; i < len(history); i++ {
+		if history[i].Message == history[i-1].Message {
+			return history[i].Message
 		}
 	}
 
 	return ""
 }
 
-func DoHistoryUpdatedTest(policyName string, messages ...string) {
+func (e *Environment) DoHistoryUpdatedTest(policyName string, messages ...string) {
 	By("Getting policy history")
 
 	// There is a limit of 10 messages in the Policy status, so if more are passed in, just truncate it.
@@ -427,52 +507,66 @@ func DoHistoryUpdatedTest(policyName string, messages ...string) {
 
 	By("Getting policy history, check latest message")
 	Eventually(func(g Gomega) {
-		history, _, err := GetHistoryMessages(policyName, 0)
+		history, err := e.GetTemplateHistory(policyName, 0)
 		g.Expect(err).ShouldNot(HaveOccurred())
 
-		lenMessage := len(messages)
-		historyMsgs := []string{}
+		historyMsgs := make([]string, 0, len(history))
 
 		GinkgoWriter.Println("Returned policy history:")
 
-		for i, h := range history {
-			historyItem, _ := h.(map[string]any)
-			m, _, _ := unstructured.NestedString(historyItem, "message")
-			historyMsgs = append(historyMsgs, m)
-			GinkgoWriter.Println(strconv.Itoa(i) + ": " + m)
+		for i, historyItem := range history {
+			historyMsgs = append(historyMsgs, historyItem.Message)
+			GinkgoWriter.Println(strconv.Itoa(i) + ": " + historyItem.Message)
 		}
 
 		By("Check history length is same")
-		g.Expect(history).Should(HaveLen(lenMessage))
+		g.Expect(history).Should(HaveLen(len(messages)))
 
 		By("Check history message same")
 		g.Expect(strings.Join(historyMsgs, "")).Should(Equal(strings.Join(messages, "")))
-	}, DefaultTimeoutSeconds, 1).Should(Succeed())
+	}, e.DefaultTimeoutSeconds, 1).Should(Succeed())
 }
 
 // InformPolicy patches the root policy to be informed and verifies that it propagates. Note: when
 // checking a policy template, it assumes the template's name matches the root policy's name.
-func InformPolicy(policyName string, templateGVRs ...schema.GroupVersionResource) {
-	setRemediationAction(policyName, "inform", templateGVRs...)
+func (e *Environment) InformPolicy(policyName string, templateGVRs ...schema.GroupVersionResource) {
+	e.setRemediationAction(policyName, "inform", []string{e.ClusterNamespaceOnHub}, templateGVRs...)
 }
 
 // EnforcePolicy patches the root policy to be enforced and verifies that it propagates. Note: when
 // checking a policy template, it assumes the template's name matches the root policy's name.
-func EnforcePolicy(policyName string, templateGVRs ...schema.GroupVersionResource) {
-	setRemediationAction(policyName, "enforce", templateGVRs...)
+func (e *Environment) EnforcePolicy(policyName string, templateGVRs ...schema.GroupVersionResource) {
+	e.setRemediationAction(policyName, "enforce", []string{e.ClusterNamespaceOnHub}, templateGVRs...)
+}
+
+// InformPolicyOnClusters is like InformPolicy, but verifies the propagation on each of the given
+// managed clusters.
+func (e *Environment) InformPolicyOnClusters(
+	policyName string, clusterNames []string, templateGVRs ...schema.GroupVersionResource,
+) {
+	e.setRemediationAction(policyName, "inform", clusterNames, templateGVRs...)
+}
+
+// EnforcePolicyOnClusters is like EnforcePolicy, but verifies the propagation on each of the given
+// managed clusters.
+func (e *Environment) EnforcePolicyOnClusters(
+	policyName string, clusterNames []string, templateGVRs ...schema.GroupVersionResource,
+) {
+	e.setRemediationAction(policyName, "enforce", clusterNames, templateGVRs...)
 }
 
-// SetRemediationAction patches the root policy, and asserts that the replicated policy on the
+// SetRemediationAction patches the root policy, and asserts that the replicated policy on each
 // managed cluster, and policy template objects (based on the provided GVRs) have remediationActions
 // that match. Note: when checking a policy template, it assumes the template's name matches the
 // root policy's name.
-func setRemediationAction(
-	policyName string, remediationAction string, templateGVRs ...schema.GroupVersionResource,
+func (e *Environment) setRemediationAction(
+	policyName string, remediationAction string, clusterNames []string, templateGVRs ...schema.GroupVersionResource,
 ) {
 	GinkgoHelper()
 
 	ctx := context.TODO()
-	rootPolicyClient := ClientHubDynamic.Resource(GvrPolicy).Namespace(UserNamespace)
+	clusters := e.managedClusters(clusterNames)
+	rootPolicyClient := e.ClientHubDynamic.Resource(GvrPolicy).Namespace(e.UserNamespace)
 
 	By("Patching remediationAction = " + remediationAction + " on root policy")
 	Eventually(func(g Gomega) {
@@ -484,36 +578,40 @@ func setRemediationAction(
 
 		_, err = rootPolicyClient.Update(ctx, rootPlc, metav1.UpdateOptions{})
 		g.Expect(err).ToNot(HaveOccurred())
-	}, DefaultTimeoutSeconds, 1).Should(Succeed())
+	}, e.DefaultTimeoutSeconds, 1).Should(Succeed())
 
-	managedPolicyClient := ClientHostingDynamic.Resource(GvrPolicy).Namespace(ClusterNamespace)
-
-	By("Checking that remediationAction = " + remediationAction + " on replicated policy")
-	Eventually(func(g Gomega) {
-		managedPlc, err := managedPolicyClient.Get(ctx, UserNamespace+"."+policyName, metav1.GetOptions{})
-		g.Expect(err).ToNot(HaveOccurred())
-
-		action, found, err := unstructured.NestedString(managedPlc.Object, "spec", "remediationAction")
-		g.Expect(err).ToNot(HaveOccurred())
-		g.Expect(found).To(BeTrue())
-		g.Expect(action).To(Equal(remediationAction))
-	}, DefaultTimeoutSeconds, 1).Should(Succeed())
-
-	for _, tmplGVR := range templateGVRs {
-		typedName := tmplGVR.String() + "/" + policyName
-		By("Checking that remediationAction = " + remediationAction + " on policy template " + typedName)
-
-		templateClient := ClientHostingDynamic.Resource(tmplGVR).Namespace(ClusterNamespace)
+	for _, cluster := range clusters {
+		managedPolicyClient := cluster.ClientHostingDynamic.Resource(GvrPolicy).Namespace(cluster.Namespace)
 
+		By("Checking that remediationAction = " + remediationAction + " on replicated policy on cluster " +
+			cluster.Name)
 		Eventually(func(g Gomega) {
-			template, err := templateClient.Get(ctx, policyName, metav1.GetOptions{})
+			managedPlc, err := managedPolicyClient.Get(ctx, e.UserNamespace+"."+policyName, metav1.GetOptions{})
 			g.Expect(err).ToNot(HaveOccurred())
 
-			action, found, err := unstructured.NestedString(template.Object, "spec", "remediationAction")
+			action, found, err := unstructured.NestedString(managedPlc.Object, "spec", "remediationAction")
 			g.Expect(err).ToNot(HaveOccurred())
 			g.Expect(found).To(BeTrue())
 			g.Expect(action).To(Equal(remediationAction))
-		}, DefaultTimeoutSeconds, 1).Should(Succeed())
+		}, e.DefaultTimeoutSeconds, 1).Should(Succeed())
+
+		for _, tmplGVR := range templateGVRs {
+			typedName := tmplGVR.String() + "/" + policyName
+			By("Checking that remediationAction = " + remediationAction + " on policy template " + typedName +
+				" on cluster " + cluster.Name)
+
+			templateClient := cluster.ClientHostingDynamic.Resource(tmplGVR).Namespace(cluster.Namespace)
+
+			Eventually(func(g Gomega) {
+				template, err := templateClient.Get(ctx, policyName, metav1.GetOptions{})
+				g.Expect(err).ToNot(HaveOccurred())
+
+				action, found, err := unstructured.NestedString(template.Object, "spec", "remediationAction")
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(found).To(BeTrue())
+				g.Expect(action).To(Equal(remediationAction))
+			}, e.DefaultTimeoutSeconds, 1).Should(Succeed())
+		}
 	}
 }
 
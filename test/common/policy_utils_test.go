@@ -0,0 +1,259 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package common
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	policiesv1 "open-cluster-management.io/governance-policy-propagator/api/v1"
+)
+
+const (
+	fakePolicyFile = "testdata/fake-policy.yaml"
+	fakePolicyName = "fake-policy"
+)
+
+// startFakeBackend returns a FakeBackend with its propagator running until the end of the spec.
+func startFakeBackend() *FakeBackend {
+	backend := NewFakeBackend()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	DeferCleanup(cancel)
+
+	backend.Propagator.Start(ctx, 10*time.Millisecond)
+
+	return backend
+}
+
+var _ = Describe("Test the policy helpers with a fake backend", func() {
+	var backend *FakeBackend
+	var env *Environment
+
+	BeforeEach(func() {
+		backend = startFakeBackend()
+		env = backend.Env
+	})
+
+	Describe("CreatePlacementDecision", func() {
+		It("Creates a PlacementDecision labeled for the Placement", func(ctx SpecContext) {
+			decision, err := env.CreatePlacementDecision(ctx, env.UserNamespace, "my-plr")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(decision.GetName()).To(Equal("my-plr-1"))
+			Expect(decision.GetNamespace()).To(Equal(env.UserNamespace))
+			Expect(decision.GetLabels()).To(HaveKeyWithValue("cluster.open-cluster-management.io/placement", "my-plr"))
+			Expect(decision.GetLabels()).To(HaveKey("generated-by-policy-test"))
+		})
+
+		It("Replaces an existing PlacementDecision", func(ctx SpecContext) {
+			decision, err := env.CreatePlacementDecision(ctx, env.UserNamespace, "my-plr")
+			Expect(err).ToNot(HaveOccurred())
+
+			decision.Object["status"] = map[string]any{
+				"decisions": []any{map[string]any{"clusterName": "cluster1", "reason": ""}},
+			}
+			_, err = env.ClientHubDynamic.Resource(GvrPlacementDecision).Namespace(env.UserNamespace).UpdateStatus(
+				ctx, decision, metav1.UpdateOptions{},
+			)
+			Expect(err).ToNot(HaveOccurred())
+
+			decision, err = env.CreatePlacementDecision(ctx, env.UserNamespace, "my-plr")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(decision.Object).ToNot(HaveKey("status"))
+
+			decisions, err := env.ClientHubDynamic.Resource(GvrPlacementDecision).Namespace(env.UserNamespace).List(
+				ctx, metav1.ListOptions{},
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(decisions.Items).To(HaveLen(1))
+		})
+	})
+
+	Describe("Policy propagation", func() {
+		BeforeEach(func(ctx SpecContext) {
+			env.DoCreatePolicyTest(ctx, fakePolicyFile, GvrConfigurationPolicy)
+		})
+
+		It("Removes the replicated policy and template on cleanup", func() {
+			env.DoCleanupPolicy(fakePolicyFile, GvrConfigurationPolicy)
+		})
+
+		It("Reports the compliance of the replicated policy on the root policy", func(ctx SpecContext) {
+			err := backend.Propagator.SetTemplateStatus(
+				ctx, env.ClusterNamespaceOnHub, env.UserNamespace, fakePolicyName, 0, policiesv1.NonCompliant,
+				"NonCompliant; violation - configmaps [fake-policy] not found in namespace default",
+			)
+			Expect(err).ToNot(HaveOccurred())
+
+			env.DoRootComplianceTest(fakePolicyName, policiesv1.NonCompliant)
+			env.DoClusterComplianceTest(fakePolicyName, policiesv1.NonCompliant, env.ClusterNamespaceOnHub)
+		})
+
+		It("Gets the compliance of the policy on the managed cluster", func(ctx SpecContext) {
+			Expect(env.GetComplianceState(fakePolicyName)(Default)).To(BeEquivalentTo(""))
+
+			err := backend.Propagator.SetTemplateStatus(
+				ctx, env.ClusterNamespaceOnHub, env.UserNamespace, fakePolicyName, 0, policiesv1.Compliant,
+				"Compliant; notification - configmaps [fake-policy] found as specified in namespace default",
+			)
+			Expect(err).ToNot(HaveOccurred())
+
+			Eventually(env.GetComplianceState(fakePolicyName), 5, 1).Should(Equal(policiesv1.Compliant))
+			Expect(env.GetClusterComplianceState(fakePolicyName, "other-cluster")(Default)).To(BeNil())
+		})
+
+		Describe("setRemediationAction", func() {
+			It("Propagates the remediationAction to the replicated policy and template", func(ctx SpecContext) {
+				env.setRemediationAction(
+					fakePolicyName, "enforce", []string{env.ClusterNamespaceOnHub}, GvrConfigurationPolicy,
+				)
+
+				template, err := env.ClientManagedDynamic.Resource(GvrConfigurationPolicy).
+					Namespace(env.ClusterNamespace).Get(ctx, fakePolicyName, metav1.GetOptions{})
+				Expect(err).ToNot(HaveOccurred())
+
+				action, _, _ := unstructured.NestedString(template.Object, "spec", "remediationAction")
+				Expect(action).To(Equal("enforce"))
+
+				env.setRemediationAction(
+					fakePolicyName, "inform", []string{env.ClusterNamespaceOnHub}, GvrConfigurationPolicy,
+				)
+			})
+
+			It("Propagates the remediationAction to multiple managed clusters", func(ctx SpecContext) {
+				backend.AddManagedCluster("cluster2")
+				clusters := []string{env.ClusterNamespaceOnHub, "cluster2"}
+
+				env.DoCreatePolicyTestOnClusters(ctx, fakePolicyFile, clusters, GvrConfigurationPolicy)
+				env.EnforcePolicyOnClusters(fakePolicyName, clusters, GvrConfigurationPolicy)
+			})
+		})
+
+		Describe("GetHistoryMessages", func() {
+			It("Fails when the policy has no status", func() {
+				history, found, err := env.GetHistoryMessages(fakePolicyName, 0)
+				Expect(err).To(MatchError(
+					"Policy local-cluster/policy-test.fake-policy: status.details[0] not found, " +
+						"the policy has 0 template details",
+				))
+				Expect(found).To(BeFalse())
+				Expect(history).To(BeEmpty())
+			})
+
+			It("Fails when the replicated policy doesn't exist", func() {
+				_, _, err := env.GetHistoryMessages("missing-policy", 0)
+				Expect(err).To(MatchError(ContainSubstring("failed to get the replicated policy")))
+				Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+			})
+
+			It("Returns the history of the template, newest first", func(ctx SpecContext) {
+				for _, message := range []string{"first", "second"} {
+					err := backend.Propagator.SetTemplateStatus(
+						ctx, env.ClusterNamespaceOnHub, env.UserNamespace, fakePolicyName, 0, policiesv1.Compliant,
+						message,
+					)
+					Expect(err).ToNot(HaveOccurred())
+				}
+
+				history, found, err := env.GetHistoryMessages(fakePolicyName, 0)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(found).To(BeTrue())
+				Expect(history).To(HaveLen(2))
+				Expect(history[0]).To(HaveKeyWithValue("message", "second"))
+				Expect(history[1]).To(HaveKeyWithValue("message", "first"))
+
+				Expect(env.GetLatestStatusMessage(fakePolicyName, 0)()).To(Equal("second"))
+
+				_, _, err = env.GetHistoryMessages(fakePolicyName, 1)
+				Expect(err).To(MatchError(ContainSubstring("status.details[1] not found")))
+			})
+
+			It("Keeps only the last 10 messages", func(ctx SpecContext) {
+				messages := []string{}
+
+				for _, message := range []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11", "12"} {
+					err := backend.Propagator.SetTemplateStatus(
+						ctx, env.ClusterNamespaceOnHub, env.UserNamespace, fakePolicyName, 0, policiesv1.Compliant,
+						message,
+					)
+					Expect(err).ToNot(HaveOccurred())
+
+					messages = append([]string{message}, messages...)
+				}
+
+				env.DoHistoryUpdatedTest(fakePolicyName, messages...)
+			})
+		})
+
+		Describe("GetDuplicateHistoryMessage", func() {
+			recordMessages := func(ctx context.Context, messages ...string) {
+				GinkgoHelper()
+
+				for _, message := range messages {
+					err := backend.Propagator.SetTemplateStatus(
+						ctx, env.ClusterNamespaceOnHub, env.UserNamespace, fakePolicyName, 0, policiesv1.Compliant,
+						message,
+					)
+					Expect(err).ToNot(HaveOccurred())
+				}
+			}
+
+			It("Returns an empty string without a status", func() {
+				Expect(env.GetDuplicateHistoryMessage(fakePolicyName)).To(BeEmpty())
+			})
+
+			It("Returns an empty string when messages only repeat later", func(ctx SpecContext) {
+				recordMessages(ctx, "a", "b", "a")
+
+				Expect(env.GetDuplicateHistoryMessage(fakePolicyName)).To(BeEmpty())
+			})
+
+			It("Returns the repeated message", func(ctx SpecContext) {
+				recordMessages(ctx, "a", "b", "b", "c")
+
+				Expect(env.GetDuplicateHistoryMessage(fakePolicyName)).To(Equal("b"))
+			})
+		})
+	})
+
+	Describe("GetOpPolicyCompMsg", func() {
+		createOperatorPolicy := func(ctx context.Context, conditions ...any) {
+			GinkgoHelper()
+
+			opPolicy := &unstructured.Unstructured{Object: map[string]any{
+				"apiVersion": GvrOperatorPolicy.GroupVersion().String(),
+				"kind":       "OperatorPolicy",
+				"metadata": map[string]any{
+					"name":      "fake-operator-policy",
+					"namespace": env.ClusterNamespace,
+				},
+				"status": map[string]any{"conditions": conditions},
+			}}
+
+			_, err := env.ClientManagedDynamic.Resource(GvrOperatorPolicy).Namespace(env.ClusterNamespace).Create(
+				ctx, opPolicy, metav1.CreateOptions{},
+			)
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		It("Returns the message of the Compliant condition", func(ctx SpecContext) {
+			createOperatorPolicy(ctx,
+				map[string]any{"type": "SubscriptionCompliant", "message": "the Subscription matches"},
+				map[string]any{"type": "Compliant", "message": "Compliant; the policy spec is valid"},
+			)
+
+			Expect(env.GetOpPolicyCompMsg("fake-operator-policy")()).To(Equal("Compliant; the policy spec is valid"))
+		})
+
+		It("Returns an empty string without a Compliant condition", func(ctx SpecContext) {
+			createOperatorPolicy(ctx, map[string]any{"type": "ValidPolicySpec", "message": "the policy spec is valid"})
+
+			Expect(env.GetOpPolicyCompMsg("fake-operator-policy")()).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,922 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package common
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+	policiesv1 "open-cluster-management.io/governance-policy-propagator/api/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/stolostron/governance-policy-framework/test/common/kubeclient"
+)
+
+// policyTemplateGVRs are the resources of the policy template kinds accepted by a RemediationStep.
+var policyTemplateGVRs = map[string]schema.GroupVersionResource{
+	"ConfigurationPolicy": GvrConfigurationPolicy,
+	"OperatorPolicy":      GvrOperatorPolicy,
+	"CertificatePolicy":   GvrCertPolicy,
+}
+
+// Scenario is a declarative policy lifecycle test, loaded from a YAML file by LoadScenario, so that
+// policies can be covered without writing Go. DescribeScenario runs each step as a spec of an
+// Ordered container. For example:
+//
+//	name: Test the policy-pod policy
+//	labels: [policy-collection, stable]
+//	steps:
+//	  - apply:
+//	      policyCollection: stable/CM-Configuration-Management/policy-pod.yaml
+//	      placement: true
+//	  - expectCompliance: {policy: policy-pod, compliance: NonCompliant}
+//	  - enforce: {policy: policy-pod, templates: [ConfigurationPolicy]}
+//	  - expectCompliance: {policy: policy-pod, compliance: Compliant}
+//	  - cleanup: {}
+type Scenario struct {
+	// Name is the text of the Ginkgo container of the scenario.
+	Name   string         `json:"name"`
+	Labels []string       `json:"labels,omitempty"`
+	Steps  []ScenarioStep `json:"steps"`
+
+	// dir is the directory of the scenario file, which relative manifest files are resolved against.
+	dir string
+}
+
+// ScenarioStep is a single step of a Scenario, which must set exactly one of the actions.
+type ScenarioStep struct {
+	// Name is the text of the spec running the step, which defaults to a description of the action.
+	Name string `json:"name,omitempty"`
+
+	Apply            *ManifestStep         `json:"apply,omitempty"`
+	Place            *PlaceStep            `json:"place,omitempty"`
+	Patch            *PatchStep            `json:"patch,omitempty"`
+	Enforce          *RemediationStep      `json:"enforce,omitempty"`
+	Inform           *RemediationStep      `json:"inform,omitempty"`
+	ExpectCompliance *ExpectComplianceStep `json:"expectCompliance,omitempty"`
+	ExpectHistory    *ExpectHistoryStep    `json:"expectHistory,omitempty"`
+	ExpectObject     *ExpectObjectStep     `json:"expectObject,omitempty"`
+	Cleanup          *ManifestStep         `json:"cleanup,omitempty"`
+}
+
+// ManifestStep applies or deletes the objects of a manifest, which is set by one of File,
+// PolicyCollection, or Manifest. A cleanup step without a manifest deletes the objects of all of
+// the previous apply steps which were not cleaned up yet, in reverse order.
+type ManifestStep struct {
+	// Cluster is the cluster of the objects, which defaults to the hub.
+	Cluster ClusterTarget `json:"cluster,omitempty"`
+	// File is a path relative to the scenario file, or a URL.
+	File string `json:"file,omitempty"`
+	// PolicyCollection is a path in the policy-collection repository, on the configured branch.
+	PolicyCollection string `json:"policyCollection,omitempty"`
+	// Manifest is inline multi-document YAML.
+	Manifest string `json:"manifest,omitempty"`
+	// Namespace is the namespace of the objects without one, which defaults to the user namespace
+	// on the hub.
+	Namespace string `json:"namespace,omitempty"`
+	// Placement creates a Placement and PlacementBinding for each Policy with ApplyPlacement, for
+	// manifests which don't include their own, and deletes them on cleanup.
+	Placement bool `json:"placement,omitempty"`
+	// Clusters are the managed clusters that the policies are placed on, which defaults to the
+	// default managed cluster. They are listed in the PlacementDecisions when ManuallyPatchDecisions
+	// is set, and the replicated policies are waited for on each of them.
+	Clusters []string `json:"clusters,omitempty"`
+}
+
+// PlaceStep places a policy which was applied without Placement with ApplyPlacement, for the
+// scenarios which change the policy on the hub before it's replicated. The placement is deleted by
+// the cleanup of all of the applied manifests.
+type PlaceStep struct {
+	Policy string `json:"policy"`
+	// Clusters default to the default managed cluster.
+	Clusters []string `json:"clusters,omitempty"`
+}
+
+// PatchStep patches an object like `oc patch`.
+type PatchStep struct {
+	// Cluster is the cluster of the object, which defaults to the hub.
+	Cluster ClusterTarget `json:"cluster,omitempty"`
+	// Resource is in any of the forms accepted by kubectl, for example `policy` or
+	// `policies.policy.open-cluster-management.io`.
+	Resource string `json:"resource"`
+	// Namespace defaults to the user namespace on the hub.
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	// Type is one of json, merge, or strategic, which is the default.
+	Type string `json:"type,omitempty"`
+	// Patch is in either JSON or YAML.
+	Patch string `json:"patch"`
+}
+
+// RemediationStep sets the remediationAction of a root policy, and waits for it to be propagated to
+// the replicated policies and policy templates.
+type RemediationStep struct {
+	Policy string `json:"policy"`
+	// Clusters default to the default managed cluster.
+	Clusters []string `json:"clusters,omitempty"`
+	// Templates are the kinds of the policy templates named like the policy to check, out of
+	// ConfigurationPolicy, OperatorPolicy, and CertificatePolicy.
+	Templates []string `json:"templates,omitempty"`
+}
+
+// StepTimeout is the timeout of the steps which wait, which defaults to the default timeout of the
+// Environment.
+type StepTimeout struct {
+	TimeoutSeconds int `json:"timeoutSeconds,omitempty"`
+	// TimeoutMultiplier sets the timeout to a multiple of the default timeout instead, so that it
+	// scales with the configured default timeout.
+	TimeoutMultiplier int `json:"timeoutMultiplier,omitempty"`
+}
+
+// ExpectComplianceStep waits for a root policy to have the compliance. When Clusters are set, the
+// status of each cluster in the root policy and its replicated policy are checked instead.
+type ExpectComplianceStep struct {
+	Policy     string                     `json:"policy"`
+	Compliance policiesv1.ComplianceState `json:"compliance"`
+	Clusters   []string                   `json:"clusters,omitempty"`
+	StepTimeout
+}
+
+// ExpectHistoryStep waits for the latest history message of a policy template of the replicated
+// policy to match the regular expression.
+type ExpectHistoryStep struct {
+	Policy string `json:"policy"`
+	// Cluster defaults to the default managed cluster.
+	Cluster  string `json:"cluster,omitempty"`
+	Template int    `json:"template,omitempty"`
+	Message  string `json:"message"`
+	StepTimeout
+}
+
+// ExpectObjectStep waits for an object to exist with the given fields, or to not exist.
+type ExpectObjectStep struct {
+	// Cluster defaults to the managed cluster.
+	Cluster    ClusterTarget `json:"cluster,omitempty"`
+	APIVersion string        `json:"apiVersion"`
+	Kind       string        `json:"kind"`
+	// Namespace defaults to the user namespace on the hub, and to `default` on the other clusters,
+	// for namespaced kinds.
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	// Exists defaults to true.
+	Exists *bool `json:"exists,omitempty"`
+	// Fields must all be set to the same values in the object. Each item of a list must match an
+	// item of the list in the object, in any order.
+	Fields map[string]any `json:"fields,omitempty"`
+	StepTimeout
+}
+
+// LoadScenario reads and validates the scenario file.
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- the files are provided by the tests
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the scenario %s: %w", path, err)
+	}
+
+	scenario := &Scenario{dir: filepath.Dir(path)}
+
+	if err := yaml.UnmarshalStrict(data, scenario); err != nil {
+		return nil, fmt.Errorf("failed to decode the scenario %s: %w", path, err)
+	}
+
+	if err := scenario.validate(); err != nil {
+		return nil, fmt.Errorf("invalid scenario %s: %w", path, err)
+	}
+
+	return scenario, nil
+}
+
+func (s *Scenario) validate() error {
+	if s.Name == "" {
+		return errors.New("the name is required")
+	}
+
+	if len(s.Steps) == 0 {
+		return errors.New("at least one step is required")
+	}
+
+	for i := range s.Steps {
+		if err := s.Steps[i].validate(); err != nil {
+			return fmt.Errorf("step %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// actions returns the names of the actions set on the step.
+func (s *ScenarioStep) actions() []string {
+	actions := []string{}
+
+	for _, action := range []struct {
+		name string
+		set  bool
+	}{
+		{"apply", s.Apply != nil},
+		{"place", s.Place != nil},
+		{"patch", s.Patch != nil},
+		{"enforce", s.Enforce != nil},
+		{"inform", s.Inform != nil},
+		{"expectCompliance", s.ExpectCompliance != nil},
+		{"expectHistory", s.ExpectHistory != nil},
+		{"expectObject", s.ExpectObject != nil},
+		{"cleanup", s.Cleanup != nil},
+	} {
+		if action.set {
+			actions = append(actions, action.name)
+		}
+	}
+
+	return actions
+}
+
+func (s *ScenarioStep) validate() error {
+	if actions := s.actions(); len(actions) != 1 {
+		return fmt.Errorf("exactly one action is required, but found %d: %s", len(actions), actions)
+	}
+
+	switch {
+	case s.Apply != nil:
+		return s.Apply.validate(true)
+	case s.Place != nil:
+		return s.Place.validate()
+	case s.Patch != nil:
+		return s.Patch.validate()
+	case s.Enforce != nil:
+		return s.Enforce.validate()
+	case s.Inform != nil:
+		return s.Inform.validate()
+	case s.ExpectCompliance != nil:
+		return errors.Join(s.ExpectCompliance.validate(), s.ExpectCompliance.StepTimeout.validate())
+	case s.ExpectHistory != nil:
+		return errors.Join(s.ExpectHistory.validate(), s.ExpectHistory.StepTimeout.validate())
+	case s.ExpectObject != nil:
+		return errors.Join(s.ExpectObject.validate(), s.ExpectObject.StepTimeout.validate())
+	default:
+		return s.Cleanup.validate(false)
+	}
+}
+
+func validateTarget(cluster ClusterTarget) error {
+	switch cluster {
+	case "", TargetHub, TargetManaged, TargetHosting:
+		return nil
+	default:
+		return fmt.Errorf("the cluster must be one of hub, managed, or hosting, but got %s", cluster)
+	}
+}
+
+func (s *ManifestStep) validate(requireSource bool) error {
+	sources := len(nonEmpty(s.File, s.PolicyCollection, s.Manifest))
+
+	if sources > 1 {
+		return errors.New("only one of file, policyCollection, and manifest can be set")
+	}
+
+	if sources == 0 && requireSource {
+		return errors.New("one of file, policyCollection, or manifest is required")
+	}
+
+	return validateTarget(s.Cluster)
+}
+
+func (s *PlaceStep) validate() error {
+	if s.Policy == "" {
+		return errors.New("the policy is required")
+	}
+
+	return nil
+}
+
+func (s *PatchStep) validate() error {
+	if s.Resource == "" || s.Name == "" || s.Patch == "" {
+		return errors.New("the resource, name, and patch are required")
+	}
+
+	if _, err := nativePatchType(s.Type); err != nil {
+		return err
+	}
+
+	return validateTarget(s.Cluster)
+}
+
+func (s *RemediationStep) validate() error {
+	if s.Policy == "" {
+		return errors.New("the policy is required")
+	}
+
+	for _, kind := range s.Templates {
+		if _, ok := policyTemplateGVRs[kind]; !ok {
+			return fmt.Errorf("unsupported policy template kind %s", kind)
+		}
+	}
+
+	return nil
+}
+
+func (t StepTimeout) validate() error {
+	if t.TimeoutSeconds < 0 || t.TimeoutMultiplier < 0 {
+		return errors.New("the timeout can't be negative")
+	}
+
+	if t.TimeoutSeconds != 0 && t.TimeoutMultiplier != 0 {
+		return errors.New("only one of timeoutSeconds and timeoutMultiplier can be set")
+	}
+
+	return nil
+}
+
+// seconds returns the timeout in seconds, with the given default timeout.
+func (t StepTimeout) seconds(defaultTimeoutSeconds int) int {
+	switch {
+	case t.TimeoutSeconds > 0:
+		return t.TimeoutSeconds
+	case t.TimeoutMultiplier > 0:
+		return t.TimeoutMultiplier * defaultTimeoutSeconds
+	default:
+		return defaultTimeoutSeconds
+	}
+}
+
+func (s *ExpectComplianceStep) validate() error {
+	if s.Policy == "" {
+		return errors.New("the policy is required")
+	}
+
+	switch s.Compliance {
+	case policiesv1.Compliant, policiesv1.NonCompliant, policiesv1.Pending:
+		return nil
+	default:
+		return fmt.Errorf("the compliance must be one of Compliant, NonCompliant, or Pending, but got %s",
+			s.Compliance)
+	}
+}
+
+func (s *ExpectHistoryStep) validate() error {
+	if s.Policy == "" || s.Message == "" {
+		return errors.New("the policy and message are required")
+	}
+
+	if _, err := regexp.Compile(s.Message); err != nil {
+		return fmt.Errorf("invalid message pattern: %w", err)
+	}
+
+	return nil
+}
+
+func (s *ExpectObjectStep) validate() error {
+	if s.APIVersion == "" || s.Kind == "" || s.Name == "" {
+		return errors.New("the apiVersion, kind, and name are required")
+	}
+
+	if _, err := schema.ParseGroupVersion(s.APIVersion); err != nil {
+		return err
+	}
+
+	return validateTarget(s.Cluster)
+}
+
+// Description returns the name of the step, or a description of its action if it has no name.
+func (s *ScenarioStep) Description() string {
+	if s.Name != "" {
+		return s.Name
+	}
+
+	switch {
+	case s.Apply != nil:
+		return "Apply " + s.Apply.source() + " on the " + string(targetOrDefault(s.Apply.Cluster, TargetHub)) +
+			" cluster"
+	case s.Place != nil:
+		return "Place the policy " + s.Place.Policy
+	case s.Patch != nil:
+		return "Patch " + s.Patch.Resource + " " + s.Patch.Name
+	case s.Enforce != nil:
+		return "Enforce the policy " + s.Enforce.Policy
+	case s.Inform != nil:
+		return "Inform the policy " + s.Inform.Policy
+	case s.ExpectCompliance != nil:
+		description := "The policy " + s.ExpectCompliance.Policy + " should be " +
+			string(s.ExpectCompliance.Compliance)
+		if len(s.ExpectCompliance.Clusters) != 0 {
+			description += " on " + strings.Join(s.ExpectCompliance.Clusters, ", ")
+		}
+
+		return description
+	case s.ExpectHistory != nil:
+		return fmt.Sprintf("The history of template %d of the policy %s should match %q",
+			s.ExpectHistory.Template, s.ExpectHistory.Policy, s.ExpectHistory.Message)
+	case s.ExpectObject != nil:
+		description := "The " + s.ExpectObject.Kind + " " +
+			namespacedName(s.ExpectObject.Namespace, s.ExpectObject.Name)
+		if s.ExpectObject.Exists != nil && !*s.ExpectObject.Exists {
+			return description + " should not exist"
+		}
+
+		return description + " should exist"
+	case s.Cleanup != nil && s.Cleanup.source() != "":
+		return "Clean up " + s.Cleanup.source() + " on the " +
+			string(targetOrDefault(s.Cleanup.Cluster, TargetHub)) + " cluster"
+	default:
+		return "Clean up the applied manifests"
+	}
+}
+
+// source describes where the objects of the manifest come from.
+func (s *ManifestStep) source() string {
+	switch {
+	case s.File != "":
+		return s.File
+	case s.PolicyCollection != "":
+		return s.PolicyCollection
+	case s.Manifest != "":
+		return "the inline manifest"
+	default:
+		return ""
+	}
+}
+
+// sameManifest returns whether the steps are for the same objects.
+func (s *ManifestStep) sameManifest(other *ManifestStep) bool {
+	return targetOrDefault(s.Cluster, TargetHub) == targetOrDefault(other.Cluster, TargetHub) &&
+		s.File == other.File && s.PolicyCollection == other.PolicyCollection && s.Manifest == other.Manifest &&
+		s.Namespace == other.Namespace
+}
+
+// ScenarioRun runs the steps of a Scenario with an Environment, and keeps track of the applied
+// manifests so that they are cleaned up even when a step fails.
+type ScenarioRun struct {
+	env      *Environment
+	scenario *Scenario
+	// applied are the apply steps which were not cleaned up yet.
+	applied []*ManifestStep
+	// placed are the place steps whose placements were not deleted yet.
+	placed []*PlaceStep
+}
+
+// NewScenarioRun returns a ScenarioRun of the scenario.
+func (e *Environment) NewScenarioRun(scenario *Scenario) *ScenarioRun {
+	return &ScenarioRun{env: e, scenario: scenario}
+}
+
+// RunStep runs the step of the scenario at the given index.
+func (r *ScenarioRun) RunStep(ctx SpecContext, idx int) {
+	GinkgoHelper()
+
+	step := &r.scenario.Steps[idx]
+	By(fmt.Sprintf("Running step %d of the scenario %s: %s", idx+1, r.scenario.Name, step.Description()))
+
+	switch {
+	case step.Apply != nil:
+		r.apply(ctx, step.Apply)
+	case step.Place != nil:
+		r.place(ctx, step.Place)
+	case step.Patch != nil:
+		r.patch(ctx, step.Patch)
+	case step.Enforce != nil:
+		r.setRemediationAction(step.Enforce, "enforce")
+	case step.Inform != nil:
+		r.setRemediationAction(step.Inform, "inform")
+	case step.ExpectCompliance != nil:
+		r.expectCompliance(ctx, step.ExpectCompliance)
+	case step.ExpectHistory != nil:
+		r.expectHistory(ctx, step.ExpectHistory)
+	case step.ExpectObject != nil:
+		r.expectObject(ctx, step.ExpectObject)
+	case step.Cleanup != nil:
+		r.cleanup(ctx, step.Cleanup)
+	}
+}
+
+// Cleanup deletes the objects of the apply steps which were not cleaned up by a cleanup step, in
+// reverse order, and the placements of the place steps. DescribeScenario calls it in an AfterAll.
+func (r *ScenarioRun) Cleanup(ctx SpecContext) {
+	GinkgoHelper()
+
+	r.cleanup(ctx, &ManifestStep{})
+}
+
+// manifests reads the objects of the step.
+func (r *ScenarioRun) manifests(step *ManifestStep) ([]*unstructured.Unstructured, error) {
+	switch {
+	case step.Manifest != "":
+		return kubeclient.DecodeManifests("inline manifest", []byte(step.Manifest))
+	case step.PolicyCollection != "":
+		return kubeclient.ReadManifests(fmt.Sprintf(
+			"https://raw.githubusercontent.com/stolostron/policy-collection/%s/%s",
+			r.env.PolicyCollectionBranch, strings.TrimPrefix(step.PolicyCollection, "/"),
+		))
+	case strings.HasPrefix(step.File, "http://") || strings.HasPrefix(step.File, "https://"),
+		filepath.IsAbs(step.File):
+		return kubeclient.ReadManifests(step.File)
+	default:
+		return kubeclient.ReadManifests(filepath.Join(r.scenario.dir, step.File))
+	}
+}
+
+// clusters returns the given managed cluster names, or the default managed cluster if there are none.
+func (r *ScenarioRun) clusters(clusterNames []string) []string {
+	if len(clusterNames) == 0 {
+		return []string{r.env.ClusterNamespaceOnHub}
+	}
+
+	return clusterNames
+}
+
+// wait waits for the object to match the predicate, up to the timeout of the step.
+func (r *ScenarioRun) wait(
+	ctx SpecContext,
+	client dynamic.Interface,
+	gvr schema.GroupVersionResource,
+	namespace, name string,
+	timeout StepTimeout,
+	description string,
+	predicate Predicate,
+) {
+	GinkgoHelper()
+
+	_, err := NewWaiter(client, gvr, namespace, name).Until(
+		ctx, time.Duration(timeout.seconds(r.env.DefaultTimeoutSeconds))*time.Second, description, predicate,
+	)
+	Expect(err).ToNot(HaveOccurred())
+}
+
+// apply applies the objects of the step. On the hub, it also places the policies as configured,
+// and waits for the policies to be replicated to the managed clusters.
+func (r *ScenarioRun) apply(ctx SpecContext, step *ManifestStep) {
+	GinkgoHelper()
+
+	e := r.env
+
+	objects, err := r.manifests(step)
+	Expect(err).ToNot(HaveOccurred())
+
+	applier, namespace := r.env.targetApplier(step.Cluster, step.Namespace)
+	results, err := applier.Apply(ctx, namespace, objects...)
+	// The step is tracked even if it partially failed, so that the applied objects are cleaned up.
+	r.applied = append(r.applied, step)
+
+	Expect(err).ToNot(HaveOccurred())
+	GinkgoWriter.Print(formatResults(results))
+
+	if targetOrDefault(step.Cluster, TargetHub) != TargetHub {
+		return
+	}
+
+	clusterNames := r.clusters(step.Clusters)
+	placed := []kubeclient.ApplyResult{}
+
+	for _, result := range results {
+		switch result.Kind {
+		case "Policy":
+			if step.Placement || slices.ContainsFunc(objects, func(obj *unstructured.Unstructured) bool {
+				return obj.GetKind() == "PlacementBinding" && bindsPolicy(obj, result.Name)
+			}) {
+				placed = append(placed, result)
+			}
+		case "Placement":
+			// The Placements of the manifest are left alone when placing the policies with ApplyPlacement
+			if e.ManuallyPatchDecisions && !step.Placement {
+				Expect(e.setPlacementDecision(ctx, result.Namespace, result.Name, clusterNames)).To(Succeed())
+			}
+		}
+	}
+
+	for _, policy := range placed {
+		if step.Placement {
+			r.applyPlacement(ctx, policy.Namespace, policy.Name, clusterNames)
+		}
+
+		r.waitForReplicas(ctx, policy.Namespace, policy.Name, clusterNames)
+	}
+}
+
+// bindsPolicy returns whether the PlacementBinding has the named Policy as a subject.
+func bindsPolicy(binding *unstructured.Unstructured, policyName string) bool {
+	subjects, _, _ := unstructured.NestedSlice(binding.Object, "subjects")
+
+	for _, subject := range subjects {
+		subjectMap, ok := subject.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		if subjectMap["kind"] == "Policy" && subjectMap["name"] == policyName {
+			return true
+		}
+	}
+
+	return false
+}
+
+// place places the policy of the step, and waits for it to be replicated to the managed clusters.
+func (r *ScenarioRun) place(ctx SpecContext, step *PlaceStep) {
+	GinkgoHelper()
+
+	clusterNames := r.clusters(step.Clusters)
+
+	// The step is tracked even if it partially failed, so that the placement is deleted.
+	r.placed = append(r.placed, step)
+
+	r.applyPlacement(ctx, r.env.UserNamespace, step.Policy, clusterNames)
+	r.waitForReplicas(ctx, r.env.UserNamespace, step.Policy, clusterNames)
+}
+
+// applyPlacement places the policy with ApplyPlacement, and lists the managed clusters in its
+// PlacementDecision when ManuallyPatchDecisions is set.
+func (r *ScenarioRun) applyPlacement(ctx SpecContext, namespace, policyName string, clusterNames []string) {
+	GinkgoHelper()
+
+	e := r.env
+
+	Expect(e.ApplyPlacement(ctx, namespace, policyName)).To(Succeed())
+
+	if e.ManuallyPatchDecisions {
+		Expect(e.setPlacementDecision(ctx, namespace, "placement-"+policyName, clusterNames)).To(Succeed())
+	}
+}
+
+// waitForReplicas waits for the policy to be replicated to the managed clusters.
+func (r *ScenarioRun) waitForReplicas(ctx SpecContext, namespace, policyName string, clusterNames []string) {
+	GinkgoHelper()
+
+	for _, cluster := range r.env.managedClusters(clusterNames) {
+		r.wait(ctx, cluster.ClientHostingDynamic, GvrPolicy, cluster.Namespace,
+			namespace+"."+policyName, StepTimeout{}, "replicated to "+cluster.Name, Exists)
+	}
+}
+
+// cleanup deletes the objects of the step, or of all of the applied steps if it has no manifest.
+// On the hub, it also deletes the placements created for the policies, and waits for the
+// replicated policies to be removed from the managed clusters.
+func (r *ScenarioRun) cleanup(ctx SpecContext, step *ManifestStep) {
+	GinkgoHelper()
+
+	steps := []*ManifestStep{step}
+
+	if step.source() == "" {
+		steps = slices.Clone(r.applied)
+		slices.Reverse(steps)
+	}
+
+	for _, step := range steps {
+		r.delete(ctx, step)
+
+		r.applied = slices.DeleteFunc(r.applied, step.sameManifest)
+	}
+
+	if step.source() != "" {
+		return
+	}
+
+	for len(r.placed) != 0 {
+		placed := r.placed[len(r.placed)-1]
+
+		Expect(r.env.DeletePlacement(r.env.UserNamespace, placed.Policy)).To(Succeed())
+
+		r.placed = r.placed[:len(r.placed)-1]
+	}
+}
+
+func (r *ScenarioRun) delete(ctx SpecContext, step *ManifestStep) {
+	GinkgoHelper()
+
+	e := r.env
+
+	objects, err := r.manifests(step)
+	Expect(err).ToNot(HaveOccurred())
+
+	applier, namespace := r.env.targetApplier(step.Cluster, step.Namespace)
+	results, err := applier.DeleteObjects(ctx, namespace, true, objects...)
+	Expect(err).ToNot(HaveOccurred())
+	GinkgoWriter.Print(formatResults(results))
+
+	if targetOrDefault(step.Cluster, TargetHub) != TargetHub {
+		return
+	}
+
+	for _, result := range results {
+		if result.Kind != "Policy" {
+			continue
+		}
+
+		if step.Placement {
+			Expect(e.DeletePlacement(result.Namespace, result.Name)).To(Succeed())
+		}
+
+		for _, cluster := range e.managedClusters(r.clusters(step.Clusters)) {
+			r.wait(ctx, cluster.ClientHostingDynamic, GvrPolicy, cluster.Namespace,
+				result.Namespace+"."+result.Name, StepTimeout{}, "removed from "+cluster.Name, NotFound)
+		}
+	}
+}
+
+func (r *ScenarioRun) patch(ctx SpecContext, step *PatchStep) {
+	GinkgoHelper()
+
+	patchType, err := nativePatchType(step.Type)
+	Expect(err).ToNot(HaveOccurred())
+
+	// Like kubectl, accept the patch in either JSON or YAML
+	patch, err := k8syaml.ToJSON([]byte(step.Patch))
+	Expect(err).ToNot(HaveOccurred())
+
+	applier, namespace := r.env.targetApplier(step.Cluster, step.Namespace)
+	_, err = applier.Patch(ctx, step.Resource, namespace, step.Name, patchType, patch)
+	Expect(err).ToNot(HaveOccurred())
+}
+
+func (r *ScenarioRun) setRemediationAction(step *RemediationStep, remediationAction string) {
+	GinkgoHelper()
+
+	templateGVRs := make([]schema.GroupVersionResource, 0, len(step.Templates))
+
+	for _, kind := range step.Templates {
+		templateGVRs = append(templateGVRs, policyTemplateGVRs[kind])
+	}
+
+	r.env.setRemediationAction(step.Policy, remediationAction, r.clusters(step.Clusters), templateGVRs...)
+}
+
+func (r *ScenarioRun) expectCompliance(ctx SpecContext, step *ExpectComplianceStep) {
+	GinkgoHelper()
+
+	e := r.env
+	description := "compliant is " + string(step.Compliance)
+
+	if len(step.Clusters) == 0 {
+		r.wait(ctx, e.ClientHubDynamic, GvrPolicy, e.UserNamespace, step.Policy, step.StepTimeout,
+			description, ComplianceIs(step.Compliance))
+
+		return
+	}
+
+	for _, cluster := range e.managedClusters(step.Clusters) {
+		r.wait(ctx, e.ClientHubDynamic, GvrPolicy, e.UserNamespace, step.Policy, step.StepTimeout,
+			"status of "+cluster.Name+" is "+string(step.Compliance),
+			ClusterComplianceIs(cluster.Name, step.Compliance))
+		r.wait(ctx, cluster.ClientHostingDynamic, GvrPolicy, cluster.Namespace, e.UserNamespace+"."+step.Policy,
+			step.StepTimeout, description, ComplianceIs(step.Compliance))
+	}
+}
+
+func (r *ScenarioRun) expectHistory(ctx SpecContext, step *ExpectHistoryStep) {
+	GinkgoHelper()
+
+	e := r.env
+	pattern := regexp.MustCompile(step.Message)
+	cluster := e.managedClusters(r.clusters(nonEmpty(step.Cluster)))[0]
+
+	r.wait(ctx, cluster.ClientHostingDynamic, GvrPolicy, cluster.Namespace, e.UserNamespace+"."+step.Policy,
+		step.StepTimeout, fmt.Sprintf("latest history message of template %d matches %q", step.Template,
+			step.Message),
+		func(obj *unstructured.Unstructured) bool {
+			if obj == nil {
+				return false
+			}
+
+			policy, err := PolicyFromUnstructured(obj)
+			if err != nil {
+				return false
+			}
+
+			message, err := LatestHistoryMessage(policy, step.Template)
+
+			return err == nil && pattern.MatchString(message)
+		},
+	)
+}
+
+func (r *ScenarioRun) expectObject(ctx SpecContext, step *ExpectObjectStep) {
+	GinkgoHelper()
+
+	cluster := targetOrDefault(step.Cluster, TargetManaged)
+	client, dynamicClient := r.env.targetClients(cluster)
+	gvk := schema.FromAPIVersionAndKind(step.APIVersion, step.Kind)
+
+	mapping, err := r.env.applierFor(client, dynamicClient).RESTMapping(gvk)
+	Expect(err).ToNot(HaveOccurred())
+
+	namespace := ""
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		namespace = step.Namespace
+		if namespace == "" {
+			namespace = metav1.NamespaceDefault
+
+			if cluster == TargetHub {
+				namespace = r.env.UserNamespace
+			}
+		}
+	}
+
+	if step.Exists != nil && !*step.Exists {
+		r.wait(ctx, dynamicClient, mapping.Resource, namespace, step.Name, step.StepTimeout,
+			"does not exist", NotFound)
+
+		return
+	}
+
+	r.wait(ctx, dynamicClient, mapping.Resource, namespace, step.Name, step.StepTimeout,
+		"exists with the expected fields",
+		func(obj *unstructured.Unstructured) bool {
+			return obj != nil && matchesFields(obj.Object, step.Fields)
+		},
+	)
+}
+
+// matchesFields returns whether the actual value contains the expected value. Maps match when
+// each expected key matches, and lists match when each expected item matches any actual item.
+// Other values are compared by their string representation, since numbers decoded from YAML and
+// from the API server have different types.
+func matchesFields(actual, expected any) bool {
+	switch expected := expected.(type) {
+	case map[string]any:
+		actualMap, ok := actual.(map[string]any)
+		if !ok {
+			return false
+		}
+
+		for key, value := range expected {
+			actualValue, found := actualMap[key]
+			if !found || !matchesFields(actualValue, value) {
+				return false
+			}
+		}
+
+		return true
+	case []any:
+		actualList, ok := actual.([]any)
+		if !ok {
+			return false
+		}
+
+		for _, item := range expected {
+			if !slices.ContainsFunc(actualList, func(actualItem any) bool {
+				return matchesFields(actualItem, item)
+			}) {
+				return false
+			}
+		}
+
+		return true
+	default:
+		return fmt.Sprint(actual) == fmt.Sprint(expected)
+	}
+}
+
+// DescribeScenario loads the scenario file, and adds it to the spec tree as an Ordered container
+// with the labels of the scenario and a spec per step. The objects applied by the scenario are
+// cleaned up in an AfterAll. A scenario which fails to load is added as a failing spec.
+func (e *Environment) DescribeScenario(path string) bool {
+	scenario, err := LoadScenario(path)
+	if err != nil {
+		return It("Loads the scenario "+path, func() {
+			Fail(err.Error())
+		})
+	}
+
+	run := e.NewScenarioRun(scenario)
+
+	return Describe(scenario.Name, Ordered, Label(scenario.Labels...), func() {
+		for i := range scenario.Steps {
+			It(scenario.Steps[i].Description(), func(ctx SpecContext) {
+				run.RunStep(ctx, i)
+			})
+		}
+
+		AfterAll(run.Cleanup)
+	})
+}
+
+// DescribeScenarios calls DescribeScenario for each scenario file matching the glob pattern.
+func (e *Environment) DescribeScenarios(pattern string) bool {
+	paths, err := filepath.Glob(pattern)
+	if err == nil && len(paths) == 0 {
+		err = fmt.Errorf("no scenario files match %s", pattern)
+	}
+
+	if err != nil {
+		return It("Loads the scenarios "+pattern, func() {
+			Fail(err.Error())
+		})
+	}
+
+	for _, path := range paths {
+		e.DescribeScenario(path)
+	}
+
+	return true
+}
@@ -0,0 +1,290 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package common
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	policiesv1 "open-cluster-management.io/governance-policy-propagator/api/v1"
+)
+
+const fakeScenarioFile = "testdata/fake-scenario.yaml"
+
+var _ = Describe("Test the scenario runner", func() {
+	var backend *FakeBackend
+	var env *Environment
+
+	BeforeEach(func() {
+		backend = startFakeBackend()
+		env = backend.Env
+	})
+
+	It("Runs the steps of the scenario against the helpers", func(ctx SpecContext) {
+		scenario, err := LoadScenario(fakeScenarioFile)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(scenario.Name).To(Equal("Test the fake-policy scenario"))
+		Expect(scenario.Steps).To(HaveLen(7))
+
+		run := env.NewScenarioRun(scenario)
+
+		By("Applying the policy")
+		run.RunStep(ctx, 0)
+
+		_, err = env.ClientManagedDynamic.Resource(GvrPolicy).Namespace(env.ClusterNamespace).Get(
+			ctx, env.UserNamespace+"."+fakePolicyName, metav1.GetOptions{},
+		)
+		Expect(err).ToNot(HaveOccurred())
+
+		err = backend.Propagator.SetTemplateStatus(
+			ctx, env.ClusterNamespaceOnHub, env.UserNamespace, fakePolicyName, 0, policiesv1.NonCompliant,
+			"NonCompliant; violation",
+		)
+		Expect(err).ToNot(HaveOccurred())
+
+		for i := 1; i < len(scenario.Steps)-1; i++ {
+			By(scenario.Steps[i].Description())
+			run.RunStep(ctx, i)
+		}
+
+		rootPolicy, err := env.ClientHubDynamic.Resource(GvrPolicy).Namespace(env.UserNamespace).Get(
+			ctx, fakePolicyName, metav1.GetOptions{},
+		)
+		Expect(err).ToNot(HaveOccurred())
+
+		disabled, _, _ := unstructured.NestedBool(rootPolicy.Object, "spec", "disabled")
+		Expect(disabled).To(BeTrue())
+
+		By("Cleaning up the applied manifests")
+		run.RunStep(ctx, len(scenario.Steps)-1)
+
+		_, err = env.ClientHubDynamic.Resource(GvrPlacement).Namespace(env.UserNamespace).Get(
+			ctx, "fake-policy-plr", metav1.GetOptions{},
+		)
+		Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+
+		_, err = env.ClientManagedDynamic.Resource(GvrPolicy).Namespace(env.ClusterNamespace).Get(
+			ctx, env.UserNamespace+"."+fakePolicyName, metav1.GetOptions{},
+		)
+		Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("Cleans up the applied manifests when the scenario didn't", func(ctx SpecContext) {
+		scenario, err := LoadScenario(fakeScenarioFile)
+		Expect(err).ToNot(HaveOccurred())
+
+		run := env.NewScenarioRun(scenario)
+		run.RunStep(ctx, 0)
+		run.Cleanup(ctx)
+
+		_, err = env.ClientHubDynamic.Resource(GvrPolicy).Namespace(env.UserNamespace).Get(
+			ctx, fakePolicyName, metav1.GetOptions{},
+		)
+		Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+		Expect(run.applied).To(BeEmpty())
+	})
+
+	It("Places the policies and checks objects on the managed cluster", func(ctx SpecContext) {
+		dir := GinkgoT().TempDir()
+		scenarioFile := filepath.Join(dir, "scenario.yaml")
+
+		err := os.WriteFile(scenarioFile, []byte(`
+name: Test the placement
+steps:
+  - apply:
+      manifest: |
+        apiVersion: policy.open-cluster-management.io/v1
+        kind: Policy
+        metadata:
+          name: placed-policy
+        spec:
+          remediationAction: inform
+          policy-templates: []
+      placement: true
+  - apply:
+      cluster: managed
+      manifest: &configmap |
+        apiVersion: v1
+        kind: ConfigMap
+        metadata:
+          name: my-config
+        data:
+          city: Raleigh
+  - expectObject:
+      apiVersion: v1
+      kind: ConfigMap
+      name: my-config
+      fields:
+        data:
+          city: Raleigh
+  - cleanup:
+      cluster: managed
+      manifest: *configmap
+  - expectObject:
+      apiVersion: v1
+      kind: ConfigMap
+      name: my-config
+      exists: false
+`), 0o600)
+		Expect(err).ToNot(HaveOccurred())
+
+		scenario, err := LoadScenario(scenarioFile)
+		Expect(err).ToNot(HaveOccurred())
+
+		run := env.NewScenarioRun(scenario)
+
+		for i := range scenario.Steps {
+			run.RunStep(ctx, i)
+		}
+
+		_, err = env.ClientHubDynamic.Resource(GvrPlacement).Namespace(env.UserNamespace).Get(
+			ctx, "placement-placed-policy", metav1.GetOptions{},
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(run.applied).To(HaveLen(1))
+
+		run.Cleanup(ctx)
+
+		_, err = env.ClientHubDynamic.Resource(GvrPlacement).Namespace(env.UserNamespace).Get(
+			ctx, "placement-placed-policy", metav1.GetOptions{},
+		)
+		Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("Places a policy patched after it was applied", func(ctx SpecContext) {
+		scenarioFile := filepath.Join(GinkgoT().TempDir(), "scenario.yaml")
+
+		err := os.WriteFile(scenarioFile, []byte(`
+name: Test the place step
+steps:
+  - apply:
+      manifest: |
+        apiVersion: policy.open-cluster-management.io/v1
+        kind: Policy
+        metadata:
+          name: patched-policy
+        spec:
+          remediationAction: inform
+          policy-templates: []
+  - patch:
+      resource: policy
+      name: patched-policy
+      type: merge
+      patch: '{"spec": {"remediationAction": "enforce"}}'
+  - place:
+      policy: patched-policy
+  - expectObject:
+      cluster: hub
+      apiVersion: policy.open-cluster-management.io/v1
+      kind: Policy
+      name: patched-policy
+      fields:
+        spec:
+          remediationAction: enforce
+      timeoutMultiplier: 2
+`), 0o600)
+		Expect(err).ToNot(HaveOccurred())
+
+		scenario, err := LoadScenario(scenarioFile)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(scenario.Steps[2].Description()).To(Equal("Place the policy patched-policy"))
+		Expect(scenario.Steps[3].ExpectObject.seconds(env.DefaultTimeoutSeconds)).To(
+			Equal(env.DefaultTimeoutSeconds * 2),
+		)
+
+		run := env.NewScenarioRun(scenario)
+
+		for i := range scenario.Steps {
+			run.RunStep(ctx, i)
+		}
+
+		_, err = env.ClientManagedDynamic.Resource(GvrPolicy).Namespace(env.ClusterNamespace).Get(
+			ctx, env.UserNamespace+".patched-policy", metav1.GetOptions{},
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(run.placed).To(HaveLen(1))
+
+		run.Cleanup(ctx)
+
+		_, err = env.ClientHubDynamic.Resource(GvrPlacement).Namespace(env.UserNamespace).Get(
+			ctx, "placement-patched-policy", metav1.GetOptions{},
+		)
+		Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+		Expect(run.placed).To(BeEmpty())
+	})
+
+	DescribeTable("Rejects invalid scenarios",
+		func(content, expected string) {
+			scenarioFile := filepath.Join(GinkgoT().TempDir(), "scenario.yaml")
+			Expect(os.WriteFile(scenarioFile, []byte(content), 0o600)).To(Succeed())
+
+			_, err := LoadScenario(scenarioFile)
+			Expect(err).To(MatchError(ContainSubstring(expected)))
+		},
+		Entry("without a name", "steps: [{cleanup: {}}]", "the name is required"),
+		Entry("without steps", "name: test", "at least one step is required"),
+		Entry("with an unknown field", "name: test\nstep: []", `unknown field "step"`),
+		Entry("with two actions",
+			"name: test\nsteps: [{cleanup: {}, enforce: {policy: p}}]",
+			"step 1: exactly one action is required, but found 2: [enforce cleanup]",
+		),
+		Entry("with an apply step without a manifest",
+			"name: test\nsteps: [{apply: {}}]", "step 1: one of file, policyCollection, or manifest is required",
+		),
+		Entry("with an unknown cluster",
+			"name: test\nsteps: [{apply: {file: a.yaml, cluster: spoke}}]", "but got spoke",
+		),
+		Entry("with an unknown compliance",
+			"name: test\nsteps: [{expectCompliance: {policy: p, compliance: Unknown}}]",
+			"the compliance must be one of Compliant, NonCompliant, or Pending, but got Unknown",
+		),
+		Entry("with an invalid message pattern",
+			"name: test\nsteps: [{expectHistory: {policy: p, message: '('}}]", "invalid message pattern",
+		),
+		Entry("with a place step without a policy",
+			"name: test\nsteps: [{place: {}}]", "step 1: the policy is required",
+		),
+		Entry("with two timeouts",
+			"name: test\nsteps: [{expectObject: {apiVersion: v1, kind: Pod, name: p, timeoutSeconds: 60, "+
+				"timeoutMultiplier: 2}}]",
+			"step 1: only one of timeoutSeconds and timeoutMultiplier can be set",
+		),
+		Entry("with an unknown template kind",
+			"name: test\nsteps: [{inform: {policy: p, templates: [GatekeeperPolicy]}}]",
+			"unsupported policy template kind GatekeeperPolicy",
+		),
+	)
+})
+
+var _ = Describe("Test matchesFields", func() {
+	actual := map[string]any{
+		"spec": map[string]any{
+			"replicas": int64(2),
+			"items": []any{
+				map[string]any{"name": "a", "value": "1"},
+				map[string]any{"name": "b", "value": "2"},
+			},
+		},
+	}
+
+	DescribeTable("Matches the expected fields",
+		func(expected map[string]any, matches bool) {
+			Expect(matchesFields(actual, expected)).To(Equal(matches))
+		},
+		Entry("with no fields", map[string]any{}, true),
+		Entry("with a number decoded from YAML", map[string]any{"spec": map[string]any{"replicas": 2.0}}, true),
+		Entry("with list items in any order", map[string]any{"spec": map[string]any{"items": []any{
+			map[string]any{"name": "b"}, map[string]any{"name": "a", "value": "1"},
+		}}}, true),
+		Entry("with a different value", map[string]any{"spec": map[string]any{"replicas": 3}}, false),
+		Entry("with a missing list item", map[string]any{"spec": map[string]any{"items": []any{
+			map[string]any{"name": "c"},
+		}}}, false),
+		Entry("with a missing field", map[string]any{"status": map[string]any{}}, false),
+	)
+})
@@ -0,0 +1,455 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package common
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog"
+)
+
+// timelineHub is the cluster name of the timeline entries recorded on the hub.
+const timelineHub = "hub"
+
+// parentPolicyLabel is the label of the policy templates with the name of their replicated policy.
+const parentPolicyLabel = "policy.open-cluster-management.io/policy"
+
+// policyKinds are the kinds of the objects whose events are recorded by a ComplianceTimeline.
+var policyKinds = []string{"Policy", "ConfigurationPolicy", "OperatorPolicy", "CertificatePolicy"}
+
+// TimelineEntry is a change to a policy observed by a ComplianceTimeline.
+type TimelineEntry struct {
+	Time time.Time
+	// Cluster is "hub" for the root policies, or the name of the managed cluster.
+	Cluster string
+	// Object is the kind and namespaced name of the policy, prefixed with "Event" for its events.
+	Object  string
+	Message string
+}
+
+// timelineSource is a namespace watched by a ComplianceTimeline.
+type timelineSource struct {
+	cluster string
+	// resource is the name of the watched resource, like policies or events.
+	resource string
+	watch    func(ctx context.Context) (watch.Interface, error)
+	list     func(ctx context.Context) ([]runtime.Object, error)
+}
+
+// ComplianceTimeline records every compliance transition, history message, and event of the
+// policies touched by a spec, on the hub and on each managed cluster, so that the order in which
+// they happened can be reviewed when the spec fails.
+type ComplianceTimeline struct {
+	env         *Environment
+	policyNames []string
+	start       time.Time
+	entries     []TimelineEntry
+	// states are the last recorded states, keyed by cluster and object
+	states map[string]string
+	lock   sync.Mutex
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewComplianceTimeline returns a ComplianceTimeline for the root policies of the given names, and
+// their replicated policies and policy templates. All of the policies are recorded when no names
+// are given.
+func (e *Environment) NewComplianceTimeline(policyNames ...string) *ComplianceTimeline {
+	return &ComplianceTimeline{env: e, policyNames: policyNames, states: map[string]string{}}
+}
+
+// RecordComplianceTimeline starts a ComplianceTimeline for the given policies, which is stopped at
+// the end of the current spec, or container for BeforeAll, and printed in the ginkgo logs only if
+// the spec fails.
+func (e *Environment) RecordComplianceTimeline(policyNames ...string) *ComplianceTimeline {
+	GinkgoHelper()
+
+	timeline := e.NewComplianceTimeline(policyNames...)
+	Expect(timeline.Start(context.Background())).To(Succeed())
+
+	DeferCleanup(func() {
+		timeline.Stop()
+
+		if CurrentSpecReport().Failed() {
+			GinkgoWriter.Println(timeline.String())
+		}
+	})
+
+	return timeline
+}
+
+// Start watches the policies and their events until Stop is called or the context is canceled.
+// The current state of the policies is recorded before it returns.
+func (t *ComplianceTimeline) Start(ctx context.Context) error {
+	ctx, t.cancel = context.WithCancel(ctx)
+	t.start = time.Now()
+
+	for _, source := range t.sources() {
+		watcher, err := t.startSource(ctx, source)
+		if err != nil {
+			t.Stop()
+
+			return err
+		}
+
+		if watcher == nil {
+			continue
+		}
+
+		t.wg.Add(1)
+
+		go t.follow(ctx, source, watcher)
+	}
+
+	return nil
+}
+
+// Stop stops the watches of the ComplianceTimeline and waits for them to finish.
+func (t *ComplianceTimeline) Stop() {
+	if t.cancel != nil {
+		t.cancel()
+	}
+
+	t.wg.Wait()
+}
+
+// Entries returns the entries recorded so far, in the order they were observed.
+func (t *ComplianceTimeline) Entries() []TimelineEntry {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return slices.Clone(t.entries)
+}
+
+// String formats the ComplianceTimeline as a table with one entry per line.
+func (t *ComplianceTimeline) String() string {
+	entries := t.Entries()
+
+	policies := "all policies"
+	if len(t.policyNames) != 0 {
+		policies = "policies " + strings.Join(t.policyNames, ", ")
+	}
+
+	var out strings.Builder
+
+	fmt.Fprintf(&out, "Compliance timeline of %s since %s (%d entries):\n",
+		policies, t.start.Format("15:04:05.000"), len(entries))
+
+	writer := tabwriter.NewWriter(&out, 0, 0, 2, ' ', 0)
+
+	for _, entry := range entries {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n",
+			entry.Time.Format("15:04:05.000"), entry.Cluster, entry.Object, entry.Message)
+	}
+
+	_ = writer.Flush()
+
+	return out.String()
+}
+
+// sources returns the root policies and their events on the hub, and the replicated policies,
+// policy templates and their events on each managed cluster.
+func (t *ComplianceTimeline) sources() []timelineSource {
+	sources := []timelineSource{
+		dynamicTimelineSource(timelineHub, t.env.ClientHubDynamic, GvrPolicy, t.env.UserNamespace),
+		eventTimelineSource(timelineHub, t.env.ClientHub, t.env.UserNamespace),
+	}
+
+	for _, clusterName := range t.env.ManagedClusterNames() {
+		cluster := t.env.GetManagedCluster(clusterName)
+
+		for _, gvr := range []schema.GroupVersionResource{
+			GvrPolicy, GvrConfigurationPolicy, GvrOperatorPolicy, GvrCertPolicy,
+		} {
+			sources = append(sources,
+				dynamicTimelineSource(clusterName, cluster.ClientHostingDynamic, gvr, cluster.Namespace))
+		}
+
+		sources = append(sources, eventTimelineSource(clusterName, cluster.ClientHosting, cluster.Namespace))
+	}
+
+	return sources
+}
+
+func dynamicTimelineSource(
+	cluster string, client dynamic.Interface, gvr schema.GroupVersionResource, namespace string,
+) timelineSource {
+	resource := client.Resource(gvr).Namespace(namespace)
+
+	return timelineSource{
+		cluster:  cluster,
+		resource: gvr.Resource,
+		watch: func(ctx context.Context) (watch.Interface, error) {
+			return resource.Watch(ctx, metav1.ListOptions{})
+		},
+		list: func(ctx context.Context) ([]runtime.Object, error) {
+			list, err := resource.List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return nil, err
+			}
+
+			objects := make([]runtime.Object, 0, len(list.Items))
+			for i := range list.Items {
+				objects = append(objects, &list.Items[i])
+			}
+
+			return objects, nil
+		},
+	}
+}
+
+func eventTimelineSource(cluster string, client kubernetes.Interface, namespace string) timelineSource {
+	events := client.CoreV1().Events(namespace)
+
+	return timelineSource{
+		cluster:  cluster,
+		resource: "events",
+		watch: func(ctx context.Context) (watch.Interface, error) {
+			return events.Watch(ctx, metav1.ListOptions{})
+		},
+		list: func(ctx context.Context) ([]runtime.Object, error) {
+			list, err := events.List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return nil, err
+			}
+
+			objects := make([]runtime.Object, 0, len(list.Items))
+			for i := range list.Items {
+				objects = append(objects, &list.Items[i])
+			}
+
+			return objects, nil
+		},
+	}
+}
+
+// startSource starts a watch on the source, then records the current state of its objects. The
+// watch is started first so that no change between the two can be missed. A nil watch is returned
+// when the resource isn't installed on the cluster, like the CertificatePolicy CRD in upstream runs,
+// so that the source is skipped.
+func (t *ComplianceTimeline) startSource(ctx context.Context, source timelineSource) (watch.Interface, error) {
+	watcher, err := source.watch(ctx)
+	if isMissingResource(err) {
+		klog.Infof("Skipping the %s of the compliance timeline on %s: %v", source.resource, source.cluster, err)
+
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf(
+			"failed to watch the %s for the compliance timeline on %s: %w", source.resource, source.cluster, err,
+		)
+	}
+
+	objects, err := source.list(ctx)
+	if err != nil {
+		watcher.Stop()
+
+		if isMissingResource(err) {
+			klog.Infof("Skipping the %s of the compliance timeline on %s: %v", source.resource, source.cluster, err)
+
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf(
+			"failed to list the %s for the compliance timeline on %s: %w", source.resource, source.cluster, err,
+		)
+	}
+
+	for _, obj := range objects {
+		t.record(source.cluster, watch.Added, obj)
+	}
+
+	return watcher, nil
+}
+
+// follow records the changes reported by the watch, and restarts it whenever it is closed, until
+// the context is canceled.
+func (t *ComplianceTimeline) follow(ctx context.Context, source timelineSource, watcher watch.Interface) {
+	defer t.wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			watcher.Stop()
+
+			return
+		case event, ok := <-watcher.ResultChan():
+			if ok {
+				if event.Type != watch.Error && event.Type != watch.Bookmark {
+					t.record(source.cluster, event.Type, event.Object)
+				}
+
+				continue
+			}
+		}
+
+		watcher.Stop()
+
+		for {
+			var err error
+
+			watcher, err = t.startSource(ctx, source)
+			if err == nil && watcher == nil {
+				// The resource was uninstalled from the cluster.
+				return
+			}
+
+			if err == nil {
+				break
+			}
+
+			if ctx.Err() != nil {
+				return
+			}
+
+			klog.V(2).Infof("Retrying: %v", err)
+
+			select {
+			case <-ctx.Done():
+			case <-time.After(waitRetryInterval):
+			}
+		}
+	}
+}
+
+func (t *ComplianceTimeline) record(cluster string, eventType watch.EventType, obj runtime.Object) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	switch obj := obj.(type) {
+	case *unstructured.Unstructured:
+		t.recordPolicy(cluster, eventType, obj)
+	case *corev1.Event:
+		t.recordEvent(cluster, eventType, obj)
+	}
+}
+
+func (t *ComplianceTimeline) recordPolicy(cluster string, eventType watch.EventType, obj *unstructured.Unstructured) {
+	if !t.tracksPolicy(obj) {
+		return
+	}
+
+	object := obj.GetKind() + " " + namespacedName(obj.GetNamespace(), obj.GetName())
+	key := cluster + " " + object
+
+	state := describeCompliance(obj)
+	if eventType == watch.Deleted {
+		state = "deleted"
+	}
+
+	if t.states[key] != state {
+		t.states[key] = state
+		t.add(cluster, object, state)
+	}
+
+	if obj.GetKind() != "Policy" || eventType == watch.Deleted {
+		return
+	}
+
+	policy, err := PolicyFromUnstructured(obj)
+	if err != nil {
+		return
+	}
+
+	for i, details := range policy.Status.Details {
+		if details == nil {
+			continue
+		}
+
+		history := details.History
+
+		// The history is newest first, so go through it backwards to record it in order
+		for j := len(history) - 1; j >= 0; j-- {
+			historyKey := fmt.Sprintf("%s history[%d] %s %s", key, i, history[j].LastTimestamp.UTC(),
+				history[j].Message)
+			if _, recorded := t.states[historyKey]; recorded {
+				continue
+			}
+
+			t.states[historyKey] = ""
+			t.add(cluster, object, fmt.Sprintf("template[%d] history %s %s", i,
+				history[j].LastTimestamp.Format(time.TimeOnly), history[j].Message))
+		}
+	}
+}
+
+func (t *ComplianceTimeline) recordEvent(cluster string, eventType watch.EventType, event *corev1.Event) {
+	if eventType == watch.Deleted || !slices.Contains(policyKinds, event.InvolvedObject.Kind) ||
+		!t.tracksName(event.InvolvedObject.Name) {
+		return
+	}
+
+	// Skip the events left over from before the timeline was started
+	lastSeen := event.LastTimestamp.Time
+	if event.EventTime.After(lastSeen) {
+		lastSeen = event.EventTime.Time
+	}
+
+	if lastSeen.Before(t.start.Truncate(time.Second)) {
+		return
+	}
+
+	object := "Event " + event.InvolvedObject.Kind + " " +
+		namespacedName(event.InvolvedObject.Namespace, event.InvolvedObject.Name)
+	key := cluster + " event " + namespacedName(event.Namespace, event.Name)
+	state := fmt.Sprintf("%s %s: %s", event.Type, event.Reason, event.Message)
+
+	if event.Count > 1 {
+		state += fmt.Sprintf(" (x%d)", event.Count)
+	}
+
+	if t.states[key] != state {
+		t.states[key] = state
+		t.add(cluster, object, state)
+	}
+}
+
+func (t *ComplianceTimeline) add(cluster, object, message string) {
+	t.entries = append(t.entries, TimelineEntry{
+		Time: time.Now(), Cluster: cluster, Object: object, Message: message,
+	})
+}
+
+// tracksPolicy returns whether the object is one of the root policies recorded by the timeline,
+// or one of their replicated policies or policy templates.
+func (t *ComplianceTimeline) tracksPolicy(obj *unstructured.Unstructured) bool {
+	if parent, ok := obj.GetLabels()[parentPolicyLabel]; ok {
+		return t.tracksName(parent)
+	}
+
+	return t.tracksName(obj.GetName())
+}
+
+// tracksName returns whether the name is one of the root policies recorded by the timeline, or the
+// `<namespace>.<name>` name of one of their replicated policies.
+func (t *ComplianceTimeline) tracksName(name string) bool {
+	if len(t.policyNames) == 0 {
+		return true
+	}
+
+	return slices.Contains(t.policyNames, strings.TrimPrefix(name, t.env.UserNamespace+"."))
+}
+
+// isMissingResource returns whether the error is because the resource isn't served by the cluster.
+func isMissingResource(err error) bool {
+	return k8serrors.IsNotFound(err) || meta.IsNoMatchError(err)
+}
@@ -0,0 +1,145 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package common
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	clienttesting "k8s.io/client-go/testing"
+	policiesv1 "open-cluster-management.io/governance-policy-propagator/api/v1"
+)
+
+var _ = Describe("Test the compliance timeline", func() {
+	var backend *FakeBackend
+	var env *Environment
+
+	BeforeEach(func() {
+		backend = startFakeBackend()
+		env = backend.Env
+	})
+
+	It("Records the compliance transitions, history and events of the policy in order", func(ctx SpecContext) {
+		timeline := env.NewComplianceTimeline(fakePolicyName)
+		Expect(timeline.Start(ctx)).To(Succeed())
+		DeferCleanup(timeline.Stop)
+
+		env.DoCreatePolicyTest(ctx, fakePolicyFile, GvrConfigurationPolicy)
+
+		for _, compliance := range []policiesv1.ComplianceState{policiesv1.NonCompliant, policiesv1.Compliant} {
+			err := backend.Propagator.SetTemplateStatus(
+				ctx, env.ClusterNamespaceOnHub, env.UserNamespace, fakePolicyName, 0, compliance,
+				string(compliance)+"; from the test",
+			)
+			Expect(err).ToNot(HaveOccurred())
+
+			env.DoRootComplianceTest(fakePolicyName, compliance)
+		}
+
+		rootPolicy := "Policy policy-test/fake-policy"
+		replicatedPolicy := "Policy local-cluster/policy-test.fake-policy"
+
+		rootPolicyMessages := func() []string {
+			messages := []string{}
+
+			for _, entry := range timeline.Entries() {
+				if entry.Object == rootPolicy || entry.Object == "Event "+rootPolicy {
+					messages = append(messages, entry.Cluster+" "+entry.Object+": "+entry.Message)
+				}
+			}
+
+			return messages
+		}
+
+		expected := []string{
+			"hub Policy policy-test/fake-policy: compliant=(none)",
+			"hub Policy policy-test/fake-policy: compliant=(none) clusters=[local-cluster=(none)]",
+			"hub Policy policy-test/fake-policy: compliant=NonCompliant clusters=[local-cluster=NonCompliant]",
+			"hub Policy policy-test/fake-policy: compliant=Compliant clusters=[local-cluster=Compliant]",
+		}
+		Eventually(rootPolicyMessages, 5, 0.1).Should(Equal(expected))
+
+		_, err := env.ClientHub.CoreV1().Events(env.UserNamespace).Create(ctx, &corev1.Event{
+			ObjectMeta: metav1.ObjectMeta{Name: "fake-policy.1", Namespace: env.UserNamespace},
+			InvolvedObject: corev1.ObjectReference{
+				Kind: "Policy", Namespace: env.UserNamespace, Name: fakePolicyName,
+			},
+			Reason:        "PolicyPropagation",
+			Message:       "Policy policy-test/fake-policy was propagated to cluster local-cluster",
+			Type:          corev1.EventTypeNormal,
+			LastTimestamp: metav1.Now(),
+		}, metav1.CreateOptions{})
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = env.ClientHub.CoreV1().Events(env.UserNamespace).Create(ctx, &corev1.Event{
+			ObjectMeta: metav1.ObjectMeta{Name: "other-policy.1", Namespace: env.UserNamespace},
+			InvolvedObject: corev1.ObjectReference{
+				Kind: "Policy", Namespace: env.UserNamespace, Name: "other-policy",
+			},
+			Reason:        "PolicyPropagation",
+			LastTimestamp: metav1.Now(),
+		}, metav1.CreateOptions{})
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(rootPolicyMessages, 5, 0.1).Should(Equal(append(expected,
+			"hub Event Policy policy-test/fake-policy: Normal PolicyPropagation: "+
+				"Policy policy-test/fake-policy was propagated to cluster local-cluster",
+		)))
+
+		Eventually(func(g Gomega) {
+			history := []string{}
+
+			for _, entry := range timeline.Entries() {
+				g.Expect(entry.Object).ToNot(ContainSubstring("other-policy"))
+
+				if entry.Object == replicatedPolicy && entry.Cluster == env.ClusterNamespaceOnHub {
+					history = append(history, entry.Message)
+				}
+			}
+
+			g.Expect(history).To(ContainElements(
+				MatchRegexp(`^template\[0\] history \S+ NonCompliant; from the test$`),
+				MatchRegexp(`^template\[0\] history \S+ Compliant; from the test$`),
+			))
+		}, 5, 0.1).Should(Succeed())
+
+		Expect(timeline.String()).To(MatchRegexp(
+			`(?m)^Compliance timeline of policies fake-policy since \S+ \(\d+ entries\):\n` +
+				`\S+ +hub +Policy policy-test/fake-policy +compliant=\(none\)$`,
+		))
+	})
+
+	// failWatches makes the watches of the resource on the managed cluster fail with the error.
+	failWatches := func(resource string, err error) {
+		backend.Managed.ClientDynamic.PrependWatchReactor(resource,
+			func(clienttesting.Action) (bool, watch.Interface, error) {
+				return true, nil, err
+			},
+		)
+	}
+
+	It("Skips the policy kinds which aren't installed", func(ctx SpecContext) {
+		failWatches("certificatepolicies", k8serrors.NewNotFound(GvrCertPolicy.GroupResource(), ""))
+
+		timeline := env.NewComplianceTimeline(fakePolicyName)
+		Expect(timeline.Start(ctx)).To(Succeed())
+		DeferCleanup(timeline.Stop)
+
+		env.DoCreatePolicyTest(ctx, fakePolicyFile, GvrConfigurationPolicy)
+
+		Eventually(timeline.Entries, 5, 0.1).Should(ContainElement(SatisfyAll(
+			HaveField("Cluster", env.ClusterNamespaceOnHub),
+			HaveField("Object", "Policy local-cluster/policy-test.fake-policy"),
+		)))
+	})
+
+	It("Fails on the other errors of the watches", func(ctx SpecContext) {
+		failWatches("certificatepolicies", k8serrors.NewForbidden(GvrCertPolicy.GroupResource(), "", nil))
+
+		err := env.NewComplianceTimeline(fakePolicyName).Start(ctx)
+		Expect(err).To(MatchError(ContainSubstring("failed to watch the certificatepolicies")))
+	})
+})
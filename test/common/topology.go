@@ -0,0 +1,141 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package common
+
+import (
+	"context"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// hostedClusterNamespacePrefix prefixes the namespace of the policy templates of a managed cluster
+// on the hub in hosted mode.
+const hostedClusterNamespacePrefix = "klusterlet-"
+
+// Location is a cluster, and optionally a namespace on it, with the clients to read from it.
+type Location struct {
+	// Cluster is TargetHub or TargetManaged, depending on which cluster the location is on.
+	Cluster    ClusterTarget
+	Namespace  string
+	Kubeconfig string
+	// Context is the context of the kubeconfig, which defaults to its current context.
+	Context string
+
+	Client        kubernetes.Interface
+	ClientDynamic dynamic.Interface
+}
+
+// In returns the location in the given namespace.
+func (l Location) In(namespace string) Location {
+	l.Namespace = namespace
+
+	return l
+}
+
+// Get returns the object of the location's namespace, or the cluster scoped object.
+func (l Location) Get(ctx context.Context, gvr schema.GroupVersionResource, name string) (
+	*unstructured.Unstructured, error,
+) {
+	return l.ClientDynamic.Resource(gvr).Namespace(l.Namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+// List returns the objects of the location's namespace, or of all namespaces if it's not set.
+func (l Location) List(ctx context.Context, gvr schema.GroupVersionResource, opts metav1.ListOptions) (
+	*unstructured.UnstructuredList, error,
+) {
+	return l.ClientDynamic.Resource(gvr).Namespace(l.Namespace).List(ctx, opts)
+}
+
+// Topology describes where the policy framework lives for a managed cluster. The policy addon
+// controllers, replicated policies, and policy templates are on the managed cluster, except in
+// hosted mode where they're on the hub, which hosts them in a namespace of the managed cluster.
+// The objects managed by the policy templates are always on the managed cluster.
+type Topology struct {
+	Hosted bool
+	// ManagedClusterName is the name of the ManagedCluster on the hub.
+	ManagedClusterName string
+	// Addon is where the policy addon controllers run.
+	Addon Location
+	// Templates is where the replicated policies and the policy templates are.
+	Templates Location
+	// Objects is the cluster of the objects managed by the policy templates, without a namespace
+	// since it depends on the policy.
+	Objects Location
+}
+
+// Topology returns the Topology of the default managed cluster.
+func (e *Environment) Topology() Topology {
+	topology := e.TopologyOf(e.DefaultManagedCluster())
+
+	topology.ManagedClusterName = e.ClusterNamespace
+	if topology.Hosted {
+		topology.ManagedClusterName = strings.TrimPrefix(e.ClusterNamespace, hostedClusterNamespacePrefix)
+	}
+
+	return topology
+}
+
+// TopologyOf returns the Topology of the managed cluster.
+func (e *Environment) TopologyOf(cluster *ManagedCluster) Topology {
+	objects := Location{
+		Cluster:       TargetManaged,
+		Kubeconfig:    cluster.Kubeconfig,
+		Context:       cluster.Context,
+		Client:        cluster.Client,
+		ClientDynamic: cluster.ClientDynamic,
+	}
+
+	topology := Topology{
+		Hosted:             cluster.Hosted,
+		ManagedClusterName: cluster.Name,
+		Addon:              objects.In(e.OCMAddOnNamespace),
+		Templates:          objects.In(cluster.Namespace),
+		Objects:            objects,
+	}
+
+	if cluster.Hosted {
+		hub := Location{
+			Cluster:       TargetHub,
+			Namespace:     cluster.Namespace,
+			Kubeconfig:    e.KubeconfigHub,
+			Context:       e.ContextHub,
+			Client:        cluster.ClientHosting,
+			ClientDynamic: cluster.ClientHostingDynamic,
+		}
+
+		topology.Addon = hub
+		topology.Templates = hub
+	}
+
+	return topology
+}
+
+// GetPolicyTemplate returns the policy template, like a ConfigurationPolicy, of the default
+// managed cluster from wherever the Topology puts it.
+func (e *Environment) GetPolicyTemplate(ctx context.Context, gvr schema.GroupVersionResource, name string) (
+	*unstructured.Unstructured, error,
+) {
+	return e.Topology().Templates.Get(ctx, gvr, name)
+}
+
+// GetManagedObject returns an object managed by the policy templates on the default managed
+// cluster, or the cluster scoped object if the namespace is empty.
+func (e *Environment) GetManagedObject(
+	ctx context.Context, gvr schema.GroupVersionResource, namespace, name string,
+) (*unstructured.Unstructured, error) {
+	return e.Topology().Objects.In(namespace).Get(ctx, gvr, name)
+}
+
+// AddonPods returns the pods of the policy addon controllers of the default managed cluster which
+// match the label selector.
+func (e *Environment) AddonPods(ctx context.Context, labelSelector string) (*corev1.PodList, error) {
+	addon := e.Topology().Addon
+
+	return addon.Client.CoreV1().Pods(addon.Namespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+}
@@ -0,0 +1,219 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package common
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"sigs.k8s.io/yaml"
+)
+
+// TopologyConfig is a topology file, which describes the clusters of the Environment as an
+// alternative to the kubeconfig and namespace flags:
+//
+//	hubs:
+//	- name: hub
+//	  kubeconfig: kubeconfig_hub
+//	  managedClusters:
+//	  - name: local-cluster
+//	    kubeconfig: kubeconfig_managed
+//	    context: managed-admin
+//	    hosted: false
+//
+// The first managed cluster of the hub is the default managed cluster of the Environment, and the
+// others are registered as additional managed clusters. Relative kubeconfig paths are relative to
+// the directory of the topology file.
+type TopologyConfig struct {
+	Hubs []HubConfig `json:"hubs"`
+}
+
+// HubConfig is a hub of a TopologyConfig.
+type HubConfig struct {
+	Name string `json:"name"`
+	// Kubeconfig is the path of the kubeconfig of the hub, which defaults to KUBECONFIG.
+	Kubeconfig string `json:"kubeconfig,omitempty"`
+	// Context is the context of the kubeconfig to use instead of its current context.
+	Context string `json:"context,omitempty"`
+	// UserNamespace is the namespace of the root policies on the hub.
+	UserNamespace string `json:"userNamespace,omitempty"`
+	// OCMNamespace is the namespace of the ACM installation on the hub.
+	OCMNamespace    string                 `json:"ocmNamespace,omitempty"`
+	ManagedClusters []ManagedClusterConfig `json:"managedClusters"`
+}
+
+// ManagedClusterConfig is a managed cluster of a HubConfig.
+type ManagedClusterConfig struct {
+	// Name is the name of the ManagedCluster on the hub.
+	Name       string `json:"name"`
+	Kubeconfig string `json:"kubeconfig,omitempty"`
+	Context    string `json:"context,omitempty"`
+	// Namespace is the namespace of the replicated policies and policy templates, which defaults to
+	// the name, or to the name prefixed with `klusterlet-` when the cluster is hosted.
+	Namespace string `json:"namespace,omitempty"`
+	// AddonNamespace is the namespace of the policy addon controllers on the managed cluster.
+	AddonNamespace string `json:"addonNamespace,omitempty"`
+	// Hosted is whether the policy templates are hosted on the hub, which is only supported for the
+	// first managed cluster.
+	Hosted bool `json:"hosted,omitempty"`
+}
+
+// LoadTopologyConfig reads and validates the topology file.
+func LoadTopologyConfig(path string) (*TopologyConfig, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- the file is provided by the tests
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the topology file %s: %w", path, err)
+	}
+
+	config := &TopologyConfig{}
+
+	if err := yaml.UnmarshalStrict(data, config); err != nil {
+		return nil, fmt.Errorf("failed to decode the topology file %s: %w", path, err)
+	}
+
+	if err := config.validate(); err != nil {
+		return nil, fmt.Errorf("invalid topology file %s: %w", path, err)
+	}
+
+	dir := filepath.Dir(path)
+
+	for i := range config.Hubs {
+		hub := &config.Hubs[i]
+		hub.Kubeconfig = relativeTo(dir, hub.Kubeconfig)
+
+		for j := range hub.ManagedClusters {
+			hub.ManagedClusters[j].Kubeconfig = relativeTo(dir, hub.ManagedClusters[j].Kubeconfig)
+		}
+	}
+
+	return config, nil
+}
+
+func (c *TopologyConfig) validate() error {
+	if len(c.Hubs) == 0 {
+		return errors.New("at least one hub is required")
+	}
+
+	for i, hub := range c.Hubs {
+		if hub.Name == "" {
+			return fmt.Errorf("hubs[%d]: the name is required", i)
+		}
+
+		if len(hub.ManagedClusters) == 0 {
+			return fmt.Errorf("hubs[%d]: at least one managed cluster is required", i)
+		}
+
+		for j, cluster := range hub.ManagedClusters {
+			if cluster.Name == "" {
+				return fmt.Errorf("hubs[%d].managedClusters[%d]: the name is required", i, j)
+			}
+
+			if cluster.Hosted && j > 0 {
+				return fmt.Errorf("hubs[%d].managedClusters[%d]: only the first managed cluster can be hosted", i, j)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Hub returns the hub of the given name, or the first hub if the name is empty.
+func (c *TopologyConfig) Hub(name string) (*HubConfig, error) {
+	if name == "" {
+		return &c.Hubs[0], nil
+	}
+
+	for i := range c.Hubs {
+		if c.Hubs[i].Name == name {
+			return &c.Hubs[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("the hub %s is not in the topology file", name)
+}
+
+// ApplyTopologyConfig configures the Environment with the clusters of the hub of the given name in
+// the topology file, or its first hub if the name is empty. Settings missing from the topology file
+// keep their current values.
+func (e *Environment) ApplyTopologyConfig(config *TopologyConfig, hubName string) error {
+	hub, err := config.Hub(hubName)
+	if err != nil {
+		return err
+	}
+
+	e.KubeconfigHub = hub.Kubeconfig
+	e.ContextHub = hub.Context
+
+	if hub.UserNamespace != "" {
+		e.UserNamespace = hub.UserNamespace
+	}
+
+	if hub.OCMNamespace != "" {
+		e.OCMNamespace = hub.OCMNamespace
+	}
+
+	cluster := hub.ManagedClusters[0]
+
+	e.KubeconfigManaged = cluster.Kubeconfig
+	e.ContextManaged = cluster.Context
+	e.ClusterNamespaceOnHub = cluster.Name
+	e.ClusterNamespace = cluster.Namespace
+	e.IsHosted = cluster.Hosted
+
+	if e.ClusterNamespace == "" {
+		e.ClusterNamespace = cluster.Name
+
+		if cluster.Hosted {
+			e.ClusterNamespace = hostedClusterNamespacePrefix + cluster.Name
+		}
+	}
+
+	if cluster.AddonNamespace != "" {
+		e.OCMAddOnNamespace = cluster.AddonNamespace
+	}
+
+	e.additionalClusterConfigs = hub.ManagedClusters[1:]
+
+	return nil
+}
+
+// loadTopologyFile applies the TopologyFile to the Environment if it's set.
+func (e *Environment) loadTopologyFile() error {
+	if e.TopologyFile == "" {
+		return nil
+	}
+
+	config, err := LoadTopologyConfig(e.TopologyFile)
+	if err != nil {
+		return err
+	}
+
+	return e.ApplyTopologyConfig(config, e.TopologyHub)
+}
+
+// registerClusterConfigs registers the additional managed clusters of the topology file.
+func (e *Environment) registerClusterConfigs() error {
+	for _, cluster := range e.additionalClusterConfigs {
+		registered, err := e.registerManagedCluster(cluster.Name, cluster.Kubeconfig, cluster.Context)
+		if err != nil {
+			return err
+		}
+
+		if cluster.Namespace != "" {
+			registered.Namespace = cluster.Namespace
+		}
+	}
+
+	return nil
+}
+
+// relativeTo returns the path relative to the directory, unless it's absolute or empty.
+func relativeTo(dir, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(dir, path)
+}
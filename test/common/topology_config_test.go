@@ -0,0 +1,106 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package common
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/stolostron/governance-policy-framework/test/common/kubeclient"
+)
+
+var _ = Describe("Test the topology file", func() {
+	server := func(kubeconfig, kubeContext string) string {
+		GinkgoHelper()
+
+		config, err := kubeclient.LoadConfig("", kubeconfig, kubeContext)
+		Expect(err).ToNot(HaveOccurred())
+
+		return config.Host
+	}
+
+	It("Configures the clusters of the first hub", func() {
+		env := NewEnvironment()
+		env.TopologyFile = "testdata/fake-topology.yaml"
+
+		Expect(env.InitInterfaces()).To(Succeed())
+
+		Expect(env.UserNamespace).To(Equal("my-policies"))
+		Expect(env.ClusterNamespaceOnHub).To(Equal("managed"))
+		Expect(env.ClusterNamespace).To(Equal("klusterlet-managed"))
+		Expect(env.IsHosted).To(BeTrue())
+		Expect(server(env.KubeconfigHub, env.ContextHub)).To(Equal("https://hub.example.com:6443"))
+		Expect(server(env.KubeconfigManaged, env.ContextManaged)).To(Equal("https://managed.example.com:6443"))
+
+		Expect(env.ManagedClusterNames()).To(Equal([]string{"cluster2", "managed"}))
+		Expect(env.Topology().ManagedClusterName).To(Equal("managed"))
+		Expect(env.TopologyOf(env.GetManagedCluster("cluster2")).Hosted).To(BeFalse())
+
+		cluster2 := env.GetManagedCluster("cluster2")
+		Expect(server(cluster2.Kubeconfig, cluster2.Context)).To(Equal("https://cluster2.example.com:6443"))
+		Expect(env.TopologyOf(env.GetManagedCluster("managed")).Templates.Context).To(Equal("hub"))
+	})
+
+	It("Selects a hub by name and keeps the settings it doesn't have", func() {
+		config, err := LoadTopologyConfig("testdata/fake-topology.yaml")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(config.Hubs[0].Kubeconfig).To(Equal(filepath.Join("testdata", "fake-kubeconfig.yaml")))
+
+		env := NewEnvironment()
+
+		Expect(env.ApplyTopologyConfig(config, "other-hub")).To(Succeed())
+		Expect(env.KubeconfigHub).To(BeEmpty())
+		Expect(env.UserNamespace).To(Equal("policy-test"))
+		Expect(env.ClusterNamespace).To(Equal("local-cluster"))
+		Expect(env.IsHosted).To(BeFalse())
+
+		Expect(env.ApplyTopologyConfig(config, "missing")).To(MatchError("the hub missing is not in the topology file"))
+	})
+
+	It("Defaults the namespace of a hosted cluster to its klusterlet namespace", func() {
+		config := &TopologyConfig{Hubs: []HubConfig{{
+			Name:            "hub",
+			ManagedClusters: []ManagedClusterConfig{{Name: "managed", Hosted: true}},
+		}}}
+
+		env := NewEnvironment()
+
+		Expect(env.ApplyTopologyConfig(config, "")).To(Succeed())
+		Expect(env.ClusterNamespaceOnHub).To(Equal("managed"))
+		Expect(env.ClusterNamespace).To(Equal("klusterlet-managed"))
+		Expect(env.IsHosted).To(BeTrue())
+	})
+
+	It("Rejects a hosted managed cluster which isn't the first", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "topology.yaml")
+
+		Expect(os.WriteFile(path, []byte(`hubs:
+- name: hub
+  managedClusters:
+  - name: cluster1
+  - name: cluster2
+    hosted: true
+`), 0o600)).To(Succeed())
+
+		_, err := LoadTopologyConfig(path)
+		Expect(err).To(MatchError(ContainSubstring(
+			"hubs[0].managedClusters[1]: only the first managed cluster can be hosted",
+		)))
+	})
+
+	It("Fails on an unknown context", func() {
+		env := NewEnvironment()
+		env.KubeconfigHub = "testdata/fake-kubeconfig.yaml"
+		env.ContextHub = "missing"
+
+		Expect(env.InitInterfaces()).To(MatchError(ContainSubstring(`context "missing" does not exist`)))
+	})
+
+	It("Selects the context in the commands", func() {
+		Expect(kubeconfigArgs("kubeconfig", "")).To(Equal([]string{"--kubeconfig=kubeconfig"}))
+		Expect(kubeconfigArgs("kubeconfig", "hub")).To(Equal([]string{"--kubeconfig=kubeconfig", "--context=hub"}))
+	})
+})
@@ -0,0 +1,106 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package common
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+var _ = Describe("Test the topology", func() {
+	var backend *FakeBackend
+	var env *Environment
+
+	BeforeEach(func() {
+		backend = startFakeBackend()
+		env = backend.Env
+	})
+
+	createConfigPolicy := func(ctx SpecContext, client dynamic.Interface, namespace string) {
+		GinkgoHelper()
+
+		_, err := client.Resource(GvrConfigurationPolicy).Namespace(namespace).Create(
+			ctx,
+			&unstructured.Unstructured{Object: map[string]any{
+				"apiVersion": "policy.open-cluster-management.io/v1",
+				"kind":       "ConfigurationPolicy",
+				"metadata":   map[string]any{"name": "my-config-policy", "namespace": namespace},
+			}},
+			metav1.CreateOptions{},
+		)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	It("Puts everything on the managed cluster", func(ctx SpecContext) {
+		topology := env.Topology()
+
+		Expect(topology.Hosted).To(BeFalse())
+		Expect(topology.ManagedClusterName).To(Equal("local-cluster"))
+		Expect(topology.Addon.Cluster).To(Equal(TargetManaged))
+		Expect(topology.Addon.Namespace).To(Equal(env.OCMAddOnNamespace))
+		Expect(topology.Templates.Cluster).To(Equal(TargetManaged))
+		Expect(topology.Templates.Namespace).To(Equal("local-cluster"))
+		Expect(topology.Objects.Cluster).To(Equal(TargetManaged))
+		Expect(topology.Objects.Namespace).To(BeEmpty())
+
+		createConfigPolicy(ctx, env.ClientManagedDynamic, "local-cluster")
+
+		policy, err := env.GetPolicyTemplate(ctx, GvrConfigurationPolicy, "my-config-policy")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(policy.GetNamespace()).To(Equal("local-cluster"))
+
+		_, err = backend.Managed.Client.CoreV1().Pods(env.OCMAddOnNamespace).Create(ctx, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "config-policy-controller", Labels: map[string]string{"app": "cpc"}},
+		}, metav1.CreateOptions{})
+		Expect(err).ToNot(HaveOccurred())
+
+		pods, err := env.AddonPods(ctx, "app=cpc")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pods.Items).To(HaveLen(1))
+	})
+
+	It("Routes the addon and the policy templates to the hub in hosted mode", func(ctx SpecContext) {
+		env.IsHosted = true
+		env.ClusterNamespace = "klusterlet-managed"
+		env.ClientHosting = backend.Hub.Client
+		env.ClientHostingDynamic = backend.Hub.ClientDynamic
+		env.ManagedClusters = map[string]*ManagedCluster{}
+
+		topology := env.Topology()
+
+		Expect(topology.Hosted).To(BeTrue())
+		Expect(topology.ManagedClusterName).To(Equal("managed"))
+		Expect(topology.Addon.Cluster).To(Equal(TargetHub))
+		Expect(topology.Addon.Namespace).To(Equal("klusterlet-managed"))
+		Expect(topology.Addon.Kubeconfig).To(Equal(env.KubeconfigHub))
+		Expect(topology.Templates.Cluster).To(Equal(TargetHub))
+		Expect(topology.Templates.Namespace).To(Equal("klusterlet-managed"))
+		Expect(topology.Objects.Cluster).To(Equal(TargetManaged))
+		Expect(topology.Objects.Kubeconfig).To(Equal(env.KubeconfigManaged))
+
+		createConfigPolicy(ctx, env.ClientHubDynamic, "klusterlet-managed")
+
+		_, err := env.GetPolicyTemplate(ctx, GvrConfigurationPolicy, "my-config-policy")
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = env.GetManagedObject(ctx, GvrConfigurationPolicy, "klusterlet-managed", "my-config-policy")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Doesn't host additional managed clusters", func() {
+		env.IsHosted = true
+
+		backend.AddManagedCluster("cluster2")
+
+		topology := env.TopologyOf(env.GetManagedCluster("cluster2"))
+
+		Expect(topology.Hosted).To(BeFalse())
+		Expect(topology.ManagedClusterName).To(Equal("cluster2"))
+		Expect(topology.Templates.Cluster).To(Equal(TargetManaged))
+		Expect(topology.Templates.Namespace).To(Equal("cluster2"))
+	})
+})
@@ -0,0 +1,207 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package common
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+)
+
+const (
+	commandsReportEntry = "Commands"
+	// maskedValue replaces the secrets in the arguments and output of the commands.
+	maskedValue = "***"
+	// maxTranscriptOutput is the maximum length of the stdout and stderr of a CommandTranscript.
+	maxTranscriptOutput = 64 * 1024
+)
+
+// sensitiveFlags are the flags of oc/kubectl whose values are masked in the transcripts.
+var sensitiveFlags = []string{"--token", "--password", "--client-key", "--from-literal"}
+
+// CommandTranscript is an oc/kubectl command run by OcHub, OcManaged, OcHosting, or OcUser.
+type CommandTranscript struct {
+	// Args are the command and its arguments, with the secrets masked.
+	Args []string `json:"args"`
+	// Native is whether the command was handled with client-go instead of the K8sClient binary.
+	Native   bool          `json:"native,omitempty"`
+	ExitCode int           `json:"exitCode"`
+	Start    time.Time     `json:"start"`
+	Duration time.Duration `json:"duration"`
+	// Stdout is masked for the commands which output secrets, like `oc whoami -t`.
+	Stdout string `json:"stdout"`
+	Stderr string `json:"stderr"`
+}
+
+// String renders the transcript like a shell session.
+func (t CommandTranscript) String() string {
+	builder := &strings.Builder{}
+
+	native := ""
+	if t.Native {
+		native = ", native"
+	}
+
+	fmt.Fprintf(builder, "$ %s\n", strings.Join(t.Args, " "))
+	fmt.Fprintf(
+		builder, "# exit code %d after %s%s, at %s\n",
+		t.ExitCode, t.Duration.Round(time.Millisecond), native, t.Start.Format(time.RFC3339Nano),
+	)
+
+	if t.Stdout != "" {
+		fmt.Fprintf(builder, "# stdout:\n%s\n", strings.TrimSuffix(t.Stdout, "\n"))
+	}
+
+	if t.Stderr != "" {
+		fmt.Fprintf(builder, "# stderr:\n%s\n", strings.TrimSuffix(t.Stderr, "\n"))
+	}
+
+	return strings.TrimSuffix(builder.String(), "\n")
+}
+
+// CommandTranscripts are the transcripts of the commands of a spec, in the order they were run.
+type CommandTranscripts []CommandTranscript
+
+func (t CommandTranscripts) String() string {
+	transcripts := make([]string, 0, len(t))
+
+	for _, transcript := range t {
+		transcripts = append(transcripts, transcript.String())
+	}
+
+	return strings.Join(transcripts, "\n\n")
+}
+
+// TrackCommands records the transcripts of the oc/kubectl commands run with the Environment from
+// now until the end of the current spec, and adds them to its report. It's meant to be called in a
+// top level BeforeEach of the suite, so that the commands of the BeforeAll nodes are recorded too.
+func (e *Environment) TrackCommands() {
+	e.transcriptsLock.Lock()
+	e.transcripts = nil
+	e.trackCommands = true
+	e.transcriptsLock.Unlock()
+
+	DeferCleanup(func() {
+		transcripts := e.Commands()
+		if len(transcripts) != 0 {
+			AddReportEntry(commandsReportEntry, transcripts, ReportEntryVisibilityFailureOrVerbose)
+		}
+	})
+}
+
+// Commands returns the transcripts of the commands recorded since TrackCommands was called.
+func (e *Environment) Commands() CommandTranscripts {
+	e.transcriptsLock.Lock()
+	defer e.transcriptsLock.Unlock()
+
+	return append(CommandTranscripts{}, e.transcripts...)
+}
+
+// recordCommand records the transcript if TrackCommands was called.
+func (e *Environment) recordCommand(transcript CommandTranscript) {
+	e.transcriptsLock.Lock()
+	defer e.transcriptsLock.Unlock()
+
+	if e.trackCommands {
+		e.transcripts = append(e.transcripts, transcript)
+	}
+}
+
+// newCommandTranscript returns the transcript of the command which was started at the given time
+// and returned the given error, with the secrets masked.
+func newCommandTranscript(args []string, start time.Time, stdout, stderr string, err error) CommandTranscript {
+	transcript := CommandTranscript{
+		Args:     maskArgs(args),
+		Start:    start,
+		Duration: time.Since(start),
+		Stdout:   truncateOutput(stdout),
+		Stderr:   truncateOutput(stderr),
+	}
+
+	if hasSensitiveOutput(args) && stdout != "" {
+		transcript.Stdout = maskedValue
+	}
+
+	var exitError *exec.ExitError
+
+	switch {
+	case errors.As(err, &exitError):
+		transcript.ExitCode = exitError.ExitCode()
+	case err != nil:
+		// The command couldn't be run, or it was handled natively and failed.
+		transcript.ExitCode = -1
+
+		if transcript.Stderr == "" {
+			transcript.Stderr = err.Error()
+		}
+	}
+
+	return transcript
+}
+
+// hasSensitiveOutput returns whether the command may output secrets, like `oc whoami -t` or
+// `oc get secret`.
+func hasSensitiveOutput(args []string) bool {
+	for _, a := range args {
+		if a == "whoami" || strings.HasPrefix(a, "secret") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// maskArgs returns a copy of the arguments with the values of the sensitiveFlags masked, and the
+// password of `oc login -p`. The key of `--from-literal=key=value` is kept.
+func maskArgs(args []string) []string {
+	masked := make([]string, len(args))
+	isLogin := false
+
+	for i, arg := range args {
+		masked[i] = arg
+
+		if arg == "login" {
+			isLogin = true
+		}
+
+		if i > 0 && isSensitiveFlag(args[i-1], isLogin) && !strings.HasPrefix(arg, "-") {
+			masked[i] = maskedValue
+
+			continue
+		}
+
+		flagName, value, hasValue := strings.Cut(arg, "=")
+		if !hasValue || !isSensitiveFlag(flagName, isLogin) {
+			continue
+		}
+
+		if key, _, isLiteral := strings.Cut(value, "="); flagName == "--from-literal" && isLiteral {
+			masked[i] = flagName + "=" + key + "=" + maskedValue
+		} else {
+			masked[i] = flagName + "=" + maskedValue
+		}
+	}
+
+	return masked
+}
+
+func isSensitiveFlag(arg string, isLogin bool) bool {
+	if isLogin && arg == "-p" {
+		return true
+	}
+
+	return slices.Contains(sensitiveFlags, arg)
+}
+
+func truncateOutput(output string) string {
+	if len(output) <= maxTranscriptOutput {
+		return output
+	}
+
+	return fmt.Sprintf("%s\n... (%d bytes truncated)", output[:maxTranscriptOutput], len(output)-maxTranscriptOutput)
+}
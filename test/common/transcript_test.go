@@ -0,0 +1,82 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package common
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Test the command transcripts", func() {
+	DescribeTable("Masks the secrets in the arguments",
+		func(args, expected []string) {
+			Expect(maskArgs(args)).To(Equal(expected))
+		},
+		Entry("token flag", []string{"oc", "--token=my-token", "get", "pods"},
+			[]string{"oc", "--token=***", "get", "pods"}),
+		Entry("separate token flag", []string{"oc", "--token", "my-token", "get", "pods"},
+			[]string{"oc", "--token", "***", "get", "pods"}),
+		Entry("login password", []string{"oc", "login", "-u", "my-user", "-p", "my-password"},
+			[]string{"oc", "login", "-u", "my-user", "-p", "***"}),
+		Entry("patch", []string{"oc", "patch", "cm", "my-cm", "-p", `{"data": {}}`},
+			[]string{"oc", "patch", "cm", "my-cm", "-p", `{"data": {}}`}),
+		Entry("literal", []string{"oc", "create", "secret", "generic", "my-secret", "--from-literal=key=value"},
+			[]string{"oc", "create", "secret", "generic", "my-secret", "--from-literal=key=***"}),
+	)
+
+	It("Records the commands run with the K8sClient binary", func() {
+		env := NewEnvironment()
+		env.K8sClient = "sh"
+		env.TrackCommands()
+
+		output, err := env.oc("-c", "echo my-output; echo my-error >&2; exit 3")
+		Expect(err).To(MatchError(ContainSubstring("my-error")))
+		Expect(output).To(Equal("my-output\n"))
+
+		// The command prints its first argument, which is `whoami`, as the token
+		_, err = env.oc("-c", "echo $0-token", "whoami")
+		Expect(err).ToNot(HaveOccurred())
+
+		transcripts := env.Commands()
+		Expect(transcripts).To(HaveLen(2))
+		Expect(transcripts[0].Args).To(Equal([]string{"sh", "-c", "echo my-output; echo my-error >&2; exit 3"}))
+		Expect(transcripts[0].ExitCode).To(Equal(3))
+		Expect(transcripts[0].Stdout).To(Equal("my-output\n"))
+		Expect(transcripts[0].Stderr).To(Equal("my-error\n"))
+		Expect(transcripts[0].Duration).To(BeNumerically(">", 0))
+		Expect(transcripts[1].ExitCode).To(Equal(0))
+		Expect(transcripts[1].Stdout).To(Equal("***"))
+
+		Expect(transcripts.String()).To(ContainSubstring("$ sh -c echo my-output; echo my-error >&2; exit 3\n"))
+		Expect(transcripts.String()).To(ContainSubstring("# stderr:\nmy-error"))
+		Expect(transcripts.String()).ToNot(ContainSubstring("whoami-token"))
+	})
+
+	It("Records the commands handled natively", func() {
+		env := startFakeBackend().Env
+		env.TrackCommands()
+
+		_, err := env.OcHub("delete", "configmap", "my-cm", "-n", "default", "--ignore-not-found")
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = env.OcHub("delete", "configmap", "my-cm", "-n", "default")
+		Expect(err).To(HaveOccurred())
+
+		transcripts := env.Commands()
+		Expect(transcripts).To(HaveLen(2))
+		Expect(transcripts[0].Native).To(BeTrue())
+		Expect(transcripts[0].Args).To(ContainElements("delete", "configmap", "my-cm"))
+		Expect(transcripts[0].ExitCode).To(Equal(0))
+		Expect(transcripts[1].ExitCode).To(Equal(-1))
+		Expect(transcripts[1].Stderr).To(ContainSubstring("not found"))
+	})
+
+	It("Doesn't record the commands until TrackCommands is called", func() {
+		env := NewEnvironment()
+		env.K8sClient = "true"
+
+		_, err := env.oc()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(env.Commands()).To(BeEmpty())
+	})
+})
@@ -43,6 +43,8 @@ type OCPUser struct {
 	Password            string
 	Username            string
 	Kubeconfig          string
+	// Labels are set on the objects created for the user, like the ownership labels of a test run.
+	Labels map[string]string
 }
 
 // GenerateInsecurePassword is a random password generator from 15-30 bytes. It is insecure
@@ -109,10 +111,10 @@ func GetKubeConfig(server, username, password string) (string, error) {
 // Prints and returns the stdout from the command.
 // If the command fails (non-zero exit code) and stderr was populated, that
 // content will be returned in the error.
-func OcUser(user OCPUser, args ...string) (string, error) {
+func (e *Environment) OcUser(user OCPUser, args ...string) (string, error) {
 	args = append([]string{"--kubeconfig=" + user.Kubeconfig}, args...)
 
-	return oc(args...)
+	return e.oc(args...)
 }
 
 // CreateOCPUser will create an OpenShift user on a cluster, configure the identity provider for
@@ -131,7 +133,7 @@ func CreateOCPUser(
 	// Create a secret to hold the generated htpasswd file with the user's credentials.
 	htpasswd := fmt.Appendf(nil, "%s:%s\n", user.Username, string(passwordBytes))
 	secret := corev1.Secret{
-		ObjectMeta: metav1.ObjectMeta{Name: user.Username},
+		ObjectMeta: metav1.ObjectMeta{Name: user.Username, Labels: user.Labels},
 		Data:       map[string][]byte{"htpasswd": htpasswd},
 		Type:       corev1.SecretTypeOpaque,
 	}
@@ -396,7 +398,7 @@ func addClusterRoles(client kubernetes.Interface, user OCPUser) error {
 
 		if role.Namespace == "" {
 			binding := rbacv1.ClusterRoleBinding{
-				ObjectMeta: metav1.ObjectMeta{Name: bindingName},
+				ObjectMeta: metav1.ObjectMeta{Name: bindingName, Labels: user.Labels},
 				RoleRef:    roleRef,
 				Subjects:   subjectObjs,
 			}
@@ -405,7 +407,7 @@ func addClusterRoles(client kubernetes.Interface, user OCPUser) error {
 			)
 		} else {
 			binding := rbacv1.RoleBinding{
-				ObjectMeta: metav1.ObjectMeta{Name: bindingName},
+				ObjectMeta: metav1.ObjectMeta{Name: bindingName, Labels: user.Labels},
 				RoleRef:    roleRef,
 				Subjects:   subjectObjs,
 			}
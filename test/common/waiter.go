@@ -0,0 +1,458 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package common
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	policiesv1 "open-cluster-management.io/governance-policy-propagator/api/v1"
+)
+
+// waitRetryInterval is how long a Waiter waits before retrying after a failed request.
+const waitRetryInterval = time.Second
+
+// Predicate reports whether an object observed by a Waiter is in the desired state. The object is
+// nil when it doesn't exist.
+type Predicate func(obj *unstructured.Unstructured) bool
+
+// ComplianceIs returns a Predicate matching a Policy, ConfigurationPolicy, OperatorPolicy, or
+// CertificatePolicy whose status.compliant is the given compliance.
+func ComplianceIs(compliance policiesv1.ComplianceState) Predicate {
+	return func(obj *unstructured.Unstructured) bool {
+		if obj == nil {
+			return false
+		}
+
+		current, _, _ := unstructured.NestedString(obj.Object, "status", "compliant")
+
+		return current == string(compliance)
+	}
+}
+
+// ClusterComplianceIs returns a Predicate matching a root policy whose status for the given
+// cluster is the given compliance.
+func ClusterComplianceIs(clusterName string, compliance policiesv1.ComplianceState) Predicate {
+	return func(obj *unstructured.Unstructured) bool {
+		if obj == nil {
+			return false
+		}
+
+		policy, err := PolicyFromUnstructured(obj)
+		if err != nil {
+			return false
+		}
+
+		current, err := ClusterComplianceState(policy, clusterName)
+
+		return err == nil && current == compliance
+	}
+}
+
+// Exists is a Predicate matching any existing object.
+func Exists(obj *unstructured.Unstructured) bool {
+	return obj != nil
+}
+
+// NotFound is a Predicate matching an object which doesn't exist.
+func NotFound(obj *unstructured.Unstructured) bool {
+	return obj == nil
+}
+
+// Transition is a state of an object observed by a Waiter.
+type Transition struct {
+	Time time.Time
+	// Event is GET for the state read when a watch is started, or the type of the watch event.
+	Event           string
+	ResourceVersion string
+	State           string
+}
+
+func (t Transition) String() string {
+	return fmt.Sprintf("%s %s rv=%s %s", t.Time.Format("15:04:05.000"), t.Event, t.ResourceVersion, t.State)
+}
+
+// WaitError is returned by a Waiter when the object doesn't reach the desired state in time. It
+// includes every state transition observed while waiting.
+type WaitError struct {
+	GVR         schema.GroupVersionResource
+	Namespace   string
+	Name        string
+	Description string
+	Timeout     time.Duration
+	Transitions []Transition
+	// Err is the last error returned by the API server while waiting, if any.
+	Err error
+}
+
+func (e *WaitError) Error() string {
+	var msg strings.Builder
+
+	fmt.Fprintf(&msg, "timed out after %s waiting for %s %s: %s", e.Timeout, e.GVR.Resource,
+		namespacedName(e.Namespace, e.Name), e.Description)
+
+	if e.Err != nil {
+		fmt.Fprintf(&msg, " (last error: %v)", e.Err)
+	}
+
+	if len(e.Transitions) == 0 {
+		msg.WriteString("; no states were observed")
+
+		return msg.String()
+	}
+
+	msg.WriteString("; observed states:")
+
+	for _, transition := range e.Transitions {
+		msg.WriteString("\n  " + transition.String())
+	}
+
+	return msg.String()
+}
+
+func (e *WaitError) Unwrap() error {
+	return e.Err
+}
+
+// Waiter watches a single object and resolves as soon as it matches a Predicate. Unlike polling
+// with fresh GET requests, the watch reports each change as it happens.
+type Waiter struct {
+	Client    dynamic.Interface
+	GVR       schema.GroupVersionResource
+	Namespace string
+	Name      string
+
+	transitions []Transition
+}
+
+// NewWaiter returns a Waiter for the named object. The namespace is empty for cluster scoped
+// objects.
+func NewWaiter(client dynamic.Interface, gvr schema.GroupVersionResource, namespace, name string) *Waiter {
+	return &Waiter{Client: client, GVR: gvr, Namespace: namespace, Name: name}
+}
+
+// Transitions returns the state transitions observed by the Waiter so far.
+func (w *Waiter) Transitions() []Transition {
+	return w.transitions
+}
+
+// Until waits up to the timeout for the object to match the predicate and returns it, which is nil
+// if the predicate matched a missing object. The description of the desired state is used in the
+// WaitError returned on a timeout.
+func (w *Waiter) Until(
+	ctx context.Context, timeout time.Duration, description string, predicate Predicate,
+) (*unstructured.Unstructured, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var lastErr error
+
+	for {
+		obj, matched, err := w.watch(ctx, predicate)
+		if matched {
+			return obj, nil
+		}
+
+		if err != nil && ctx.Err() == nil {
+			lastErr = err
+
+			select {
+			case <-ctx.Done():
+			case <-time.After(waitRetryInterval):
+			}
+		}
+
+		if ctx.Err() != nil {
+			return nil, &WaitError{
+				GVR:         w.GVR,
+				Namespace:   w.Namespace,
+				Name:        w.Name,
+				Description: description,
+				Timeout:     timeout,
+				Transitions: w.transitions,
+				Err:         lastErr,
+			}
+		}
+	}
+}
+
+// watch starts a watch on the object, then checks its current state and every change reported by
+// the watch against the predicate. It returns without a match when the watch is closed.
+func (w *Waiter) watch(ctx context.Context, predicate Predicate) (*unstructured.Unstructured, bool, error) {
+	client := w.Client.Resource(w.GVR).Namespace(w.Namespace)
+
+	// The watch is started before the GET so that no change between the two can be missed.
+	watcher, err := client.Watch(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("metadata.name", w.Name).String(),
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	defer watcher.Stop()
+
+	obj, err := client.Get(ctx, w.Name, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		obj = nil
+	} else if err != nil {
+		return nil, false, err
+	}
+
+	if w.observe("GET", obj, predicate) {
+		return obj, true, nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return nil, false, nil
+			}
+
+			switch event.Type {
+			case watch.Error:
+				return nil, false, k8serrors.FromObject(event.Object)
+			case watch.Bookmark:
+				continue
+			}
+
+			obj, ok := event.Object.(*unstructured.Unstructured)
+			if !ok || obj.GetName() != w.Name {
+				continue
+			}
+
+			if event.Type == watch.Deleted {
+				obj = nil
+			}
+
+			if w.observe(string(event.Type), obj, predicate) {
+				return obj, true, nil
+			}
+		}
+	}
+}
+
+// observe records the state of the object if it changed since the last observation, and returns
+// whether it matches the predicate.
+func (w *Waiter) observe(event string, obj *unstructured.Unstructured, predicate Predicate) bool {
+	transition := Transition{Time: time.Now(), Event: event, State: describeState(obj)}
+	if obj != nil {
+		transition.ResourceVersion = obj.GetResourceVersion()
+	}
+
+	if len(w.transitions) == 0 || w.transitions[len(w.transitions)-1].State != transition.State {
+		w.transitions = append(w.transitions, transition)
+	}
+
+	return predicate(obj)
+}
+
+// describeState summarizes the compliance related state of a policy, including the latest history
+// message of each template of a Policy, or the generation of any other object.
+func describeState(obj *unstructured.Unstructured) string {
+	state := describeCompliance(obj)
+
+	if obj == nil || obj.GetKind() != "Policy" {
+		return state
+	}
+
+	policy, err := PolicyFromUnstructured(obj)
+	if err != nil {
+		return state
+	}
+
+	for i := range policy.Status.Details {
+		if message, err := LatestHistoryMessage(policy, i); err == nil {
+			state += fmt.Sprintf(" template[%d]=%q", i, message)
+		}
+	}
+
+	return state
+}
+
+// describeCompliance summarizes the compliance of a policy, with the per-cluster compliance of a
+// root policy and the condition messages of a policy template, the phase and conditions of a
+// namespace, or the generation of any other object.
+func describeCompliance(obj *unstructured.Unstructured) string {
+	if obj == nil {
+		return "not found"
+	}
+
+	if obj.GetKind() == "Namespace" {
+		return describeNamespace(obj)
+	}
+
+	compliance, _, _ := unstructured.NestedString(obj.Object, "status", "compliant")
+	state := "compliant=" + stateOrNone(compliance)
+
+	switch obj.GetKind() {
+	case "Policy":
+		policy, err := PolicyFromUnstructured(obj)
+		if err != nil {
+			return state
+		}
+
+		clusters := make([]string, 0, len(policy.Status.Status))
+		for _, clusterStatus := range policy.Status.Status {
+			if clusterStatus == nil {
+				continue
+			}
+
+			clusters = append(
+				clusters, clusterStatus.ClusterNamespace+"="+stateOrNone(string(clusterStatus.ComplianceState)),
+			)
+		}
+
+		if len(clusters) != 0 {
+			state += " clusters=[" + strings.Join(clusters, ", ") + "]"
+		}
+	case "ConfigurationPolicy":
+		configPolicy, err := ConfigurationPolicyFromUnstructured(obj)
+		if err != nil {
+			return state
+		}
+
+		for i := range configPolicy.Status.CompliancyDetails {
+			if messages, err := configPolicy.ConditionMessages(i); err == nil && len(messages) != 0 {
+				state += fmt.Sprintf(" template[%d]=%q", i, strings.Join(messages, "; "))
+			}
+		}
+	case "OperatorPolicy":
+		opPolicy, err := OperatorPolicyFromUnstructured(obj)
+		if err != nil {
+			return state
+		}
+
+		if condition, err := opPolicy.Condition("Compliant"); err == nil {
+			state += fmt.Sprintf(" message=%q", condition.Message)
+		}
+	default:
+		return fmt.Sprintf("generation=%d", obj.GetGeneration())
+	}
+
+	return state
+}
+
+// describeNamespace summarizes the phase of a namespace and its true conditions, which list the
+// objects and finalizers left in a terminating namespace.
+func describeNamespace(obj *unstructured.Unstructured) string {
+	phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+	state := "phase=" + stateOrNone(phase)
+
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, condition := range conditions {
+		condition, ok := condition.(map[string]any)
+		if !ok || condition["status"] != "True" {
+			continue
+		}
+
+		state += fmt.Sprintf(" %v=%q", condition["type"], condition["message"])
+	}
+
+	return state
+}
+
+func stateOrNone(state string) string {
+	if state == "" {
+		return "(none)"
+	}
+
+	return state
+}
+
+func namespacedName(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+
+	return namespace + "/" + name
+}
+
+// WaitForObject waits up to the default timeout for the named object to match the predicate, by
+// watching it with the given client.
+func (e *Environment) WaitForObject(
+	ctx context.Context,
+	client dynamic.Interface,
+	gvr schema.GroupVersionResource,
+	namespace, name, description string,
+	predicate Predicate,
+) (*unstructured.Unstructured, error) {
+	return NewWaiter(client, gvr, namespace, name).Until(
+		ctx, time.Duration(e.DefaultTimeoutSeconds)*time.Second, description, predicate,
+	)
+}
+
+// WaitForRootPolicy waits up to the default timeout for the root policy on the hub to match the
+// predicate.
+func (e *Environment) WaitForRootPolicy(
+	ctx context.Context, policyName, description string, predicate Predicate,
+) (*unstructured.Unstructured, error) {
+	return e.WaitForObject(ctx, e.ClientHubDynamic, GvrPolicy, e.UserNamespace, policyName, description, predicate)
+}
+
+// WaitForCompliance waits up to timeoutSeconds for the root policy on the hub to have the given
+// compliance on the managed cluster of the Environment.
+func (e *Environment) WaitForCompliance(
+	ctx context.Context, policyName string, compliance policiesv1.ComplianceState, timeoutSeconds int,
+) error {
+	_, err := NewWaiter(e.ClientHubDynamic, GvrPolicy, e.UserNamespace, policyName).Until(
+		ctx,
+		time.Duration(timeoutSeconds)*time.Second,
+		fmt.Sprintf("%s on the cluster %s", compliance, e.ClusterNamespaceOnHub),
+		ClusterComplianceIs(e.ClusterNamespaceOnHub, compliance),
+	)
+
+	return err
+}
+
+// WaitForReplicatedPolicy waits up to the default timeout for the replicated policy on the given
+// managed cluster to match the predicate.
+func (e *Environment) WaitForReplicatedPolicy(
+	ctx context.Context, policyName, clusterName, description string, predicate Predicate,
+) (*unstructured.Unstructured, error) {
+	return e.waitForClusterObject(
+		ctx, GvrPolicy, e.UserNamespace+"."+policyName, clusterName, description, predicate,
+	)
+}
+
+// WaitForConfigurationPolicy waits up to the default timeout for the ConfigurationPolicy on the
+// given managed cluster to match the predicate.
+func (e *Environment) WaitForConfigurationPolicy(
+	ctx context.Context, name, clusterName, description string, predicate Predicate,
+) (*unstructured.Unstructured, error) {
+	return e.waitForClusterObject(ctx, GvrConfigurationPolicy, name, clusterName, description, predicate)
+}
+
+// WaitForOperatorPolicy waits up to the default timeout for the OperatorPolicy on the given
+// managed cluster to match the predicate.
+func (e *Environment) WaitForOperatorPolicy(
+	ctx context.Context, name, clusterName, description string, predicate Predicate,
+) (*unstructured.Unstructured, error) {
+	return e.waitForClusterObject(ctx, GvrOperatorPolicy, name, clusterName, description, predicate)
+}
+
+// waitForClusterObject waits for an object in the policy namespace of the given managed cluster, on
+// the cluster hosting its policies.
+func (e *Environment) waitForClusterObject(
+	ctx context.Context,
+	gvr schema.GroupVersionResource,
+	name, clusterName, description string,
+	predicate Predicate,
+) (*unstructured.Unstructured, error) {
+	cluster := e.GetManagedCluster(clusterName)
+	if cluster == nil {
+		return nil, fmt.Errorf("managed cluster '%s' is not registered in the test environment", clusterName)
+	}
+
+	return e.WaitForObject(ctx, cluster.ClientHostingDynamic, gvr, cluster.Namespace, name, description, predicate)
+}
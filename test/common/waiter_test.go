@@ -0,0 +1,100 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package common
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	policiesv1 "open-cluster-management.io/governance-policy-propagator/api/v1"
+)
+
+var _ = Describe("Test the watch based Waiter", func() {
+	var backend *FakeBackend
+	var env *Environment
+
+	BeforeEach(func(ctx SpecContext) {
+		backend = startFakeBackend()
+		env = backend.Env
+
+		env.DoCreatePolicyTest(ctx, fakePolicyFile, GvrConfigurationPolicy)
+	})
+
+	It("Resolves as soon as the root policy matches", func(ctx SpecContext) {
+		go func() {
+			defer GinkgoRecover()
+
+			time.Sleep(100 * time.Millisecond)
+
+			err := backend.Propagator.SetTemplateStatus(
+				ctx, env.ClusterNamespaceOnHub, env.UserNamespace, fakePolicyName, 0, policiesv1.NonCompliant,
+				"NonCompliant; violation",
+			)
+			Expect(err).ToNot(HaveOccurred())
+		}()
+
+		policy, err := env.WaitForRootPolicy(
+			ctx, fakePolicyName, "compliant is NonCompliant", ComplianceIs(policiesv1.NonCompliant),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(policy.GetName()).To(Equal(fakePolicyName))
+
+		_, err = env.WaitForReplicatedPolicy(
+			ctx, fakePolicyName, env.ClusterNamespaceOnHub, "compliant is NonCompliant",
+			ComplianceIs(policiesv1.NonCompliant),
+		)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Resolves when the object is deleted", func(ctx SpecContext) {
+		go func() {
+			defer GinkgoRecover()
+
+			time.Sleep(100 * time.Millisecond)
+
+			err := env.ClientManagedDynamic.Resource(GvrConfigurationPolicy).Namespace(env.ClusterNamespace).Delete(
+				ctx, fakePolicyName, metav1.DeleteOptions{},
+			)
+			Expect(err).ToNot(HaveOccurred())
+		}()
+
+		obj, err := NewWaiter(
+			env.ClientManagedDynamic, GvrConfigurationPolicy, env.ClusterNamespace, fakePolicyName,
+		).Until(ctx, 5*time.Second, "deleted", func(obj *unstructured.Unstructured) bool { return obj == nil })
+		Expect(err).ToNot(HaveOccurred())
+		Expect(obj).To(BeNil())
+	})
+
+	It("Returns the observed transitions on a timeout", func(ctx SpecContext) {
+		go func() {
+			defer GinkgoRecover()
+
+			time.Sleep(100 * time.Millisecond)
+
+			err := backend.Propagator.SetTemplateStatus(
+				ctx, env.ClusterNamespaceOnHub, env.UserNamespace, fakePolicyName, 0, policiesv1.NonCompliant,
+				"NonCompliant; violation",
+			)
+			Expect(err).ToNot(HaveOccurred())
+		}()
+
+		waiter := NewWaiter(env.ClientHubDynamic, GvrPolicy, env.UserNamespace, fakePolicyName)
+		_, err := waiter.Until(ctx, time.Second, "compliant is Compliant", ComplianceIs(policiesv1.Compliant))
+
+		var waitErr *WaitError
+		Expect(errors.As(err, &waitErr)).To(BeTrue())
+		Expect(waitErr.Transitions).To(HaveLen(2))
+		Expect(waitErr.Transitions[0].Event).To(Equal("GET"))
+		Expect(waitErr.Transitions[0].State).To(Equal("compliant=(none) clusters=[local-cluster=(none)]"))
+		Expect(waitErr.Transitions[1].Event).To(Equal("MODIFIED"))
+		Expect(waitErr.Transitions[1].State).To(Equal("compliant=NonCompliant clusters=[local-cluster=NonCompliant]"))
+		Expect(err.Error()).To(HavePrefix(
+			"timed out after 1s waiting for policies policy-test/fake-policy: compliant is Compliant; " +
+				"observed states:\n",
+		))
+	})
+})
@@ -10,7 +10,6 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/client-go/dynamic"
 	policiesv1 "open-cluster-management.io/governance-policy-propagator/api/v1"
 	"open-cluster-management.io/governance-policy-propagator/test/utils"
 
@@ -18,6 +17,8 @@ import (
 )
 
 func ConfigPruneBehavior(labels ...string) bool {
+	env := DefaultEnvironment
+
 	const (
 		pruneConfigMapName string = "test-prune-configmap"
 		pruneConfigMapYaml string = "../resources/configuration_policy_prune/configmap-only.yaml"
@@ -27,28 +28,28 @@ func ConfigPruneBehavior(labels ...string) bool {
 		return func() {
 			By("Cleaning up policy " + policyName + ", ignoring if not found")
 
-			outHub, err := OcHub("delete", "-f", policyYaml, "-n", UserNamespace, "--ignore-not-found")
+			outHub, err := env.OcHub("delete", "-f", policyYaml, "-n", env.UserNamespace, "--ignore-not-found")
 			GinkgoWriter.Printf("cleanPolicy OcHub output: %v\n", outHub)
 			Expect(err).ToNot(HaveOccurred())
 
 			Eventually(func(g Gomega) {
-				outManaged, err := OcHosting(
+				outManaged, err := env.OcHosting(
 					"get", "configurationpolicies", "-A",
 				)
 				GinkgoWriter.Printf("cleanPolicy OcManaged configurationpolicy output: %v\n", outManaged)
 				g.Expect(outManaged).To(BeEmpty())
 				g.Expect(err).ToNot(HaveOccurred())
-			}, DefaultTimeoutSeconds, 1).Should(Succeed())
+			}, env.DefaultTimeoutSeconds, 1).Should(Succeed())
 
-			outManaged, err := OcHosting(
-				"delete", "events", "-n", ClusterNamespace,
-				"--field-selector=involvedObject.name="+UserNamespace+"."+policyName,
+			outManaged, err := env.OcHosting(
+				"delete", "events", "-n", env.ClusterNamespace,
+				"--field-selector=involvedObject.name="+env.UserNamespace+"."+policyName,
 				"--ignore-not-found",
 			)
 			GinkgoWriter.Printf("cleanPolicy OcManaged policy event output: %v\n", outManaged)
 			Expect(err).ToNot(HaveOccurred())
-			outManaged, err = OcHosting(
-				"delete", "events", "-n", ClusterNamespace,
+			outManaged, err = env.OcHosting(
+				"delete", "events", "-n", env.ClusterNamespace,
 				"--field-selector=involvedObject.name="+policyName,
 				"--ignore-not-found",
 			)
@@ -58,35 +59,29 @@ func ConfigPruneBehavior(labels ...string) bool {
 	}
 
 	pruneTestCreatedByPolicy := func(ctx context.Context, policyName, policyYaml string, cmShouldBeDeleted bool) {
-		clientManagedDynamic := NewKubeClientDynamic("", KubeconfigManaged, "")
-
-		var clientHostingDynamic dynamic.Interface
-
-		if IsHosted {
-			clientHostingDynamic = NewKubeClientDynamic("", KubeconfigHub, "")
-		} else {
-			clientHostingDynamic = NewKubeClientDynamic("", KubeconfigManaged, "")
-		}
+		topology := env.Topology()
+		clientManagedDynamic := topology.Objects.ClientDynamic
+		clientHostingDynamic := topology.Templates.ClientDynamic
 
-		DoCreatePolicyTest(ctx, policyYaml, GvrConfigurationPolicy)
+		env.DoCreatePolicyTest(ctx, policyYaml, GvrConfigurationPolicy)
 
-		DoRootComplianceTest(policyName, policiesv1.Compliant)
+		env.DoRootComplianceTest(policyName, policiesv1.Compliant)
 
 		By("Checking if the status of ConfigurationPolicy " + policyName + " is Compliant")
 		Eventually(func() string {
 			cfgPol := utils.GetWithTimeout(clientHostingDynamic, GvrConfigurationPolicy,
-				policyName, ClusterNamespace, true, DefaultTimeoutSeconds)
+				policyName, env.ClusterNamespace, true, env.DefaultTimeoutSeconds)
 
 			compliant, _, _ := unstructured.NestedString(cfgPol.Object, "status", "compliant")
 
 			return compliant
-		}, DefaultTimeoutSeconds, 1).Should(Equal(string(policiesv1.Compliant)))
+		}, env.DefaultTimeoutSeconds, 1).Should(Equal(string(policiesv1.Compliant)))
 
 		if cmShouldBeDeleted {
 			By("Checking that the ConfigurationPolicy has a finalizer")
 			Eventually(func() []string {
 				cfgPol := utils.GetWithTimeout(clientHostingDynamic, GvrConfigurationPolicy,
-					policyName, ClusterNamespace, true, DefaultTimeoutSeconds)
+					policyName, env.ClusterNamespace, true, env.DefaultTimeoutSeconds)
 
 				return cfgPol.GetFinalizers()
 			}, 30, 5).ShouldNot(BeEmpty())
@@ -99,27 +94,26 @@ func ConfigPruneBehavior(labels ...string) bool {
 			pruneConfigMapName,
 			"default",
 			true,
-			DefaultTimeoutSeconds,
+			env.DefaultTimeoutSeconds,
 		)
 
 		By("Checking that the ConfigurationPolicy identified that it created the object")
 		Eventually(func(g Gomega) any {
-			cfgPol := utils.GetWithTimeout(clientHostingDynamic, GvrConfigurationPolicy,
-				policyName, ClusterNamespace, true, DefaultTimeoutSeconds)
+			unstructCfgPol := utils.GetWithTimeout(clientHostingDynamic, GvrConfigurationPolicy,
+				policyName, env.ClusterNamespace, true, env.DefaultTimeoutSeconds)
 
-			relObj, _, err := unstructured.NestedSlice(cfgPol.Object, "status", "relatedObjects")
+			cfgPol, err := ConfigurationPolicyFromUnstructured(unstructCfgPol)
 			g.Expect(err).ToNot(HaveOccurred())
-			g.Expect(relObj).ToNot(BeEmpty())
 
-			createdByPolicy, _, err := unstructured.NestedBool(
-				relObj[0].(map[string]any), "properties", "createdByPolicy")
+			relObj, err := cfgPol.RelatedObject(0)
 			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(relObj.Properties).ToNot(BeNil())
 
-			return createdByPolicy
-		}, DefaultTimeoutSeconds, 5).Should(BeTrue(), "createdByPolicy should be true")
+			return relObj.Properties.CreatedByPolicy != nil && *relObj.Properties.CreatedByPolicy
+		}, env.DefaultTimeoutSeconds, 5).Should(BeTrue(), "createdByPolicy should be true")
 
 		//nolint:contextcheck
-		DoCleanupPolicy(policyYaml, GvrConfigurationPolicy)
+		env.DoCleanupPolicy(policyYaml, GvrConfigurationPolicy)
 
 		By("Checking if the configmap was deleted")
 		utils.GetWithTimeout(
@@ -128,40 +122,34 @@ func ConfigPruneBehavior(labels ...string) bool {
 			pruneConfigMapName,
 			"default",
 			!cmShouldBeDeleted,
-			DefaultTimeoutSeconds,
+			env.DefaultTimeoutSeconds,
 		)
 	}
 
 	pruneTestForegroundDeletion := func(ctx context.Context, policyName, policyYaml string) {
-		clientManagedDynamic := NewKubeClientDynamic("", KubeconfigManaged, "")
-		clientHubDynamic := NewKubeClientDynamic("", KubeconfigHub, "")
-
-		var clientHostingDynamic dynamic.Interface
-
-		if IsHosted {
-			clientHostingDynamic = NewKubeClientDynamic("", KubeconfigHub, "")
-		} else {
-			clientHostingDynamic = NewKubeClientDynamic("", KubeconfigManaged, "")
-		}
+		topology := env.Topology()
+		clientManagedDynamic := topology.Objects.ClientDynamic
+		clientHubDynamic := env.ClientHubDynamic
+		clientHostingDynamic := topology.Templates.ClientDynamic
 
-		DoCreatePolicyTest(ctx, policyYaml, GvrConfigurationPolicy)
+		env.DoCreatePolicyTest(ctx, policyYaml, GvrConfigurationPolicy)
 
-		DoRootComplianceTest(policyName, policiesv1.Compliant)
+		env.DoRootComplianceTest(policyName, policiesv1.Compliant)
 
 		By("Checking if the status of ConfigurationPolicy " + policyName + " is Compliant")
 		Eventually(func() string {
 			cfgPol := utils.GetWithTimeout(clientHostingDynamic, GvrConfigurationPolicy,
-				policyName, ClusterNamespace, true, DefaultTimeoutSeconds)
+				policyName, env.ClusterNamespace, true, env.DefaultTimeoutSeconds)
 
 			compliant, _, _ := unstructured.NestedString(cfgPol.Object, "status", "compliant")
 
 			return compliant
-		}, DefaultTimeoutSeconds, 1).Should(Equal(string(policiesv1.Compliant)))
+		}, env.DefaultTimeoutSeconds, 1).Should(Equal(string(policiesv1.Compliant)))
 
 		By("Checking that the ConfigurationPolicy has a finalizer")
 		Eventually(func() []string {
 			cfgPol := utils.GetWithTimeout(clientHostingDynamic, GvrConfigurationPolicy,
-				policyName, ClusterNamespace, true, DefaultTimeoutSeconds)
+				policyName, env.ClusterNamespace, true, env.DefaultTimeoutSeconds)
 
 			return cfgPol.GetFinalizers()
 		}, 30, 5).ShouldNot(BeEmpty())
@@ -173,13 +161,13 @@ func ConfigPruneBehavior(labels ...string) bool {
 			pruneConfigMapName,
 			"default",
 			true,
-			DefaultTimeoutSeconds,
+			env.DefaultTimeoutSeconds,
 		)
 
 		By("Applying a finalizer to the configmap")
 
 		//nolint:contextcheck
-		_, err := OcManaged(
+		_, err := env.OcManaged(
 			"patch",
 			"configmap",
 			pruneConfigMapName,
@@ -193,9 +181,9 @@ func ConfigPruneBehavior(labels ...string) bool {
 		By("Deleting the root policy")
 
 		//nolint:contextcheck
-		_, err = OcHub(
+		_, err = env.OcHub(
 			"delete", "-f", policyYaml,
-			"-n", UserNamespace,
+			"-n", env.UserNamespace,
 			"--ignore-not-found",
 		)
 		Expect(err).ToNot(HaveOccurred())
@@ -204,9 +192,9 @@ func ConfigPruneBehavior(labels ...string) bool {
 			clientHubDynamic,
 			GvrPolicy,
 			policyName,
-			UserNamespace,
+			env.UserNamespace,
 			false,
-			DefaultTimeoutSeconds,
+			env.DefaultTimeoutSeconds,
 		)
 
 		// In the future, we might check the replicated Policy on the hub or managed cluster,
@@ -215,13 +203,13 @@ func ConfigPruneBehavior(labels ...string) bool {
 		By("Checking that the ConfigurationPolicy is still on the cluster")
 		Consistently(func() any {
 			return utils.GetWithTimeout(clientHostingDynamic, GvrConfigurationPolicy, policyName,
-				ClusterNamespace, true, DefaultTimeoutSeconds)
+				env.ClusterNamespace, true, env.DefaultTimeoutSeconds)
 		}, 30, 5).ShouldNot(BeNil())
 
 		By("Removing any finalizers from the configmap")
 
 		//nolint:contextcheck
-		_, err = OcManaged("patch", "configmap", pruneConfigMapName, "-n", "default",
+		_, err = env.OcManaged("patch", "configmap", pruneConfigMapName, "-n", "default",
 			"--type=json", "-p", `[{"op":"remove", "path":"/metadata/finalizers"}]`)
 		Expect(err).ToNot(HaveOccurred())
 
@@ -232,7 +220,7 @@ func ConfigPruneBehavior(labels ...string) bool {
 			pruneConfigMapName,
 			"default",
 			false,
-			DefaultTimeoutSeconds,
+			env.DefaultTimeoutSeconds,
 		)
 
 		By("Checking that the ConfigurationPolicy is now cleaned up")
@@ -240,42 +228,36 @@ func ConfigPruneBehavior(labels ...string) bool {
 			clientHostingDynamic,
 			GvrConfigurationPolicy,
 			policyName,
-			ClusterNamespace,
+			env.ClusterNamespace,
 			false,
-			DefaultTimeoutSeconds,
+			env.DefaultTimeoutSeconds,
 		)
 	}
 
 	pruneTestInformPolicy := func(ctx context.Context, policyName, policyYaml string, cmShouldBeDeleted bool) {
-		clientManagedDynamic := NewKubeClientDynamic("", KubeconfigManaged, "")
-
-		var clientHostingDynamic dynamic.Interface
-
-		if IsHosted {
-			clientHostingDynamic = NewKubeClientDynamic("", KubeconfigHub, "")
-		} else {
-			clientHostingDynamic = NewKubeClientDynamic("", KubeconfigManaged, "")
-		}
+		topology := env.Topology()
+		clientManagedDynamic := topology.Objects.ClientDynamic
+		clientHostingDynamic := topology.Templates.ClientDynamic
 
-		DoCreatePolicyTest(ctx, policyYaml, GvrConfigurationPolicy)
+		env.DoCreatePolicyTest(ctx, policyYaml, GvrConfigurationPolicy)
 
-		DoRootComplianceTest(policyName, policiesv1.Compliant)
+		env.DoRootComplianceTest(policyName, policiesv1.Compliant)
 
 		By("Checking if the status of ConfigurationPolicy " + policyName + " is Compliant")
 		Eventually(func() string {
 			cfgPol := utils.GetWithTimeout(clientHostingDynamic, GvrConfigurationPolicy,
-				policyName, ClusterNamespace, true, DefaultTimeoutSeconds)
+				policyName, env.ClusterNamespace, true, env.DefaultTimeoutSeconds)
 
 			compliant, _, _ := unstructured.NestedString(cfgPol.Object, "status", "compliant")
 
 			return compliant
-		}, DefaultTimeoutSeconds, 1).Should(Equal(string(policiesv1.Compliant)))
+		}, env.DefaultTimeoutSeconds, 1).Should(Equal(string(policiesv1.Compliant)))
 
 		if cmShouldBeDeleted {
 			By("Checking that the ConfigurationPolicy has a finalizer")
 			Eventually(func() []string {
 				cfgPol := utils.GetWithTimeout(clientHostingDynamic, GvrConfigurationPolicy,
-					policyName, ClusterNamespace, true, DefaultTimeoutSeconds)
+					policyName, env.ClusterNamespace, true, env.DefaultTimeoutSeconds)
 
 				return cfgPol.GetFinalizers()
 			}, 30, 5).ShouldNot(BeEmpty())
@@ -287,16 +269,16 @@ func ConfigPruneBehavior(labels ...string) bool {
 			GvrConfigMap,
 			pruneConfigMapName,
 			"default", true,
-			DefaultTimeoutSeconds,
+			env.DefaultTimeoutSeconds,
 		)
 
 		By("Changing the policy to inform")
 
 		//nolint:contextcheck
-		_, err := OcHub(
+		_, err := env.OcHub(
 			"patch",
 			"policies.policy.open-cluster-management.io",
-			policyName, "-n", UserNamespace,
+			policyName, "-n", env.UserNamespace,
 			"--type=json", "-p",
 			`[{"op":"replace", "path":"/spec/remediationAction", "value":"inform"}]`,
 		)
@@ -307,9 +289,9 @@ func ConfigPruneBehavior(labels ...string) bool {
 				clientHostingDynamic,
 				GvrConfigurationPolicy,
 				policyName,
-				ClusterNamespace,
+				env.ClusterNamespace,
 				true,
-				DefaultTimeoutSeconds,
+				env.DefaultTimeoutSeconds,
 			)
 			if configpol == nil {
 				return errors.New("could not get configuration policy")
@@ -318,10 +300,10 @@ func ConfigPruneBehavior(labels ...string) bool {
 			remAction, _, _ := unstructured.NestedString(configpol.Object, "spec", "remediationAction")
 
 			return remAction
-		}, DefaultTimeoutSeconds, 1).Should(MatchRegexp(".nform"))
+		}, env.DefaultTimeoutSeconds, 1).Should(MatchRegexp(".nform"))
 
 		//nolint:contextcheck
-		DoCleanupPolicy(policyYaml, GvrConfigurationPolicy)
+		env.DoCleanupPolicy(policyYaml, GvrConfigurationPolicy)
 
 		By("Checking if the configmap was deleted")
 		utils.GetWithTimeout(
@@ -330,25 +312,19 @@ func ConfigPruneBehavior(labels ...string) bool {
 			pruneConfigMapName,
 			"default",
 			!cmShouldBeDeleted,
-			DefaultTimeoutSeconds,
+			env.DefaultTimeoutSeconds,
 		)
 	}
 
 	pruneTestEditedByPolicy := func(ctx context.Context, policyName, policyYaml string, cmShouldBeDeleted bool) {
-		clientManagedDynamic := NewKubeClientDynamic("", KubeconfigManaged, "")
-
-		var clientHostingDynamic dynamic.Interface
-
-		if IsHosted {
-			clientHostingDynamic = NewKubeClientDynamic("", KubeconfigHub, "")
-		} else {
-			clientHostingDynamic = NewKubeClientDynamic("", KubeconfigManaged, "")
-		}
+		topology := env.Topology()
+		clientManagedDynamic := topology.Objects.ClientDynamic
+		clientHostingDynamic := topology.Templates.ClientDynamic
 
 		By("Creating the configmap before the policy")
 
 		//nolint:contextcheck
-		_, err := OcManaged("apply", "-f", pruneConfigMapYaml, "-n", "default")
+		_, err := env.OcManaged("apply", "-f", pruneConfigMapYaml, "-n", "default")
 		Expect(err).ToNot(HaveOccurred())
 		By("Checking the configmap's initial data")
 
@@ -361,7 +337,7 @@ func ConfigPruneBehavior(labels ...string) bool {
 				pruneConfigMapName,
 				"default",
 				true,
-				DefaultTimeoutSeconds,
+				env.DefaultTimeoutSeconds,
 			)
 			data, ok, err := unstructured.NestedMap(cm.Object, "data")
 			g.Expect(ok).To(BeTrue())
@@ -370,27 +346,27 @@ func ConfigPruneBehavior(labels ...string) bool {
 			initialValue, ok = data["testvalue"].(string)
 			g.Expect(ok).To(BeTrue())
 			g.Expect(initialValue).ToNot(BeEmpty())
-		}, DefaultTimeoutSeconds, 1).Should(Succeed())
+		}, env.DefaultTimeoutSeconds, 1).Should(Succeed())
 
-		DoCreatePolicyTest(ctx, policyYaml, GvrConfigurationPolicy)
+		env.DoCreatePolicyTest(ctx, policyYaml, GvrConfigurationPolicy)
 
-		DoRootComplianceTest(policyName, policiesv1.Compliant)
+		env.DoRootComplianceTest(policyName, policiesv1.Compliant)
 
 		By("Checking if the status of ConfigurationPolicy " + policyName + " is Compliant")
 		Eventually(func() string {
 			cfgPol := utils.GetWithTimeout(clientHostingDynamic, GvrConfigurationPolicy,
-				policyName, ClusterNamespace, true, DefaultTimeoutSeconds)
+				policyName, env.ClusterNamespace, true, env.DefaultTimeoutSeconds)
 
 			compliant, _, _ := unstructured.NestedString(cfgPol.Object, "status", "compliant")
 
 			return compliant
-		}, DefaultTimeoutSeconds, 1).Should(Equal(string(policiesv1.Compliant)))
+		}, env.DefaultTimeoutSeconds, 1).Should(Equal(string(policiesv1.Compliant)))
 
 		if cmShouldBeDeleted {
 			By("Checking that the ConfigurationPolicy has a finalizer")
 			Eventually(func() []string {
 				cfgPol := utils.GetWithTimeout(clientHostingDynamic, GvrConfigurationPolicy,
-					policyName, ClusterNamespace, true, DefaultTimeoutSeconds)
+					policyName, env.ClusterNamespace, true, env.DefaultTimeoutSeconds)
 
 				return cfgPol.GetFinalizers()
 			}, 30, 5).ShouldNot(BeEmpty())
@@ -404,7 +380,7 @@ func ConfigPruneBehavior(labels ...string) bool {
 				pruneConfigMapName,
 				"default",
 				true,
-				DefaultTimeoutSeconds,
+				env.DefaultTimeoutSeconds,
 			)
 			data, ok, err := unstructured.NestedMap(cm.Object, "data")
 			g.Expect(ok).To(BeTrue())
@@ -413,10 +389,10 @@ func ConfigPruneBehavior(labels ...string) bool {
 			newValue, ok := data["testvalue"].(string)
 			g.Expect(ok).To(BeTrue())
 			g.Expect(newValue).To(Not(Equal(initialValue)))
-		}, DefaultTimeoutSeconds, 1).Should(Succeed())
+		}, env.DefaultTimeoutSeconds, 1).Should(Succeed())
 
 		//nolint:contextcheck
-		DoCleanupPolicy(policyYaml, GvrConfigurationPolicy)
+		env.DoCleanupPolicy(policyYaml, GvrConfigurationPolicy)
 
 		By("Checking if the configmap was deleted")
 		utils.GetWithTimeout(
@@ -425,7 +401,7 @@ func ConfigPruneBehavior(labels ...string) bool {
 			pruneConfigMapName,
 			"default",
 			!cmShouldBeDeleted,
-			DefaultTimeoutSeconds,
+			env.DefaultTimeoutSeconds,
 		)
 	}
 
@@ -434,12 +410,12 @@ func ConfigPruneBehavior(labels ...string) bool {
 		cleanConfigMap := func() {
 			By("Removing any finalizers from the configmap")
 
-			_, _ = OcManaged("patch", "configmap", pruneConfigMapName, "-n", "default",
+			_, _ = env.OcManaged("patch", "configmap", pruneConfigMapName, "-n", "default",
 				"--type=json", "-p", `[{"op":"remove", "path":"/metadata/finalizers"}]`)
 
 			By("Deleting the configmap")
 
-			_, err := OcManaged(
+			_, err := env.OcManaged(
 				"delete", "-f", pruneConfigMapYaml,
 				"--ignore-not-found", "--timeout=30s",
 			)
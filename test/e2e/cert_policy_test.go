@@ -265,20 +265,20 @@ var _ = Describe("Test cert policy", func() {
 			Expect(err).ToNot(HaveOccurred())
 
 			_, err = common.OcHosting(
-				"delete", "events", "-n", common.ClusterNamespace,
+				"delete", "events", "-n", clusterNamespace,
 				"--field-selector=involvedObject.name="+certPolicyName,
 				"--ignore-not-found",
 			)
 			Expect(err).ToNot(HaveOccurred())
 			_, err = common.OcHosting(
-				"delete", "events", "-n", common.ClusterNamespace,
-				"--field-selector=involvedObject.name="+common.UserNamespace+"."+certPolicyName,
+				"delete", "events", "-n", clusterNamespace,
+				"--field-selector=involvedObject.name="+userNamespace+"."+certPolicyName,
 				"--ignore-not-found",
 			)
 			Expect(err).ToNot(HaveOccurred())
 			_, err = common.OcHub(
-				"delete", "events", "-n", common.ClusterNamespaceOnHub,
-				"--field-selector=involvedObject.name="+common.UserNamespace+"."+certPolicyName,
+				"delete", "events", "-n", clusterNamespaceOnHub,
+				"--field-selector=involvedObject.name="+userNamespace+"."+certPolicyName,
 				"--ignore-not-found",
 			)
 			Expect(err).ToNot(HaveOccurred())
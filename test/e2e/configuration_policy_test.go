@@ -24,20 +24,20 @@ func configPolicyTestCleanUp(rolePolicyName, rolePolicyYAML string) {
 	common.DoCleanupPolicy(rolePolicyYAML, common.GvrConfigurationPolicy)
 
 	_, err := common.OcHosting(
-		"delete", "events", "-n", common.ClusterNamespace,
-		"--field-selector=involvedObject.name="+common.UserNamespace+"."+rolePolicyName,
+		"delete", "events", "-n", clusterNamespace,
+		"--field-selector=involvedObject.name="+userNamespace+"."+rolePolicyName,
 		"--ignore-not-found",
 	)
 	Expect(err).ToNot(HaveOccurred())
 	_, err = common.OcHosting(
-		"delete", "events", "-n", common.ClusterNamespace,
+		"delete", "events", "-n", clusterNamespace,
 		"--field-selector=involvedObject.name="+rolePolicyName,
 		"--ignore-not-found",
 	)
 	Expect(err).ToNot(HaveOccurred())
 	_, err = common.OcHub(
-		"delete", "events", "-n", common.ClusterNamespaceOnHub,
-		"--field-selector=involvedObject.name="+common.UserNamespace+"."+rolePolicyName,
+		"delete", "events", "-n", clusterNamespaceOnHub,
+		"--field-selector=involvedObject.name="+userNamespace+"."+rolePolicyName,
 		"--ignore-not-found",
 	)
 	Expect(err).ToNot(HaveOccurred())
@@ -4,12 +4,11 @@
 package e2e
 
 import (
+	"encoding/json"
 	"testing"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
-	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
@@ -34,6 +33,13 @@ var (
 	clientHostingDynamic  dynamic.Interface
 )
 
+// suiteData is set up once by process 1 in SynchronizedBeforeSuite, and shared with the other
+// processes.
+type suiteData struct {
+	RunID        string
+	Capabilities common.Capabilities
+}
+
 func TestE2e(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "Policy Framework e2e Suite")
@@ -51,53 +57,74 @@ var _ = test.TemplateSyncErrors()
 
 var _ = test.PolicyOrdering()
 
-var _ = BeforeSuite(func(ctx SpecContext) {
+var _ = SynchronizedBeforeSuite(func(ctx SpecContext) []byte {
+	By("Snapshotting the resources audited for leaks")
+
+	Expect(common.DefaultEnvironment.InitInterfaces()).To(Succeed())
+	Expect(common.StartLeakAudit(ctx)).To(Succeed())
+
+	By("Discovering the capabilities of the clusters")
+	Expect(common.ReportCapabilities(ctx)).To(Succeed())
+
+	capabilities, err := common.DiscoverCapabilities(ctx)
+	Expect(err).ToNot(HaveOccurred())
+
+	GinkgoWriter.Println("Labeling the created objects with the run ID " + common.DefaultEnvironment.RunID)
+
+	payload, err := json.Marshal(suiteData{RunID: common.DefaultEnvironment.RunID, Capabilities: capabilities})
+	Expect(err).ToNot(HaveOccurred())
+
+	return payload
+}, func(ctx SpecContext, payload []byte) {
+	var data suiteData
+	Expect(json.Unmarshal(payload, &data)).To(Succeed())
+
 	By("Setup hub and managed client")
 
-	common.InitInterfaces(common.KubeconfigHub, common.KubeconfigManaged, common.IsHosted)
-
-	kubeconfigHub = common.KubeconfigHub
-	kubeconfigManaged = common.KubeconfigManaged
-	userNamespace = common.UserNamespace
-	clusterNamespace = common.ClusterNamespace
-	defaultTimeoutSeconds = common.DefaultTimeoutSeconds
-	clusterNamespaceOnHub = common.ClusterNamespaceOnHub
-	clientHub = common.ClientHub
-	clientHubDynamic = common.ClientHubDynamic
-	clientManaged = common.ClientManaged
-	clientManagedDynamic = common.ClientManagedDynamic
-	clientHosting = common.ClientHosting
-	clientHostingDynamic = common.ClientHostingDynamic
+	env := common.DefaultEnvironment
+	env.RunID = data.RunID
+	env.SetCapabilities(data.Capabilities)
+	Expect(env.InitInterfaces()).To(Succeed())
+
+	kubeconfigHub = env.KubeconfigHub
+	kubeconfigManaged = env.KubeconfigManaged
+	clusterNamespace = env.ClusterNamespace
+	defaultTimeoutSeconds = env.DefaultTimeoutSeconds
+	clusterNamespaceOnHub = env.ClusterNamespaceOnHub
+	clientHub = env.ClientHub
+	clientHubDynamic = env.ClientHubDynamic
+	clientManaged = env.ClientManaged
+	clientManagedDynamic = env.ClientManagedDynamic
+	clientHosting = env.ClientHosting
+	clientHostingDynamic = env.ClientHostingDynamic
 
 	common.VerifyManagedCluster(ctx)
 	common.VerifyMCE(ctx)
 
-	By("Create Namespace if needed")
+	By("Setting up the user namespace")
 
-	namespaces := clientHub.CoreV1().Namespaces()
-	if _, err := namespaces.Get(
-		ctx,
-		userNamespace,
-		metav1.GetOptions{},
-	); err != nil && errors.IsNotFound(err) {
-		Expect(namespaces.Create(ctx, &corev1.Namespace{
-			ObjectMeta: metav1.ObjectMeta{
-				Name: userNamespace,
-			},
-		}, metav1.CreateOptions{})).NotTo(BeNil())
-	}
-
-	Expect(namespaces.Get(ctx, userNamespace, metav1.GetOptions{})).NotTo(BeNil())
-
-	if !common.ManuallyPatchDecisions {
-		By("Create ManagedClusterSetBinding")
-
-		err := common.ApplyManagedClusterSetBinding(ctx)
-		Expect(err).ToNot(HaveOccurred())
-	}
+	Expect(common.SetupUserNamespace(ctx, common.UserNamespaceOptions{
+		ManagedClusterSetBinding: !env.ManuallyPatchDecisions,
+		KeepNamespace:            true,
+	})).To(Succeed())
+
+	userNamespace = env.UserNamespace
 })
 
-var _ = AfterSuite(func(ctx SpecContext) {
+// Skip the specs whose decorators, like common.RequiresOCP, aren't met by the clusters. As a top
+// level BeforeEach, it runs before the BeforeAll nodes of ordered containers.
+var _ = BeforeEach(common.CheckGates)
+
+// Count the API requests of each spec, including those of the BeforeAll nodes, in its report.
+var _ = BeforeEach(common.TrackAPIRequests)
+
+// Record the transcripts of the oc/kubectl commands of each spec in its report.
+var _ = BeforeEach(common.TrackCommands)
+
+// Collect a debug bundle of each failed spec, before its AfterEach and AfterAll cleanup.
+var _ = JustAfterEach(common.ReportFailedSpecDebugBundle)
+
+var _ = SynchronizedAfterSuite(func(ctx SpecContext) {
 	By("Cleaning up generated PlacementDecisions")
 	Expect(clientHubDynamic.Resource(common.GvrPlacementDecision).Namespace(userNamespace).DeleteCollection(
 		ctx,
@@ -105,13 +132,7 @@ var _ = AfterSuite(func(ctx SpecContext) {
 		metav1.ListOptions{
 			LabelSelector: "generated-by-policy-test",
 		})).To(Succeed())
-
-	if !common.ManuallyPatchDecisions {
-		By("Cleaning up ManagedClusterSetBinding")
-
-		_ = clientHubDynamic.Resource(common.GvrManagedClusterSetBinding).
-			Namespace(userNamespace).Delete(
-			ctx, "global", metav1.DeleteOptions{},
-		)
-	}
+}, func() {
+	// Process 1 waits here for the other processes to finish, so that the leak audit started in
+	// SynchronizedBeforeSuite runs once none of them are creating objects anymore.
 })
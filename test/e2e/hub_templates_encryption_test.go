@@ -40,11 +40,29 @@ var _ = Describe("Test Hub Template Encryption", Ordered, func() {
 		const lastRotatedAnnotation = "policy.open-cluster-management.io/last-rotated"
 		const triggerUpdateAnnotation = "policy.open-cluster-management.io/trigger-update"
 
-		JustAfterEach(func(ctx SpecContext) {
-			if CurrentSpecReport().Failed() {
-				DebugHubEncryption(policyName, secretName, configMapName, userNamespace,
-					configMapCopyName, secretCopyName)
-			}
+		BeforeAll(func() {
+			common.AddDebugResources(common.DebugBundleOptions{
+				Resources: []common.DebugResource{
+					{Cluster: common.TargetHub, GVR: common.GvrSecret, Namespace: userNamespace, Name: secretName},
+					{
+						Cluster: common.TargetHub, GVR: common.GvrConfigMap, Namespace: userNamespace,
+						Name: configMapName,
+					},
+					{
+						Cluster: common.TargetHub, GVR: common.GvrSecret, Namespace: clusterNamespace,
+						Name: "policy-encryption-key",
+					},
+					{
+						Cluster: common.TargetHosting, GVR: common.GvrSecret, Namespace: clusterNamespace,
+						Name: "policy-encryption-key",
+					},
+					{Cluster: common.TargetManaged, GVR: common.GvrSecret, Namespace: "default", Name: secretCopyName},
+					{
+						Cluster: common.TargetManaged, GVR: common.GvrConfigMap, Namespace: "default",
+						Name: configMapCopyName,
+					},
+				},
+			})
 		})
 
 		It("Should be created on the managed cluster", func() {
@@ -79,25 +97,18 @@ var _ = Describe("Test Hub Template Encryption", Ordered, func() {
 			)
 			Expect(managedplc).NotTo(BeNil())
 
-			plcTemplates, ok, err := unstructured.NestedSlice(managedplc.Object, "spec", "policy-templates")
-			Expect(ok).To(BeTrue())
+			replicatedPlc, err := common.PolicyFromUnstructured(managedplc)
 			Expect(err).ToNot(HaveOccurred())
-			Expect(plcTemplates).To(HaveLen(1))
+			Expect(replicatedPlc.Spec.PolicyTemplates).To(HaveLen(1))
 
-			plcTemplate, ok := plcTemplates[0].(map[string]any)
-			Expect(ok).To(BeTrue())
-
-			objectTemplates, ok, err := unstructured.NestedSlice(
-				plcTemplate, "objectDefinition", "spec", "object-templates",
-			)
-			Expect(ok).To(BeTrue())
+			configPolicy, err := common.PolicyTemplateConfigurationPolicy(replicatedPlc, 0)
 			Expect(err).ToNot(HaveOccurred())
-			Expect(objectTemplates).To(HaveLen(2))
+			Expect(configPolicy.Spec.ObjectTemplates).To(HaveLen(2))
 
-			secretTemplate, ok := objectTemplates[0].(map[string]any)
-			Expect(ok).To(BeTrue())
+			secretTemplate, err := configPolicy.ObjectTemplate(0)
+			Expect(err).ToNot(HaveOccurred())
 
-			city, ok, err := unstructured.NestedString(secretTemplate, "objectDefinition", "data", "city")
+			city, ok, err := unstructured.NestedString(secretTemplate.ObjectDefinition, "data", "city")
 			Expect(ok).To(BeTrue())
 			Expect(err).ToNot(HaveOccurred())
 
@@ -107,7 +118,7 @@ var _ = Describe("Test Hub Template Encryption", Ordered, func() {
 			Expect(strings.Contains(city, "Raleigh")).ToNot(BeTrue())
 			Expect(strings.Contains(city, "UmFsZWlnaA==")).ToNot(BeTrue())
 
-			state, ok, err := unstructured.NestedString(secretTemplate, "objectDefinition", "data", "state")
+			state, ok, err := unstructured.NestedString(secretTemplate.ObjectDefinition, "data", "state")
 			Expect(ok).To(BeTrue())
 			Expect(err).ToNot(HaveOccurred())
 
@@ -117,10 +128,10 @@ var _ = Describe("Test Hub Template Encryption", Ordered, func() {
 			Expect(strings.Contains(state, "North Carolina")).ToNot(BeTrue())
 			Expect(strings.Contains(state, "Tm9ydGggQ2Fyb2xpbmE=")).ToNot(BeTrue())
 
-			configMapTemplate, ok := objectTemplates[1].(map[string]any)
-			Expect(ok).To(BeTrue())
+			configMapTemplate, err := configPolicy.ObjectTemplate(1)
+			Expect(err).ToNot(HaveOccurred())
 
-			cert, ok, err := unstructured.NestedString(configMapTemplate, "objectDefinition", "data", "cert")
+			cert, ok, err := unstructured.NestedString(configMapTemplate.ObjectDefinition, "data", "cert")
 			Expect(ok).To(BeTrue())
 			Expect(err).ToNot(HaveOccurred())
 
@@ -308,74 +319,3 @@ var _ = Describe("Test Hub Template Encryption", Ordered, func() {
 		})
 	})
 })
-
-func DebugHubEncryption(policyName, secretName, configMapName, hubNs,
-	configMapCopyName, secretCopyName string,
-) {
-	By("Collecting debug information")
-
-	dumpDebug := func(title string, output string) {
-		GinkgoWriter.Printf("\n=== DEBUG: %s:\n%s", title, output)
-	}
-
-	outYaml := "-o=yaml"
-
-	debugHubCmds := map[string][]string{
-		"Root Policy in " + hubNs: {
-			"get", "policy", policyName, "-n", hubNs, outYaml,
-		},
-		"Secret output": {
-			"get", "secret", secretName, "-n", hubNs, outYaml,
-		},
-		"ConfigMap output": {
-			"get", "configmap", configMapName, "-n", hubNs, outYaml,
-		},
-		"Encryption key output on the hub": {
-			"get", "secret", "policy-encryption-key", "-n", clusterNamespace, outYaml,
-		},
-	}
-
-	for cmdName, cmd := range debugHubCmds {
-		out, _ := common.OcHub(cmd...)
-		dumpDebug(cmdName, out)
-	}
-
-	gfaNs := "open-cluster-management-agent-addon"
-	if common.IsHosted {
-		gfaNs = clusterNamespace
-	}
-
-	debugHostingCmds := map[string][]string{
-		"ConfigurationPolicy list": {
-			"get", "configurationpolicy", "-n", clusterNamespace,
-		},
-		"ConfigurationPolicy output": {
-			"get", "configurationpolicy", "-n", clusterNamespace, outYaml,
-		},
-		"Governance-policy-framework-addon log": {
-			"logs", "deployment/governance-policy-framework", "-n", gfaNs,
-		},
-		"Encryption key output on Managed Cluster": {
-			"get", "secret", "policy-encryption-key", "-n", clusterNamespace, outYaml,
-		},
-	}
-
-	for cmdName, cmd := range debugHostingCmds {
-		out, _ := common.OcHosting(cmd...)
-		dumpDebug(cmdName, out)
-	}
-
-	debugManagedCmds := map[string][]string{
-		"Secret output on Managed Cluster": {
-			"get", "secret", secretCopyName, "-n", "default", outYaml,
-		},
-		"ConfigMap output on Managed Cluster": {
-			"get", "configmap", configMapCopyName, "-n", "default", outYaml,
-		},
-	}
-
-	for cmdName, cmd := range debugManagedCmds {
-		out, _ := common.OcManaged(cmd...)
-		dumpDebug(cmdName, out)
-	}
-}
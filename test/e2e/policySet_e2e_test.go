@@ -36,7 +36,7 @@ var _ = Describe("Test policy set", func() {
 			err = common.PatchPlacement(userNamespace, testPolicySetName+"-plr")
 			Expect(err).ToNot(HaveOccurred())
 
-			if common.ManuallyPatchDecisions {
+			if common.DefaultEnvironment.ManuallyPatchDecisions {
 				By("Patching " + testPolicySetName + "-plr with decision of cluster " + clusterNamespaceOnHub)
 				pld, err := common.CreatePlacementDecision(ctx, userNamespace, testPolicySetName+"-plr")
 				Expect(err).ToNot(HaveOccurred())
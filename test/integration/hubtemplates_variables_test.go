@@ -46,7 +46,7 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test hub template variables", Orde
 			Expect(cm.Data["label"]).To(Equal("raleigh"))
 			Expect(cm.Data["annotation"]).To(Equal("NC"))
 			Expect(cm.Data["name"]).To(Equal(policyName))
-			Expect(cm.Data["namespace"]).To(Equal(common.UserNamespace))
+			Expect(cm.Data["namespace"]).To(Equal(userNamespace))
 		})
 
 		AfterAll(func() {
@@ -67,7 +67,7 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test hub template variables", Orde
 
 			_, err = common.OcManaged(
 				"delete", "events", "-n", clusterNamespace,
-				"--field-selector=involvedObject.name="+common.UserNamespace+"."+policyName,
+				"--field-selector=involvedObject.name="+userNamespace+"."+policyName,
 				"--ignore-not-found",
 			)
 			Expect(err).ToNot(HaveOccurred())
@@ -114,7 +114,7 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test hub template variables", Orde
 
 			_, err = common.OcManaged(
 				"delete", "events", "-n", clusterNamespace,
-				"--field-selector=involvedObject.name="+common.UserNamespace+"."+policyName,
+				"--field-selector=involvedObject.name="+userNamespace+"."+policyName,
 				"--ignore-not-found",
 			)
 			Expect(err).ToNot(HaveOccurred())
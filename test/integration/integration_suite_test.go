@@ -3,19 +3,16 @@
 package integration
 
 import (
+	"encoding/json"
 	"fmt"
-	"strings"
 	"testing"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
-	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog"
-	"open-cluster-management.io/governance-policy-propagator/test/utils"
 
 	"github.com/stolostron/governance-policy-framework/test/common"
 )
@@ -38,20 +35,29 @@ var (
 	ocmAddonNS            string
 	kubeconfigHub         string
 	kubeconfigManaged     string
+	contextHub            string
+	contextManaged        string
 	defaultTimeoutSeconds int
 	clientHub             kubernetes.Interface
 	clientHubDynamic      dynamic.Interface
 	clientManaged         kubernetes.Interface
 	clientManagedDynamic  dynamic.Interface
 	gitopsUser            common.OCPUser
-
-	canCreateOpenshiftNamespacesInitialized bool
-	canCreateOpenshiftNamespacesResult      bool
 )
 
+// suiteData is set up once by process 1 in SynchronizedBeforeSuite, and shared with the other
+// processes.
+type suiteData struct {
+	RunID        string
+	Capabilities common.Capabilities
+	// GitOpsUser is the user of the GitOps tests, with the path of its kubeconfig.
+	GitOpsUser common.OCPUser
+}
+
 func TestIntegration(t *testing.T) {
 	policyCollectBaseURL = fmt.Sprintf(
-		"https://raw.githubusercontent.com/stolostron/policy-collection/%s/", common.PolicyCollectionBranch,
+		"https://raw.githubusercontent.com/stolostron/policy-collection/%s/",
+		common.DefaultEnvironment.PolicyCollectionBranch,
 	)
 	policyCollectCommunityURL = policyCollectBaseURL + "community/"
 	policyCollectStableURL = policyCollectBaseURL + "stable/"
@@ -71,52 +77,78 @@ func init() {
 	common.InitFlags(nil)
 }
 
-var _ = BeforeSuite(func(ctx SpecContext) {
+var _ = SynchronizedBeforeSuite(func(ctx SpecContext) []byte {
+	By("Snapshotting the resources audited for leaks")
+
+	Expect(common.DefaultEnvironment.InitInterfaces()).To(Succeed())
+	Expect(common.StartLeakAudit(ctx)).To(Succeed())
+
+	By("Discovering the capabilities of the clusters")
+	Expect(common.ReportCapabilities(ctx)).To(Succeed())
+
+	capabilities, err := common.DiscoverCapabilities(ctx)
+	Expect(err).ToNot(HaveOccurred())
+
+	GinkgoWriter.Println("Labeling the created objects with the run ID " + common.DefaultEnvironment.RunID)
+
+	data := suiteData{RunID: common.DefaultEnvironment.RunID, Capabilities: capabilities}
+
+	By("Setting up GitOps user")
+	common.GitOpsUserSetup(ctx, &data.GitOpsUser)
+
+	payload, err := json.Marshal(data)
+	Expect(err).ToNot(HaveOccurred())
+
+	return payload
+}, func(ctx SpecContext, payload []byte) {
+	var data suiteData
+	Expect(json.Unmarshal(payload, &data)).To(Succeed())
+
+	gitopsUser = data.GitOpsUser
+
 	By("Setup hub and managed client")
-	common.InitInterfaces(common.KubeconfigHub, common.KubeconfigManaged, common.IsHosted)
-	kubeconfigHub = common.KubeconfigHub
-	kubeconfigManaged = common.KubeconfigManaged
-	userNamespace = common.UserNamespace
-	clusterNamespace = common.ClusterNamespace
-	ocmNS = common.OCMNamespace
-	ocmAddonNS = common.OCMAddOnNamespace
-	defaultTimeoutSeconds = common.DefaultTimeoutSeconds
-
-	clientHub = common.ClientHub
-	clientHubDynamic = common.ClientHubDynamic
-	clientManaged = common.ClientManaged
-	clientManagedDynamic = common.ClientManagedDynamic
+	env := common.DefaultEnvironment
+	env.RunID = data.RunID
+	env.SetCapabilities(data.Capabilities)
+	Expect(env.InitInterfaces()).To(Succeed())
+	kubeconfigHub = env.KubeconfigHub
+	kubeconfigManaged = env.KubeconfigManaged
+	contextHub = env.ContextHub
+	contextManaged = env.ContextManaged
+	clusterNamespace = env.ClusterNamespace
+	ocmNS = env.OCMNamespace
+	ocmAddonNS = env.OCMAddOnNamespace
+	defaultTimeoutSeconds = env.DefaultTimeoutSeconds
+
+	clientHub = env.ClientHub
+	clientHubDynamic = env.ClientHubDynamic
+	clientManaged = env.ClientManaged
+	clientManagedDynamic = env.ClientManagedDynamic
 
 	common.VerifyManagedCluster(ctx)
 	common.VerifyMCE(ctx)
 
-	By("Create Namespace if needed")
+	By("Setting up the user namespace")
 
-	namespaces := clientHub.CoreV1().Namespaces()
-	if _, err := namespaces.Get(
-		ctx,
-		userNamespace,
-		metav1.GetOptions{},
-	); err != nil && errors.IsNotFound(err) {
-		Expect(namespaces.Create(ctx, &corev1.Namespace{
-			ObjectMeta: metav1.ObjectMeta{
-				Name: userNamespace,
-			},
-		}, metav1.CreateOptions{})).NotTo(BeNil())
-	}
+	Expect(common.SetupUserNamespace(ctx, common.UserNamespaceOptions{ManagedClusterSetBinding: true})).To(Succeed())
 
-	Expect(namespaces.Get(ctx, userNamespace, metav1.GetOptions{})).NotTo(BeNil())
+	userNamespace = env.UserNamespace
+})
 
-	By("Create ManagedClusterSetBinding")
+// Skip the specs whose decorators, like common.RequiresOCP, aren't met by the clusters. As a top
+// level BeforeEach, it runs before the BeforeAll nodes of ordered containers.
+var _ = BeforeEach(common.CheckGates)
 
-	err := common.ApplyManagedClusterSetBinding(ctx)
-	Expect(err).ToNot(HaveOccurred())
+// Count the API requests of each spec, including those of the BeforeAll nodes, in its report.
+var _ = BeforeEach(common.TrackAPIRequests)
 
-	By("Setting up GitOps user")
-	common.GitOpsUserSetup(ctx, &gitopsUser)
-})
+// Record the transcripts of the oc/kubectl commands of each spec in its report.
+var _ = BeforeEach(common.TrackCommands)
 
-var _ = AfterSuite(func(ctx SpecContext) {
+// Collect a debug bundle of each failed spec, before its AfterEach and AfterAll cleanup.
+var _ = JustAfterEach(common.ReportFailedSpecDebugBundle)
+
+var _ = SynchronizedAfterSuite(func(ctx SpecContext) {
 	By("Cleaning up generated PlacementDecisions")
 	Expect(clientHubDynamic.Resource(common.GvrPlacementDecision).Namespace(userNamespace).DeleteCollection(
 		ctx,
@@ -125,62 +157,14 @@ var _ = AfterSuite(func(ctx SpecContext) {
 			LabelSelector: "generated-by-policy-test",
 		})).To(Succeed())
 
-	if userNamespace != "open-cluster-management-global-set" {
-		By("Delete Namespace if needed")
-
-		_, err := common.OcHub(
-			"delete", "namespace", userNamespace,
-			"--ignore-not-found",
-		)
-		Expect(err).ToNot(HaveOccurred())
-
-		_, err = common.OcHub(
-			"delete", "managedclustersetbinding", "global", "-n",
-			userNamespace, "--ignore-not-found",
-		)
-		Expect(err).ToNot(HaveOccurred())
-	}
-
 	_, err := common.OcHub(
 		"delete", "pod", "default",
 		"pod-that-does-not-exist", "--ignore-not-found",
 	)
 	Expect(err).ToNot(HaveOccurred())
-
+}, func(ctx SpecContext) {
+	// Process 1 waits here for the other processes to finish, so that the GitOps user they share is
+	// only removed once none of them use it, and the leak audit started in SynchronizedBeforeSuite
+	// runs once none of them are creating objects anymore.
 	common.GitOpsCleanup(ctx, gitopsUser)
 })
-
-func canCreateOpenshiftNamespaces() bool {
-	// Only check once - this makes it faster and prevents the answer changing mid-suite.
-	if canCreateOpenshiftNamespacesInitialized {
-		return canCreateOpenshiftNamespacesResult
-	}
-
-	canCreateOpenshiftNamespacesInitialized = true
-	canCreateOpenshiftNamespacesResult = false
-
-	// A server-side dry run will check the admission webhooks, but it needs to use the right
-	// serviceaccount because the kubeconfig might have superuser privileges to get around them.
-	out, _ := utils.KubectlWithOutput(
-		"create", "ns", "openshift-grc-test",
-		"--kubeconfig="+kubeconfigManaged,
-		"--dry-run=server",
-		"--as=system:serviceaccount:"+ocmAddonNS+":config-policy-controller-sa",
-	)
-
-	if strings.Contains(out, "namespace/openshift-grc-test created") {
-		canCreateOpenshiftNamespacesResult = true
-	}
-
-	if strings.Contains(out, "namespaces \"openshift-grc-test\" already exists") {
-		// Weird situation, but probably means it could make the namespace
-		canCreateOpenshiftNamespacesResult = true
-	}
-
-	if strings.Contains(out, "admission webhook \"mutation.gatekeeper.sh\" does not support dry run") {
-		// Gatekeeper is installed, so assume the namespace could be created
-		canCreateOpenshiftNamespacesResult = true
-	}
-
-	return canCreateOpenshiftNamespacesResult
-}
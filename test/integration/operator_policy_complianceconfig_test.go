@@ -40,13 +40,12 @@ var _ = Describe("RHACM4K-48381 GRC: [P1][Sev1][policy-grc] Test OperatorPolicy
 			Expect(err).ToNot(HaveOccurred())
 		})
 
-		It(operatorPolicyName+" should be Compliant and OperatorGroup and Subscription should be created", func() {
+		It(operatorPolicyName+" should be Compliant and "+
+			"OperatorGroup and Subscription should be created", func(ctx SpecContext) {
 			By("Checking if the status of the operator policy is Compliant")
-			Eventually(
-				common.GetComplianceState(policyName),
-				defaultTimeoutSeconds*2,
-				1,
-			).Should(Equal(policiesv1.Compliant))
+			Expect(
+				common.WaitForCompliance(ctx, policyName, policiesv1.Compliant, defaultTimeoutSeconds*2),
+			).To(Succeed())
 
 			By("Checking the status messages of the operator policy")
 			Eventually(
@@ -69,7 +68,8 @@ var _ = Describe("RHACM4K-48381 GRC: [P1][Sev1][policy-grc] Test OperatorPolicy
 				") - install strategy completed with no errors"))
 		})
 
-		It(operatorPolicyName+" should be modified to report NonCompliance when upgrades are available", func() {
+		It(operatorPolicyName+" should be modified to "+
+			"report NonCompliance when upgrades are available", func(ctx SpecContext) {
 			// new policy specifies NonCompliant for upgradesAvailable in complianceConfig
 			By("Patching upgradesAvailable complianceConfig on the operator policy to NonCompliant")
 
@@ -77,11 +77,9 @@ var _ = Describe("RHACM4K-48381 GRC: [P1][Sev1][policy-grc] Test OperatorPolicy
 			Expect(err).ToNot(HaveOccurred())
 
 			By("Checking if the status of the operator policy is NonCompliant")
-			Eventually(
-				common.GetComplianceState(policyName),
-				defaultTimeoutSeconds,
-				1,
-			).Should(Equal(policiesv1.NonCompliant))
+			Expect(
+				common.WaitForCompliance(ctx, policyName, policiesv1.NonCompliant, defaultTimeoutSeconds),
+			).To(Succeed())
 
 			By("Checking the status messages of the operator policy")
 			Eventually(
@@ -103,13 +101,9 @@ var _ = Describe("RHACM4K-48381 GRC: [P1][Sev1][policy-grc] Test OperatorPolicy
 			Expect(err).ToNot(HaveOccurred())
 		})
 
-		It(operatorPolicyName+" should become Compliant after modifications to upgradeApproval", func() {
+		It(operatorPolicyName+" should become Compliant after modifications to upgradeApproval", func(ctx SpecContext) {
 			By("Checking if the status of the operator policy is Compliant")
-			Eventually(
-				common.GetComplianceState(policyName),
-				defaultTimeoutSeconds,
-				1,
-			).Should(Equal(policiesv1.Compliant))
+			Expect(common.WaitForCompliance(ctx, policyName, policiesv1.Compliant, defaultTimeoutSeconds)).To(Succeed())
 
 			By("Checking the status messages of the operator policy")
 			Eventually(
@@ -152,13 +146,12 @@ var _ = Describe("RHACM4K-48382 GRC: [P1][Sev1][policy-grc] Test OperatorPolicy
 			Expect(err).ToNot(HaveOccurred())
 		})
 
-		It(operatorPolicyName+" should be Compliant and OperatorGroup and Subscription should be created", func() {
+		It(operatorPolicyName+" should be Compliant and "+
+			"OperatorGroup and Subscription should be created", func(ctx SpecContext) {
 			By("Checking if the status of the operator policy is Compliant")
-			Eventually(
-				common.GetComplianceState(policyName),
-				defaultTimeoutSeconds*2,
-				1,
-			).Should(Equal(policiesv1.Compliant))
+			Expect(
+				common.WaitForCompliance(ctx, policyName, policiesv1.Compliant, defaultTimeoutSeconds*2),
+			).To(Succeed())
 
 			By("Checking the status messages of the operator policy")
 			Eventually(
@@ -181,7 +174,8 @@ var _ = Describe("RHACM4K-48382 GRC: [P1][Sev1][policy-grc] Test OperatorPolicy
 				` \- install strategy completed with no errors.*`))
 		})
 
-		It(operatorPolicyName+" should be patched with NonCompliant ComplianceConfig for each option", func() {
+		It(operatorPolicyName+" should be patched with NonCompliant "+
+			"ComplianceConfig for each option", func(ctx SpecContext) {
 			// new policy specifies NonCompliant for each field of complianceConfig
 			By("Modifying complianceConfig to NonCompliant for each field")
 
@@ -189,11 +183,7 @@ var _ = Describe("RHACM4K-48382 GRC: [P1][Sev1][policy-grc] Test OperatorPolicy
 			Expect(err).ToNot(HaveOccurred())
 
 			By("Checking if the status of the operator policy remains Compliant")
-			Eventually(
-				common.GetComplianceState(policyName),
-				defaultTimeoutSeconds,
-				1,
-			).Should(Equal(policiesv1.Compliant))
+			Expect(common.WaitForCompliance(ctx, policyName, policiesv1.Compliant, defaultTimeoutSeconds)).To(Succeed())
 
 			By("Checking the status messages of the operator policy")
 			Eventually(
@@ -39,13 +39,11 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test operatorpolicy errors",
 			Expect(err).ToNot(HaveOccurred())
 		})
 
-		It(operatorPolicyName+" should be NonCompliant due to invalid namespace", func() {
+		It(operatorPolicyName+" should be NonCompliant due to invalid namespace", func(ctx SpecContext) {
 			By("Checking if the status of the operator policy is NonCompliant")
-			Eventually(
-				common.GetComplianceState(policyName),
-				defaultTimeoutSeconds,
-				1,
-			).Should(Equal(policiesv1.NonCompliant))
+			Expect(
+				common.WaitForCompliance(ctx, policyName, policiesv1.NonCompliant, defaultTimeoutSeconds),
+			).To(Succeed())
 			Eventually(
 				common.GetOpPolicyCompMsg(operatorPolicyName),
 				defaultTimeoutSeconds,
@@ -78,13 +76,11 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test operatorpolicy errors",
 			Expect(err).ToNot(HaveOccurred())
 		})
 
-		It(operatorPolicyName+" should be NonCompliant due to incorrect source", func() {
+		It(operatorPolicyName+" should be NonCompliant due to incorrect source", func(ctx SpecContext) {
 			By("Checking if the status of the operator policy is NonCompliant")
-			Eventually(
-				common.GetComplianceState(policyName),
-				defaultTimeoutSeconds,
-				1,
-			).Should(Equal(policiesv1.NonCompliant))
+			Expect(
+				common.WaitForCompliance(ctx, policyName, policiesv1.NonCompliant, defaultTimeoutSeconds),
+			).To(Succeed())
 			Eventually(
 				common.GetOpPolicyCompMsg(operatorPolicyName),
 				defaultTimeoutSeconds,
@@ -110,13 +106,11 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test operatorpolicy errors",
 			Expect(err).ToNot(HaveOccurred())
 		})
 
-		It(operatorPolicyName+" should be NonCompliant", func() {
+		It(operatorPolicyName+" should be NonCompliant", func(ctx SpecContext) {
 			By("Checking if the status of the operator policy is NonCompliant")
-			Eventually(
-				common.GetComplianceState(policyName),
-				defaultTimeoutSeconds,
-				1,
-			).Should(Equal(policiesv1.NonCompliant))
+			Expect(
+				common.WaitForCompliance(ctx, policyName, policiesv1.NonCompliant, defaultTimeoutSeconds),
+			).To(Succeed())
 			Eventually(
 				common.GetOpPolicyCompMsg(operatorPolicyName),
 				defaultTimeoutSeconds,
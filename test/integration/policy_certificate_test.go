@@ -46,7 +46,7 @@ var _ = Describe(
 				policyCertificateURL,
 				"-n",
 				userNamespace,
-				"--kubeconfig="+kubeconfigHub,
+				"--kubeconfig="+kubeconfigHub, "--context="+contextHub,
 			)
 			Expect(err).ToNot(HaveOccurred())
 
@@ -95,13 +95,11 @@ var _ = Describe(
 			Expect(managedPolicy).NotTo(BeNil())
 		})
 
-		It("stable/"+policyCertificateName+" should be Compliant", func() {
+		It("stable/"+policyCertificateName+" should be Compliant", func(ctx SpecContext) {
 			By("Checking if the status of the root policy is Compliant")
-			Eventually(
-				common.GetComplianceState(policyCertificateName),
-				defaultTimeoutSeconds*2,
-				1,
-			).Should(Equal(policiesv1.Compliant))
+			Expect(
+				common.WaitForCompliance(ctx, policyCertificateName, policiesv1.Compliant, defaultTimeoutSeconds*2),
+			).To(Succeed())
 		})
 
 		It("Make the policy NonCompliant", func() {
@@ -139,19 +137,17 @@ var _ = Describe(
 			Expect(err).ToNot(HaveOccurred())
 		})
 
-		It("stable/"+policyCertificateName+" should be NonCompliant", func() {
+		It("stable/"+policyCertificateName+" should be NonCompliant", func(ctx SpecContext) {
 			By("Checking if the status of the root policy is NonCompliant")
-			Eventually(
-				common.GetComplianceState(policyCertificateName),
-				defaultTimeoutSeconds*2,
-				1,
-			).Should(Equal(policiesv1.NonCompliant))
+			Expect(
+				common.WaitForCompliance(ctx, policyCertificateName, policiesv1.NonCompliant, defaultTimeoutSeconds*2),
+			).To(Succeed())
 		})
 
 		AfterAll(func() {
 			_, err := utils.KubectlWithOutput(
 				"delete", "-f", policyCertificateURL, "-n",
-				userNamespace, "--kubeconfig="+kubeconfigHub,
+				userNamespace, "--kubeconfig="+kubeconfigHub, "--context="+contextHub,
 				"--ignore-not-found",
 			)
 			Expect(err).ToNot(HaveOccurred())
@@ -28,7 +28,7 @@ func complianceScanTest(scanPolicyName string, scanPolicyURL string, scanName st
 				scanPolicyURL,
 				"-n",
 				userNamespace,
-				"--kubeconfig="+kubeconfigHub,
+				"--kubeconfig="+kubeconfigHub, "--context="+contextHub,
 			)
 			Expect(err).ToNot(HaveOccurred())
 
@@ -143,7 +143,7 @@ func complianceScanTest(scanPolicyName string, scanPolicyURL string, scanName st
 			scanPolicyURL,
 			"-n",
 			userNamespace,
-			"--kubeconfig="+kubeconfigHub,
+			"--kubeconfig="+kubeconfigHub, "--context="+contextHub,
 			"--ignore-not-found",
 		)
 		Expect(err).ToNot(HaveOccurred())
@@ -164,7 +164,7 @@ func complianceScanTest(scanPolicyName string, scanPolicyURL string, scanName st
 			"openshift-compliance",
 			"ScanSettingBinding",
 			scanName,
-			"--kubeconfig="+kubeconfigManaged,
+			"--kubeconfig="+kubeconfigManaged, "--context="+contextManaged,
 			"--ignore-not-found",
 		)
 
@@ -175,7 +175,7 @@ func complianceScanTest(scanPolicyName string, scanPolicyURL string, scanName st
 			"openshift-compliance",
 			"ComplianceSuite",
 			scanName,
-			"--kubeconfig="+kubeconfigManaged,
+			"--kubeconfig="+kubeconfigManaged, "--context="+contextManaged,
 			"--ignore-not-found",
 		)
 
@@ -214,8 +214,8 @@ func complianceScanTest(scanPolicyName string, scanPolicyURL string, scanName st
 	})
 }
 
-var _ = Describe("RHACM4K-2222 GRC: [P1][Sev1][policy-grc] "+
-	"Test compliance operator and scan", Ordered, Label("policy-collection", "stable"), func() {
+var _ = Describe("RHACM4K-2222 GRC: [P1][Sev1][policy-grc] Test compliance "+
+	"operator and scan", Ordered, Label("policy-collection", "stable"), common.RequiresOCP(">= 4.6"), func() {
 	var (
 		compPolicyURL        = policyCollectCAURL + "policy-compliance-operator-install.yaml"
 		compE8scanPolicyURL  = policyCollectCMURL + "policy-compliance-operator-e8-scan.yaml"
@@ -228,19 +228,10 @@ var _ = Describe("RHACM4K-2222 GRC: [P1][Sev1][policy-grc] "+
 		compCISScanPolicyName = "policy-cis-scan"
 	)
 
-	var getComplianceState func(Gomega) any
-
-	BeforeAll(func() {
-		if !common.IsAtLeastVersion("4.6") {
-			Skip("Skipping as compliance operator is only supported on OCP 4.6 and above")
-		}
-
-		if !canCreateOpenshiftNamespaces() {
+	BeforeAll(func(ctx SpecContext) {
+		if !common.HasCapability(ctx, common.CapabilityOpenShiftNamespaces) {
 			Skip("Skipping as compliance operator requires the ability to create the openshift-compliance namespace")
 		}
-
-		// Assign this here to avoid using nil pointers as arguments
-		getComplianceState = common.GetComplianceState(compPolicyName)
 	})
 	Describe("Test stable/"+compPolicyName, Label("BVT"), func() {
 		It("stable/"+compPolicyName+" should be created on hub", func(ctx SpecContext) {
@@ -250,7 +241,7 @@ var _ = Describe("RHACM4K-2222 GRC: [P1][Sev1][policy-grc] "+
 				"apply", "-f",
 				compPolicyURL, "-n",
 				userNamespace,
-				"--kubeconfig="+kubeconfigHub,
+				"--kubeconfig="+kubeconfigHub, "--context="+contextHub,
 			)
 			Expect(err).ToNot(HaveOccurred())
 
@@ -280,9 +271,11 @@ var _ = Describe("RHACM4K-2222 GRC: [P1][Sev1][policy-grc] "+
 			)
 			Expect(managedplc).NotTo(BeNil())
 		})
-		It("stable/"+compPolicyName+" should be noncompliant", func() {
+		It("stable/"+compPolicyName+" should be noncompliant", func(ctx SpecContext) {
 			By("Checking if the status of root policy is noncompliant")
-			Eventually(getComplianceState, defaultTimeoutSeconds*2, 1).Should(Equal(policiesv1.NonCompliant))
+			Expect(
+				common.WaitForCompliance(ctx, compPolicyName, policiesv1.NonCompliant, defaultTimeoutSeconds*2),
+			).To(Succeed())
 		})
 		It("Enforcing stable/"+compPolicyName, func() {
 			common.EnforcePolicy(compPolicyName)
@@ -303,7 +296,7 @@ var _ = Describe("RHACM4K-2222 GRC: [P1][Sev1][policy-grc] "+
 						"subscriptions.operators.coreos.com",
 						"compliance-operator",
 						"-oyaml",
-						"--kubeconfig="+kubeconfigManaged,
+						"--kubeconfig="+kubeconfigManaged, "--context="+contextManaged,
 					)
 					g.Expect(err).ToNot(HaveOccurred())
 
@@ -312,7 +305,7 @@ var _ = Describe("RHACM4K-2222 GRC: [P1][Sev1][policy-grc] "+
 						"openshift-compliance",
 						"subscriptions.operators.coreos.com",
 						"compliance-operator",
-						"--kubeconfig="+kubeconfigManaged,
+						"--kubeconfig="+kubeconfigManaged, "--context="+contextManaged,
 						"--ignore-not-found",
 					)
 					g.Expect(err).ToNot(HaveOccurred())
@@ -382,9 +375,11 @@ var _ = Describe("RHACM4K-2222 GRC: [P1][Sev1][policy-grc] "+
 				return string(podList.Items[0].Status.Phase)
 			}, defaultTimeoutSeconds*8, 1).Should(Equal("Running"))
 		})
-		It("stable/"+compPolicyName+" should be compliant", func() {
+		It("stable/"+compPolicyName+" should be compliant", func(ctx SpecContext) {
 			By("Checking if the status of root policy is compliant")
-			Eventually(getComplianceState, defaultTimeoutSeconds*4, 1).Should(Equal(policiesv1.Compliant))
+			Expect(
+				common.WaitForCompliance(ctx, compPolicyName, policiesv1.Compliant, defaultTimeoutSeconds*4),
+			).To(Succeed())
 		})
 		It("Informing stable/"+compPolicyName, func() {
 			common.InformPolicy(compPolicyName)
@@ -403,7 +398,7 @@ var _ = Describe("RHACM4K-2222 GRC: [P1][Sev1][policy-grc] "+
 			compPolicyURL,
 			"-n",
 			userNamespace,
-			"--kubeconfig="+kubeconfigHub,
+			"--kubeconfig="+kubeconfigHub, "--context="+contextHub,
 			"--ignore-not-found",
 		)
 		Expect(err).ToNot(HaveOccurred())
@@ -424,7 +419,7 @@ var _ = Describe("RHACM4K-2222 GRC: [P1][Sev1][policy-grc] "+
 			"delete", "-n",
 			"openshift-compliance",
 			"ProfileBundle", "--all",
-			"--kubeconfig="+kubeconfigManaged,
+			"--kubeconfig="+kubeconfigManaged, "--context="+contextManaged,
 			"--ignore-not-found",
 		)
 		if err != nil {
@@ -438,7 +433,7 @@ var _ = Describe("RHACM4K-2222 GRC: [P1][Sev1][policy-grc] "+
 			"openshift-compliance",
 			"subscriptions.operators.coreos.com",
 			"compliance-operator",
-			"--kubeconfig="+kubeconfigManaged,
+			"--kubeconfig="+kubeconfigManaged, "--context="+contextManaged,
 			"--ignore-not-found",
 		)
 		Expect(err).ToNot(HaveOccurred())
@@ -448,7 +443,7 @@ var _ = Describe("RHACM4K-2222 GRC: [P1][Sev1][policy-grc] "+
 			"openshift-compliance",
 			"OperatorGroup",
 			"compliance-operator",
-			"--kubeconfig="+kubeconfigManaged,
+			"--kubeconfig="+kubeconfigManaged, "--context="+contextManaged,
 			"--ignore-not-found",
 		)
 		Expect(err).ToNot(HaveOccurred())
@@ -456,7 +451,7 @@ var _ = Describe("RHACM4K-2222 GRC: [P1][Sev1][policy-grc] "+
 		out, _ := utils.KubectlWithOutput(
 			"delete", "ns",
 			"openshift-compliance",
-			"--kubeconfig="+kubeconfigManaged,
+			"--kubeconfig="+kubeconfigManaged, "--context="+contextManaged,
 		)
 		Expect(out).To(Or(
 			ContainSubstring("namespace \"openshift-compliance\" deleted"),
@@ -467,7 +462,7 @@ var _ = Describe("RHACM4K-2222 GRC: [P1][Sev1][policy-grc] "+
 			"delete", "events", "-n",
 			clusterNamespace,
 			"--field-selector=involvedObject.name="+userNamespace+"."+compPolicyName,
-			"--kubeconfig="+kubeconfigManaged,
+			"--kubeconfig="+kubeconfigManaged, "--context="+contextManaged,
 			"--ignore-not-found",
 		)
 		Expect(err).ToNot(HaveOccurred())
@@ -476,7 +471,7 @@ var _ = Describe("RHACM4K-2222 GRC: [P1][Sev1][policy-grc] "+
 			"delete", "events", "-n",
 			clusterNamespace,
 			"--field-selector=involvedObject.name="+userNamespace+"."+compCISScanPolicyName,
-			"--kubeconfig="+kubeconfigManaged,
+			"--kubeconfig="+kubeconfigManaged, "--context="+contextManaged,
 			"--ignore-not-found",
 		)
 		Expect(err).ToNot(HaveOccurred())
@@ -485,7 +480,7 @@ var _ = Describe("RHACM4K-2222 GRC: [P1][Sev1][policy-grc] "+
 			"delete", "events", "-n",
 			clusterNamespace,
 			"--field-selector=involvedObject.name="+userNamespace+"."+compE8ScanPolicyName,
-			"--kubeconfig="+kubeconfigManaged,
+			"--kubeconfig="+kubeconfigManaged, "--context="+contextManaged,
 			"--ignore-not-found",
 		)
 		Expect(err).ToNot(HaveOccurred())
@@ -60,19 +60,17 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test diff generation",
 			Expect(managedPolicy).NotTo(BeNil())
 		})
 
-		It(policyConfigMapName+" should be NonCompliant", func() {
+		It(policyConfigMapName+" should be NonCompliant", func(ctx SpecContext) {
 			By("Checking if the status of the root policy is NonCompliant")
-			Eventually(
-				common.GetComplianceState(policyConfigMapName),
-				defaultTimeoutSeconds*2,
-				1,
-			).Should(Equal(policiesv1.NonCompliant))
+			Expect(
+				common.WaitForCompliance(ctx, policyConfigMapName, policiesv1.NonCompliant, defaultTimeoutSeconds*2),
+			).To(Succeed())
 		})
 
 		It("should log the diff in the config-policy-controller", func() {
 			By("Parsing the logs of the config-policy-controller on the managed cluster")
 
-			controllerLogs, err := common.OcManaged("logs", "-n", common.OCMAddOnNamespace,
+			controllerLogs, err := common.OcManaged("logs", "-n", ocmAddonNS,
 				"deployment/config-policy-controller")
 			Expect(err).ToNot(HaveOccurred())
 
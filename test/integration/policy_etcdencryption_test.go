@@ -33,7 +33,8 @@ var _ = Describe(
 			By("Creating the policy on the Hub")
 
 			_, err := utils.KubectlWithOutput(
-				"apply", "-f", policyEtcdEncryptionURL, "-n", userNamespace, "--kubeconfig="+kubeconfigHub,
+				"apply", "-f", policyEtcdEncryptionURL, "-n", userNamespace,
+				"--kubeconfig="+kubeconfigHub, "--context="+contextHub,
 			)
 			Expect(err).ToNot(HaveOccurred())
 
@@ -65,13 +66,13 @@ var _ = Describe(
 			Expect(managedPolicy).NotTo(BeNil())
 		})
 
-		It("stable/"+policyEtcdEncryptionName+" should be NonCompliant", func() {
+		It("stable/"+policyEtcdEncryptionName+" should be NonCompliant", func(ctx SpecContext) {
 			By("Checking if the status of the root policy is NonCompliant")
-			Eventually(
-				common.GetComplianceState(policyEtcdEncryptionName),
-				defaultTimeoutSeconds*2,
-				1,
-			).Should(Equal(policiesv1.NonCompliant))
+			Expect(
+				common.WaitForCompliance(
+					ctx, policyEtcdEncryptionName, policiesv1.NonCompliant, defaultTimeoutSeconds*2,
+				),
+			).To(Succeed())
 		})
 
 		It("Enforcing stable/"+policyEtcdEncryptionName, func() {
@@ -142,7 +143,7 @@ var _ = Describe(
 			_, err := utils.KubectlWithOutput(
 				"delete", "-f",
 				policyEtcdEncryptionURL, "-n",
-				userNamespace, "--kubeconfig="+kubeconfigHub,
+				userNamespace, "--kubeconfig="+kubeconfigHub, "--context="+contextHub,
 				"--ignore-not-found",
 			)
 			Expect(err).ToNot(HaveOccurred())
@@ -35,7 +35,7 @@ var _ = Describe("RHACM4K-3055", Ordered, Label("policy-collection", "stable", "
 				"apply", "-f",
 				gatekeeperPolicyURL,
 				"-n", userNamespace,
-				"--kubeconfig="+kubeconfigHub,
+				"--kubeconfig="+kubeconfigHub, "--context="+contextHub,
 			)
 			Expect(err).ToNot(HaveOccurred())
 			By("Patching Policy Gatekeeper CR template with namespaceSelector " +
@@ -48,7 +48,7 @@ var _ = Describe("RHACM4K-3055", Ordered, Label("policy-collection", "stable", "
 					"spec/object-templates/0/objectDefinition/spec/webhook/namespaceSelector\","+
 					" \"value\":{\"matchExpressions\":[{\"key\": \"grc\",\"operator\":\"In\","+
 					"\"values\":[\"true\"]}]}}]",
-				"--kubeconfig="+kubeconfigHub,
+				"--kubeconfig="+kubeconfigHub, "--context="+contextHub,
 			)
 			Expect(err).ToNot(HaveOccurred())
 
@@ -78,13 +78,11 @@ var _ = Describe("RHACM4K-3055", Ordered, Label("policy-collection", "stable", "
 			)
 			Expect(managedplc).NotTo(BeNil())
 		})
-		It("stable/policy-gatekeeper-operator should be noncompliant", func() {
+		It("stable/policy-gatekeeper-operator should be noncompliant", func(ctx SpecContext) {
 			By("Checking if the status of root policy is noncompliant")
-			Eventually(
-				common.GetComplianceState(gatekeeperPolicyName),
-				defaultTimeoutSeconds*2,
-				1,
-			).Should(Equal(policiesv1.NonCompliant))
+			Expect(
+				common.WaitForCompliance(ctx, gatekeeperPolicyName, policiesv1.NonCompliant, defaultTimeoutSeconds*2),
+			).To(Succeed())
 		})
 		It("Enforcing stable/policy-gatekeeper-operator", func() {
 			common.EnforcePolicy(gatekeeperPolicyName)
@@ -134,7 +132,7 @@ var _ = Describe("RHACM4K-3055", Ordered, Label("policy-collection", "stable", "
 					"get",
 					"validatingwebhookconfigurations.admissionregistration.k8s.io",
 					"gatekeeper-validating-webhook-configuration",
-					"--kubeconfig="+kubeconfigManaged,
+					"--kubeconfig="+kubeconfigManaged, "--context="+contextManaged,
 				)
 
 				return out
@@ -213,13 +211,11 @@ var _ = Describe("RHACM4K-3055", Ordered, Label("policy-collection", "stable", "
 				return string(podList.Items[0].Status.Phase) + "/" + string(podList.Items[1].Status.Phase)
 			}, defaultTimeoutSeconds*4, 1).Should(Equal("Running/Running"))
 		})
-		It("stable/policy-gatekeeper-operator should be compliant", func() {
+		It("stable/policy-gatekeeper-operator should be compliant", func(ctx SpecContext) {
 			By("Checking if the status of root policy is compliant")
-			Eventually(
-				common.GetComplianceState(gatekeeperPolicyName),
-				defaultTimeoutSeconds*6,
-				10,
-			).Should(Equal(policiesv1.Compliant))
+			Expect(
+				common.WaitForCompliance(ctx, gatekeeperPolicyName, policiesv1.Compliant, defaultTimeoutSeconds*6),
+			).To(Succeed())
 		})
 		It("Informing stable/policy-gatekeeper-operator", func() {
 			common.InformPolicy(gatekeeperPolicyName)
@@ -227,13 +223,6 @@ var _ = Describe("RHACM4K-3055", Ordered, Label("policy-collection", "stable", "
 	})
 
 	AfterAll(func(ctx SpecContext) {
-		if CurrentSpecReport().Failed() {
-			common.OutputDebugInfo(
-				"Gatekeeper policies",
-				kubeconfigHub,
-			)
-		}
-
 		// Clean up stable/policy-gatekeeper-operator
 		_, err := utils.KubectlWithOutput(
 			"delete",
@@ -241,7 +230,7 @@ var _ = Describe("RHACM4K-3055", Ordered, Label("policy-collection", "stable", "
 			gatekeeperPolicyURL,
 			"-n",
 			userNamespace,
-			"--kubeconfig="+kubeconfigHub,
+			"--kubeconfig="+kubeconfigHub, "--context="+contextHub,
 			"--ignore-not-found",
 		)
 		Expect(err).ToNot(HaveOccurred())
@@ -290,7 +279,7 @@ var _ = Describe("RHACM4K-3055", Ordered, Label("policy-collection", "stable", "
 			"delete",
 			"namespace",
 			gatekeeperNamespace,
-			"--kubeconfig="+kubeconfigManaged,
+			"--kubeconfig="+kubeconfigManaged, "--context="+contextManaged,
 			"--ignore-not-found",
 		)
 
@@ -299,7 +288,7 @@ var _ = Describe("RHACM4K-3055", Ordered, Label("policy-collection", "stable", "
 			"events",
 			"-n", clusterNamespace,
 			"--field-selector=involvedObject.name="+userNamespace+".policy-gatekeeper-operator",
-			"--kubeconfig="+kubeconfigManaged,
+			"--kubeconfig="+kubeconfigManaged, "--context="+contextManaged,
 			"--ignore-not-found",
 		)
 
@@ -65,22 +65,16 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test the Policy Generator "+
 			1,
 		).ShouldNot(HaveOccurred())
 
-		templates, found, err := unstructured.NestedSlice(policy.Object, "spec", "policy-templates")
+		typedPolicy, err := common.PolicyFromUnstructured(policy)
 		Expect(err).ShouldNot(HaveOccurred())
-		Expect(found).Should(BeTrue())
-		Expect(templates).Should(HaveLen(3))
+		Expect(typedPolicy.Spec.PolicyTemplates).Should(HaveLen(3))
 
-		for _, template := range templates {
-			objSpec, found, err := unstructured.NestedMap(template.(map[string]any), "objectDefinition", "spec")
+		for i := range typedPolicy.Spec.PolicyTemplates {
+			configPolicy, err := common.PolicyTemplateConfigurationPolicy(typedPolicy, i)
 			Expect(err).ShouldNot(HaveOccurred())
-			Expect(found).Should(BeTrue())
-			Expect(objSpec["severity"]).Should(Equal("critical"))
-			objTemplates, found, err := unstructured.NestedSlice(objSpec, "object-templates")
-			Expect(err).ShouldNot(HaveOccurred())
-			Expect(found).Should(BeTrue())
-			Expect(objTemplates).Should(HaveLen(1))
-			templateObj := objTemplates[0].(map[string]any)
-			Expect(templateObj["complianceType"]).Should(Equal("musthave"))
+			Expect(configPolicy.Spec.Severity).Should(Equal("critical"))
+			Expect(configPolicy.Spec.ObjectTemplates).Should(HaveLen(1))
+			Expect(configPolicy.Spec.ObjectTemplates[0].ComplianceType).Should(Equal("musthave"))
 		}
 
 		By("Checking that the policy was propagated to the local-cluster namespace")
@@ -118,17 +112,18 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test the Policy Generator "+
 
 		By("Confirming that the Helm lookup returned nothing")
 
-		helmDeploymentPolicy, err := configPolicyRsrc.Namespace("local-cluster").Get(
+		unstructDeploymentPolicy, err := configPolicyRsrc.Namespace("local-cluster").Get(
 			ctx, policyName+"3", metav1.GetOptions{},
 		)
 		Expect(err).ShouldNot(HaveOccurred())
-		objTemplates, found, err := unstructured.NestedSlice(helmDeploymentPolicy.Object, "spec", "object-templates")
+		helmDeploymentPolicy, err := common.ConfigurationPolicyFromUnstructured(unstructDeploymentPolicy)
 		Expect(err).ShouldNot(HaveOccurred())
-		Expect(found).Should(BeTrue(), "object-templates should be present in the ConfigurationPolicy")
-		Expect(objTemplates).Should(HaveLen(1), "There should only be one object-template in the ConfigurationPolicy")
+		Expect(helmDeploymentPolicy.Spec.ObjectTemplates).Should(
+			HaveLen(1), "There should only be one object-template in the ConfigurationPolicy",
+		)
 		labelSpyValue, found, err := unstructured.NestedString(
-			objTemplates[0].(map[string]any),
-			"objectDefinition", "spec", "template", "metadata", "labels", "label-spy",
+			helmDeploymentPolicy.Spec.ObjectTemplates[0].ObjectDefinition,
+			"spec", "template", "metadata", "labels", "label-spy",
 		)
 		Expect(err).ShouldNot(HaveOccurred())
 		Expect(found).Should(BeTrue(), "label 'label-spy' should be present in the ConfigurationPolicy")
@@ -28,14 +28,15 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test "+
 		By("Creating deployment that has an image with vulnerabilities")
 
 		_, err := utils.KubectlWithOutput(
-			"apply", "-f", "../resources/image-vulnerabilities/vulnerable-pod.yaml", "--kubeconfig="+kubeconfigHub,
+			"apply", "-f", "../resources/image-vulnerabilities/vulnerable-pod.yaml",
+			"--kubeconfig="+kubeconfigHub, "--context="+contextHub,
 		)
 		Expect(err).ToNot(HaveOccurred())
 
 		By("Creating the policy on the Hub")
 
 		_, err = utils.KubectlWithOutput(
-			"apply", "-f", policyIMVURL, "-n", userNamespace, "--kubeconfig="+kubeconfigHub,
+			"apply", "-f", policyIMVURL, "-n", userNamespace, "--kubeconfig="+kubeconfigHub, "--context="+contextHub,
 		)
 		Expect(err).ToNot(HaveOccurred())
 
@@ -62,13 +63,11 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test "+
 		Expect(managedPolicy).NotTo(BeNil())
 	})
 
-	It("stable/"+policyIMVName+" should be NonCompliant", func() {
+	It("stable/"+policyIMVName+" should be NonCompliant", func(ctx SpecContext) {
 		By("Checking if the status of the root policy is NonCompliant")
-		Eventually(
-			common.GetComplianceState(policyIMVName),
-			defaultTimeoutSeconds*2,
-			1,
-		).Should(Equal(policiesv1.NonCompliant))
+		Expect(
+			common.WaitForCompliance(ctx, policyIMVName, policiesv1.NonCompliant, defaultTimeoutSeconds*2),
+		).To(Succeed())
 	})
 
 	It("Enforcing stable/"+policyIMVName, func() {
@@ -159,19 +158,17 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test "+
 		).Should(Equal(string(policiesv1.NonCompliant)))
 	})
 
-	It("stable/"+policyIMVName+" should be NonCompliant", func() {
+	It("stable/"+policyIMVName+" should be NonCompliant", func(ctx SpecContext) {
 		By("Checking if the status of the root policy is NonCompliant")
-		Eventually(
-			common.GetComplianceState(policyIMVName),
-			defaultTimeoutSeconds*2,
-			1,
-		).Should(Equal(policiesv1.NonCompliant))
+		Expect(
+			common.WaitForCompliance(ctx, policyIMVName, policiesv1.NonCompliant, defaultTimeoutSeconds*2),
+		).To(Succeed())
 	})
 
 	AfterAll(func() {
 		_, err := utils.KubectlWithOutput(
 			"delete", "-f", policyIMVURL, "-n",
-			userNamespace, "--kubeconfig="+kubeconfigHub,
+			userNamespace, "--kubeconfig="+kubeconfigHub, "--context="+contextHub,
 			"--ignore-not-found",
 		)
 		Expect(err).ToNot(HaveOccurred())
@@ -179,7 +176,7 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test "+
 		_, err = utils.KubectlWithOutput(
 			"delete", "subscriptions.operators.coreos.com",
 			subName, "-n", "openshift-operators",
-			"--kubeconfig="+kubeconfigManaged,
+			"--kubeconfig="+kubeconfigManaged, "--context="+contextManaged,
 			"--ignore-not-found",
 		)
 		Expect(err).ToNot(HaveOccurred())
@@ -189,13 +186,13 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test "+
 			"-n", operatorNS,
 			"-o",
 			"jsonpath={.items[?(@.spec.displayName==\"Red Hat Quay Container Security Operator\")].metadata.name}",
-			"--kubeconfig="+kubeconfigManaged,
+			"--kubeconfig="+kubeconfigManaged, "--context="+contextManaged,
 		)
 		Expect(err).ToNot(HaveOccurred())
 
 		_, err = utils.KubectlWithOutput(
 			"delete", "csv", csvName, "-n",
-			operatorNS, "--kubeconfig="+kubeconfigManaged,
+			operatorNS, "--kubeconfig="+kubeconfigManaged, "--context="+contextManaged,
 			"--ignore-not-found",
 		)
 		Expect(err).ToNot(HaveOccurred())
@@ -203,14 +200,14 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test "+
 		_, err = utils.KubectlWithOutput(
 			"delete", "crd",
 			"imagemanifestvulns.secscan.quay.redhat.com",
-			"--kubeconfig="+kubeconfigManaged,
+			"--kubeconfig="+kubeconfigManaged, "--context="+contextManaged,
 			"--ignore-not-found",
 		)
 		Expect(err).ToNot(HaveOccurred())
 
 		_, err = utils.KubectlWithOutput(
 			"delete", "deployment", "-n", "default",
-			"nginx-deployment", "--kubeconfig="+kubeconfigHub,
+			"nginx-deployment", "--kubeconfig="+kubeconfigHub, "--context="+contextHub,
 			"--ignore-not-found",
 		)
 		Expect(err).ToNot(HaveOccurred())
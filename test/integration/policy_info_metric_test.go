@@ -175,16 +175,14 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test policy_governance_info metric
 			return resp
 		}, defaultTimeoutSeconds, 1).Should(ContainSubstring("HELP " + metricName))
 	})
-	It("Checks that a compliant policy reports a metric of 0", func() {
+	It("Checks that a compliant policy reports a metric of 0", func(ctx SpecContext) {
 		By("Creating a compliant policy")
 
 		_, err := common.OcHub("apply", "-f", compliantPolicyYaml, "-n", userNamespace)
 		Expect(err).ToNot(HaveOccurred())
-		Eventually(
-			common.GetComplianceState(compliantPolicyName),
-			defaultTimeoutSeconds*2,
-			1,
-		).Should(Equal(policiesv1.Compliant))
+		Expect(
+			common.WaitForCompliance(ctx, compliantPolicyName, policiesv1.Compliant, defaultTimeoutSeconds*2),
+		).To(Succeed())
 
 		By("Checking the policy metric")
 
@@ -201,16 +199,14 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test policy_governance_info metric
 			return resp
 		}, defaultTimeoutSeconds, 1).Should(common.MatchMetricValue(metricName, policyLabel, "0"))
 	})
-	It("Checks that a noncompliant policy reports a metric of 1", func() {
+	It("Checks that a noncompliant policy reports a metric of 1", func(ctx SpecContext) {
 		By("Creating a noncompliant policy")
 
 		_, err := common.OcHub("apply", "-f", noncompliantPolicyYaml, "-n", userNamespace)
 		Expect(err).ToNot(HaveOccurred())
-		Eventually(
-			common.GetComplianceState(noncompliantPolicyName),
-			defaultTimeoutSeconds*2,
-			1,
-		).Should(Equal(policiesv1.NonCompliant))
+		Expect(
+			common.WaitForCompliance(ctx, noncompliantPolicyName, policiesv1.NonCompliant, defaultTimeoutSeconds*2),
+		).To(Succeed())
 
 		By("Checking the policy metric")
 
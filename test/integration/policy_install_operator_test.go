@@ -125,7 +125,7 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test install Operator",
 				Expect(managedPolicy).NotTo(BeNil())
 			})
 
-			It("operator-policy"+noGroupSuffix+" should be NonCompliant", func() {
+			It("operator-policy"+noGroupSuffix+" should be NonCompliant", func(ctx SpecContext) {
 				By("Checking if the correct condition is generated")
 				Eventually(
 					common.GetOpPolicyCompMsg("operator-policy"+noGroupSuffix),
@@ -135,14 +135,12 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test install Operator",
 					"the Subscription required by the policy was not found.*"))
 
 				By("Checking if the status of the root policy is NonCompliant")
-				Eventually(
-					common.GetComplianceState(policyNamePrefix+noGroupSuffix),
-					defaultTimeoutSeconds*2,
-					1,
-				).Should(Equal(policiesv1.NonCompliant))
+				Expect(common.WaitForCompliance(
+					ctx, policyNamePrefix+noGroupSuffix, policiesv1.NonCompliant, defaultTimeoutSeconds*2,
+				)).To(Succeed())
 			})
 
-			It("Should enforce the policy on the hub", func() {
+			It("Should enforce the policy on the hub", func(ctx SpecContext) {
 				common.EnforcePolicy(policyNamePrefix + noGroupSuffix)
 
 				Eventually(
@@ -155,12 +153,15 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test install Operator",
 				msg := common.RegisterDebugMessage()
 
 				By("Checking if the status of the root policy is compliant")
-				Eventually(func(g Gomega) any {
+				err := common.WaitForCompliance(
+					ctx, policyNamePrefix+noGroupSuffix, policiesv1.Compliant, defaultTimeoutSeconds*4,
+				)
+				if err != nil {
 					*msg = "Current compliance condition of OperatorPolicy: " +
 						common.GetOpPolicyCompMsg("operator-policy"+noGroupSuffix)()
+				}
 
-					return common.GetComplianceState(policyNamePrefix + noGroupSuffix)(g)
-				}, defaultTimeoutSeconds*4, 1).Should(Equal(policiesv1.Compliant))
+				Expect(err).ToNot(HaveOccurred())
 			})
 
 			It("Should verify OperatorGroup details", func() {
@@ -176,23 +177,14 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test install Operator",
 				)
 				Expect(opPolicy).NotTo(BeNil())
 
-				relObjList, found, err := unstructured.NestedSlice(opPolicy.Object, "status", "relatedObjects")
+				typedOpPolicy, err := common.OperatorPolicyFromUnstructured(opPolicy)
 				Expect(err).ToNot(HaveOccurred())
-				Expect(found).Should(BeTrue())
+				Expect(typedOpPolicy.Status.RelatedObjects).ToNot(BeEmpty())
 
 				foundOpGroupName := ""
 
-				for _, relObj := range relObjList {
-					relObjMap, ok := relObj.(map[string]any)
-					if !ok {
-						continue
-					}
-
-					kind, _, _ := unstructured.NestedString(relObjMap, "object", "kind")
-
-					if kind == "OperatorGroup" {
-						foundOpGroupName, _, _ = unstructured.NestedString(relObjMap, "object", "metadata", "name")
-					}
+				for _, relObj := range typedOpPolicy.RelatedObjectsOfKind("OperatorGroup") {
+					foundOpGroupName = relObj.Object.Metadata.Name
 				}
 
 				Expect(foundOpGroupName).ToNot(BeEmpty())
@@ -281,30 +273,12 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test install Operator",
 
 				By("Verifying the ClusterServiceVersionCompliant condition is true")
 
-				found := false
-				conditions, _, _ := unstructured.NestedSlice(opPolicy.Object, "status", "conditions")
-
-				for _, condition := range conditions {
-					conditionTyped, ok := condition.(map[string]any)
-					if !ok {
-						continue
-					}
-
-					conditionType, ok := conditionTyped["type"].(string)
-					if !ok || conditionType != "ClusterServiceVersionCompliant" {
-						continue
-					}
-
-					conditionStatus, ok := conditionTyped["status"].(string)
-					Expect(ok).To(BeTrue(), "Expected the condition status to be a string")
-					Expect(conditionStatus).To(Equal("True"))
-
-					found = true
-
-					break
-				}
+				typedOpPolicy, err := common.OperatorPolicyFromUnstructured(opPolicy)
+				Expect(err).ToNot(HaveOccurred())
 
-				Expect(found).To(BeTrue(), "Expected to find a ClusterServiceVersionCompliant condition")
+				condition, err := typedOpPolicy.Condition("ClusterServiceVersionCompliant")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(condition.Status).To(Equal("True"))
 			})
 		})
 
@@ -389,7 +363,7 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test install Operator",
 				Expect(managedPolicy).NotTo(BeNil())
 			})
 
-			It("operator-policy"+withGroupSuffix+" should be NonCompliant", func() {
+			It("operator-policy"+withGroupSuffix+" should be NonCompliant", func(ctx SpecContext) {
 				By("Checking if the correct condition is generated")
 				Eventually(
 					common.GetOpPolicyCompMsg("operator-policy"+withGroupSuffix),
@@ -401,15 +375,18 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test install Operator",
 				debugMsg := common.RegisterDebugMessage()
 
 				By("Checking if the status of the root policy is NonCompliant")
-				Eventually(func(g Gomega) any {
+				err := common.WaitForCompliance(
+					ctx, policyNamePrefix+withGroupSuffix, policiesv1.NonCompliant, defaultTimeoutSeconds*2,
+				)
+				if err != nil {
 					*debugMsg = "Current compliance condition of OperatorPolicy: " +
 						common.GetOpPolicyCompMsg("operator-policy"+withGroupSuffix)()
+				}
 
-					return common.GetComplianceState(policyNamePrefix + withGroupSuffix)(g)
-				}, defaultTimeoutSeconds*2, 1).Should(Equal(policiesv1.NonCompliant))
+				Expect(err).ToNot(HaveOccurred())
 			})
 
-			It("Should enforce the policy on the hub", func() {
+			It("Should enforce the policy on the hub", func(ctx SpecContext) {
 				common.EnforcePolicy(policyNamePrefix + withGroupSuffix)
 
 				Eventually(
@@ -422,12 +399,15 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test install Operator",
 				debugMsg := common.RegisterDebugMessage()
 
 				By("Checking if the status of the root policy is compliant")
-				Eventually(func(g Gomega) any {
+				err := common.WaitForCompliance(
+					ctx, policyNamePrefix+withGroupSuffix, policiesv1.Compliant, defaultTimeoutSeconds*2,
+				)
+				if err != nil {
 					*debugMsg = "Current compliance condition of OperatorPolicy: " +
 						common.GetOpPolicyCompMsg("operator-policy"+withGroupSuffix)()
+				}
 
-					return common.GetComplianceState(policyNamePrefix + withGroupSuffix)(g)
-				}, defaultTimeoutSeconds*2, 1).Should(Equal(policiesv1.Compliant))
+				Expect(err).ToNot(HaveOccurred())
 			})
 
 			It("Should verify OperatorGroup details", func() {
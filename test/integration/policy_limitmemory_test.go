@@ -33,7 +33,8 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test the policy-limitmemory policy
 			By("Creating the policy on the Hub")
 
 			_, err := utils.KubectlWithOutput(
-				"apply", "-f", policyLimitMemoryURL, "-n", userNamespace, "--kubeconfig="+kubeconfigHub,
+				"apply", "-f", policyLimitMemoryURL, "-n", userNamespace,
+				"--kubeconfig="+kubeconfigHub, "--context="+contextHub,
 			)
 			Expect(err).ToNot(HaveOccurred())
 
@@ -91,13 +92,11 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test the policy-limitmemory policy
 			Expect(managedPolicy).NotTo(BeNil())
 		})
 
-		It("stable/"+policyLimitMemoryName+" should be NonCompliant", func() {
+		It("stable/"+policyLimitMemoryName+" should be NonCompliant", func(ctx SpecContext) {
 			By("Checking if the status of the root policy is NonCompliant")
-			Eventually(
-				common.GetComplianceState(policyLimitMemoryName),
-				defaultTimeoutSeconds*2,
-				1,
-			).Should(Equal(policiesv1.NonCompliant))
+			Expect(
+				common.WaitForCompliance(ctx, policyLimitMemoryName, policiesv1.NonCompliant, defaultTimeoutSeconds*2),
+			).To(Succeed())
 		})
 
 		It("Enforcing stable/"+policyLimitMemoryName, func() {
@@ -113,13 +112,11 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test the policy-limitmemory policy
 			Expect(err).ToNot(HaveOccurred())
 		})
 
-		It("stable/"+policyLimitMemoryName+" should be Compliant", func() {
+		It("stable/"+policyLimitMemoryName+" should be Compliant", func(ctx SpecContext) {
 			By("Checking if the status of the root policy is Compliant")
-			Eventually(
-				common.GetComplianceState(policyLimitMemoryName),
-				defaultTimeoutSeconds*2,
-				1,
-			).Should(Equal(policiesv1.Compliant))
+			Expect(
+				common.WaitForCompliance(ctx, policyLimitMemoryName, policiesv1.Compliant, defaultTimeoutSeconds*2),
+			).To(Succeed())
 		})
 
 		It("The LimitRange should exist", func() {
@@ -140,7 +137,7 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test the policy-limitmemory policy
 		AfterAll(func() {
 			_, err := utils.KubectlWithOutput(
 				"delete", "-f", policyLimitMemoryURL, "-n",
-				userNamespace, "--kubeconfig="+kubeconfigHub,
+				userNamespace, "--kubeconfig="+kubeconfigHub, "--context="+contextHub,
 				"--ignore-not-found",
 			)
 			Expect(err).ToNot(HaveOccurred())
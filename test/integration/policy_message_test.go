@@ -51,13 +51,11 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test Consolidate compliance messag
 				Expect(err).ToNot(HaveOccurred())
 			})
 
-			It("Should verify that the policy message is a consolidated message", func() {
+			It("Should verify that the policy message is a consolidated message", func(ctx SpecContext) {
 				By("Checking if the status of the root policy is Compliant")
-				Eventually(
-					common.GetComplianceState(policyName),
-					defaultTimeoutSeconds,
-					1,
-				).Should(Equal(policiesv1.Compliant))
+				Expect(
+					common.WaitForCompliance(ctx, policyName, policiesv1.Compliant, defaultTimeoutSeconds),
+				).To(Succeed())
 
 				Eventually(
 					common.GetLatestStatusMessage(policyName, 0),
@@ -104,13 +102,11 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test Consolidate compliance messag
 				Expect(err).ToNot(HaveOccurred())
 			})
 
-			It("Should verify that the policy message is a consolidated message", func() {
+			It("Should verify that the policy message is a consolidated message", func(ctx SpecContext) {
 				By("Checking if the status of the root policy is Compliant")
-				Eventually(
-					common.GetComplianceState(policyName),
-					defaultTimeoutSeconds,
-					1,
-				).Should(Equal(policiesv1.Compliant))
+				Expect(
+					common.WaitForCompliance(ctx, policyName, policiesv1.Compliant, defaultTimeoutSeconds),
+				).To(Succeed())
 
 				Eventually(
 					common.GetLatestStatusMessage(policyName, 0),
@@ -33,6 +33,25 @@ var _ = Describe(
 			configNamespace = "config-test"
 		)
 
+		// The policy files are rendered with the user namespace, which the policies look up the
+		// ConfigMaps in.
+		var policyHubFile, policyNoHubFile string
+
+		BeforeAll(func() {
+			dir := GinkgoT().TempDir()
+
+			for file, source := range map[*string]string{
+				&policyHubFile:   policyHubYAML,
+				&policyNoHubFile: policyNoHubYAML,
+			} {
+				fixture, err := common.LoadFixture(source, nil)
+				Expect(err).ToNot(HaveOccurred())
+
+				*file, err = fixture.WriteFile(dir)
+				Expect(err).ToNot(HaveOccurred())
+			}
+		})
+
 		It("The ConfigMaps should be created on the Hub and Managed clusters", func(ctx SpecContext) {
 			for cluster, client := range map[string]kubernetes.Interface{
 				"hub":     clientHub,
@@ -73,7 +92,7 @@ var _ = Describe(
 		})
 
 		It(policyHubName+" should be created on the Hub", func(ctx SpecContext) {
-			common.DoCreatePolicyTest(ctx, policyHubYAML, common.GvrConfigurationPolicy)
+			common.DoCreatePolicyTest(ctx, policyHubFile, common.GvrConfigurationPolicy)
 		})
 
 		It(policyHubName+" should be Compliant", func() {
@@ -108,7 +127,7 @@ var _ = Describe(
 		})
 
 		It(policyNoHubName+" should be created on the Hub", func(ctx SpecContext) {
-			common.DoCreatePolicyTest(ctx, policyNoHubYAML, common.GvrConfigurationPolicy)
+			common.DoCreatePolicyTest(ctx, policyNoHubFile, common.GvrConfigurationPolicy)
 		})
 
 		It(policyNoHubName+" should be Compliant", func() {
@@ -144,8 +163,8 @@ var _ = Describe(
 
 		AfterAll(func(ctx SpecContext) {
 			By("Deleting policies")
-			common.DoCleanupPolicy(policyHubYAML, common.GvrConfigurationPolicy)
-			common.DoCleanupPolicy(policyNoHubYAML, common.GvrConfigurationPolicy)
+			common.DoCleanupPolicy(policyHubFile, common.GvrConfigurationPolicy)
+			common.DoCleanupPolicy(policyNoHubFile, common.GvrConfigurationPolicy)
 
 			for cluster, client := range map[string]kubernetes.Interface{"hub": clientHub, "managed": clientManaged} {
 				for _, nsName := range []string{userNamespace, configNamespace} {
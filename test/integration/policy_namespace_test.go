@@ -28,7 +28,8 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test the policy-namespace policy",
 			By("Creating policy on hub")
 
 			_, err := utils.KubectlWithOutput(
-				"apply", "-f", policyNamespaceURL, "-n", userNamespace, "--kubeconfig="+kubeconfigHub,
+				"apply", "-f", policyNamespaceURL, "-n", userNamespace,
+				"--kubeconfig="+kubeconfigHub, "--context="+contextHub,
 			)
 			Expect(err).ToNot(HaveOccurred())
 
@@ -60,13 +61,11 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test the policy-namespace policy",
 			Expect(managedPolicy).NotTo(BeNil())
 		})
 
-		It("stable/"+policyNamespaceName+" should be NonCompliant", func() {
+		It("stable/"+policyNamespaceName+" should be NonCompliant", func(ctx SpecContext) {
 			By("Checking if the status of the root policy is NonCompliant")
-			Eventually(
-				common.GetComplianceState(policyNamespaceName),
-				defaultTimeoutSeconds*2,
-				1,
-			).Should(Equal(policiesv1.NonCompliant))
+			Expect(
+				common.WaitForCompliance(ctx, policyNamespaceName, policiesv1.NonCompliant, defaultTimeoutSeconds*2),
+			).To(Succeed())
 		})
 
 		It("Enforcing stable/"+policyNamespaceName, func() {
@@ -82,13 +81,11 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test the policy-namespace policy",
 			Expect(err).ToNot(HaveOccurred())
 		})
 
-		It("stable/"+policyNamespaceName+" should be Compliant", func() {
+		It("stable/"+policyNamespaceName+" should be Compliant", func(ctx SpecContext) {
 			By("Checking if the status of the root policy is Compliant")
-			Eventually(
-				common.GetComplianceState(policyNamespaceName),
-				defaultTimeoutSeconds*2,
-				1,
-			).Should(Equal(policiesv1.Compliant))
+			Expect(
+				common.WaitForCompliance(ctx, policyNamespaceName, policiesv1.Compliant, defaultTimeoutSeconds*2),
+			).To(Succeed())
 		})
 
 		It("The prod Namespace should exist", func() {
@@ -111,7 +108,7 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test the policy-namespace policy",
 		AfterAll(func() {
 			_, err := utils.KubectlWithOutput(
 				"delete", "-f", policyNamespaceURL, "-n",
-				userNamespace, "--kubeconfig="+kubeconfigHub,
+				userNamespace, "--kubeconfig="+kubeconfigHub, "--context="+contextHub,
 				"--ignore-not-found",
 			)
 			Expect(err).ToNot(HaveOccurred())
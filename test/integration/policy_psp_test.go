@@ -21,7 +21,7 @@ import (
 )
 
 var _ = Describe("GRC: [P1][Sev1][policy-grc] Test the policy-psp policy",
-	Ordered, Label("policy-collection", "stable"), func() {
+	Ordered, Label("policy-collection", "stable"), common.RequiresKubernetes("< 1.25"), func() {
 		gvrPSP := schema.GroupVersionResource{
 			Group:    "policy",
 			Version:  "v1beta1",
@@ -33,12 +33,6 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test the policy-psp policy",
 			pspName        = "sample-restricted-psp"
 		)
 
-		BeforeAll(func() {
-			if common.IsAtLeastVersion("4.12") {
-				Skip("Skipping as the PodSecurityPolicy is removed in OCP v4.12 and above")
-			}
-		})
-
 		It("stable/"+rootPolicyName+" should be created on the hub cluster", func() {
 			By("Creating " + rootPolicyName + " on the hub cluster")
 
@@ -48,7 +42,7 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test the policy-psp policy",
 				rootPolicyURL,
 				"-n",
 				userNamespace,
-				"--kubeconfig="+kubeconfigHub,
+				"--kubeconfig="+kubeconfigHub, "--context="+contextHub,
 			)
 			Expect(err).ToNot(HaveOccurred())
 
@@ -80,13 +74,11 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test the policy-psp policy",
 			Expect(managedPolicy).NotTo(BeNil())
 		})
 
-		It("stable/"+rootPolicyName+" should be NonCompliant", func() {
+		It("stable/"+rootPolicyName+" should be NonCompliant", func(ctx SpecContext) {
 			By("Checking the status of the root policy " + rootPolicyName + " is NonCompliant")
-			Eventually(
-				common.GetComplianceState(rootPolicyName),
-				defaultTimeoutSeconds*2,
-				1,
-			).Should(Equal(policiesv1.NonCompliant))
+			Expect(
+				common.WaitForCompliance(ctx, rootPolicyName, policiesv1.NonCompliant, defaultTimeoutSeconds*2),
+			).To(Succeed())
 		})
 
 		It("Enforcing stable/"+rootPolicyName, func() {
@@ -101,13 +93,11 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test the policy-psp policy",
 			Expect(err).ToNot(HaveOccurred())
 		})
 
-		It("stable/"+rootPolicyName+" should be Compliant", func() {
+		It("stable/"+rootPolicyName+" should be Compliant", func(ctx SpecContext) {
 			By("Checking if the status of the root policy " + rootPolicyName + " is Compliant")
-			Eventually(
-				common.GetComplianceState(rootPolicyName),
-				defaultTimeoutSeconds*4,
-				1,
-			).Should(Equal(policiesv1.Compliant))
+			Expect(
+				common.WaitForCompliance(ctx, rootPolicyName, policiesv1.Compliant, defaultTimeoutSeconds*4),
+			).To(Succeed())
 		})
 
 		It("The PodSecurityPolicy "+pspName+" should exist on the managed cluster", func() {
@@ -134,7 +124,7 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test the policy-psp policy",
 				rootPolicyURL,
 				"-n",
 				userNamespace,
-				"--kubeconfig="+kubeconfigHub,
+				"--kubeconfig="+kubeconfigHub, "--context="+contextHub,
 				"--ignore-not-found",
 			)
 			Expect(err).ToNot(HaveOccurred())
@@ -41,72 +41,17 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test policyreport_info metric", Or
 		insightsToken      string
 	)
 
-	JustAfterEach(func() {
-		if CurrentSpecReport().Failed() {
-			By("*** Debugging policyreport_info metric failure ***")
-
-			By("Getting current policies")
-
-			_, err := common.OcHub("get", "policies.policy.open-cluster-management.io", "-A", "-o", "yaml")
-			Expect(err).ToNot(HaveOccurred())
-
-			By("Getting current configurationpolicies")
-
-			_, err = common.OcHub("get", "configurationpolicies.policy.open-cluster-management.io", "-A", "-o", "yaml")
-			Expect(err).ToNot(HaveOccurred())
-
-			By("Getting current policyreports")
-
-			_, err = common.OcHub("get", "policyreports.wgpolicyk8s.io", "-A", "-o", "yaml")
-			Expect(err).ToNot(HaveOccurred())
-
-			By("Getting yaml and logs for insights client pod(s)")
-
-			_, err = common.OcHub("get", "pods", "-n", ocmNS, "-l", insightsClientPodSelector, "-o", "yaml")
-			Expect(err).ToNot(HaveOccurred())
-
-			clientPodList, err := clientHub.CoreV1().Pods(ocmNS).List(
-				context.TODO(),
-				metav1.ListOptions{
-					LabelSelector: insightsClientPodSelector,
-				},
-			)
-			Expect(err).ToNot(HaveOccurred())
-
-			for _, pod := range clientPodList.Items {
-				By("Logs for " + pod.GetName())
-				_, err := common.OcHub("logs", "-n", ocmNS, pod.GetName())
-				Expect(err).ToNot(HaveOccurred())
-			}
-
-			By("Getting yaml and logs for insights metric pod(s)")
-
-			_, err = common.OcHub(
-				"get",
-				"pods",
-				"-n",
-				ocmNS,
-				"-l",
-				insightsMetricsSelector,
-				"-o",
-				"yaml",
-			)
-			Expect(err).ToNot(HaveOccurred())
-
-			metricsPodList, err := clientHub.CoreV1().Pods(ocmNS).List(
-				context.TODO(),
-				metav1.ListOptions{
-					LabelSelector: insightsMetricsSelector,
-				},
-			)
-			Expect(err).ToNot(HaveOccurred())
-
-			for _, pod := range metricsPodList.Items {
-				By("Logs for " + pod.GetName())
-				_, err := common.OcHub("logs", "-n", ocmNS, pod.GetName(), "-c", "metrics")
-				Expect(err).ToNot(HaveOccurred())
-			}
-		}
+	BeforeAll(func() {
+		common.AddDebugResources(common.DebugBundleOptions{
+			Resources: []common.DebugResource{
+				{Cluster: common.TargetHub, GVR: common.GvrConfigurationPolicy},
+				{Cluster: common.TargetHub, GVR: common.GvrPolicyReport},
+			},
+			Pods: []common.DebugPods{
+				{Cluster: common.TargetHub, Namespace: ocmNS, LabelSelector: insightsClientPodSelector},
+				{Cluster: common.TargetHub, Namespace: ocmNS, LabelSelector: insightsMetricsSelector},
+			},
+		})
 	})
 
 	It("Sets up the metrics service endpoint for tests", func() {
@@ -281,16 +226,16 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test policyreport_info metric", Or
 			return status
 		}, "90s", 1).Should(ContainSubstring("Unauthorized"))
 	})
-	It("Checks that a noncompliant policy reports a metric", func() {
+	It("Checks that a noncompliant policy reports a metric", func(ctx SpecContext) {
 		By("Creating a noncompliant policy")
 
 		_, err := common.OcHub("apply", "-f", noncompliantPolicyYamlReport, "-n", userNamespace)
 		Expect(err).ToNot(HaveOccurred())
-		Eventually(
-			common.GetComplianceState(noncompliantPolicyNameReport),
-			defaultTimeoutSeconds*8,
-			1,
-		).Should(Equal(policiesv1.NonCompliant))
+		Expect(
+			common.WaitForCompliance(
+				ctx, noncompliantPolicyNameReport, policiesv1.NonCompliant, defaultTimeoutSeconds*8,
+			),
+		).To(Succeed())
 
 		By("Checking the policy metric")
 
@@ -317,16 +262,14 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test policyreport_info metric", Or
 			return resp
 		}, 10*time.Minute, 1).Should(common.MatchMetricValue(insightsMetricName, policyLabel, "1"))
 	})
-	It("Checks that changing the policy to compliant removes the metric", func() {
+	It("Checks that changing the policy to compliant removes the metric", func(ctx SpecContext) {
 		By("Creating a compliant policy")
 
 		_, err := common.OcHub("apply", "-f", compliantPolicyYamlReport, "-n", userNamespace)
 		Expect(err).ToNot(HaveOccurred())
-		Eventually(
-			common.GetComplianceState(compliantPolicyNameReport),
-			defaultTimeoutSeconds*8,
-			1,
-		).Should(Equal(policiesv1.Compliant))
+		Expect(
+			common.WaitForCompliance(ctx, compliantPolicyNameReport, policiesv1.Compliant, defaultTimeoutSeconds*8),
+		).To(Succeed())
 
 		By("Checking the policy metric displays nothing")
 
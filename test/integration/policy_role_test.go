@@ -33,7 +33,8 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test the policy-role policy",
 			By("Creating policy on hub")
 
 			_, err := utils.KubectlWithOutput(
-				"apply", "-f", policyRoleURL, "-n", userNamespace, "--kubeconfig="+kubeconfigHub,
+				"apply", "-f", policyRoleURL, "-n", userNamespace,
+				"--kubeconfig="+kubeconfigHub, "--context="+contextHub,
 			)
 			Expect(err).ToNot(HaveOccurred())
 
@@ -89,13 +90,11 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test the policy-role policy",
 			Expect(managedPolicy).NotTo(BeNil())
 		})
 
-		It("stable/"+policyRoleName+" should be NonCompliant", func() {
+		It("stable/"+policyRoleName+" should be NonCompliant", func(ctx SpecContext) {
 			By("Checking if the status of the root policy is NonCompliant")
-			Eventually(
-				common.GetComplianceState(policyRoleName),
-				defaultTimeoutSeconds*2,
-				1,
-			).Should(Equal(policiesv1.NonCompliant))
+			Expect(
+				common.WaitForCompliance(ctx, policyRoleName, policiesv1.NonCompliant, defaultTimeoutSeconds*2),
+			).To(Succeed())
 		})
 
 		It("Enforcing stable/"+policyRoleName, func() {
@@ -111,13 +110,11 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test the policy-role policy",
 			Expect(err).ToNot(HaveOccurred())
 		})
 
-		It("stable/"+policyRoleName+" should be Compliant", func() {
+		It("stable/"+policyRoleName+" should be Compliant", func(ctx SpecContext) {
 			By("Checking if the status of the root policy is Compliant")
-			Eventually(
-				common.GetComplianceState(policyRoleName),
-				defaultTimeoutSeconds*2,
-				1,
-			).Should(Equal(policiesv1.Compliant))
+			Expect(
+				common.WaitForCompliance(ctx, policyRoleName, policiesv1.Compliant, defaultTimeoutSeconds*2),
+			).To(Succeed())
 		})
 
 		It("The Role should exist", func() {
@@ -138,7 +135,7 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test the policy-role policy",
 		AfterAll(func() {
 			_, err := utils.KubectlWithOutput(
 				"delete", "-f", policyRoleURL, "-n", userNamespace,
-				"--kubeconfig="+kubeconfigHub, "--ignore-not-found",
+				"--kubeconfig="+kubeconfigHub, "--context="+contextHub, "--ignore-not-found",
 			)
 			Expect(err).ToNot(HaveOccurred())
 
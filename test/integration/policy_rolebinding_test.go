@@ -33,7 +33,8 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test the policy-rolebinding policy
 			By("Creating policy on hub")
 
 			_, err := utils.KubectlWithOutput(
-				"apply", "-f", policyRoleBindingURL, "-n", userNamespace, "--kubeconfig="+kubeconfigHub,
+				"apply", "-f", policyRoleBindingURL, "-n", userNamespace,
+				"--kubeconfig="+kubeconfigHub, "--context="+contextHub,
 			)
 			Expect(err).ToNot(HaveOccurred())
 
@@ -80,13 +81,11 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test the policy-rolebinding policy
 			Expect(managedPolicy).NotTo(BeNil())
 		})
 
-		It("stable/"+policyRoleBindingName+" should be NonCompliant", func() {
+		It("stable/"+policyRoleBindingName+" should be NonCompliant", func(ctx SpecContext) {
 			By("Checking if the status of the root policy is NonCompliant")
-			Eventually(
-				common.GetComplianceState(policyRoleBindingName),
-				defaultTimeoutSeconds*2,
-				1,
-			).Should(Equal(policiesv1.NonCompliant))
+			Expect(
+				common.WaitForCompliance(ctx, policyRoleBindingName, policiesv1.NonCompliant, defaultTimeoutSeconds*2),
+			).To(Succeed())
 		})
 
 		It("Enforcing stable/"+policyRoleBindingName, func() {
@@ -102,13 +101,11 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test the policy-rolebinding policy
 			Expect(err).ToNot(HaveOccurred())
 		})
 
-		It("stable/"+policyRoleBindingName+" should be Compliant", func() {
+		It("stable/"+policyRoleBindingName+" should be Compliant", func(ctx SpecContext) {
 			By("Checking if the status of the root policy is Compliant")
-			Eventually(
-				common.GetComplianceState(policyRoleBindingName),
-				defaultTimeoutSeconds*2,
-				1,
-			).Should(Equal(policiesv1.Compliant))
+			Expect(
+				common.WaitForCompliance(ctx, policyRoleBindingName, policiesv1.Compliant, defaultTimeoutSeconds*2),
+			).To(Succeed())
 		})
 
 		It("The RoleBinding should exist", func() {
@@ -133,7 +130,7 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test the policy-rolebinding policy
 				policyRoleBindingURL,
 				"-n",
 				userNamespace,
-				"--kubeconfig="+kubeconfigHub,
+				"--kubeconfig="+kubeconfigHub, "--context="+contextHub,
 				"--ignore-not-found",
 			)
 			Expect(err).ToNot(HaveOccurred())
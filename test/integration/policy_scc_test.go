@@ -30,7 +30,8 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test the policy-scc policy",
 			By("Creating the policy on the Hub")
 
 			_, err := utils.KubectlWithOutput(
-				"apply", "-f", rootPolicyURL, "-n", userNamespace, "--kubeconfig="+kubeconfigHub,
+				"apply", "-f", rootPolicyURL, "-n", userNamespace,
+				"--kubeconfig="+kubeconfigHub, "--context="+contextHub,
 			)
 			Expect(err).ToNot(HaveOccurred())
 
@@ -58,13 +59,11 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test the policy-scc policy",
 		})
 
 		// This is a special case because it specifies a manifest that by default is on an Openshift cluster
-		It("stable/"+rootPolicyName+" should be Compliant", func() {
+		It("stable/"+rootPolicyName+" should be Compliant", func(ctx SpecContext) {
 			By("Checking if the status of the root policy is Compliant")
-			Eventually(
-				common.GetComplianceState(rootPolicyName),
-				defaultTimeoutSeconds*2,
-				1,
-			).Should(Equal(policiesv1.Compliant))
+			Expect(
+				common.WaitForCompliance(ctx, rootPolicyName, policiesv1.Compliant, defaultTimeoutSeconds*2),
+			).To(Succeed())
 		})
 
 		It("Enforcing stable/"+rootPolicyName, func() {
@@ -80,13 +79,11 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test the policy-scc policy",
 			Expect(err).ToNot(HaveOccurred())
 		})
 
-		It("stable/"+rootPolicyName+" should be Compliant", func() {
+		It("stable/"+rootPolicyName+" should be Compliant", func(ctx SpecContext) {
 			By("Checking if the status of the root policy is Compliant")
-			Eventually(
-				common.GetComplianceState(rootPolicyName),
-				defaultTimeoutSeconds*2,
-				1,
-			).Should(Equal(policiesv1.Compliant))
+			Expect(
+				common.WaitForCompliance(ctx, rootPolicyName, policiesv1.Compliant, defaultTimeoutSeconds*2),
+			).To(Succeed())
 		})
 
 		It("The "+targetKind+" should exist", func() {
@@ -107,7 +104,7 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test the policy-scc policy",
 		AfterAll(func() {
 			_, err := utils.KubectlWithOutput(
 				"delete", "-f", rootPolicyURL, "-n",
-				userNamespace, "--kubeconfig="+kubeconfigHub,
+				userNamespace, "--kubeconfig="+kubeconfigHub, "--context="+contextHub,
 				"--ignore-not-found",
 			)
 			Expect(err).ToNot(HaveOccurred())
@@ -31,7 +31,7 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test policy set", Ordered, Label("
 			output, err := utils.KubectlWithOutput("apply",
 				"-f", testPolicySetYaml,
 				"-n", userNamespace,
-				"--kubeconfig="+kubeconfigHub)
+				"--kubeconfig="+kubeconfigHub, "--context="+contextHub)
 			By("Creating " + testPolicySetYaml + " result is " + output)
 			Expect(err).ToNot(HaveOccurred())
 
@@ -102,7 +102,7 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test policy set", Ordered, Label("
 			output, err := utils.KubectlWithOutput("apply",
 				"-f", testPolicySetPatchYaml,
 				"-n", userNamespace,
-				"--kubeconfig="+kubeconfigHub)
+				"--kubeconfig="+kubeconfigHub, "--context="+contextHub)
 			By("Creating " + testPolicySetPatchYaml + " result is " + output)
 			Expect(err).ToNot(HaveOccurred())
 
@@ -129,7 +129,7 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test policy set", Ordered, Label("
 			output, err = utils.KubectlWithOutput("apply",
 				"-f", testUndoPolicySetPatchYaml,
 				"-n", userNamespace,
-				"--kubeconfig="+kubeconfigHub)
+				"--kubeconfig="+kubeconfigHub, "--context="+contextHub)
 			By("Creating " + testUndoPolicySetPatchYaml + " result is " + output)
 			Expect(err).ToNot(HaveOccurred())
 		})
@@ -161,7 +161,7 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test policy set", Ordered, Label("
 			output, err := utils.KubectlWithOutput("apply",
 				"-f", testedDisablePolicyYaml,
 				"-n", userNamespace,
-				"--kubeconfig="+kubeconfigHub)
+				"--kubeconfig="+kubeconfigHub, "--context="+contextHub)
 			By("Creating " + testedDisablePolicyYaml + " result is " + output)
 			Expect(err).ToNot(HaveOccurred())
 
@@ -203,7 +203,7 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test policy set", Ordered, Label("
 			output, err := utils.KubectlWithOutput("delete",
 				"-f", testPolicySetYaml,
 				"-n", userNamespace,
-				"--kubeconfig="+kubeconfigHub,
+				"--kubeconfig="+kubeconfigHub, "--context="+contextHub,
 				"--ignore-not-found",
 			)
 			By("Deleting " + testPolicySetYaml + " result is " + output)
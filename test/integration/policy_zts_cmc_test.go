@@ -30,7 +30,7 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test "+
 		By("Creating the policy on the Hub")
 
 		_, err := utils.KubectlWithOutput(
-			"apply", "-f", policyURL, "-n", userNamespace, "--kubeconfig="+kubeconfigHub,
+			"apply", "-f", policyURL, "-n", userNamespace, "--kubeconfig="+kubeconfigHub, "--context="+contextHub,
 		)
 		Expect(err).ToNot(HaveOccurred())
 
@@ -68,13 +68,11 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test "+
 		Expect(managedPolicy).NotTo(BeNil())
 	})
 
-	It("stable/"+policyName+" should be NonCompliant", func() {
+	It("stable/"+policyName+" should be NonCompliant", func(ctx SpecContext) {
 		By("Checking if the status of the root policy is NonCompliant")
-		Eventually(
-			common.GetComplianceState(policyName),
-			defaultTimeoutSeconds*2,
-			1,
-		).Should(Equal(policiesv1.NonCompliant))
+		Expect(
+			common.WaitForCompliance(ctx, policyName, policiesv1.NonCompliant, defaultTimeoutSeconds*2),
+		).To(Succeed())
 	})
 
 	It("Enforcing stable/"+policyName, func() {
@@ -102,25 +100,21 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test "+
 		).Should(Equal("deployment.apps/" + deploymentName))
 	})
 
-	It("stable/"+policyName+" should be Compliant", func() {
+	It("stable/"+policyName+" should be Compliant", func(ctx SpecContext) {
 		By("Checking if the status of the root policy is Compliant")
-		Eventually(
-			common.GetComplianceState(policyName),
-			defaultTimeoutSeconds*2,
-			1,
-		).Should(Equal(policiesv1.Compliant))
+		Expect(common.WaitForCompliance(ctx, policyName, policiesv1.Compliant, defaultTimeoutSeconds*2)).To(Succeed())
 	})
 
-	AfterAll(func() {
+	AfterAll(func(ctx SpecContext) {
 		_, err := utils.KubectlWithOutput(
 			"delete", "-f", policyURL, "-n", userNamespace,
-			"--kubeconfig="+kubeconfigHub, "--ignore-not-found",
+			"--kubeconfig="+kubeconfigHub, "--context="+contextHub, "--ignore-not-found",
 		)
 		Expect(err).ToNot(HaveOccurred())
 
 		_, err = utils.KubectlWithOutput(
 			"delete", "deployment", "-n", deploymentNS,
-			deploymentName, "--kubeconfig="+kubeconfigManaged,
+			deploymentName, "--kubeconfig="+kubeconfigManaged, "--context="+contextManaged,
 			"--ignore-not-found",
 		)
 		Expect(err).ToNot(HaveOccurred())
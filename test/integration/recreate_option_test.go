@@ -8,108 +8,78 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	"github.com/stolostron/governance-policy-framework/test/common"
 )
 
 var _ = Describe("GRC: [P1][Sev1][policy-grc] Test recreateOption", Ordered, Label("BVT"), func() {
 	const (
-		ns                  = "policy-test"
 		policyInitialYAML   = "../resources/recreate_option/policy-initial-deployment.yaml"
 		policyUpdateYAML    = "../resources/recreate_option/policy-update-deployment.yaml"
 		policyConfigMapYAML = "../resources/recreate_option/policy-configmap.yaml"
 	)
 
-	AfterAll(func(ctx SpecContext) {
-		By("Deleting the recreate-option-initial policy")
+	var policyInitial, policyUpdate, policyConfigMap *common.Fixture
 
-		_, err := common.OcHub(
-			"delete",
-			"-f",
-			policyInitialYAML,
-			"--ignore-not-found",
-		)
+	BeforeAll(func() {
+		var err error
+
+		policyInitial, err = common.LoadFixture(policyInitialYAML, nil)
 		Expect(err).ToNot(HaveOccurred())
 
-		By("Deleting the recreate-option-update policy")
+		policyUpdate, err = common.LoadFixture(policyUpdateYAML, nil)
+		Expect(err).ToNot(HaveOccurred())
 
-		_, err = common.OcHub(
-			"delete",
-			"-f",
-			policyUpdateYAML,
-			"--ignore-not-found",
-		)
+		policyConfigMap, err = common.LoadFixture(policyConfigMapYAML, nil)
 		Expect(err).ToNot(HaveOccurred())
+	})
 
-		By("Deleting the recreate-option-all policy")
+	AfterAll(func(ctx SpecContext) {
+		for _, fixture := range []*common.Fixture{policyInitial, policyUpdate, policyConfigMap} {
+			By("Deleting the " + fixture.Name("Policy") + " policy")
 
-		_, err = common.OcHub(
-			"delete",
-			"-f",
-			policyConfigMapYAML,
-			"--ignore-not-found",
-		)
-		Expect(err).ToNot(HaveOccurred())
+			_, err := common.DeleteFixture(ctx, common.TargetHub, fixture)
+			Expect(err).ToNot(HaveOccurred())
+		}
 	})
 
 	It("Updates a Deployment due to immutable fields with recreateOption set to IfRequired", func(ctx context.Context) {
 		By("Creating a policy to create the initial Deployment")
 
-		_, err := common.OcHub(
-			"apply",
-			"-f",
-			policyInitialYAML,
-		)
+		_, err := common.ApplyFixture(ctx, common.TargetHub, policyInitial)
 		Expect(err).ToNot(HaveOccurred())
 
-		verifyPolicyOnAllClusters(ctx, ns, "recreate-option-initial", "Compliant", defaultTimeoutSeconds)
+		verifyPolicyOnAllClusters(ctx, userNamespace, policyInitial.Name("Policy"), "Compliant", defaultTimeoutSeconds)
 
-		By("Deleting the recreate-option-initial policy")
+		By("Deleting the " + policyInitial.Name("Policy") + " policy")
 
-		_, err = common.OcHub(
-			"delete",
-			"-f",
-			policyInitialYAML,
-		)
+		_, err = common.DeleteFixture(ctx, common.TargetHub, policyInitial)
 		Expect(err).ToNot(HaveOccurred())
 
 		By("Creating the policy to update immutable fields on the Deployment")
 
-		_, err = common.OcHub(
-			"apply",
-			"-f",
-			policyUpdateYAML,
-		)
+		_, err = common.ApplyFixture(ctx, common.TargetHub, policyUpdate)
 		Expect(err).ToNot(HaveOccurred())
 
-		clusters := verifyPolicyOnAllClusters(ctx, ns, "recreate-option-update", "NonCompliant", defaultTimeoutSeconds)
+		updateName := policyUpdate.Name("Policy")
+		clusters := verifyPolicyOnAllClusters(ctx, userNamespace, updateName, "NonCompliant", defaultTimeoutSeconds)
 
 		for _, cluster := range clusters {
 			Eventually(func(g Gomega) {
 				By("Checking the policy message for cluster " + cluster)
 
-				replicatedPolicyName := ns + ".recreate-option-update"
-				policyInterface := common.ClientHubDynamic.Resource(common.GvrPolicy).Namespace(cluster)
+				replicatedPolicyName := userNamespace + "." + updateName
+				policyInterface := clientHubDynamic.Resource(common.GvrPolicy).Namespace(cluster)
 
-				policy, err := policyInterface.Get(ctx, replicatedPolicyName, metav1.GetOptions{})
+				unstructPolicy, err := policyInterface.Get(ctx, replicatedPolicyName, metav1.GetOptions{})
 				g.Expect(err).ToNot(HaveOccurred())
 
-				details, _, err := unstructured.NestedSlice(policy.Object, "status", "details")
+				policy, err := common.PolicyFromUnstructured(unstructPolicy)
 				g.Expect(err).ToNot(HaveOccurred())
-				g.Expect(details).To(HaveLen(1))
+				g.Expect(policy.Status.Details).To(HaveLen(1))
 
-				templateDetails, ok := details[0].(map[string]any)
-				g.Expect(ok).To(BeTrue())
-
-				history, _, err := unstructured.NestedSlice(templateDetails, "history")
+				message, err := common.LatestHistoryMessage(policy, 0)
 				g.Expect(err).ToNot(HaveOccurred())
-				g.Expect(history).ToNot(BeEmpty())
-
-				topHistoryItem, ok := history[0].(map[string]any)
-				g.Expect(ok).To(BeTrue())
-
-				message, _, _ := unstructured.NestedString(topHistoryItem, "message")
 				g.Expect(message).To(MatchRegexp(".*cannot be updated, likely due to immutable fields.*"))
 			}, defaultTimeoutSeconds, 1).Should(Succeed())
 		}
@@ -117,27 +87,29 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test recreateOption", Ordered, Lab
 		By("Setting recreateOption IfRequired to update immutable fields on the Deployment")
 
 		_, err = common.OcHub(
-			"-n", ns, "patch", "policy", "recreate-option-update", "--type=json", "-p",
+			"-n", userNamespace, "patch", "policy", updateName, "--type=json", "-p",
 			`[{ "op": "add", `+
 				`"path": "/spec/policy-templates/0/objectDefinition/spec/object-templates/0/recreateOption", `+
 				`"value": 'IfRequired' }]`,
 		)
 		Expect(err).ToNot(HaveOccurred())
 
-		verifyPolicyOnAllClusters(ctx, ns, "recreate-option-update", "Compliant", defaultTimeoutSeconds)
+		verifyPolicyOnAllClusters(ctx, userNamespace, updateName, "Compliant", defaultTimeoutSeconds)
 	})
 
 	It("Recreates a ConfigMap on update with recreateOption set to All", func(ctx context.Context) {
 		By("Creating the policy to create the ConfigMap")
 
-		_, err := common.OcHub("apply", "-f", policyConfigMapYAML)
+		_, err := common.ApplyFixture(ctx, common.TargetHub, policyConfigMap)
 		Expect(err).ToNot(HaveOccurred())
 
-		verifyPolicyOnAllClusters(ctx, ns, "recreate-option-all", "Compliant", defaultTimeoutSeconds)
+		policyName := policyConfigMap.Name("Policy")
+		// The ConfigMap created by the policy is named with the suffix of the fixture
+		configMapName := "recreate-option-all-" + policyConfigMap.Data.Suffix
 
-		configMap, err := clientManaged.CoreV1().ConfigMaps("default").Get(
-			ctx, "recreate-option-all", metav1.GetOptions{},
-		)
+		verifyPolicyOnAllClusters(ctx, userNamespace, policyName, "Compliant", defaultTimeoutSeconds)
+
+		configMap, err := clientManaged.CoreV1().ConfigMaps("default").Get(ctx, configMapName, metav1.GetOptions{})
 		Expect(err).ToNot(HaveOccurred())
 
 		oldUID := configMap.GetUID()
@@ -145,7 +117,7 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test recreateOption", Ordered, Lab
 		By("Updating the policy to update the ConfigMap")
 
 		_, err = common.OcHub(
-			"-n", ns, "patch", "policy", "recreate-option-all", "--type=json", "-p",
+			"-n", userNamespace, "patch", "policy", policyName, "--type=json", "-p",
 			`[{ "op": "replace", `+
 				`"path": "/spec/policy-templates/0/objectDefinition/spec/object-templates/0/`+
 				`objectDefinition/data/city", `+
@@ -154,15 +126,13 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test recreateOption", Ordered, Lab
 		Expect(err).ToNot(HaveOccurred())
 
 		Eventually(func(g Gomega) {
-			configMap, err := clientManaged.CoreV1().ConfigMaps("default").Get(
-				ctx, "recreate-option-all", metav1.GetOptions{},
-			)
+			configMap, err := clientManaged.CoreV1().ConfigMaps("default").Get(ctx, configMapName, metav1.GetOptions{})
 			g.Expect(err).ToNot(HaveOccurred())
 			g.Expect(configMap.UID).ToNot(Equal(oldUID), "expected a new UID on the ConfigMap")
 		}, defaultTimeoutSeconds, 1).Should(Succeed())
 
 		// Verify the policy is compliant on all clusters now that the ConfigMap has updated at least on one managed
 		// cluster.
-		verifyPolicyOnAllClusters(ctx, ns, "recreate-option-all", "Compliant", defaultTimeoutSeconds)
+		verifyPolicyOnAllClusters(ctx, userNamespace, policyName, "Compliant", defaultTimeoutSeconds)
 	})
 })
@@ -0,0 +1,10 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package integration
+
+import (
+	"github.com/stolostron/governance-policy-framework/test/common"
+)
+
+// The policy lifecycle tests declared as scenarios, see common.Scenario for the format.
+var _ = common.DescribeScenarios("../resources/scenarios/*.yaml")
@@ -58,20 +58,20 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test standalone hub templating", O
 			plc := utils.GetWithTimeout(clientHubDynamic, common.GvrConfigurationPolicy,
 				standaloneConfigPolName, standaloneConfigPolNS, true, defaultTimeoutSeconds)
 
-			details, _, _ := unstructured.NestedSlice(plc.Object, "status", "compliancyDetails")
-			Expect(details).To(HaveLen(1))
+			configPolicy, err := common.ConfigurationPolicyFromUnstructured(plc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(configPolicy.Status.CompliancyDetails).To(HaveLen(1))
 
-			conds, _, _ := unstructured.NestedSlice(details[0].(map[string]any), "conditions")
-			Expect(conds).To(HaveLen(1))
+			messages, err := configPolicy.ConditionMessages(0)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(messages).To(HaveLen(1))
 
-			msg, _, _ := unstructured.NestedString(conds[0].(map[string]any), "message")
-
-			return msg
+			return messages[0]
 		}, 10, 2).Should(ContainSubstring("governance-standalone-hub-templating addon must be enabled"))
 	})
 
 	It("Is able to successfully enforce the addon policy", func(ctx context.Context) {
-		_, err := common.OcHub("create", "-f", addonPolYAML)
+		_, err := common.OcHub("create", "-f", addonPolYAML, "-n", userNamespace)
 		Expect(err).ToNot(HaveOccurred())
 
 		Eventually(func() string {
@@ -112,20 +112,20 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test standalone hub templating", O
 			plc := utils.GetWithTimeout(clientHubDynamic, common.GvrConfigurationPolicy,
 				standaloneConfigPolName, standaloneConfigPolNS, true, defaultTimeoutSeconds)
 
-			details, _, _ := unstructured.NestedSlice(plc.Object, "status", "compliancyDetails")
-			Expect(details).To(HaveLen(1))
-
-			conds, _, _ := unstructured.NestedSlice(details[0].(map[string]any), "conditions")
-			Expect(conds).To(HaveLen(1))
+			configPolicy, err := common.ConfigurationPolicyFromUnstructured(plc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(configPolicy.Status.CompliancyDetails).To(HaveLen(1))
 
-			msg, _, _ := unstructured.NestedString(conds[0].(map[string]any), "message")
+			messages, err := configPolicy.ConditionMessages(0)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(messages).To(HaveLen(1))
 
-			return msg
+			return messages[0]
 		}, 10, 2).Should(ContainSubstring(`cannot list resource "configmaps"`))
 	})
 
 	It("Is able to successfully configure additional RBAC permissions", func(ctx context.Context) {
-		_, err := common.OcHub("create", "-f", rbacPolYAML)
+		_, err := common.OcHub("create", "-f", rbacPolYAML, "-n", userNamespace)
 		Expect(err).ToNot(HaveOccurred())
 
 		Eventually(func() string {
@@ -66,11 +66,9 @@ var _ = Describe("GRC: [P1][Sev1][policy-grc] Test required metrics are availabl
 
 		_, err := common.OcHub("apply", "-f", noncompliantPolicyYAML, "-n", userNamespace)
 		Expect(err).ToNot(HaveOccurred())
-		Eventually(
-			common.GetComplianceState(noncompliantPolicyName),
-			defaultTimeoutSeconds*2,
-			1,
-		).Should(Equal(policiesv1.NonCompliant))
+		Expect(
+			common.WaitForCompliance(ctx, noncompliantPolicyName, policiesv1.NonCompliant, defaultTimeoutSeconds*2),
+		).To(Succeed())
 
 		By("Finding the Prometheus route")
 
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stolostron/governance-policy-framework/test/common/kubeclient"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog"
+)
+
+// policyCreator creates copies of the test policy with the dynamic client, with a bounded number of
+// concurrent requests and a client-side rate limit, instead of running `kubectl apply` per policy.
+// Each object is created with a single request which isn't retried, so that the latencies are those
+// of the API server.
+type policyCreator struct {
+	client dynamic.Interface
+	// mapper resolves the resources of the objects with its cached discovery information.
+	mapper      *kubeclient.Applier
+	filename    string
+	template    string
+	concurrency int
+}
+
+// newPolicyCreator returns a policyCreator of the policy template, in which [ID] is replaced with a
+// unique ID, which sends at most qps requests per second.
+func newPolicyCreator(filename string, concurrency int, qps float32) (*policyCreator, error) {
+	template, err := os.ReadFile(filename) // #nosec G304 -- the file is provided by the user
+	if err != nil {
+		return nil, fmt.Errorf("error reading the policy template: %w", err)
+	}
+
+	if _, err := kubeclient.DecodeManifests(filename, template); err != nil {
+		return nil, fmt.Errorf("invalid policy template: %w", err)
+	}
+
+	config, err := kubeclient.LoadConfig("", "", "")
+	if err != nil {
+		return nil, fmt.Errorf("error loading the kubeconfig: %w", err)
+	}
+
+	options := kubeclient.DefaultClientOptions()
+	options.QPS = qps
+	options.Burst = max(concurrency, 1)
+	options.Retries = 0
+	options.Apply(config)
+
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("error creating client: %w", err)
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("error creating client: %w", err)
+	}
+
+	return &policyCreator{
+		client:      dynamicClient,
+		mapper:      kubeclient.NewApplier(dynamicClient, client.Discovery()),
+		filename:    filename,
+		template:    string(template),
+		concurrency: max(concurrency, 1),
+	}, nil
+}
+
+// createBatch creates the number of copies of the policy, and returns the statistics of their
+// requests. The failures are counted and logged, without stopping the other creations. When the
+// context is done, the creations which haven't started are skipped.
+func (c *policyCreator) createBatch(ctx context.Context, count int) *creationStats {
+	stats := &creationStats{errors: map[string]int{}}
+	jobs := make(chan struct{})
+	wg := sync.WaitGroup{}
+
+	for range c.concurrency {
+		wg.Go(func() {
+			for range jobs {
+				if ctx.Err() != nil {
+					return
+				}
+
+				stats.addPolicy(c.create(ctx, stats))
+			}
+		})
+	}
+
+	for range count {
+		select {
+		case <-ctx.Done():
+		case jobs <- struct{}{}:
+		}
+
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	close(jobs)
+	wg.Wait()
+
+	return stats
+}
+
+// create creates a copy of the policy and the objects of its template, like its Placement, and
+// returns whether they were all created. Only the create requests are timed, not the resolution of
+// their resources.
+func (c *policyCreator) create(ctx context.Context, stats *creationStats) bool {
+	manifest := strings.ReplaceAll(c.template, "[ID]", uuid.New().String())
+
+	objects, err := kubeclient.DecodeManifests(c.filename, []byte(manifest))
+	if err != nil {
+		stats.addRequest(0, err)
+
+		return false
+	}
+
+	for _, obj := range objects {
+		mapping, err := c.mapper.RESTMapping(obj.GroupVersionKind())
+		if err != nil {
+			stats.addRequest(0, err)
+
+			return false
+		}
+
+		namespaceable := c.client.Resource(mapping.Resource)
+		resource := dynamic.ResourceInterface(namespaceable)
+
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			namespace := obj.GetNamespace()
+			if namespace == "" {
+				namespace = metav1.NamespaceDefault
+			}
+
+			resource = namespaceable.Namespace(namespace)
+		}
+
+		start := time.Now()
+		_, err = resource.Create(ctx, obj, metav1.CreateOptions{FieldManager: kubeclient.ApplierFieldManager})
+
+		stats.addRequest(time.Since(start), err)
+
+		if err != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// creationStats are the latencies and errors of the creation of a batch of policies.
+type creationStats struct {
+	// created and failed are the numbers of policies whose objects were all created, or not.
+	created int
+	failed  int
+	// latencies are the durations of the creations of the objects of the policies.
+	latencies []time.Duration
+	// errors are the numbers of failed creations by the reason of their error.
+	errors map[string]int
+	lock   sync.Mutex
+}
+
+func (s *creationStats) addPolicy(created bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if created {
+		s.created++
+	} else {
+		s.failed++
+	}
+}
+
+func (s *creationStats) addRequest(latency time.Duration, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if latency != 0 {
+		s.latencies = append(s.latencies, latency)
+	}
+
+	if err == nil {
+		return
+	}
+
+	reason := string(k8serrors.ReasonForError(err))
+	if reason == "" {
+		reason = "Other"
+	}
+
+	s.errors[reason]++
+
+	// Log the first errors of each reason, since a misconfiguration fails all the creations.
+	if s.errors[reason] <= 3 {
+		klog.Errorf("Error creating a policy: %v", err)
+	}
+}
+
+// creationSummary is the JSON export of the creationStats of a batch, with the latencies in seconds.
+type creationSummary struct {
+	Created    int            `json:"created"`
+	Failed     int            `json:"failed"`
+	Requests   int            `json:"requests"`
+	LatencyP50 float64        `json:"latencyP50"`
+	LatencyP95 float64        `json:"latencyP95"`
+	LatencyP99 float64        `json:"latencyP99"`
+	LatencyMax float64        `json:"latencyMax"`
+	Errors     map[string]int `json:"errors,omitempty"`
+}
+
+func (s *creationStats) summary() *creationSummary {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	sorted := slices.Sorted(slices.Values(s.latencies))
+
+	return &creationSummary{
+		Created:    s.created,
+		Failed:     s.failed,
+		Requests:   len(sorted),
+		LatencyP50: percentile(sorted, 0.5).Seconds(),
+		LatencyP95: percentile(sorted, 0.95).Seconds(),
+		LatencyP99: percentile(sorted, 0.99).Seconds(),
+		LatencyMax: percentile(sorted, 1).Seconds(),
+		Errors:     maps.Clone(s.errors),
+	}
+}
+
+func (s *creationStats) String() string {
+	summary := s.summary()
+
+	str := fmt.Sprintf(
+		"%d policies created, %d failed, %d requests with a latency of %.3fs (p50), %.3fs (p95), "+
+			"%.3fs (p99), %.3fs (max)",
+		summary.Created, summary.Failed, summary.Requests,
+		summary.LatencyP50, summary.LatencyP95, summary.LatencyP99, summary.LatencyMax,
+	)
+
+	if len(summary.Errors) != 0 {
+		reasons := []string{}
+
+		for _, reason := range slices.Sorted(maps.Keys(summary.Errors)) {
+			reasons = append(reasons, fmt.Sprintf("%s: %d", reason, summary.Errors[reason]))
+		}
+
+		str += ", errors by reason: " + strings.Join(reasons, ", ")
+	}
+
+	return str
+}
+
+// percentile returns the latency below which the given fraction of the sorted latencies are.
+func percentile(sorted []time.Duration, fraction float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	return sorted[min(int(fraction*float64(len(sorted))), len(sorted)-1)]
+}
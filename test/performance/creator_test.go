@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stolostron/governance-policy-framework/test/common/kubeclient"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+var _ = Describe("Test the creation statistics", func() {
+	latencies := []time.Duration{
+		1 * time.Second, 2 * time.Second, 3 * time.Second, 4 * time.Second, 5 * time.Second,
+		6 * time.Second, 7 * time.Second, 8 * time.Second, 9 * time.Second, 10 * time.Second,
+	}
+
+	DescribeTable("Returns the percentile of the sorted latencies",
+		func(sorted []time.Duration, fraction float64, expected time.Duration) {
+			Expect(percentile(sorted, fraction)).To(Equal(expected))
+		},
+		Entry("without latencies", nil, 0.5, time.Duration(0)),
+		Entry("with a single latency", []time.Duration{time.Second}, 0.99, time.Second),
+		Entry("the lowest", latencies, 0.0, 1*time.Second),
+		Entry("the median", latencies, 0.5, 6*time.Second),
+		Entry("the 95th percentile", latencies, 0.95, 10*time.Second),
+		Entry("the 25th percentile", latencies, 0.25, 3*time.Second),
+		Entry("the highest", latencies, 1.0, 10*time.Second),
+	)
+
+	It("Stops creating the policies when the context is done", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		creator := &policyCreator{template: "[ID]", concurrency: 4}
+
+		stats := creator.createBatch(ctx, 100)
+		Expect(stats.created).To(BeZero())
+		Expect(stats.failed).To(BeZero())
+	})
+
+	It("Creates each object of the policies with a single request", func(ctx SpecContext) {
+		const filename = "resources/templates/cfgmap-plc.yaml"
+
+		template, err := os.ReadFile(filename)
+		Expect(err).ToNot(HaveOccurred())
+
+		policies := schema.GroupVersionResource{
+			Group: "policy.open-cluster-management.io", Version: "v1", Resource: "policies",
+		}
+		placementBindings := policies.GroupVersion().WithResource("placementbindings")
+		placements := schema.GroupVersionResource{
+			Group: "cluster.open-cluster-management.io", Version: "v1beta1", Resource: "placements",
+		}
+		listKinds := map[schema.GroupVersionResource]string{
+			policies: "PolicyList", placementBindings: "PlacementBindingList", placements: "PlacementList",
+		}
+		client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds)
+		discovery := &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{Resources: []*metav1.APIResourceList{
+			{
+				GroupVersion: "policy.open-cluster-management.io/v1",
+				APIResources: []metav1.APIResource{
+					{Name: "policies", Kind: "Policy", Namespaced: true},
+					{Name: "placementbindings", Kind: "PlacementBinding", Namespaced: true},
+				},
+			},
+			{
+				GroupVersion: "cluster.open-cluster-management.io/v1beta1",
+				APIResources: []metav1.APIResource{{Name: "placements", Kind: "Placement", Namespaced: true}},
+			},
+		}}}
+
+		creator := &policyCreator{
+			client:      client,
+			mapper:      kubeclient.NewApplier(client, discovery),
+			filename:    filename,
+			template:    string(template),
+			concurrency: 2,
+		}
+
+		stats := creator.createBatch(ctx, 5)
+		Expect(stats.created).To(Equal(5))
+		Expect(stats.failed).To(BeZero())
+		Expect(stats.latencies).To(HaveLen(15))
+
+		verbs := map[string]int{}
+		for _, action := range client.Actions() {
+			verbs[action.GetVerb()]++
+		}
+
+		Expect(verbs).To(Equal(map[string]int{"create": 15}))
+	})
+})
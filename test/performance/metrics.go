@@ -0,0 +1,270 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/api"
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"k8s.io/klog"
+)
+
+// The metrics sources selectable with the --metrics-source flag.
+const (
+	// sourceThanos queries the recording rules of the OpenShift monitoring stack through the
+	// thanos-querier Route, or the --metrics-url.
+	sourceThanos = "thanos"
+	// sourcePrometheus queries the cAdvisor metrics of a plain Prometheus at the --metrics-url, like
+	// one port-forwarded from a KinD cluster.
+	sourcePrometheus = "prometheus"
+	// sourceMetricsServer scrapes the PodMetrics of the metrics-server.
+	sourceMetricsServer = "metrics-server"
+	// sourceKubelet scrapes the cAdvisor resource metrics of the kubelets through the API server.
+	sourceKubelet = "kubelet"
+)
+
+// metricsSource gathers the CPU and memory usage of the config-policy-controller and the kube API
+// server.
+type metricsSource interface {
+	// collect returns the values of the metrics of the profile over the window which ends now.
+	collect(ctx context.Context, window time.Duration, numPolicies int) (metricData, error)
+	// collectSeries returns the time series of the Series of the profile between start and end.
+	collectSeries(ctx context.Context, start, end time.Time) ([]timeSeries, error)
+	// profile returns the metrics gathered by collect.
+	profile() *queryProfile
+}
+
+// metricsOptions are the flags which configure the metricsSource.
+type metricsOptions struct {
+	source         string
+	url            string
+	token          string
+	insecure       bool
+	profile        string
+	scrapeInterval time.Duration
+	// seriesStep is the resolution of the time series of the Prometheus sources.
+	seriesStep time.Duration
+}
+
+// newMetricsSource returns the metricsSource selected by the options. The scrapers run until the
+// context is canceled.
+func newMetricsSource(ctx context.Context, opts metricsOptions) (metricsSource, error) {
+	switch opts.source {
+	case sourceThanos:
+		if opts.token == "" || opts.url == "" {
+			token, thanosHost, err := setupMetrics(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			opts.token = cmp.Or(opts.token, token)
+			opts.url = cmp.Or(opts.url, "https://"+thanosHost)
+		}
+
+		return newPrometheusSource(opts)
+	case sourcePrometheus:
+		return newPrometheusSource(opts)
+	case sourceMetricsServer, sourceKubelet:
+		if opts.profile != "" {
+			return nil, fmt.Errorf("the %s metrics source doesn't support query profiles", opts.source)
+		}
+
+		return newScraperSource(ctx, opts)
+	default:
+		return nil, fmt.Errorf(
+			"unknown metrics source %s, expected one of %s, %s, %s, or %s",
+			opts.source, sourceThanos, sourcePrometheus, sourceMetricsServer, sourceKubelet,
+		)
+	}
+}
+
+// prometheusSource queries a Prometheus API, like the one of Thanos.
+type prometheusSource struct {
+	api     v1.API
+	queries *queryProfile
+	step    time.Duration
+}
+
+func newPrometheusSource(opts metricsOptions) (*prometheusSource, error) {
+	if opts.url == "" {
+		return nil, fmt.Errorf("the --metrics-url is required for the %s metrics source", opts.source)
+	}
+
+	queries, err := loadQueryProfile(opts.profile, opts.source)
+	if err != nil {
+		return nil, err
+	}
+
+	tr := &http.Transport{}
+	if opts.insecure {
+		tr.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+
+	trHeader := NewWithHeader(tr)
+	if opts.token != "" {
+		trHeader.Set("Authorization", "Bearer "+opts.token)
+	}
+
+	client, err := api.NewClient(api.Config{
+		Address: opts.url,
+		Client:  &http.Client{Transport: trHeader},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating client: %w", err)
+	}
+
+	return &prometheusSource{api: v1.NewAPI(client), queries: queries, step: opts.seriesStep}, nil
+}
+
+func (s *prometheusSource) profile() *queryProfile {
+	return s.queries
+}
+
+func (s *prometheusSource) collect(ctx context.Context, window time.Duration, numPolicies int) (metricData, error) {
+	data := metricData{numPolicies: numPolicies, values: map[string]float64{}}
+
+	for i, query := range s.queries.Queries {
+		promQL, err := query.render(window)
+		if err != nil {
+			return metricData{}, err
+		}
+
+		ts, values, err := s.query(ctx, promQL)
+		if err != nil {
+			return metricData{}, err
+		}
+
+		if i == 0 {
+			data.timestamp = ts.Format("15:04:05")
+		}
+
+		data.values[query.Name] = query.aggregate(values)
+	}
+
+	return data, nil
+}
+
+// query returns the values of the series of the result of the instant query, and its timestamp.
+func (s *prometheusSource) query(ctx context.Context, query string) (time.Time, []float64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	result, warnings, err := s.api.Query(ctx, query, time.Now())
+	if err != nil {
+		return time.Time{}, nil, fmt.Errorf("error querying Prometheus: %w", err)
+	}
+
+	if len(warnings) > 0 {
+		klog.Warningf("Prometheus query warnings: %v\n", warnings)
+	}
+
+	vector, ok := result.(model.Vector)
+	if !ok || len(vector) == 0 {
+		return time.Time{}, nil, fmt.Errorf("metrics response to %s is empty: %v", query, result)
+	}
+
+	values := make([]float64, 0, len(vector))
+
+	for _, sample := range vector {
+		values = append(values, float64(sample.Value))
+	}
+
+	return vector[0].Timestamp.Time(), values, nil
+}
+
+func (s *prometheusSource) collectSeries(ctx context.Context, start, end time.Time) ([]timeSeries, error) {
+	series := make([]timeSeries, 0, len(s.queries.Series))
+
+	for _, query := range s.queries.Series {
+		promQL, err := query.render(end.Sub(start))
+		if err != nil {
+			return nil, err
+		}
+
+		valuesByTime, err := s.queryRange(ctx, promQL, v1.Range{Start: start, End: end, Step: s.step})
+		if err != nil {
+			return nil, err
+		}
+
+		timestamps := slices.SortedFunc(maps.Keys(valuesByTime), time.Time.Compare)
+		points := make([]point, 0, len(timestamps))
+
+		for _, timestamp := range timestamps {
+			if value := query.aggregate(valuesByTime[timestamp]); isFinite(value) {
+				points = append(points, point{Time: timestamp, Value: value})
+			}
+		}
+
+		series = append(series, timeSeries{Name: query.Name, Unit: query.Unit, Points: points})
+	}
+
+	return series, nil
+}
+
+// queryRange returns the values of the series of the result of the range query, by timestamp.
+func (s *prometheusSource) queryRange(
+	ctx context.Context, query string, r v1.Range,
+) (map[time.Time][]float64, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	result, warnings, err := s.api.QueryRange(ctx, query, r)
+	if err != nil {
+		return nil, fmt.Errorf("error querying Prometheus: %w", err)
+	}
+
+	if len(warnings) > 0 {
+		klog.Warningf("Prometheus query warnings: %v\n", warnings)
+	}
+
+	matrix, ok := result.(model.Matrix)
+	if !ok {
+		return nil, fmt.Errorf("metrics response to %s is not a matrix: %v", query, result)
+	}
+
+	valuesByTime := map[time.Time][]float64{}
+
+	for _, stream := range matrix {
+		for _, sample := range stream.Values {
+			timestamp := sample.Timestamp.Time()
+			valuesByTime[timestamp] = append(valuesByTime[timestamp], float64(sample.Value))
+		}
+	}
+
+	return valuesByTime, nil
+}
+
+// setupMetrics returns the API token of the current user and the host of the thanos-querier Route.
+func setupMetrics(ctx context.Context) (token string, thanosHost string, err error) {
+	tokenBytes, err := exec.CommandContext(ctx, "oc", "whoami", "--show-token").CombinedOutput()
+	if err != nil {
+		return "", "", fmt.Errorf("error getting API token from `oc whoami --show-token`: %w", err)
+	}
+
+	thanosB64, err := exec.CommandContext(ctx,
+		"kubectl", "get", "route", "thanos-querier", "-n",
+		"openshift-monitoring", "-o=jsonpath='{.spec.host}'",
+	).CombinedOutput()
+	if err != nil {
+		return "", "", fmt.Errorf("error getting the thanos-querier route: %w", err)
+	}
+
+	thanosHost = strings.Trim(string(thanosB64), "'")
+	if thanosHost == "" {
+		return "", "", errors.New("the thanos-querier route has no host")
+	}
+
+	klog.V(2).Info("Setup complete! Token and metrics route retrieved successfully")
+
+	return strings.TrimSpace(string(tokenBytes)), thanosHost, nil
+}
@@ -0,0 +1,399 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+	"github.com/stolostron/governance-policy-framework/test/common/kubeclient"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog"
+)
+
+var gvrPodMetrics = schema.GroupVersionResource{Group: "metrics.k8s.io", Version: "v1beta1", Resource: "podmetrics"}
+
+// errPriming is returned by a scrape which only primed the counters needed to compute rates.
+var errPriming = errors.New("the counters are primed")
+
+// component is a workload whose usage is measured: the CPU usage of all the containers of its pods,
+// and the memory usage of its main container, like the recording rules of OpenShift.
+type component struct {
+	pod       *regexp.Regexp
+	container string
+}
+
+var (
+	controllerComponent = component{
+		pod: regexp.MustCompile(`^config-policy-controller-`), container: "config-policy-controller",
+	}
+	apiServerComponent = component{pod: regexp.MustCompile(`^kube-apiserver-`), container: "kube-apiserver"}
+)
+
+// scraperProfile are the metrics of the scraperSource, like the default profiles of the Prometheus
+// sources.
+var scraperProfile = &queryProfile{Queries: []profileQuery{
+	{Name: "avg_cpu_controller", Description: "avg cpu (controller)", Unit: "cores"},
+	{Name: "max_cpu_controller", Description: "max cpu (controller)", Unit: "cores"},
+	{Name: "avg_cpu_apiserver", Description: "avg cpu (apiserver)", Unit: "cores"},
+	{Name: "max_cpu_apiserver", Description: "max cpu (apiserver)", Unit: "cores"},
+	{Name: "avg_memory_controller", Description: "avg memory (controller)", Unit: "MB"},
+	{Name: "max_memory_controller", Description: "max memory (controller)", Unit: "MB"},
+	{Name: "avg_memory_apiserver", Description: "avg memory (apiserver)", Unit: "MB"},
+	{Name: "max_memory_apiserver", Description: "max memory (apiserver)", Unit: "MB"},
+}, Series: []profileQuery{
+	{Name: "cpu_controller", Description: "cpu (controller)", Unit: "cores"},
+	{Name: "cpu_apiserver", Description: "cpu (apiserver)", Unit: "cores"},
+	{Name: "memory_controller", Description: "memory (controller)", Unit: "MB"},
+	{Name: "memory_apiserver", Description: "memory (apiserver)", Unit: "MB"},
+}}
+
+// usageSample is the usage of the components at a point in time, in cores and bytes.
+type usageSample struct {
+	time          time.Time
+	controllerCPU float64
+	controllerMem float64
+	apiServerCPU  float64
+	apiServerMem  float64
+}
+
+// add adds the usage of a container to the component of its pod.
+func (s *usageSample) add(pod, container string, cpu, memory float64) {
+	switch {
+	case controllerComponent.pod.MatchString(pod):
+		s.controllerCPU += cpu
+		if container == controllerComponent.container {
+			s.controllerMem += memory
+		}
+	case apiServerComponent.pod.MatchString(pod):
+		s.apiServerCPU += cpu
+		if container == apiServerComponent.container {
+			s.apiServerMem += memory
+		}
+	}
+}
+
+// usage returns the usage of the components by the name of their series in the scraperProfile.
+func (s usageSample) usage() map[string]float64 {
+	return map[string]float64{
+		"cpu_controller":    s.controllerCPU,
+		"cpu_apiserver":     s.apiServerCPU,
+		"memory_controller": s.controllerMem * 0.000001,
+		"memory_apiserver":  s.apiServerMem * 0.000001,
+	}
+}
+
+// scraperSource periodically scrapes the usage of the components, for clusters without Prometheus.
+type scraperSource struct {
+	scrape  func(ctx context.Context) (usageSample, error)
+	samples []usageSample
+	lock    sync.Mutex
+}
+
+// newScraperSource returns a scraperSource of the metrics-server or of the kubelets, which has
+// scraped once and keeps scraping at the scrape interval until the context is canceled.
+func newScraperSource(ctx context.Context, opts metricsOptions) (*scraperSource, error) {
+	config, err := kubeclient.LoadConfig("", "", "")
+	if err != nil {
+		return nil, fmt.Errorf("error loading the kubeconfig: %w", err)
+	}
+
+	kubeclient.DefaultClientOptions().Apply(config)
+
+	source := &scraperSource{}
+
+	if opts.source == sourceMetricsServer {
+		client, err := dynamic.NewForConfig(config)
+		if err != nil {
+			return nil, fmt.Errorf("error creating client: %w", err)
+		}
+
+		source.scrape = func(ctx context.Context) (usageSample, error) {
+			return scrapeMetricsServer(ctx, client)
+		}
+	} else {
+		client, err := kubernetes.NewForConfig(config)
+		if err != nil {
+			return nil, fmt.Errorf("error creating client: %w", err)
+		}
+
+		kubelets := &kubeletScraper{client: client, counters: map[string]cpuCounter{}}
+		source.scrape = kubelets.scrape
+	}
+
+	for {
+		err := source.scrapeOnce(ctx)
+		if err == nil {
+			break
+		}
+
+		if !errors.Is(err, errPriming) {
+			return nil, err
+		}
+
+		klog.V(2).Infof("Waiting %s for the CPU usage counters to increase", opts.scrapeInterval)
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(opts.scrapeInterval):
+		}
+	}
+
+	go func() {
+		ticker := time.NewTicker(opts.scrapeInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := source.scrapeOnce(ctx); err != nil && !errors.Is(err, errPriming) {
+					klog.Warningf("Error scraping the metrics: %v", err)
+				}
+			}
+		}
+	}()
+
+	return source, nil
+}
+
+func (s *scraperSource) scrapeOnce(ctx context.Context) error {
+	sample, err := s.scrape(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.samples = append(s.samples, sample)
+
+	return nil
+}
+
+func (s *scraperSource) profile() *queryProfile {
+	return scraperProfile
+}
+
+func (s *scraperSource) collect(_ context.Context, window time.Duration, numPolicies int) (metricData, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := time.Now()
+	values := map[string]float64{}
+	count := 0
+
+	for _, sample := range s.samples {
+		if now.Sub(sample.time) > window {
+			continue
+		}
+
+		count++
+
+		for name, value := range sample.usage() {
+			values["avg_"+name] += value
+			values["max_"+name] = max(values["max_"+name], value)
+		}
+	}
+
+	if count == 0 {
+		return metricData{}, fmt.Errorf("no metrics were scraped in the past %s", window)
+	}
+
+	for name := range values {
+		if strings.HasPrefix(name, "avg_") {
+			values[name] /= float64(count)
+		}
+	}
+
+	return metricData{timestamp: now.Format("15:04:05"), numPolicies: numPolicies, values: values}, nil
+}
+
+func (s *scraperSource) collectSeries(_ context.Context, start, end time.Time) ([]timeSeries, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	series := make([]timeSeries, 0, len(scraperProfile.Series))
+
+	for _, query := range scraperProfile.Series {
+		points := []point{}
+
+		for _, sample := range s.samples {
+			if sample.time.Before(start) || sample.time.After(end) {
+				continue
+			}
+
+			points = append(points, point{Time: sample.time, Value: sample.usage()[query.Name]})
+		}
+
+		series = append(series, timeSeries{Name: query.Name, Unit: query.Unit, Points: points})
+	}
+
+	return series, nil
+}
+
+// scrapeMetricsServer returns the usage of the components from the PodMetrics of the metrics-server.
+func scrapeMetricsServer(ctx context.Context, client dynamic.Interface) (usageSample, error) {
+	podMetrics, err := client.Resource(gvrPodMetrics).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return usageSample{}, fmt.Errorf("error listing the PodMetrics, is the metrics-server installed? %w", err)
+	}
+
+	sample := usageSample{time: time.Now()}
+
+	for _, pod := range podMetrics.Items {
+		containers, _, _ := unstructured.NestedSlice(pod.Object, "containers")
+
+		for _, container := range containers {
+			containerMap, ok := container.(map[string]any)
+			if !ok {
+				continue
+			}
+
+			usage, _ := containerMap["usage"].(map[string]any)
+			name, _ := containerMap["name"].(string)
+
+			sample.add(pod.GetName(), name, quantity(usage["cpu"]), quantity(usage["memory"]))
+		}
+	}
+
+	return sample, nil
+}
+
+// quantity returns the value of the resource quantity, or 0 if it's invalid.
+func quantity(value any) float64 {
+	str, ok := value.(string)
+	if !ok {
+		return 0
+	}
+
+	parsed, err := resource.ParseQuantity(str)
+	if err != nil {
+		return 0
+	}
+
+	return parsed.AsApproximateFloat64()
+}
+
+// cpuCounter is a value of the container_cpu_usage_seconds_total counter of a container.
+type cpuCounter struct {
+	seconds   float64
+	timestamp time.Time
+	// rate is the last rate computed from the counter, in cores.
+	rate float64
+}
+
+// rateSince returns the rate of the counter since its previous value, in cores. The counter restarts
+// from 0 when the container restarts, like with the rate function of Prometheus. The previous rate is
+// kept when the kubelet didn't update the counter in between.
+func (c cpuCounter) rateSince(previous cpuCounter) float64 {
+	elapsed := c.timestamp.Sub(previous.timestamp).Seconds()
+	if elapsed <= 0 {
+		return previous.rate
+	}
+
+	increase := c.seconds - previous.seconds
+	if increase < 0 {
+		increase = c.seconds
+	}
+
+	return increase / elapsed
+}
+
+// kubeletScraper scrapes the resource metrics of the kubelets, which are measured by cAdvisor,
+// through the node proxy of the API server.
+type kubeletScraper struct {
+	client   kubernetes.Interface
+	counters map[string]cpuCounter
+	primed   bool
+}
+
+func (k *kubeletScraper) scrape(ctx context.Context) (usageSample, error) {
+	nodes, err := k.client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return usageSample{}, fmt.Errorf("error listing the nodes: %w", err)
+	}
+
+	sample := usageSample{time: time.Now()}
+
+	for _, node := range nodes.Items {
+		body, err := k.client.CoreV1().RESTClient().Get().
+			AbsPath("/api/v1/nodes", node.Name, "proxy/metrics/resource").
+			Stream(ctx)
+		if err != nil {
+			return usageSample{}, fmt.Errorf("error scraping the kubelet of the node %s: %w", node.Name, err)
+		}
+
+		parser := expfmt.NewTextParser(model.UTF8Validation)
+		families, err := parser.TextToMetricFamilies(body)
+
+		body.Close()
+
+		if err != nil {
+			return usageSample{}, fmt.Errorf(
+				"error parsing the metrics of the kubelet of the node %s: %w", node.Name, err,
+			)
+		}
+
+		for _, metric := range families["container_cpu_usage_seconds_total"].GetMetric() {
+			pod, container := podAndContainer(metric)
+			key := node.Name + "/" + labelValue(metric, "namespace") + "/" + pod + "/" + container
+
+			counter := cpuCounter{seconds: metric.GetCounter().GetValue(), timestamp: sample.time}
+			if metric.GetTimestampMs() != 0 {
+				counter.timestamp = time.UnixMilli(metric.GetTimestampMs())
+			}
+
+			// The rate of a new container is 0 until the next scrape.
+			if previous, ok := k.counters[key]; ok {
+				counter.rate = counter.rateSince(previous)
+			}
+
+			k.counters[key] = counter
+
+			sample.add(pod, container, counter.rate, 0)
+		}
+
+		for _, metric := range families["container_memory_working_set_bytes"].GetMetric() {
+			pod, container := podAndContainer(metric)
+
+			sample.add(pod, container, 0, metric.GetGauge().GetValue())
+		}
+	}
+
+	// The rates of the first scrape are all 0.
+	if !k.primed {
+		k.primed = true
+
+		return usageSample{}, errPriming
+	}
+
+	return sample, nil
+}
+
+func podAndContainer(metric *dto.Metric) (string, string) {
+	return labelValue(metric, "pod"), labelValue(metric, "container")
+}
+
+func labelValue(metric *dto.Metric, name string) string {
+	for _, label := range metric.GetLabel() {
+		if label.GetName() == name {
+			return label.GetValue()
+		}
+	}
+
+	return ""
+}
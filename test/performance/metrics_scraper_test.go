@@ -0,0 +1,27 @@
+package main
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Test the metrics scrapers", func() {
+	start := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	previous := cpuCounter{seconds: 10, timestamp: start, rate: 0.25}
+
+	DescribeTable("Computes the CPU rate of the kubelet counters",
+		func(seconds float64, elapsed time.Duration, expected float64) {
+			counter := cpuCounter{seconds: seconds, timestamp: start.Add(elapsed)}
+
+			Expect(counter.rateSince(previous)).To(BeNumerically("~", expected, 1e-9))
+		},
+		Entry("of a busy container", 14.0, 4*time.Second, 1.0),
+		Entry("of a partly used core", 11.0, 4*time.Second, 0.25),
+		Entry("of an idle container", 10.0, 15*time.Second, 0.0),
+		Entry("of a restarted container", 2.0, 4*time.Second, 0.5),
+		Entry("of a counter which wasn't updated", 10.0, time.Duration(0), 0.25),
+		Entry("of a counter older than the previous one", 12.0, -time.Second, 0.25),
+	)
+})
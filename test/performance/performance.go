@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"crypto/tls"
 	"encoding/csv"
 	"flag"
 	"fmt"
@@ -16,10 +15,6 @@ import (
 	"text/tabwriter"
 	"time"
 
-	"github.com/google/uuid"
-	"github.com/prometheus/client_golang/api"
-	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
-	"github.com/prometheus/common/model"
 	"github.com/spf13/pflag"
 	"k8s.io/klog"
 )
@@ -50,260 +45,121 @@ func (h WithHeader) RoundTrip(req *http.Request) (*http.Response, error) {
 	return h.rt.RoundTrip(req)
 }
 
-func query(host string, token string, query string, insecure bool) (time.Time, float64) {
-	tr := &http.Transport{}
-	if insecure {
-		tr.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
-	}
-
-	trHeader := NewWithHeader(tr)
-	trHeader.Set("Authorization", "Bearer "+token)
-
-	cl := &http.Client{Transport: trHeader}
-
-	client, err := api.NewClient(api.Config{
-		Address: "https://" + host,
-		Client:  cl,
-	})
+// getMetrics returns the metricData of the past window from the metrics source.
+func getMetrics(ctx context.Context, source metricsSource, window time.Duration, numPolicies int) metricData {
+	data, err := source.collect(ctx, window, numPolicies)
 	if err != nil {
-		klog.Exitf("Error creating client: %v\n", err)
+		klog.Exitf("Error collecting the metrics: %v\n", err)
 	}
 
-	v1api := v1.NewAPI(client)
-
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	// log metrics
+	klog.V(2).Infof("Utilization over the past %s:", window)
 
-	result, warnings, err := v1api.Query(ctx, query, time.Now())
-	if err != nil {
-		klog.Exitf("Error querying Prometheus: %v\n", err)
+	for _, query := range source.profile().Queries {
+		klog.V(2).Infof("%s: %.5f %s", query.header(), data.values[query.Name], query.Unit)
 	}
 
-	if len(warnings) > 0 {
-		klog.Warningf("Prometheus query warnings: %v\n", warnings)
-	}
+	return data
+}
 
-	if len(result.(model.Vector)) > 0 {
-		val := result.(model.Vector)[0]
+type metricData struct {
+	timestamp   string
+	numPolicies int
+	// timeToCompliance is the time from the start of the creation of the batch until all the policies
+	// reported a compliance state, or 0 if it wasn't measured.
+	timeToCompliance time.Duration
+	// creation is the creation of the batch, or nil for the baseline.
+	creation *creationStats
+	// values are the values of the metrics of the queryProfile, by name.
+	values map[string]float64
+}
 
-		metric, err := strconv.ParseFloat(val.Value.String(), 32)
-		if err != nil {
-			klog.Exitf("Error parsing metric response: %v\n", err)
+// hasTimeToCompliance returns whether the time to compliance of a batch was measured, in which case
+// it's a column of the results.
+func hasTimeToCompliance(data []metricData) bool {
+	for _, entry := range data {
+		if entry.timeToCompliance != 0 {
+			return true
 		}
-
-		return val.Timestamp.Time(), metric
 	}
 
-	klog.Exitf("Error: metrics response is empty: %v\n", result)
-
-	return time.Time{}, -1
+	return false
 }
 
-func setupMetrics(ctx context.Context) (token string, thanosHost string) {
-	tokenBytes, err := exec.CommandContext(ctx, "oc", "whoami", "--show-token").CombinedOutput()
-	if err != nil {
-		klog.Exitf("Error getting API token from `oc whoami --show-token`: %v", err)
+// timeToComplianceColumn returns the time to compliance in seconds, or an empty string if it wasn't
+// measured.
+func (d metricData) timeToComplianceColumn() string {
+	if d.timeToCompliance == 0 {
+		return ""
 	}
 
-	thanosB64, err := exec.CommandContext(ctx,
-		"kubectl", "get", "route", "thanos-querier", "-n",
-		"openshift-monitoring", "-o=jsonpath='{.spec.host}'",
-	).CombinedOutput()
-	if err != nil {
-		klog.Exitf("Error getting API token from secret: %s", err)
+	return fmt.Sprintf("%.1f", d.timeToCompliance.Seconds())
+}
+
+// pretty print tables of results to stdout, one per unit of the metrics of the profile
+func printTable(profile *queryProfile, data []metricData) {
+	units := []string{}
+	queriesByUnit := map[string][]profileQuery{}
+
+	for _, query := range profile.Queries {
+		if _, ok := queriesByUnit[query.Unit]; !ok {
+			units = append(units, query.Unit)
+		}
+
+		queriesByUnit[query.Unit] = append(queriesByUnit[query.Unit], query)
 	}
 
-	klog.V(2).Info("Setup complete! Token and metrics route retrieved successfully")
+	table := tabwriter.NewWriter(os.Stdout, 1, 4, 1, ' ', tabwriter.Debug|tabwriter.AlignRight)
 
-	return strings.TrimSpace(string(tokenBytes)), strings.Trim(string(thanosB64), "'")
-}
+	for _, unit := range units {
+		headers := []string{"time", "# policies"}
 
-func getMetrics(
-	thanosHost string, token string,
-	perBatchSleep int, numPolicies int,
-	insecure bool,
-) metricData {
-	// print metrics
-	ts, controllerCPUAvg := query(
-		thanosHost,
-		token,
-		fmt.Sprintf(
-			"avg_over_time(pod:container_cpu_usage:sum{pod=~'config-policy-controller-.*'}[%dm:30s])",
-			perBatchSleep,
-		),
-		insecure,
-	)
-	_, apiServerCPUAvg := query(
-		thanosHost,
-		token,
-		fmt.Sprintf(
-			"sum(avg_over_time(pod:container_cpu_usage:sum{pod=~'kube-apiserver-ip-.*'}[%dm:30s]))",
-			perBatchSleep,
-		),
-		insecure,
-	)
-	_, controllerCPUMax := query(
-		thanosHost,
-		token,
-		fmt.Sprintf(
-			"max_over_time(pod:container_cpu_usage:sum{pod=~'config-policy-controller-.*'}[%dm:30s])",
-			perBatchSleep,
-		),
-		insecure,
-	)
-	_, apiServerCPUMax := query(
-		thanosHost,
-		token,
-		fmt.Sprintf(
-			"sum(max_over_time(pod:container_cpu_usage:sum{pod=~'kube-apiserver-ip-.*'}[%dm:30s]))",
-			perBatchSleep,
-		),
-		insecure,
-	)
+		if hasTimeToCompliance(data) {
+			headers = append(headers, "compliant after (s)")
+		}
 
-	_, controllerMemAvg := query(
-		thanosHost,
-		token,
-		fmt.Sprintf(
-			"sum(avg_over_time(container_memory_working_set_bytes{container='config-policy-controller'}[%dm:30s])) "+
-				"* 0.000001",
-			perBatchSleep,
-		),
-		insecure,
-	)
-	_, apiServerMemAvg := query(
-		thanosHost,
-		token,
-		fmt.Sprintf(
-			"sum(avg_over_time(container_memory_working_set_bytes{container='kube-apiserver'}[%dm:30s])) * 0.000001",
-			perBatchSleep,
-		),
-		insecure,
-	)
-	_, controllerMemMax := query(
-		thanosHost,
-		token,
-		fmt.Sprintf(
-			"sum(max_over_time(container_memory_working_set_bytes{container='config-policy-controller'}[%dm:30s])) "+
-				"* 0.000001",
-			perBatchSleep,
-		),
-		insecure,
-	)
-	_, apiServerMemMax := query(
-		thanosHost,
-		token,
-		fmt.Sprintf(
-			"sum(max_over_time(container_memory_working_set_bytes{container='kube-apiserver'}[%dm:30s])) * 0.000001",
-			perBatchSleep,
-		),
-		insecure,
-	)
+		for _, query := range queriesByUnit[unit] {
+			headers = append(headers, query.header())
+		}
 
-	// log metrics
-	klog.V(2).Infof("CPU utilization over the past %d minutes:", perBatchSleep)
-	klog.V(2).Infof("config policy controller: %.5f / %.5f (avg/max)", controllerCPUAvg, controllerCPUMax)
-	klog.V(2).Infof("kube API server: %.5f / %.5f (avg/max)", apiServerCPUAvg, apiServerCPUMax)
-
-	klog.V(2).Infof("Memory utilization over the past %d minutes:", perBatchSleep)
-	klog.V(2).Infof("config policy controller: %.5f MB / %.5f MB (avg/max)", controllerMemAvg, controllerMemMax)
-	klog.V(2).Infof("kube API server: %.5f MB / %.5f MB (avg/max)", apiServerMemAvg, apiServerMemMax)
-
-	return metricData{
-		timestamp:        ts.Format("15:04:05"),
-		numPolicies:      numPolicies,
-		controllerCPUAvg: controllerCPUAvg,
-		controllerCPUMax: controllerCPUMax,
-		apiServerCPUAvg:  apiServerCPUAvg,
-		apiServerCPUMax:  apiServerCPUMax,
-		controllerMemAvg: controllerMemAvg,
-		controllerMemMax: controllerMemMax,
-		apiServerMemAvg:  apiServerMemAvg,
-		apiServerMemMax:  apiServerMemMax,
-	}
-}
+		separators := make([]string, len(headers))
+		for i, header := range headers {
+			separators[i] = strings.Repeat("=", len(header))
+		}
 
-func genUniquePolicy(inFilename string, outFilename string) error {
-	input, err := os.ReadFile(inFilename)
-	if err != nil {
-		return err
-	}
+		fmt.Fprintln(table, strings.Join(separators, "\t")+"\t")
+		fmt.Fprintln(table, strings.Join(headers, "\t")+"\t")
+		fmt.Fprintln(table, strings.Join(separators, "\t")+"\t")
 
-	output := strings.ReplaceAll(string(input), "[ID]", uuid.New().String())
+		for i := range data {
+			row := []string{data[i].timestamp, strconv.Itoa(data[i].numPolicies)}
 
-	err = os.WriteFile(outFilename, []byte(output), 0o644)
-	if err != nil {
-		return err
-	}
+			if hasTimeToCompliance(data) {
+				row = append(row, data[i].timeToComplianceColumn())
+			}
 
-	return nil
-}
+			for _, query := range queriesByUnit[unit] {
+				row = append(row, strings.TrimSpace(fmt.Sprintf("%.5f %s", data[i].values[query.Name], unit)))
+			}
 
-type metricData struct {
-	timestamp        string
-	numPolicies      int
-	controllerCPUAvg float64
-	controllerCPUMax float64
-	apiServerCPUAvg  float64
-	apiServerCPUMax  float64
-	controllerMemAvg float64
-	controllerMemMax float64
-	apiServerMemAvg  float64
-	apiServerMemMax  float64
-}
+			fmt.Fprintln(table, strings.Join(row, "\t")+"\t")
+		}
 
-// pretty print table of results to stdout
-func printTable(data []metricData) {
-	table := tabwriter.NewWriter(os.Stdout, 1, 4, 1, ' ', tabwriter.Debug|tabwriter.AlignRight)
-	fmt.Fprintln(table, "========\t==========\t====================\t"+
-		"====================\t===================\t===================\t")
-	fmt.Fprintln(table, "time\t# policies\tavg cpu (controller)\t"+
-		"max cpu (controller)\tavg cpu (apiserver)\tmax cpu (apiserver)\t")
-	fmt.Fprintln(table, "========\t==========\t====================\t"+
-		"====================\t===================\t===================\t")
-
-	for i := range data {
-		fmt.Fprintf(table, "%s\t%d\t%s\t%s\t%s\t%s\t\n",
-			data[i].timestamp,
-			data[i].numPolicies,
-			fmt.Sprintf("%.5f", data[i].controllerCPUAvg),
-			fmt.Sprintf("%.5f", data[i].controllerCPUMax),
-			fmt.Sprintf("%.5f", data[i].apiServerCPUAvg),
-			fmt.Sprintf("%.5f", data[i].apiServerCPUMax),
-		)
-	}
+		klog.V(5).Infof("============================================" +
+			"================================================================")
 
-	klog.V(5).Infof("============================================" +
-		"================================================================")
-	klog.V(5).Infof("CPU Data (core usage):")
-	table.Flush()
-
-	fmt.Fprintln(table, "========\t==========\t====================\t"+
-		"====================\t===================\t===================\t")
-	fmt.Fprintln(table, "time\t# policies\tavg memory (controller)\t"+
-		"max memory (controller)\tavg memory (apiserver)\tmax memory (apiserver)\t")
-	fmt.Fprintln(table, "========\t==========\t====================\t"+
-		"====================\t===================\t===================\t")
-
-	for i := range data {
-		fmt.Fprintf(table, "%s\t%d\t%s\t%s\t%s\t%s\t\n",
-			data[i].timestamp,
-			data[i].numPolicies,
-			fmt.Sprintf("%.5f MB", data[i].controllerMemAvg),
-			fmt.Sprintf("%.5f MB", data[i].controllerMemMax),
-			fmt.Sprintf("%.5f MB", data[i].apiServerMemAvg),
-			fmt.Sprintf("%.5f MB", data[i].apiServerMemMax),
-		)
-	}
+		if unit == "" {
+			klog.V(5).Infof("Data:")
+		} else {
+			klog.V(5).Infof("Data (%s):", unit)
+		}
 
-	klog.V(5).Infof("============================================" +
-		"================================================================")
-	klog.V(5).Infof("Memory Data:")
-	table.Flush()
+		table.Flush()
+	}
 }
 
-// export table of results to a csv file
-func exportTable(cpuData []metricData, filename string) {
+// export table of results to a csv file, with a column per metric of the profile
+func exportTable(profile *queryProfile, data []metricData, filename string) {
 	f, err := os.Create(filename)
 	if err != nil {
 		klog.Exitf("Error: failed to create %s; %s", filename, err)
@@ -318,27 +174,31 @@ func exportTable(cpuData []metricData, filename string) {
 	w := csv.NewWriter(f)
 	defer w.Flush()
 
-	line := []string{
-		"time", "numPolicies", "avg_cpu_controller", "max_cpu_controller", "avg_cpu_apiserver", "max_cpu_apiserver",
-		"avg_memory_controller", "max_memory_controller", "avg_memory_apiserver", "max_memory_apiserver",
+	line := []string{"time", "numPolicies"}
+
+	if hasTimeToCompliance(data) {
+		line = append(line, "timeToCompliance")
+	}
+
+	for _, query := range profile.Queries {
+		line = append(line, query.Name)
 	}
+
 	if err := w.Write(line); err != nil {
 		klog.Exitf("Error writing headers to file; %s", err)
 	}
 
-	for _, entry := range cpuData {
-		line = []string{
-			entry.timestamp,
-			strconv.Itoa(entry.numPolicies),
-			fmt.Sprintf("%.5f", entry.controllerCPUAvg),
-			fmt.Sprintf("%.5f", entry.controllerCPUMax),
-			fmt.Sprintf("%.5f", entry.apiServerCPUAvg),
-			fmt.Sprintf("%.5f", entry.apiServerCPUMax),
-			fmt.Sprintf("%.5f MB", entry.controllerMemAvg),
-			fmt.Sprintf("%.5f MB", entry.controllerMemMax),
-			fmt.Sprintf("%.5f MB", entry.apiServerMemAvg),
-			fmt.Sprintf("%.5f MB", entry.apiServerMemMax),
+	for _, entry := range data {
+		line = []string{entry.timestamp, strconv.Itoa(entry.numPolicies)}
+
+		if hasTimeToCompliance(data) {
+			line = append(line, entry.timeToComplianceColumn())
 		}
+
+		for _, query := range profile.Queries {
+			line = append(line, fmt.Sprintf("%.5f", entry.values[query.Name]))
+		}
+
 		if err := w.Write(line); err != nil {
 			klog.Exitf("Error writing data to file; %s", err)
 		}
@@ -356,94 +216,153 @@ func main() {
 
 	var plcFilename, outputFilename string
 	var nPerBatch, nTotal, perBatchSleep int
-	var insecure bool
+	var htmlReport bool
+	var waitFor string
+	var createConcurrency int
+	var createQPS float32
+	var pollInterval, complianceTimeout time.Duration
+	var metricsOpts metricsOptions
 
 	pflag.StringVarP(&plcFilename, "policy", "p",
 		"resources/templates/cfgmap-plc.yaml", "path to test policy YAML, relative to performance directory")
 	pflag.IntVarP(&nPerBatch, "policies-per-batch", "b", 100, "number of policies to create per batch")
 	pflag.IntVarP(&nTotal, "total-policies", "t", 1000, "total number of policies created")
+	pflag.IntVar(&createConcurrency, "create-concurrency", 10, "number of policies to create concurrently")
+	pflag.Float32Var(&createQPS, "create-qps", 20, "maximum number of requests per second to create the policies")
 	pflag.IntVarP(&perBatchSleep, "sleep", "s", 20, "time (min) to sleep after creating a batch of policies")
-	pflag.StringVar(&outputFilename, "csv", "results.csv", "path to CSV to export results to")
-	pflag.BoolVar(&insecure, "insecure-skip-verify", false, "skip certificate verification on metrics requests")
+	pflag.StringVar(&waitFor, "wait-for", waitForSleep,
+		"what to wait for after creating a batch of policies before collecting the metrics: sleep, which "+
+			"sleeps the --sleep minutes, or compliance, which waits until all the policies have a compliance state")
+	pflag.DurationVar(&pollInterval, "poll-interval", 10*time.Second,
+		"how often to check the compliance state of the policies with --wait-for=compliance")
+	pflag.DurationVar(&complianceTimeout, "compliance-timeout", time.Hour,
+		"how long to wait for the policies of a batch to have a compliance state with --wait-for=compliance")
+	pflag.StringVar(&outputFilename, "csv", "results.csv",
+		"path to CSV to export results to; the time series are exported next to it, "+
+			"to <name>-series.csv and <name>.json")
+	pflag.BoolVar(&htmlReport, "html-report", false,
+		"also export the results to <name>.html, with a chart of each time series")
+	pflag.BoolVar(
+		&metricsOpts.insecure, "insecure-skip-verify", false, "skip certificate verification on metrics requests",
+	)
+	pflag.StringVar(&metricsOpts.source, "metrics-source", sourceThanos,
+		"where to get the metrics from: thanos (OpenShift monitoring), prometheus (e.g. in KinD), "+
+			"metrics-server, or kubelet (cAdvisor resource metrics through the API server)")
+	pflag.StringVar(&metricsOpts.url, "metrics-url", "",
+		"URL of the Prometheus API for the thanos and prometheus sources; "+
+			"defaults to the thanos-querier Route for the thanos source")
+	pflag.StringVar(&metricsOpts.token, "metrics-token", "",
+		"bearer token for the Prometheus API; defaults to `oc whoami --show-token` for the thanos source")
+	pflag.StringVar(&metricsOpts.profile, "query-profile", "",
+		"path to a YAML query profile of the metrics to gather for the thanos and prometheus sources; "+
+			"defaults to the profile of the source in the profiles directory")
+	pflag.DurationVar(&metricsOpts.scrapeInterval, "scrape-interval", 30*time.Second,
+		"how often the metrics-server and kubelet sources scrape the metrics")
+	pflag.DurationVar(&metricsOpts.seriesStep, "series-step", 30*time.Second,
+		"resolution of the time series of the thanos and prometheus sources")
 
 	pflag.Parse()
 
-	ctx := context.Background()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	creator, err := newPolicyCreator(path.Join(performanceDir, plcFilename), createConcurrency, createQPS)
+	if err != nil {
+		klog.Exitf("Error setting up the creation of the policies: %v", err)
+	}
+
+	var waiter *complianceWaiter
+
+	switch waitFor {
+	case waitForSleep:
+	case waitForCompliance:
+		waiter, err = newComplianceWaiter(pollInterval, complianceTimeout)
+		if err != nil {
+			klog.Exitf("Error setting up the compliance checks: %v", err)
+		}
+	default:
+		klog.Exitf("Unknown --wait-for %s, expected %s or %s", waitFor, waitForSleep, waitForCompliance)
+	}
 
-	token, thanosHost := setupMetrics(ctx)
+	source, err := newMetricsSource(ctx, metricsOpts)
+	if err != nil {
+		klog.Exitf("Error setting up the %s metrics source: %v", metricsOpts.source, err)
+	}
 
 	klog.Info("Starting the Config Policy Controller performance test :)")
 
 	tableData := []metricData{}
+	seriesData := []batchSeries{}
 
-	allMetrics := getMetrics(thanosHost, token, perBatchSleep, 0, insecure)
+	baselineWindow := time.Duration(perBatchSleep) * time.Minute
+	baselineEnd := time.Now()
+	allMetrics := getMetrics(ctx, source, baselineWindow, 0)
 
 	tableData = append(tableData, allMetrics)
-
-	// setup temp directory for auto-generated policy YAML to live in
-	policyDir, err := os.MkdirTemp(path.Join(performanceDir, "resources"), "policies")
-	if err != nil {
-		klog.Exitf("Error creating temp policy directory: %s", err)
-	}
-
-	defer os.RemoveAll(policyDir) // clean up
+	seriesData = append(seriesData, getSeries(ctx, source, baselineEnd.Add(-baselineWindow), baselineEnd, 0))
 
 	totalPlcs := 0
+	createdPlcs := 0
+
 	for totalPlcs < nTotal {
 		start := time.Now()
 
 		// create a batch of policies
 		klog.V(2).Infof("Creating %d copies of %s on the managed cluster...\n", nPerBatch, plcFilename)
 
-		batchFails := 0
+		stats := creator.createBatch(ctx, nPerBatch)
+		totalPlcs += nPerBatch
+		createdPlcs += stats.created
 
-		for range nPerBatch {
-			err = genUniquePolicy(path.Join(performanceDir, plcFilename), path.Join(policyDir, "current_policy.yaml"))
-			if err != nil {
-				klog.Exitf("Error patching policy with unique name: %s", err)
-			}
+		klog.V(2).Infof("Creation of the batch: %s", stats)
 
-			tries := 2
-			for tries > 0 {
-				creationOutput, err := exec.CommandContext(ctx,
-					"kubectl", "apply", "-f",
-					path.Join(policyDir, "current_policy.yaml"),
-				).CombinedOutput()
-				if err != nil {
-					klog.Errorf("Error creating policy: %s, %s", err, string(creationOutput))
-
-					tries--
-					if tries == 0 {
-						klog.Fatal()
-					}
-				} else {
-					break
-				}
-			}
-
-			totalPlcs++
-		}
-
-		// sleep for the remainder of perBatchSleep
 		end := time.Now()
 		elapsed := end.Sub(start)
-		bonusTime := time.Duration(perBatchSleep)*time.Minute - elapsed
 
-		klog.V(2).Infof(
-			"%d policies created in %.2f seconds! Waiting an additional %.2f seconds for policies to process...\n",
-			nPerBatch-batchFails,
-			elapsed.Seconds(),
-			bonusTime.Seconds(),
-		)
+		var timeToCompliance time.Duration
 
-		time.Sleep(bonusTime)
+		if waiter != nil {
+			klog.V(2).Infof(
+				"%d policies created in %.2f seconds! Waiting for all %d policies to have a compliance state...\n",
+				stats.created,
+				elapsed.Seconds(),
+				createdPlcs,
+			)
 
-		allMetrics := getMetrics(thanosHost, token, perBatchSleep, totalPlcs, insecure)
+			timeToCompliance, err = waiter.wait(ctx, start, createdPlcs)
+			if err != nil {
+				klog.Errorf("Error waiting for the compliance of the batch: %v", err)
+			} else {
+				klog.V(2).Infof(
+					"The policies have a compliance state %.2f seconds after the start of the batch (%.2f policies/s)",
+					timeToCompliance.Seconds(),
+					float64(stats.created)/timeToCompliance.Seconds(),
+				)
+			}
+		} else {
+			// sleep for the remainder of perBatchSleep
+			bonusTime := time.Duration(perBatchSleep)*time.Minute - elapsed
+
+			klog.V(2).Infof(
+				"%d policies created in %.2f seconds! Waiting an additional %.2f seconds for policies to process...\n",
+				stats.created,
+				elapsed.Seconds(),
+				bonusTime.Seconds(),
+			)
+
+			time.Sleep(bonusTime)
+		}
+
+		batchEnd := time.Now()
+		allMetrics := getMetrics(ctx, source, metricsWindow(start), createdPlcs)
+		allMetrics.timeToCompliance = timeToCompliance
+		allMetrics.creation = stats
 
 		tableData = append(tableData, allMetrics)
+		seriesData = append(seriesData, getSeries(ctx, source, start, batchEnd, createdPlcs))
 	}
 
-	printTable(tableData)
+	printTable(source.profile(), tableData)
 
 	wd, err := os.Getwd()
 	if err != nil {
@@ -457,7 +376,16 @@ func main() {
 		klog.Errorf("Error creating output directory: %s", err)
 	}
 
-	exportTable(tableData, path.Join(performanceDir, "output", outputFilename))
+	exportTable(source.profile(), tableData, path.Join(performanceDir, "output", outputFilename))
+
+	outputBase := path.Join(performanceDir, "output", strings.TrimSuffix(outputFilename, ".csv"))
+
+	exportSeries(seriesData, outputBase+"-series.csv")
+	exportJSON(source.profile(), tableData, seriesData, outputBase+".json")
+
+	if htmlReport {
+		exportReport(source.profile(), tableData, seriesData, outputBase+".html")
+	}
 
 	klog.Info("Performance test completed! Cleaning up...")
 
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"sigs.k8s.io/yaml"
+)
+
+// defaultProfiles are the query profiles of the Prometheus metrics sources, named after them.
+//
+//go:embed profiles/*.yaml
+var defaultProfiles embed.FS
+
+// The aggregations of the series of the result of a query.
+const (
+	aggregationSum   = "sum"
+	aggregationAvg   = "avg"
+	aggregationMax   = "max"
+	aggregationMin   = "min"
+	aggregationFirst = "first"
+)
+
+var columnNameRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// queryProfile is the set of metrics gathered after each batch of policies, which are the columns of
+// the results, and the time series recorded during each batch.
+type queryProfile struct {
+	Queries []profileQuery `json:"queries"`
+	// Series are queried over the time range of each batch, to show the spikes hidden by the
+	// aggregations over the window of the Queries.
+	Series []profileQuery `json:"series,omitempty"`
+}
+
+// profileQuery is a metric of a queryProfile.
+type profileQuery struct {
+	// Name is the column of the metric in the CSV.
+	Name string `json:"name"`
+	// Description is the header of the metric in the printed table, which defaults to the name.
+	Description string `json:"description,omitempty"`
+	// Query is the PromQL template of the metric, in which {{ .Window }} is the time waited after
+	// each batch, like 20m. The Series are instant vector queries evaluated at each step of the
+	// range, like the inner expression of an avg_over_time of the Queries.
+	Query string `json:"query,omitempty"`
+	// Unit of the metric, like cores or MB. The printed tables group the metrics by unit.
+	Unit string `json:"unit,omitempty"`
+	// Aggregation combines the series of the result: sum (the default), avg, max, min, or first.
+	Aggregation string `json:"aggregation,omitempty"`
+
+	template *template.Template
+}
+
+// queryWindow is the data of the templates of the queries.
+type queryWindow struct {
+	Window string
+}
+
+// loadQueryProfile reads the query profile at the path, or the default profile of the metrics
+// source if the path is empty.
+func loadQueryProfile(path string, source string) (*queryProfile, error) {
+	var data []byte
+	var err error
+
+	if path == "" {
+		data, err = defaultProfiles.ReadFile("profiles/" + defaultProfileName(source) + ".yaml")
+	} else {
+		data, err = os.ReadFile(path) // #nosec G304 -- the file is provided by the user
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("error reading the query profile: %w", err)
+	}
+
+	profile, err := parseQueryProfile(data)
+	if err != nil {
+		if path == "" {
+			path = "of the " + source + " source"
+		}
+
+		return nil, fmt.Errorf("invalid query profile %s: %w", path, err)
+	}
+
+	return profile, nil
+}
+
+// defaultProfileName returns the name of the embedded profile of the metrics source.
+func defaultProfileName(source string) string {
+	if source == sourceThanos {
+		return "openshift"
+	}
+
+	return source
+}
+
+// parseQueryProfile decodes and validates a query profile.
+func parseQueryProfile(data []byte) (*queryProfile, error) {
+	profile := &queryProfile{}
+
+	if err := yaml.UnmarshalStrict(data, profile); err != nil {
+		return nil, err
+	}
+
+	if len(profile.Queries) == 0 {
+		return nil, errors.New("at least one query is required")
+	}
+
+	if err := parseQueries("queries", profile.Queries); err != nil {
+		return nil, err
+	}
+
+	if err := parseQueries("series", profile.Series); err != nil {
+		return nil, err
+	}
+
+	return profile, nil
+}
+
+// parseQueries validates the queries of the field of a profile, and parses their templates.
+func parseQueries(field string, queries []profileQuery) error {
+	names := map[string]bool{}
+
+	for i := range queries {
+		query := &queries[i]
+
+		if !columnNameRegex.MatchString(query.Name) {
+			return fmt.Errorf("%s[%d]: the name %q must be a valid column name", field, i, query.Name)
+		}
+
+		if names[query.Name] {
+			return fmt.Errorf("%s[%d]: the name %s is not unique", field, i, query.Name)
+		}
+
+		names[query.Name] = true
+
+		if query.Query == "" {
+			return fmt.Errorf("%s[%d]: the query is required", field, i)
+		}
+
+		var err error
+
+		query.template, err = template.New(query.Name).Option("missingkey=error").Parse(query.Query)
+		if err != nil {
+			return fmt.Errorf("%s[%d]: invalid query template: %w", field, i, err)
+		}
+
+		switch query.Aggregation {
+		case "":
+			query.Aggregation = aggregationSum
+		case aggregationSum, aggregationAvg, aggregationMax, aggregationMin, aggregationFirst:
+		default:
+			return fmt.Errorf(
+				"%s[%d]: unknown aggregation %s, expected one of %s, %s, %s, %s, or %s", field, i, query.Aggregation,
+				aggregationSum, aggregationAvg, aggregationMax, aggregationMin, aggregationFirst,
+			)
+		}
+	}
+
+	return nil
+}
+
+// header returns the header of the metric in the printed table.
+func (q profileQuery) header() string {
+	if q.Description != "" {
+		return q.Description
+	}
+
+	return q.Name
+}
+
+// render returns the PromQL of the metric for the window.
+func (q profileQuery) render(window time.Duration) (string, error) {
+	builder := &strings.Builder{}
+
+	if err := q.template.Execute(builder, queryWindow{Window: model.Duration(window).String()}); err != nil {
+		return "", fmt.Errorf("error rendering the query %s: %w", q.Name, err)
+	}
+
+	return builder.String(), nil
+}
+
+// aggregate combines the values of the series of the result of the query.
+func (q profileQuery) aggregate(values []float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+
+	result := values[0]
+
+	for _, value := range values[1:] {
+		switch q.Aggregation {
+		case aggregationMax:
+			result = max(result, value)
+		case aggregationMin:
+			result = min(result, value)
+		case aggregationFirst:
+		default:
+			result += value
+		}
+	}
+
+	if q.Aggregation == aggregationAvg {
+		result /= float64(len(values))
+	}
+
+	return result
+}
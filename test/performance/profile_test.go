@@ -0,0 +1,87 @@
+package main
+
+import (
+	"io/fs"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Test the query profiles", func() {
+	It("Parses the embedded profiles", func() {
+		files, err := fs.Glob(defaultProfiles, "profiles/*.yaml")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(files).ToNot(BeEmpty())
+
+		for _, file := range files {
+			data, err := defaultProfiles.ReadFile(file)
+			Expect(err).ToNot(HaveOccurred())
+
+			profile, err := parseQueryProfile(data)
+			Expect(err).ToNot(HaveOccurred(), "invalid profile %s", file)
+
+			for _, query := range append(profile.Queries, profile.Series...) {
+				_, err := query.render(20 * time.Minute)
+				Expect(err).ToNot(HaveOccurred(), "invalid query %s of the profile %s", query.Name, file)
+			}
+		}
+	})
+
+	DescribeTable("Loads the default profile of the Prometheus metrics sources",
+		func(source string) {
+			profile, err := loadQueryProfile("", source)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(profile.Queries).ToNot(BeEmpty())
+		},
+		Entry("thanos", sourceThanos),
+		Entry("prometheus", sourcePrometheus),
+	)
+
+	It("Renders the window in the queries and defaults the aggregation", func() {
+		profile, err := parseQueryProfile([]byte(`queries:
+- name: avg_cpu
+  query: avg_over_time(cpu[{{ .Window }}:30s])
+`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(profile.Queries[0].Aggregation).To(Equal(aggregationSum))
+		Expect(profile.Queries[0].header()).To(Equal("avg_cpu"))
+
+		query, err := profile.Queries[0].render(90 * time.Second)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(query).To(Equal("avg_over_time(cpu[1m30s:30s])"))
+	})
+
+	DescribeTable("Rejects the invalid profiles",
+		func(profile string, expected string) {
+			_, err := parseQueryProfile([]byte(profile))
+			Expect(err).To(MatchError(ContainSubstring(expected)))
+		},
+		Entry("without queries", "series: []", "at least one query is required"),
+		Entry("with an unknown field", "queries: [{name: cpu, query: cpu, window: 1m}]", `unknown field "window"`),
+		Entry("with an invalid column name", "queries: [{name: cpu usage, query: cpu}]",
+			`queries[0]: the name "cpu usage" must be a valid column name`),
+		Entry("with a duplicate name", "queries: [{name: cpu, query: cpu}, {name: cpu, query: cpu}]",
+			"queries[1]: the name cpu is not unique"),
+		Entry("without a query", "queries: [{name: cpu}]", "queries[0]: the query is required"),
+		Entry("with an invalid template", "queries: [{name: cpu, query: '{{ .Window'}]",
+			"queries[0]: invalid query template"),
+		Entry("with an unknown aggregation", "queries: [{name: cpu, query: cpu, aggregation: median}]",
+			"queries[0]: unknown aggregation median"),
+		Entry("with an invalid series", "queries: [{name: cpu, query: cpu}]\nseries: [{name: cpu}]",
+			"series[0]: the query is required"),
+	)
+
+	DescribeTable("Aggregates the values of the series",
+		func(aggregation string, expected float64) {
+			query := profileQuery{Aggregation: aggregation}
+
+			Expect(query.aggregate([]float64{2, 6, 1})).To(Equal(expected))
+		},
+		Entry("sum", aggregationSum, 9.0),
+		Entry("avg", aggregationAvg, 3.0),
+		Entry("max", aggregationMax, 6.0),
+		Entry("min", aggregationMin, 1.0),
+		Entry("first", aggregationFirst, 2.0),
+	)
+})
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/stolostron/governance-policy-framework/test/common/kubeclient"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog"
+)
+
+// The modes selectable with the --wait-for flag, which decide when the metrics of a batch are
+// collected.
+const (
+	// waitForSleep waits the --sleep minutes after each batch.
+	waitForSleep = "sleep"
+	// waitForCompliance waits until all the policies created so far report a compliance state.
+	waitForCompliance = "compliance"
+)
+
+// policySelector selects the root policies created by the test, and not their replicas in the
+// cluster namespaces.
+const policySelector = "grc-test=config-policy-performance,!policy.open-cluster-management.io/root-policy"
+
+// gvrPolicy is the resource of the root policies.
+var gvrPolicy = schema.GroupVersionResource{
+	Group:    "policy.open-cluster-management.io",
+	Version:  "v1",
+	Resource: "policies",
+}
+
+// minMetricsWindow is the shortest window the metrics are collected over, so that the queries of the
+// profiles, which sample every 30 seconds, and the scrapers have at least a value.
+const minMetricsWindow = time.Minute
+
+// complianceWaiter polls the root policies of the test until they report a compliance state.
+type complianceWaiter struct {
+	client       dynamic.Interface
+	pollInterval time.Duration
+	timeout      time.Duration
+}
+
+func newComplianceWaiter(pollInterval, timeout time.Duration) (*complianceWaiter, error) {
+	config, err := kubeclient.LoadConfig("", "", "")
+	if err != nil {
+		return nil, fmt.Errorf("error loading the kubeconfig: %w", err)
+	}
+
+	kubeclient.DefaultClientOptions().Apply(config)
+
+	client, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("error creating client: %w", err)
+	}
+
+	return &complianceWaiter{client: client, pollInterval: pollInterval, timeout: timeout}, nil
+}
+
+// wait returns the time from the start of the batch until the expected number of policies report a
+// compliance state, or an error if they don't before the timeout.
+func (w *complianceWaiter) wait(ctx context.Context, start time.Time, expected int) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, w.timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		compliant, err := w.countCompliant(ctx)
+		if err != nil {
+			klog.Warningf("Error counting the policies with a compliance state: %v", err)
+		} else {
+			klog.V(2).Infof("%d/%d policies have a compliance state", compliant, expected)
+
+			if compliant >= expected {
+				return time.Since(start), nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return 0, fmt.Errorf(
+				"the policies didn't all report a compliance state within %s: %w", w.timeout, ctx.Err(),
+			)
+		case <-ticker.C:
+		}
+	}
+}
+
+// countCompliant returns the number of root policies of the test which are Compliant or NonCompliant.
+func (w *complianceWaiter) countCompliant(ctx context.Context) (int, error) {
+	policies, err := w.client.Resource(gvrPolicy).List(ctx, metav1.ListOptions{LabelSelector: policySelector})
+	if err != nil {
+		return 0, fmt.Errorf("error listing the policies: %w", err)
+	}
+
+	count := 0
+
+	for _, policy := range policies.Items {
+		compliant, _, _ := unstructured.NestedString(policy.Object, "status", "compliant")
+		if compliant == "Compliant" || compliant == "NonCompliant" {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// metricsWindow returns the window to collect the metrics of a batch over, which started at the
+// given time.
+func metricsWindow(start time.Time) time.Duration {
+	return max(time.Since(start).Round(time.Second), minMetricsWindow)
+}
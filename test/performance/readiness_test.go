@@ -0,0 +1,20 @@
+package main
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Test the readiness of the batches", func() {
+	DescribeTable("Returns the window of the metrics of a batch",
+		func(elapsed time.Duration, expected time.Duration) {
+			Expect(metricsWindow(time.Now().Add(-elapsed))).To(Equal(expected))
+		},
+		Entry("shorter than the minimum", 10*time.Second, minMetricsWindow),
+		Entry("of a batch which just started", time.Duration(0), minMetricsWindow),
+		Entry("rounded to the second", 5*time.Minute+30*time.Second+200*time.Millisecond, 5*time.Minute+30*time.Second),
+		Entry("of a long wait", 2*time.Hour, 2*time.Hour),
+	)
+})
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/klog"
+)
+
+// The dimensions of the charts of the HTML report, in pixels.
+const (
+	chartWidth   = 960
+	chartHeight  = 280
+	chartPadding = 50
+)
+
+// reportTemplate is a self-contained page, with the charts drawn as inline SVG, so that the report
+// can be opened offline and attached to an issue.
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Config Policy Controller performance</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: right; }
+svg { display: block; margin-bottom: 2em; }
+.axis { stroke: #333; }
+.batch { stroke: #999; stroke-dasharray: 4 4; }
+.series { fill: none; stroke: #1f77b4; stroke-width: 1.5; }
+text { font-size: 11px; }
+</style>
+</head>
+<body>
+<h1>Config Policy Controller performance</h1>
+<h2>Summary</h2>
+<table>
+<tr>{{ range .Headers }}<th>{{ . }}</th>{{ end }}</tr>
+{{ range .Rows }}<tr>{{ range . }}<td>{{ . }}</td>{{ end }}</tr>
+{{ end }}</table>
+{{ range .Charts }}{{ $chart := . }}<h2>{{ .Title }}</h2>
+<svg width="{{ .Width }}" height="{{ .Height }}" xmlns="http://www.w3.org/2000/svg">
+<line class="axis" x1="{{ .Left }}" y1="{{ .Bottom }}" x2="{{ .Right }}" y2="{{ .Bottom }}"/>
+<line class="axis" x1="{{ .Left }}" y1="{{ .Top }}" x2="{{ .Left }}" y2="{{ .Bottom }}"/>
+<text x="{{ .Left }}" y="{{ .Top }}" text-anchor="end" dx="-4">{{ .YMax }}</text>
+<text x="{{ .Left }}" y="{{ .Bottom }}" text-anchor="end" dx="-4">0</text>
+<text x="{{ .Left }}" y="{{ .Bottom }}" dy="16">{{ .XStart }}</text>
+<text x="{{ .Right }}" y="{{ .Bottom }}" dy="16" text-anchor="end">{{ .XEnd }}</text>
+{{ range .Batches }}<line class="batch" x1="{{ .X }}" y1="{{ $chart.Top }}" x2="{{ .X }}" y2="{{ $chart.Bottom }}"/>
+<text x="{{ .X }}" y="{{ $chart.Top }}" dx="4" dy="10">{{ .Label }}</text>
+{{ end }}{{ range .Lines }}<polyline class="series" points="{{ . }}"/>
+{{ end }}</svg>
+{{ end }}</body>
+</html>
+`))
+
+// reportData is the data of the reportTemplate.
+type reportData struct {
+	Headers []string
+	Rows    [][]string
+	Charts  []chart
+}
+
+// chart is the SVG chart of a series of the profile over all the batches.
+type chart struct {
+	Title                    string
+	Width, Height            int
+	Left, Right, Top, Bottom int
+	YMax                     string
+	XStart, XEnd             string
+	Batches                  []chartBatch
+	// Lines are the points attributes of the polylines of the series, one per batch.
+	Lines []string
+}
+
+// chartBatch marks the start of the creation of a batch on a chart.
+type chartBatch struct {
+	X     float64
+	Label string
+}
+
+// export the summary table and a chart per series of the profile to an html file
+func exportReport(profile *queryProfile, data []metricData, batches []batchSeries, filename string) {
+	report := reportData{Headers: []string{"time", "# policies"}}
+
+	if hasTimeToCompliance(data) {
+		report.Headers = append(report.Headers, "compliant after (s)")
+	}
+
+	for _, query := range profile.Queries {
+		header := query.header()
+		if query.Unit != "" {
+			header += " (" + query.Unit + ")"
+		}
+
+		report.Headers = append(report.Headers, header)
+	}
+
+	for _, entry := range data {
+		row := []string{entry.timestamp, strconv.Itoa(entry.numPolicies)}
+
+		if hasTimeToCompliance(data) {
+			row = append(row, entry.timeToComplianceColumn())
+		}
+
+		for _, query := range profile.Queries {
+			row = append(row, fmt.Sprintf("%.5f", entry.values[query.Name]))
+		}
+
+		report.Rows = append(report.Rows, row)
+	}
+
+	if len(batches) != 0 {
+		for _, query := range profile.Series {
+			report.Charts = append(report.Charts, newChart(query, batches))
+		}
+	}
+
+	f, err := os.Create(filename)
+	if err != nil {
+		klog.Exitf("Error: failed to create %s; %s", filename, err)
+	}
+
+	defer func() {
+		if cerr := f.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	if err := reportTemplate.Execute(f, report); err != nil {
+		klog.Exitf("Error writing the report to file; %s", err)
+	}
+}
+
+// newChart returns the chart of the series of the query over the time range of the batches.
+func newChart(query profileQuery, batches []batchSeries) chart {
+	c := chart{
+		Title:  query.header(),
+		Width:  chartWidth,
+		Height: chartHeight,
+		Left:   chartPadding,
+		Right:  chartWidth - chartPadding,
+		Top:    chartPadding / 2,
+		Bottom: chartHeight - chartPadding,
+	}
+
+	if query.Unit != "" {
+		c.Title += " (" + query.Unit + ")"
+	}
+
+	start := batches[0].Start
+	end := batches[len(batches)-1].End
+	yMax := 0.0
+
+	for _, batch := range batches {
+		for _, series := range batch.Series {
+			if series.Name != query.Name {
+				continue
+			}
+
+			for _, p := range series.Points {
+				yMax = max(yMax, p.Value)
+			}
+		}
+	}
+
+	// Leave some room above the highest point, and avoid dividing by 0 when all the values are 0.
+	if yMax == 0 {
+		yMax = 1
+	} else {
+		yMax *= 1.1
+	}
+
+	c.YMax = strconv.FormatFloat(yMax, 'g', 4, 64)
+	c.XStart = start.Format(time.TimeOnly)
+	c.XEnd = end.Format(time.TimeOnly)
+
+	x := func(t time.Time) float64 {
+		if !end.After(start) {
+			return float64(c.Left)
+		}
+
+		return float64(c.Left) + float64(c.Right-c.Left)*t.Sub(start).Seconds()/end.Sub(start).Seconds()
+	}
+
+	y := func(value float64) float64 {
+		return float64(c.Bottom) - float64(c.Bottom-c.Top)*value/yMax
+	}
+
+	for _, batch := range batches {
+		c.Batches = append(c.Batches, chartBatch{
+			X: x(batch.Start), Label: strconv.Itoa(batch.NumPolicies) + " policies",
+		})
+
+		for _, series := range batch.Series {
+			if series.Name != query.Name || len(series.Points) == 0 {
+				continue
+			}
+
+			points := make([]string, 0, len(series.Points))
+
+			for _, p := range series.Points {
+				points = append(points, fmt.Sprintf("%.1f,%.1f", x(p.Time), y(p.Value)))
+			}
+
+			c.Lines = append(c.Lines, strings.Join(points, " "))
+		}
+	}
+
+	return c
+}
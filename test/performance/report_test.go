@@ -0,0 +1,65 @@
+package main
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Test the HTML report", func() {
+	start := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	query := profileQuery{Name: "cpu", Description: "cpu (controller)", Unit: "cores"}
+
+	// at returns the time the given number of seconds after the start.
+	at := func(seconds int) time.Time {
+		return start.Add(time.Duration(seconds) * time.Second)
+	}
+
+	// batch returns a batch between the times with the series of the query made of the points.
+	batch := func(numPolicies int, startSeconds, endSeconds int, points ...point) batchSeries {
+		return batchSeries{
+			NumPolicies: numPolicies,
+			Start:       at(startSeconds),
+			End:         at(endSeconds),
+			Series: []timeSeries{
+				{Name: "cpu", Points: points},
+				{Name: "other", Points: []point{{Time: at(startSeconds), Value: 100}}},
+			},
+		}
+	}
+
+	It("Labels the chart with the query and the time range", func() {
+		c := newChart(query, []batchSeries{batch(0, 0, 60), batch(100, 60, 120)})
+
+		Expect(c.Title).To(Equal("cpu (controller) (cores)"))
+		Expect(c.XStart).To(Equal("15:04:05"))
+		Expect(c.XEnd).To(Equal("15:06:05"))
+		Expect(c.Batches).To(Equal([]chartBatch{{X: 50, Label: "0 policies"}, {X: 480, Label: "100 policies"}}))
+		Expect(c.Lines).To(BeEmpty())
+	})
+
+	DescribeTable("Scales the series to the chart",
+		func(batches []batchSeries, expectedYMax string, expectedLines []string) {
+			c := newChart(query, batches)
+
+			Expect(c.YMax).To(Equal(expectedYMax))
+			Expect(c.Lines).To(Equal(expectedLines))
+		},
+		Entry("with values over several batches",
+			[]batchSeries{
+				batch(0, 0, 60, point{Time: at(0), Value: 1}),
+				batch(100, 60, 120, point{Time: at(60), Value: 10}, point{Time: at(120), Value: 5}),
+			},
+			"11", []string{"50.0,211.4", "480.0,43.6 910.0,136.8"},
+		),
+		Entry("with only zeros",
+			[]batchSeries{batch(0, 0, 60, point{Time: at(0)}, point{Time: at(30)}, point{Time: at(60)})},
+			"1", []string{"50.0,230.0 480.0,230.0 910.0,230.0"},
+		),
+		Entry("with a single point",
+			[]batchSeries{batch(0, 0, 0, point{Time: at(0), Value: 2})},
+			"2.2", []string{"50.0,43.6"},
+		),
+	)
+})
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"time"
+
+	"k8s.io/klog"
+)
+
+// point is a value of a timeSeries.
+type point struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+// timeSeries is the values of a series of the queryProfile during a batch.
+type timeSeries struct {
+	Name   string  `json:"name"`
+	Unit   string  `json:"unit,omitempty"`
+	Points []point `json:"points"`
+}
+
+// batchSeries are the time series of a batch of policies, from the start of its creation to the
+// end of the wait after it. The first batch is the baseline before any policy is created.
+type batchSeries struct {
+	NumPolicies int          `json:"numPolicies"`
+	Start       time.Time    `json:"start"`
+	End         time.Time    `json:"end"`
+	Series      []timeSeries `json:"series"`
+}
+
+// results is the content of the JSON export of the results.
+type results struct {
+	Summary []summaryEntry `json:"summary"`
+	Batches []batchSeries  `json:"batches"`
+}
+
+// summaryEntry is a row of the summary table. The metrics without a value are null.
+type summaryEntry struct {
+	Time        string `json:"time"`
+	NumPolicies int    `json:"numPolicies"`
+	// TimeToCompliance is in seconds, and omitted if it wasn't measured.
+	TimeToCompliance *float64 `json:"timeToCompliance,omitempty"`
+	// Creation is omitted for the baseline.
+	Creation *creationSummary    `json:"creation,omitempty"`
+	Values   map[string]*float64 `json:"values"`
+}
+
+// getSeries returns the time series of the batch from the metrics source. The series only add
+// details to the summary table, so an error is logged instead of ending the test.
+func getSeries(ctx context.Context, source metricsSource, start, end time.Time, numPolicies int) batchSeries {
+	batch := batchSeries{NumPolicies: numPolicies, Start: start, End: end}
+
+	if len(source.profile().Series) == 0 {
+		return batch
+	}
+
+	series, err := source.collectSeries(ctx, start, end)
+	if err != nil {
+		klog.Errorf("Error collecting the time series of the batch: %v\n", err)
+
+		return batch
+	}
+
+	batch.Series = series
+
+	return batch
+}
+
+// isFinite returns whether the value isn't NaN or infinite, which JSON can't encode.
+func isFinite(value float64) bool {
+	return !math.IsNaN(value) && !math.IsInf(value, 0)
+}
+
+// export the time series of each batch to a csv file, with a row per point
+func exportSeries(batches []batchSeries, filename string) {
+	f, err := os.Create(filename)
+	if err != nil {
+		klog.Exitf("Error: failed to create %s; %s", filename, err)
+	}
+
+	defer func() {
+		if cerr := f.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	w := csv.NewWriter(f)
+	defer w.Flush()
+
+	if err := w.Write([]string{"numPolicies", "series", "unit", "time", "value"}); err != nil {
+		klog.Exitf("Error writing headers to file; %s", err)
+	}
+
+	for _, batch := range batches {
+		for _, series := range batch.Series {
+			for _, p := range series.Points {
+				line := []string{
+					strconv.Itoa(batch.NumPolicies),
+					series.Name,
+					series.Unit,
+					p.Time.Format(time.RFC3339),
+					fmt.Sprintf("%.5f", p.Value),
+				}
+
+				if err := w.Write(line); err != nil {
+					klog.Exitf("Error writing data to file; %s", err)
+				}
+			}
+		}
+	}
+}
+
+// export the summary table and the time series of each batch to a json file
+func exportJSON(profile *queryProfile, data []metricData, batches []batchSeries, filename string) {
+	export := results{Summary: make([]summaryEntry, 0, len(data)), Batches: batches}
+
+	for _, entry := range data {
+		summary := summaryEntry{
+			Time: entry.timestamp, NumPolicies: entry.numPolicies, Values: map[string]*float64{},
+		}
+
+		if entry.creation != nil {
+			summary.Creation = entry.creation.summary()
+		}
+
+		if entry.timeToCompliance != 0 {
+			seconds := entry.timeToCompliance.Seconds()
+			summary.TimeToCompliance = &seconds
+		}
+
+		for _, query := range profile.Queries {
+			summary.Values[query.Name] = nil
+
+			if value := entry.values[query.Name]; isFinite(value) {
+				summary.Values[query.Name] = &value
+			}
+		}
+
+		export.Summary = append(export.Summary, summary)
+	}
+
+	output, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		klog.Exitf("Error encoding the results; %s", err)
+	}
+
+	if err := os.WriteFile(filename, output, 0o644); err != nil {
+		klog.Exitf("Error: failed to write %s; %s", filename, err)
+	}
+}
@@ -14,6 +14,8 @@ import (
 )
 
 func PolicyOrdering(labels ...string) bool {
+	env := DefaultEnvironment
+
 	const (
 		initialPolicyYaml      = "../resources/policy_ordering/dep-policy-initial.yaml"
 		initialPolicyName      = "dep-policy-initial"
@@ -32,11 +34,11 @@ func PolicyOrdering(labels ...string) bool {
 
 	cleanup := func() {
 		By("Cleaning up")
-		DoCleanupPolicy(initialPolicyYaml)
-		DoCleanupPolicy(policyWithDepYaml)
-		DoCleanupPolicy(policyWithExtraDepYaml)
-		DoCleanupPolicy(ignorePendingYaml)
-		DoCleanupPolicy(plcWithDepOnSetYaml)
+		env.DoCleanupPolicy(initialPolicyYaml)
+		env.DoCleanupPolicy(policyWithDepYaml)
+		env.DoCleanupPolicy(policyWithExtraDepYaml)
+		env.DoCleanupPolicy(ignorePendingYaml)
+		env.DoCleanupPolicy(plcWithDepOnSetYaml)
 
 		configmapNames := []string{
 			"dep-initial-cfgmap",
@@ -47,13 +49,13 @@ func PolicyOrdering(labels ...string) bool {
 		}
 
 		for _, name := range configmapNames {
-			_, err := OcManaged("delete", "configmap", name, "-n=default", "--ignore-not-found")
+			_, err := env.OcManaged("delete", "configmap", name, "-n=default", "--ignore-not-found")
 			Expect(err).ToNot(HaveOccurred())
 		}
 
-		_, err := OcHub(
+		_, err := env.OcHub(
 			"delete", "-f", testPolicySetYaml,
-			"-n", UserNamespace, "--ignore-not-found",
+			"-n", env.UserNamespace, "--ignore-not-found",
 		)
 		Expect(err).ToNot(HaveOccurred())
 	}
@@ -61,136 +63,145 @@ func PolicyOrdering(labels ...string) bool {
 	Describe("GRC: [P1][Sev1][policy-grc] Test policy ordering", Ordered, Label(labels...), func() {
 		Describe("Ordering via a dependency on a Policy", Ordered, func() {
 			BeforeAll(func(ctx SpecContext) {
+				env.RecordComplianceTimeline(initialPolicyName, policyWithDepName)
+
 				By("Creating the initial policy to use as a dependency")
-				DoCreatePolicyTest(ctx, initialPolicyYaml, GvrConfigurationPolicy)
-				DoRootComplianceTest(initialPolicyName, policiesv1.NonCompliant)
+				env.DoCreatePolicyTest(ctx, initialPolicyYaml, GvrConfigurationPolicy)
+				env.DoRootComplianceTest(initialPolicyName, policiesv1.NonCompliant)
 
 				By("Creating the policy that depends on the initial policy")
-				DoCreatePolicyTest(ctx, policyWithDepYaml)
+				env.DoCreatePolicyTest(ctx, policyWithDepYaml)
 			})
 			It("Should be pending while the initial policy is non-compliant", func() {
-				DoRootComplianceTest(policyWithDepName, policiesv1.Pending)
+				env.DoRootComplianceTest(policyWithDepName, policiesv1.Pending)
 			})
 			It("Should become active after the initial policy is enforced", func() {
-				EnforcePolicy(initialPolicyName, GvrConfigurationPolicy)
-				DoRootComplianceTest(initialPolicyName, policiesv1.Compliant)
-				DoRootComplianceTest(policyWithDepName, policiesv1.NonCompliant)
+				env.EnforcePolicy(initialPolicyName, GvrConfigurationPolicy)
+				env.DoRootComplianceTest(initialPolicyName, policiesv1.Compliant)
+				env.DoRootComplianceTest(policyWithDepName, policiesv1.NonCompliant)
 			})
 			It("Should become pending again when the initial policy is deleted", func() {
-				DoCleanupPolicy(initialPolicyYaml)
-				DoRootComplianceTest(policyWithDepName, policiesv1.Pending)
+				env.DoCleanupPolicy(initialPolicyYaml)
+				env.DoRootComplianceTest(policyWithDepName, policiesv1.Pending)
 			})
 			It("Should become active when dependencies are removed", func() {
-				_, err := OcHub(
+				_, err := env.OcHub(
 					"patch",
 					"policy", policyWithDepName,
-					"-n", UserNamespace,
+					"-n", env.UserNamespace,
 					"--type=json",
 					"-p", `[{"op": "remove", "path": "/spec/dependencies"}]`,
 				)
 				Expect(err).ToNot(HaveOccurred())
-				DoRootComplianceTest(policyWithDepName, policiesv1.NonCompliant)
+				env.DoRootComplianceTest(policyWithDepName, policiesv1.NonCompliant)
 			})
 			It("Should return to pending when dependencies are restored", func(ctx SpecContext) {
-				DoCreatePolicyTest(ctx, policyWithDepYaml)
-				DoRootComplianceTest(policyWithDepName, policiesv1.Pending)
+				env.DoCreatePolicyTest(ctx, policyWithDepYaml)
+				env.DoRootComplianceTest(policyWithDepName, policiesv1.Pending)
 			})
 			AfterAll(cleanup)
 		})
 		Describe("Ordering via an extraDependency on a ConfigurationPolicy", Ordered, func() {
 			BeforeAll(func(ctx SpecContext) {
+				env.RecordComplianceTimeline(initialPolicyName, policyWithExtraDepName)
+
 				By("Creating the initial policy to use as a dependency")
-				DoCreatePolicyTest(ctx, initialPolicyYaml, GvrConfigurationPolicy)
-				DoRootComplianceTest(initialPolicyName, policiesv1.NonCompliant)
+				env.DoCreatePolicyTest(ctx, initialPolicyYaml, GvrConfigurationPolicy)
+				env.DoRootComplianceTest(initialPolicyName, policiesv1.NonCompliant)
 
 				By("Creating the policy that depends on the initial policy")
-				DoCreatePolicyTest(ctx, policyWithExtraDepYaml)
+				env.DoCreatePolicyTest(ctx, policyWithExtraDepYaml)
 			})
 			It("Should be pending while the initial policy is non-compliant", func() {
-				DoRootComplianceTest(policyWithExtraDepName, policiesv1.Pending)
+				env.DoRootComplianceTest(policyWithExtraDepName, policiesv1.Pending)
 			})
 			It("Should become active after the initial policy is enforced", func() {
-				EnforcePolicy(initialPolicyName, GvrConfigurationPolicy)
-				DoRootComplianceTest(initialPolicyName, policiesv1.Compliant)
-				DoRootComplianceTest(policyWithExtraDepName, policiesv1.NonCompliant)
+				env.EnforcePolicy(initialPolicyName, GvrConfigurationPolicy)
+				env.DoRootComplianceTest(initialPolicyName, policiesv1.Compliant)
+				env.DoRootComplianceTest(policyWithExtraDepName, policiesv1.NonCompliant)
 			})
 			It("Should become pending again when the initial policy is deleted", func() {
-				DoCleanupPolicy(initialPolicyYaml)
-				DoRootComplianceTest(policyWithExtraDepName, policiesv1.Pending)
+				env.DoCleanupPolicy(initialPolicyYaml)
+				env.DoRootComplianceTest(policyWithExtraDepName, policiesv1.Pending)
 			})
 			It("Should become active when extraDependencies are removed", func() {
-				_, err := OcHub(
+				_, err := env.OcHub(
 					"patch",
 					"policy", policyWithExtraDepName,
-					"-n", UserNamespace,
+					"-n", env.UserNamespace,
 					"--type=json",
 					"-p", `[{"op": "remove", "path": "/spec/policy-templates/0/extraDependencies"}]`,
 				)
 				Expect(err).ToNot(HaveOccurred())
-				DoRootComplianceTest(policyWithExtraDepName, policiesv1.NonCompliant)
+				env.DoRootComplianceTest(policyWithExtraDepName, policiesv1.NonCompliant)
 			})
 			It("Should return to pending when extraDependencies are restored", func(ctx SpecContext) {
-				DoCreatePolicyTest(ctx, policyWithExtraDepYaml)
-				DoRootComplianceTest(policyWithExtraDepName, policiesv1.Pending)
+				env.DoCreatePolicyTest(ctx, policyWithExtraDepYaml)
+				env.DoRootComplianceTest(policyWithExtraDepName, policiesv1.Pending)
 			})
 			AfterAll(cleanup)
 		})
 		Describe("IgnorePending should allow policies to be compliant when one template is pending", Ordered, func() {
 			BeforeAll(func(ctx SpecContext) {
+				env.RecordComplianceTimeline(initialPolicyName, ignorePendingName)
+
 				By("Creating the initial policy to use as a dependency")
-				DoCreatePolicyTest(ctx, initialPolicyYaml, GvrConfigurationPolicy)
-				DoRootComplianceTest(initialPolicyName, policiesv1.NonCompliant)
+				env.DoCreatePolicyTest(ctx, initialPolicyYaml, GvrConfigurationPolicy)
+				env.DoRootComplianceTest(initialPolicyName, policiesv1.NonCompliant)
 
 				By("Creating the policy that depends on the initial policy")
-				DoCreatePolicyTest(ctx, ignorePendingYaml)
+				env.DoCreatePolicyTest(ctx, ignorePendingYaml)
 			})
 			It("Should be compliant overall", func() {
-				DoRootComplianceTest(ignorePendingName, policiesv1.Compliant)
+				env.DoRootComplianceTest(ignorePendingName, policiesv1.Compliant)
 			})
 			It("The pending template should not be created", func() {
 				utils.GetWithTimeout(
-					ClientManagedDynamic, GvrConfigurationPolicy, "dep-policy-ignorepending-extra",
-					ClusterNamespace, false, DefaultTimeoutSeconds,
+					env.ClientManagedDynamic, GvrConfigurationPolicy, "dep-policy-ignorepending-extra",
+					env.ClusterNamespace, false, env.DefaultTimeoutSeconds,
 				)
 			})
 			AfterAll(cleanup)
 		})
 		Describe("Ordering via a dependency on a PolicySet", Ordered, func() {
+			BeforeAll(func() {
+				env.RecordComplianceTimeline(testPolicyName, plcWithDepOnSetName)
+			})
 			It("Should create policyset with noncompliant status", func(ctx SpecContext) {
 				By("Creating the initial policy set to use as a dependency")
 
-				_, err := OcHub("apply", "-f", testPolicySetYaml, "-n", UserNamespace)
+				_, err := env.OcHub("apply", "-f", testPolicySetYaml, "-n", env.UserNamespace)
 				Expect(err).ToNot(HaveOccurred())
 
 				rootPolicy := utils.GetWithTimeout(
-					ClientHubDynamic, GvrPolicy, testPolicyName, UserNamespace, true, DefaultTimeoutSeconds,
+					env.ClientHubDynamic, GvrPolicy, testPolicyName, env.UserNamespace, true, env.DefaultTimeoutSeconds,
 				)
 				Expect(rootPolicy).NotTo(BeNil())
 
-				err = PatchPlacement(UserNamespace, testPolicySetName+"-plr")
+				err = env.PatchPlacement(env.UserNamespace, testPolicySetName+"-plr")
 				Expect(err).ToNot(HaveOccurred())
 
-				if ManuallyPatchDecisions {
-					By("Patching " + testPolicySetName + "-plr with decision of cluster " + ClusterNamespaceOnHub)
-					pld, err := CreatePlacementDecision(ctx, UserNamespace, testPolicySetName+"-plr")
+				if env.ManuallyPatchDecisions {
+					By("Patching " + testPolicySetName + "-plr with decision of cluster " + env.ClusterNamespaceOnHub)
+					pld, err := env.CreatePlacementDecision(ctx, env.UserNamespace, testPolicySetName+"-plr")
 					Expect(err).ToNot(HaveOccurred())
 
-					pld.Object["status"] = utils.GeneratePldStatus("", "", ClusterNamespaceOnHub)
-					_, err = ClientHubDynamic.Resource(GvrPlacementDecision).Namespace(UserNamespace).UpdateStatus(
-						ctx, pld, metav1.UpdateOptions{},
-					)
+					pld.Object["status"] = utils.GeneratePldStatus("", "", env.ClusterNamespaceOnHub)
+					_, err = env.ClientHubDynamic.Resource(GvrPlacementDecision).Namespace(env.UserNamespace).
+						UpdateStatus(ctx, pld, metav1.UpdateOptions{})
 					Expect(err).ToNot(HaveOccurred())
 				}
 
-				By("Checking " + testPolicyName + " on managed cluster in ns " + ClusterNamespaceOnHub)
+				By("Checking " + testPolicyName + " on managed cluster in ns " + env.ClusterNamespaceOnHub)
 				managedplc := utils.GetWithTimeout(
-					ClientHubDynamic, GvrPolicy, UserNamespace+"."+testPolicyName, ClusterNamespaceOnHub, true,
-					DefaultTimeoutSeconds,
+					env.ClientHubDynamic, GvrPolicy, env.UserNamespace+"."+testPolicyName, env.ClusterNamespaceOnHub,
+					true, env.DefaultTimeoutSeconds,
 				)
 				Expect(managedplc).NotTo(BeNil())
 
 				plcSet := utils.GetWithTimeout(
-					ClientHubDynamic, GvrPolicySet, testPolicySetName, UserNamespace, true, DefaultTimeoutSeconds,
+					env.ClientHubDynamic, GvrPolicySet, testPolicySetName, env.UserNamespace, true,
+					env.DefaultTimeoutSeconds,
 				)
 				Expect(plcSet).NotTo(BeNil())
 
@@ -200,41 +211,41 @@ func PolicyOrdering(labels ...string) bool {
 
 				Eventually(func() any {
 					rootPlcSet := utils.GetWithTimeout(
-						ClientHubDynamic,
+						env.ClientHubDynamic,
 						GvrPolicySet,
 						testPolicySetName,
-						UserNamespace,
+						env.UserNamespace,
 						true,
-						DefaultTimeoutSeconds,
+						env.DefaultTimeoutSeconds,
 					)
 
 					return rootPlcSet.Object["status"]
-				}, DefaultTimeoutSeconds, 1).Should(utils.SemanticEqual(yamlPlc.Object["status"]))
+				}, env.DefaultTimeoutSeconds, 1).Should(utils.SemanticEqual(yamlPlc.Object["status"]))
 			})
 			It("Should be pending while the initial policy is non-compliant", func(ctx SpecContext) {
 				By("Creating the policy that depends on the initial policy")
-				DoCreatePolicyTest(ctx, plcWithDepOnSetYaml)
-				DoRootComplianceTest(plcWithDepOnSetName, policiesv1.Pending)
+				env.DoCreatePolicyTest(ctx, plcWithDepOnSetYaml)
+				env.DoRootComplianceTest(plcWithDepOnSetName, policiesv1.Pending)
 			})
 			It("Should become active after the initial policy is enforced", func() {
-				EnforcePolicy(testPolicyName, GvrConfigurationPolicy)
-				DoRootComplianceTest(testPolicyName, policiesv1.Compliant)
-				DoRootComplianceTest(plcWithDepOnSetName, policiesv1.NonCompliant)
+				env.EnforcePolicy(testPolicyName, GvrConfigurationPolicy)
+				env.DoRootComplianceTest(testPolicyName, policiesv1.Compliant)
+				env.DoRootComplianceTest(plcWithDepOnSetName, policiesv1.NonCompliant)
 			})
 			It("Should become pending again when the initial policy is deleted", func() {
-				_, err := OcHub(
+				_, err := env.OcHub(
 					"delete", "-f", testPolicySetYaml,
-					"-n", UserNamespace, "--ignore-not-found",
+					"-n", env.UserNamespace, "--ignore-not-found",
 				)
 				Expect(err).ToNot(HaveOccurred())
 
-				_, err = OcManaged(
+				_, err = env.OcManaged(
 					"delete", "pod",
 					"-n", "default",
 					"pod-dne", "--ignore-not-found",
 				)
 				Expect(err).ToNot(HaveOccurred())
-				DoRootComplianceTest(plcWithDepOnSetName, policiesv1.Pending)
+				env.DoRootComplianceTest(plcWithDepOnSetName, policiesv1.Pending)
 			})
 			AfterAll(cleanup)
 		})
@@ -12,6 +12,8 @@ import (
 )
 
 func TemplateSyncErrors(labels ...string) bool {
+	env := DefaultEnvironment
+
 	const (
 		nonexistentPolicyKindYaml string = "../resources/template-sync-errors/pretend-policy-template.yaml"
 		nonexistentPolicyKindName string = "pretend-policy-template"
@@ -22,88 +24,88 @@ func TemplateSyncErrors(labels ...string) bool {
 	Describe("GRC: [P1][Sev1][policy-grc] Test handling template-sync errors", Label(labels...), func() {
 		Describe("Test using a template with a non-existent CRD", Ordered, func() {
 			AfterAll(func() {
-				_, err := OcHub(
+				_, err := env.OcHub(
 					"delete", "-f",
-					nonexistentPolicyKindYaml, "-n", UserNamespace,
+					nonexistentPolicyKindYaml, "-n", env.UserNamespace,
 					"--ignore-not-found",
 				)
 				Expect(err).ToNot(HaveOccurred())
 			})
 			It("Should be noncompliant with a mapping not found status", func(ctx SpecContext) {
-				DoCreatePolicyTest(ctx, nonexistentPolicyKindYaml)
-				DoRootComplianceTest(nonexistentPolicyKindName, policiesv1.NonCompliant)
+				env.DoCreatePolicyTest(ctx, nonexistentPolicyKindYaml)
+				env.DoRootComplianceTest(nonexistentPolicyKindName, policiesv1.NonCompliant)
 
 				Eventually(
-					GetLatestStatusMessage(nonexistentPolicyKindName, 0),
-					DefaultTimeoutSeconds, 1,
+					env.GetLatestStatusMessage(nonexistentPolicyKindName, 0),
+					env.DefaultTimeoutSeconds, 1,
 				).Should(MatchRegexp(".*Mapping not found.*"))
 			})
 			It("Should become compliant when the kind is fixed", func() {
-				_, err := OcHub("patch", "policies.policy.open-cluster-management.io", nonexistentPolicyKindName,
-					"-n", UserNamespace, "--type=json", "-p", `[{
+				_, err := env.OcHub("patch", "policies.policy.open-cluster-management.io", nonexistentPolicyKindName,
+					"-n", env.UserNamespace, "--type=json", "-p", `[{
 						"op":"replace",
 						"path":"/spec/policy-templates/0/objectDefinition/kind",
 						"value":"ConfigurationPolicy"
 					}]`)
 				Expect(err).ToNot(HaveOccurred())
-				DoRootComplianceTest(nonexistentPolicyKindName, policiesv1.Compliant)
+				env.DoRootComplianceTest(nonexistentPolicyKindName, policiesv1.Compliant)
 
 				Eventually(
-					GetLatestStatusMessage(nonexistentPolicyKindName, 0),
-					DefaultTimeoutSeconds, 1,
+					env.GetLatestStatusMessage(nonexistentPolicyKindName, 0),
+					env.DefaultTimeoutSeconds, 1,
 				).ShouldNot(MatchRegexp(".*Mapping not found.*"))
 			})
 			It("Should become noncompliant when the original policy is restored", func() {
-				_, err := OcHub("apply", "-f", nonexistentPolicyKindYaml, "-n", UserNamespace)
+				_, err := env.OcHub("apply", "-f", nonexistentPolicyKindYaml, "-n", env.UserNamespace)
 				Expect(err).ToNot(HaveOccurred())
-				DoRootComplianceTest(nonexistentPolicyKindName, policiesv1.NonCompliant)
+				env.DoRootComplianceTest(nonexistentPolicyKindName, policiesv1.NonCompliant)
 
 				Eventually(
-					GetLatestStatusMessage(nonexistentPolicyKindName, 0),
-					DefaultTimeoutSeconds, 1,
+					env.GetLatestStatusMessage(nonexistentPolicyKindName, 0),
+					env.DefaultTimeoutSeconds, 1,
 				).Should(MatchRegexp(".*Mapping not found.*"))
 			})
 		})
 		Describe("Test using a template with an invalid CR", Ordered, func() {
 			AfterAll(func() {
-				_, err := OcHub(
+				_, err := env.OcHub(
 					"delete", "-f", invalidCRPolicyYaml,
-					"-n", UserNamespace, "--ignore-not-found",
+					"-n", env.UserNamespace, "--ignore-not-found",
 				)
 				Expect(err).ToNot(HaveOccurred())
 			})
 			It("Should be noncompliant and report the reason the CR is invalid", func(ctx SpecContext) {
-				DoCreatePolicyTest(ctx, invalidCRPolicyYaml)
-				DoRootComplianceTest(invalidCRPolicyName, policiesv1.NonCompliant)
+				env.DoCreatePolicyTest(ctx, invalidCRPolicyYaml)
+				env.DoRootComplianceTest(invalidCRPolicyName, policiesv1.NonCompliant)
 
 				Eventually(
-					GetLatestStatusMessage(invalidCRPolicyName, 0),
-					DefaultTimeoutSeconds, 1,
+					env.GetLatestStatusMessage(invalidCRPolicyName, 0),
+					env.DefaultTimeoutSeconds, 1,
 				).Should(MatchRegexp(".*Failed to create.*Unsupported value.*"))
 			})
 			It("Should become compliant when the spec is fixed", func() {
-				_, err := OcHub("patch", "policies.policy.open-cluster-management.io", invalidCRPolicyName,
-					"-n", UserNamespace, "--type=json", "-p", `[{
+				_, err := env.OcHub("patch", "policies.policy.open-cluster-management.io", invalidCRPolicyName,
+					"-n", env.UserNamespace, "--type=json", "-p", `[{
 						"op":"replace",
 						"path":"/spec/policy-templates/0/objectDefinition/spec/pruneObjectBehavior",
 						"value":"None"
 					}]`)
 				Expect(err).ToNot(HaveOccurred())
-				DoRootComplianceTest(invalidCRPolicyName, policiesv1.Compliant)
+				env.DoRootComplianceTest(invalidCRPolicyName, policiesv1.Compliant)
 
 				Eventually(
-					GetLatestStatusMessage(invalidCRPolicyName, 0),
-					DefaultTimeoutSeconds, 1,
+					env.GetLatestStatusMessage(invalidCRPolicyName, 0),
+					env.DefaultTimeoutSeconds, 1,
 				).ShouldNot(MatchRegexp(".*Failed to create.*Unsupported value.*"))
 			})
 			It("Should become noncompliant when the original policy is restored", func() {
-				_, err := OcHub("apply", "-f", invalidCRPolicyYaml, "-n", UserNamespace)
+				_, err := env.OcHub("apply", "-f", invalidCRPolicyYaml, "-n", env.UserNamespace)
 				Expect(err).ToNot(HaveOccurred())
-				DoRootComplianceTest(invalidCRPolicyName, policiesv1.NonCompliant)
+				env.DoRootComplianceTest(invalidCRPolicyName, policiesv1.NonCompliant)
 
 				Eventually(
-					GetLatestStatusMessage(invalidCRPolicyName, 0),
-					DefaultTimeoutSeconds, 1,
+					env.GetLatestStatusMessage(invalidCRPolicyName, 0),
+					env.DefaultTimeoutSeconds, 1,
 				).Should(MatchRegexp(".*Failed to update.*Unsupported value.*"))
 			})
 		})